@@ -92,6 +92,11 @@ func createDirectChannel(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !app.SessionCanSeeUsers(c.Session, []string{userId}) {
+		c.SetPermissionError(model.PERMISSION_VIEW_MEMBERS)
+		return
+	}
+
 	if sc, err := app.CreateDirectChannel(c.Session.UserId, userId); err != nil {
 		c.Err = err
 		return
@@ -124,6 +129,11 @@ func createGroupChannel(c *Context, w http.ResponseWriter, r *http.Request) {
 		userIds = append(userIds, c.Session.UserId)
 	}
 
+	if !app.SessionCanSeeUsers(c.Session, userIds) {
+		c.SetPermissionError(model.PERMISSION_VIEW_MEMBERS)
+		return
+	}
+
 	if sc, err := app.CreateGroupChannel(userIds); err != nil {
 		c.Err = err
 		return
@@ -611,7 +621,7 @@ func getPinnedPosts(c *Context, w http.ResponseWriter, r *http.Request) {
 		posts = result.Data.(*model.PostList)
 	}
 
-	w.Write([]byte(posts.ToJson()))
+	w.Write([]byte(app.PreparePostListForUser(posts, c.Session.UserId).ToJson()))
 }
 
 func addMember(c *Context, w http.ResponseWriter, r *http.Request) {
@@ -759,6 +769,11 @@ func searchMoreChannels(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !app.SessionHasPermissionToTeam(c.Session, c.TeamId, model.PERMISSION_LIST_TEAM_CHANNELS) {
+		c.SetPermissionError(model.PERMISSION_LIST_TEAM_CHANNELS)
+		return
+	}
+
 	if channels, err := app.SearchChannelsUserNotIn(c.TeamId, c.Session.UserId, props.Term); err != nil {
 		c.Err = err
 		return
@@ -777,6 +792,11 @@ func autocompleteChannels(c *Context, w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	if !app.SessionHasPermissionToTeam(c.Session, c.TeamId, model.PERMISSION_LIST_TEAM_CHANNELS) {
+		c.SetPermissionError(model.PERMISSION_LIST_TEAM_CHANNELS)
+		return
+	}
+
 	if channels, err := app.SearchChannels(c.TeamId, term); err != nil {
 		c.Err = err
 		return
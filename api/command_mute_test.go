@@ -0,0 +1,56 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+)
+
+func TestMuteCommand(t *testing.T) {
+	th := Setup().InitBasic()
+	Client := th.BasicClient
+	channel := th.BasicChannel
+
+	Client.Must(Client.Command(channel.Id, "/mute"))
+
+	if member, err := app.GetChannelMember(channel.Id, th.BasicUser.Id); err != nil {
+		t.Fatal(err)
+	} else if !member.IsMuted() {
+		t.Fatal("channel should have been muted")
+	}
+
+	Client.Must(Client.Command(channel.Id, "/mute"))
+
+	if member, err := app.GetChannelMember(channel.Id, th.BasicUser.Id); err != nil {
+		t.Fatal(err)
+	} else if member.IsMuted() {
+		t.Fatal("channel should have been unmuted")
+	}
+
+	channel2 := th.CreateChannel(Client, th.BasicTeam)
+
+	Client.Must(Client.Command(channel.Id, "/mute ~"+channel2.Name+" 1h"))
+
+	if member, err := app.GetChannelMember(channel2.Id, th.BasicUser.Id); err != nil {
+		t.Fatal(err)
+	} else if !member.IsMuted() {
+		t.Fatal("other channel should have been muted")
+	} else if member.NotifyProps[model.MUTE_UNTIL_NOTIFY_PROP] == "0" {
+		t.Fatal("mute should have an expiry")
+	}
+
+	if member, err := app.GetChannelMember(channel.Id, th.BasicUser.Id); err != nil {
+		t.Fatal(err)
+	} else if member.IsMuted() {
+		t.Fatal("current channel shouldn't have been muted")
+	}
+
+	rs := Client.Must(Client.Command(channel.Id, "/mute ~"+model.NewId())).Data.(*model.CommandResponse)
+	if rs.Text == "" {
+		t.Fatal("should have returned an error for a missing channel")
+	}
+}
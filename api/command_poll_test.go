@@ -0,0 +1,34 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestPollCommand(t *testing.T) {
+	th := Setup().InitBasic()
+	Client := th.BasicClient
+	channel := th.BasicChannel
+
+	Client.Must(Client.Command(channel.Id, `/poll "Where should we eat?" Pizza "Thai food" --anonymous`))
+
+	posts := Client.Must(Client.GetPosts(channel.Id, 0, 2, "")).Data.(*model.PostList)
+	post := posts.Posts[posts.Order[0]]
+
+	if post.Type != model.POST_POLL || post.Message != "Where should we eat?" {
+		t.Fatal("should have created a poll")
+	}
+
+	if poll := model.PollFromPost(post); poll == nil || len(poll.Options) != 2 || poll.Options[1].Text != "Thai food" || !poll.Anonymous {
+		t.Fatal("created the wrong poll")
+	}
+
+	r1 := Client.Must(Client.Command(channel.Id, `/poll "Where should we eat?"`)).Data.(*model.CommandResponse)
+	if r1.Text == "" || r1.ResponseType != model.COMMAND_RESPONSE_TYPE_EPHEMERAL {
+		t.Fatal("should have explained how to use the command")
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+)
+
+func TestRemindCommand(t *testing.T) {
+	th := Setup().InitBasic()
+	Client := th.BasicClient
+	channel := th.BasicChannel
+
+	post := th.CreatePost(Client, channel)
+
+	r1 := Client.Must(Client.Command(channel.Id, "/remind me "+post.Id+" in 30 minutes")).Data.(*model.CommandResponse)
+	if r1.Text == "" || r1.ResponseType != model.COMMAND_RESPONSE_TYPE_EPHEMERAL {
+		t.Fatal("should have confirmed the reminder")
+	}
+
+	Client.Must(Client.Command(channel.Id, "/remind http://localhost:8065/"+th.BasicTeam.Name+"/pl/"+post.Id+" tomorrow at 9am"))
+
+	if reminders, err := app.GetPostRemindersForUser(th.BasicUser.Id); err != nil {
+		t.Fatal(err)
+	} else if len(reminders) != 2 {
+		t.Fatal("should have created the reminders")
+	} else if reminders[0].PostId != post.Id || reminders[1].PostId != post.Id {
+		t.Fatal("created reminders for the wrong post")
+	}
+
+	Client.Must(Client.Command(channel.Id, "/remind me in 30 minutes"))
+	Client.Must(Client.Command(channel.Id, "/remind me "+post.Id+" sometime"))
+
+	if reminders, err := app.GetPostRemindersForUser(th.BasicUser.Id); err != nil {
+		t.Fatal(err)
+	} else if len(reminders) != 2 {
+		t.Fatal("shouldn't have created reminders without a post or a time")
+	}
+}
@@ -0,0 +1,41 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+)
+
+func TestScheduleCommand(t *testing.T) {
+	th := Setup().InitBasic()
+	Client := th.BasicClient
+	channel := th.BasicChannel
+
+	r1 := Client.Must(Client.Command(channel.Id, "/schedule in 2 hours  hello **world**")).Data.(*model.CommandResponse)
+	if r1.Text == "" || r1.ResponseType != model.COMMAND_RESPONSE_TYPE_EPHEMERAL {
+		t.Fatal("should have confirmed the scheduled post")
+	}
+
+	if scheduledPosts, err := app.GetScheduledPostsForUser(th.BasicUser.Id); err != nil {
+		t.Fatal(err)
+	} else if len(scheduledPosts) != 1 {
+		t.Fatal("should have scheduled a post")
+	} else if scheduledPost := scheduledPosts[0]; scheduledPost.ChannelId != channel.Id || scheduledPost.Message != "hello **world**" {
+		t.Fatal("scheduled the wrong post")
+	} else if scheduledPost.ScheduledAt <= model.GetMillis() {
+		t.Fatal("should have scheduled the post for later")
+	}
+
+	Client.Must(Client.Command(channel.Id, "/schedule whenever hello"))
+	Client.Must(Client.Command(channel.Id, "/schedule tomorrow"))
+
+	if scheduledPosts, err := app.GetScheduledPostsForUser(th.BasicUser.Id); err != nil {
+		t.Fatal(err)
+	} else if len(scheduledPosts) != 1 {
+		t.Fatal("shouldn't have scheduled posts without a time or a message")
+	}
+}
@@ -203,6 +203,13 @@ func (h handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		c.UserRequired()
 	}
 
+	// APIv3 doesn't tell reads and writes apart, so OAuth tokens with a
+	// narrower scope than the default can only be used with APIv4
+	if c.Err == nil && h.requireUser && !c.Session.HasOAuthScope(model.OAUTH_SCOPE_USER) {
+		c.Err = model.NewLocAppError("ServeHTTP", "api.context.oauth_scope.app_error", map[string]interface{}{"Scope": model.OAUTH_SCOPE_USER}, "")
+		c.Err.StatusCode = http.StatusForbidden
+	}
+
 	if c.Err == nil && h.requireMfa {
 		c.MfaRequired()
 	}
@@ -213,6 +220,7 @@ func (h handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	if c.Err == nil && h.isUserActivity && token != "" && len(c.Session.UserId) > 0 {
 		app.SetStatusOnline(c.Session.UserId, c.Session.Id, false)
+		app.UpdateLastActivityAtIfNeeded(&c.Session)
 	}
 
 	if c.Err == nil && (h.requireUser || h.requireSystemAdmin) {
@@ -305,8 +313,8 @@ func (c *Context) UserRequired() {
 }
 
 func (c *Context) MfaRequired() {
-	// Must be licensed for MFA and have it configured for enforcement
-	if !utils.IsLicensed || !*utils.License.Features.MFA || !*utils.Cfg.ServiceSettings.EnableMultifactorAuthentication || !*utils.Cfg.ServiceSettings.EnforceMultifactorAuthentication {
+	// Must have MFA configured for enforcement
+	if !*utils.Cfg.ServiceSettings.EnableMultifactorAuthentication || !*utils.Cfg.ServiceSettings.EnforceMultifactorAuthentication {
 		return
 	}
 
@@ -363,6 +371,13 @@ func (c *Context) RemoveSessionCookie(w http.ResponseWriter, r *http.Request) {
 	}
 
 	http.SetCookie(w, cookie)
+
+	http.SetCookie(w, &http.Cookie{
+		Name:   model.SESSION_COOKIE_CSRF,
+		Value:  "",
+		Path:   "/",
+		MaxAge: -1,
+	})
 }
 
 func (c *Context) SetInvalidParam(where string, name string) {
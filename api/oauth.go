@@ -40,6 +40,8 @@ func InitOAuth() {
 
 	BaseRoutes.Root.Handle("/oauth/authorize", AppHandlerTrustRequester(authorizeOAuth)).Methods("GET")
 	BaseRoutes.Root.Handle("/oauth/access_token", ApiAppHandlerTrustRequester(getAccessToken)).Methods("POST")
+	BaseRoutes.Root.Handle("/oauth/introspect", ApiAppHandlerTrustRequester(introspectAccessToken)).Methods("POST")
+	BaseRoutes.Root.Handle("/oauth/revoke", ApiAppHandlerTrustRequester(revokeAccessToken)).Methods("POST")
 
 	// Handle all the old routes, to be later removed
 	BaseRoutes.Root.Handle("/{service:[A-Za-z0-9]+}/complete", AppHandlerIndependent(completeOAuth)).Methods("GET")
@@ -171,12 +173,10 @@ func allowOAuth(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	scope := r.URL.Query().Get("scope")
+	scope := model.NormalizeOAuthScope(r.URL.Query().Get("scope"))
 	state := r.URL.Query().Get("state")
-
-	if len(scope) == 0 {
-		scope = model.DEFAULT_SCOPE
-	}
+	codeChallenge := r.URL.Query().Get("code_challenge")
+	codeChallengeMethod := r.URL.Query().Get("code_challenge_method")
 
 	var oauthApp *model.OAuthApp
 	if result := <-app.Srv.Store.OAuth().GetApp(clientId); result.Err != nil {
@@ -199,9 +199,17 @@ func allowOAuth(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	authData := &model.AuthData{UserId: c.Session.UserId, ClientId: clientId, CreateAt: model.GetMillis(), RedirectUri: redirectUri, State: state, Scope: scope}
+	authData := &model.AuthData{UserId: c.Session.UserId, ClientId: clientId, CreateAt: model.GetMillis(), RedirectUri: redirectUri, State: state, Scope: scope, CodeChallenge: codeChallenge, CodeChallengeMethod: codeChallengeMethod}
 	authData.Code = model.HashPassword(fmt.Sprintf("%v:%v:%v:%v", clientId, redirectUri, authData.CreateAt, c.Session.UserId))
 
+	authData.PreSave()
+	if err := authData.IsValid(); err != nil {
+		c.LogAudit("fail - invalid code challenge")
+		responseData["redirect"] = redirectUri + "?error=invalid_request&state=" + url.QueryEscape(state)
+		w.Write([]byte(model.MapToJson(responseData)))
+		return
+	}
+
 	// this saves the OAuth2 app as authorized
 	authorizedApp := model.Preference{
 		UserId:   c.Session.UserId,
@@ -339,12 +347,10 @@ func authorizeOAuth(c *Context, w http.ResponseWriter, r *http.Request) {
 	responseType := r.URL.Query().Get("response_type")
 	clientId := r.URL.Query().Get("client_id")
 	redirect := r.URL.Query().Get("redirect_uri")
-	scope := r.URL.Query().Get("scope")
+	scope := model.NormalizeOAuthScope(r.URL.Query().Get("scope"))
 	state := r.URL.Query().Get("state")
-
-	if len(scope) == 0 {
-		scope = model.DEFAULT_SCOPE
-	}
+	codeChallenge := r.URL.Query().Get("code_challenge")
+	codeChallengeMethod := r.URL.Query().Get("code_challenge_method")
 
 	if len(responseType) == 0 || len(clientId) == 0 || len(redirect) == 0 {
 		c.Err = model.NewLocAppError("authorizeOAuth", "api.oauth.authorize_oauth.missing.app_error", nil, "")
@@ -367,8 +373,8 @@ func authorizeOAuth(c *Context, w http.ResponseWriter, r *http.Request) {
 
 	isAuthorized := false
 	if result := <-app.Srv.Store.Preference().Get(c.Session.UserId, model.PREFERENCE_CATEGORY_AUTHORIZED_OAUTH_APP, clientId); result.Err == nil {
-		// when we support scopes we should check if the scopes match
-		isAuthorized = true
+		// the user has to authorize the app again if it asks for more than it was granted
+		isAuthorized = model.OAuthScopeIncludes(result.Data.(model.Preference).Value, scope)
 	}
 
 	// Automatically allow if the app is trusted
@@ -382,7 +388,8 @@ func authorizeOAuth(c *Context, w http.ResponseWriter, r *http.Request) {
 
 		doAllow := func() (*http.Response, *model.AppError) {
 			HttpClient := &http.Client{}
-			url := c.GetSiteURL() + "/api/v3/oauth/allow?response_type=" + model.AUTHCODE_RESPONSE_TYPE + "&client_id=" + clientId + "&redirect_uri=" + url.QueryEscape(redirect) + "&scope=" + scope + "&state=" + url.QueryEscape(state)
+			url := c.GetSiteURL() + "/api/v3/oauth/allow?response_type=" + model.AUTHCODE_RESPONSE_TYPE + "&client_id=" + clientId + "&redirect_uri=" + url.QueryEscape(redirect) + "&scope=" + url.QueryEscape(scope) + "&state=" + url.QueryEscape(state) +
+				"&code_challenge=" + url.QueryEscape(codeChallenge) + "&code_challenge_method=" + url.QueryEscape(codeChallengeMethod)
 			rq, _ := http.NewRequest("GET", url, strings.NewReader(""))
 
 			rq.Header.Set(model.HEADER_AUTH, model.HEADER_BEARER+" "+c.Session.Token)
@@ -443,6 +450,7 @@ func getAccessToken(c *Context, w http.ResponseWriter, r *http.Request) {
 			c.Err = model.NewLocAppError("getAccessToken", "api.oauth.get_access_token.missing_refresh_token.app_error", nil, "")
 			return
 		}
+	case model.CLIENT_CREDENTIALS_GRANT_TYPE:
 	default:
 		c.Err = model.NewLocAppError("getAccessToken", "api.oauth.get_access_token.bad_grant.app_error", nil, "")
 		return
@@ -454,8 +462,11 @@ func getAccessToken(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Public clients can't keep a secret, they prove that they started the
+	// authorization with a PKCE code verifier instead
 	secret := r.FormValue("client_secret")
-	if len(secret) == 0 {
+	codeVerifier := r.FormValue("code_verifier")
+	if len(secret) == 0 && (grantType != model.ACCESS_TOKEN_GRANT_TYPE || len(codeVerifier) == 0) {
 		c.Err = model.NewLocAppError("getAccessToken", "api.oauth.get_access_token.bad_client_secret.app_error", nil, "")
 		return
 	}
@@ -469,7 +480,7 @@ func getAccessToken(c *Context, w http.ResponseWriter, r *http.Request) {
 		oauthApp = result.Data.(*model.OAuthApp)
 	}
 
-	if oauthApp.ClientSecret != secret {
+	if len(secret) > 0 && oauthApp.ClientSecret != secret {
 		c.LogAudit("fail - invalid client credentials")
 		c.Err = model.NewLocAppError("getAccessToken", "api.oauth.get_access_token.credentials.app_error", nil, "")
 		return
@@ -507,6 +518,18 @@ func getAccessToken(c *Context, w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		if len(authData.CodeChallenge) > 0 {
+			if !authData.VerifyCodeVerifier(codeVerifier) {
+				c.LogAudit("fail - code verifier did not match code challenge")
+				c.Err = model.NewLocAppError("getAccessToken", "api.oauth.get_access_token.code_verifier.app_error", nil, "")
+				return
+			}
+		} else if len(secret) == 0 {
+			c.LogAudit("fail - invalid client credentials")
+			c.Err = model.NewLocAppError("getAccessToken", "api.oauth.get_access_token.credentials.app_error", nil, "")
+			return
+		}
+
 		uchan := app.Srv.Store.User().Get(authData.UserId)
 		if result := <-uchan; result.Err != nil {
 			c.Err = model.NewLocAppError("getAccessToken", "api.oauth.get_access_token.internal_user.app_error", nil, "")
@@ -515,54 +538,28 @@ func getAccessToken(c *Context, w http.ResponseWriter, r *http.Request) {
 			user = result.Data.(*model.User)
 		}
 
-		tchan := app.Srv.Store.OAuth().GetPreviousAccessData(user.Id, clientId)
-		if result := <-tchan; result.Err != nil {
-			c.Err = model.NewLocAppError("getAccessToken", "api.oauth.get_access_token.internal.app_error", nil, "")
+		if access, err := issueAccessToken(oauthApp, user, redirectUri, model.NormalizeOAuthScope(authData.Scope), true); err != nil {
+			c.Err = err
 			return
-		} else if result.Data != nil {
-			accessData := result.Data.(*model.AccessData)
-			if accessData.IsExpired() {
-				if access, err := newSessionUpdateToken(oauthApp.Name, accessData, user); err != nil {
-					c.Err = err
-					return
-				} else {
-					accessRsp = access
-				}
-			} else {
-				//return the same token and no need to create a new session
-				accessRsp = &model.AccessResponse{
-					AccessToken: accessData.Token,
-					TokenType:   model.ACCESS_TOKEN_TYPE,
-					ExpiresIn:   int32((accessData.ExpiresAt - model.GetMillis()) / 1000),
-				}
-			}
 		} else {
-			// create a new session and return new access token
-			var session *model.Session
-			if result, err := newSession(oauthApp.Name, user); err != nil {
-				c.Err = err
-				return
-			} else {
-				session = result
-			}
-
-			accessData = &model.AccessData{ClientId: clientId, UserId: user.Id, Token: session.Token, RefreshToken: model.NewId(), RedirectUri: redirectUri, ExpiresAt: session.ExpiresAt}
-
-			if result := <-app.Srv.Store.OAuth().SaveAccessData(accessData); result.Err != nil {
-				l4g.Error(result.Err)
-				c.Err = model.NewLocAppError("getAccessToken", "api.oauth.get_access_token.internal_saving.app_error", nil, "")
-				return
-			}
-
-			accessRsp = &model.AccessResponse{
-				AccessToken:  session.Token,
-				TokenType:    model.ACCESS_TOKEN_TYPE,
-				RefreshToken: accessData.RefreshToken,
-				ExpiresIn:    int32(*utils.Cfg.ServiceSettings.SessionLengthSSOInDays * 60 * 60 * 24),
-			}
+			accessRsp = access
 		}
 
 		<-app.Srv.Store.OAuth().RemoveAuthData(authData.Code)
+	} else if grantType == model.CLIENT_CREDENTIALS_GRANT_TYPE {
+		if appUser, err := app.GetOAuthAppUser(oauthApp); err != nil {
+			c.Err = err
+			return
+		} else {
+			user = appUser
+		}
+
+		if access, err := issueAccessToken(oauthApp, user, oauthApp.CallbackUrls[0], model.NormalizeOAuthScope(r.FormValue("scope")), false); err != nil {
+			c.Err = err
+			return
+		} else {
+			accessRsp = access
+		}
 	} else {
 		// when grantType is refresh_token
 		if result := <-app.Srv.Store.OAuth().GetAccessDataByRefreshToken(refreshToken); result.Err != nil {
@@ -573,6 +570,12 @@ func getAccessToken(c *Context, w http.ResponseWriter, r *http.Request) {
 			accessData = result.Data.(*model.AccessData)
 		}
 
+		if accessData.ClientId != clientId {
+			c.LogAudit("fail - refresh token was issued to another client")
+			c.Err = model.NewLocAppError("getAccessToken", "api.oauth.get_access_token.refresh_token.app_error", nil, "")
+			return
+		}
+
 		uchan := app.Srv.Store.User().Get(accessData.UserId)
 		if result := <-uchan; result.Err != nil {
 			c.Err = model.NewLocAppError("getAccessToken", "api.oauth.get_access_token.internal_user.app_error", nil, "")
@@ -581,6 +584,7 @@ func getAccessToken(c *Context, w http.ResponseWriter, r *http.Request) {
 			user = result.Data.(*model.User)
 		}
 
+		accessData.Scope = model.NormalizeOAuthScope(accessData.Scope)
 		if access, err := newSessionUpdateToken(oauthApp.Name, accessData, user); err != nil {
 			c.Err = err
 			return
@@ -598,6 +602,122 @@ func getAccessToken(c *Context, w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(accessRsp.ToJson()))
 }
 
+// issueAccessToken returns an access token for the user to use with the app.
+// A previous token is returned as is while it is still valid for the same
+// scope, otherwise it is replaced.
+func issueAccessToken(oauthApp *model.OAuthApp, user *model.User, redirectUri string, scope string, withRefreshToken bool) (*model.AccessResponse, *model.AppError) {
+	tchan := app.Srv.Store.OAuth().GetPreviousAccessData(user.Id, oauthApp.Id)
+	if result := <-tchan; result.Err != nil {
+		return nil, model.NewLocAppError("getAccessToken", "api.oauth.get_access_token.internal.app_error", nil, "")
+	} else if result.Data != nil {
+		accessData := result.Data.(*model.AccessData)
+		if accessData.IsExpired() || accessData.Scope != scope {
+			accessData.Scope = scope
+			return newSessionUpdateToken(oauthApp.Name, accessData, user)
+		}
+
+		//return the same token and no need to create a new session
+		return &model.AccessResponse{
+			AccessToken: accessData.Token,
+			TokenType:   model.ACCESS_TOKEN_TYPE,
+			ExpiresIn:   int32((accessData.ExpiresAt - model.GetMillis()) / 1000),
+			Scope:       accessData.Scope,
+		}, nil
+	}
+
+	// create a new session and return new access token
+	session, err := newSession(oauthApp.Name, user, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	accessData := &model.AccessData{ClientId: oauthApp.Id, UserId: user.Id, Token: session.Token, RedirectUri: redirectUri, ExpiresAt: session.ExpiresAt, Scope: scope}
+	if withRefreshToken {
+		accessData.RefreshToken = model.NewId()
+	}
+
+	if result := <-app.Srv.Store.OAuth().SaveAccessData(accessData); result.Err != nil {
+		l4g.Error(result.Err)
+		return nil, model.NewLocAppError("getAccessToken", "api.oauth.get_access_token.internal_saving.app_error", nil, "")
+	}
+
+	return &model.AccessResponse{
+		AccessToken:  session.Token,
+		TokenType:    model.ACCESS_TOKEN_TYPE,
+		RefreshToken: accessData.RefreshToken,
+		ExpiresIn:    int32(*utils.Cfg.ServiceSettings.SessionLengthSSOInDays * 60 * 60 * 24),
+		Scope:        scope,
+	}, nil
+}
+
+// authenticateOAuthClient checks the client credentials sent with HTTP basic
+// authentication or in the request body.
+func authenticateOAuthClient(c *Context, r *http.Request, where string) *model.OAuthApp {
+	clientId, secret, ok := r.BasicAuth()
+	if !ok {
+		clientId = r.FormValue("client_id")
+		secret = r.FormValue("client_secret")
+	}
+
+	if len(clientId) != 26 || len(secret) == 0 {
+		c.Err = model.NewLocAppError(where, "api.oauth.get_access_token.credentials.app_error", nil, "")
+		c.Err.StatusCode = http.StatusUnauthorized
+		return nil
+	}
+
+	if result := <-app.Srv.Store.OAuth().GetApp(clientId); result.Err != nil || result.Data.(*model.OAuthApp).ClientSecret != secret {
+		c.LogAudit("fail - invalid client credentials")
+		c.Err = model.NewLocAppError(where, "api.oauth.get_access_token.credentials.app_error", nil, "")
+		c.Err.StatusCode = http.StatusUnauthorized
+		return nil
+	} else {
+		return result.Data.(*model.OAuthApp)
+	}
+}
+
+func introspectAccessToken(c *Context, w http.ResponseWriter, r *http.Request) {
+	if !utils.Cfg.ServiceSettings.EnableOAuthServiceProvider {
+		c.Err = model.NewLocAppError("introspectAccessToken", "api.oauth.get_access_token.disabled.app_error", nil, "")
+		c.Err.StatusCode = http.StatusNotImplemented
+		return
+	}
+
+	r.ParseForm()
+
+	oauthApp := authenticateOAuthClient(c, r, "introspectAccessToken")
+	if oauthApp == nil {
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Pragma", "no-cache")
+
+	w.Write([]byte(app.IntrospectOAuthToken(oauthApp.Id, r.FormValue("token")).ToJson()))
+}
+
+func revokeAccessToken(c *Context, w http.ResponseWriter, r *http.Request) {
+	if !utils.Cfg.ServiceSettings.EnableOAuthServiceProvider {
+		c.Err = model.NewLocAppError("revokeAccessToken", "api.oauth.get_access_token.disabled.app_error", nil, "")
+		c.Err.StatusCode = http.StatusNotImplemented
+		return
+	}
+
+	r.ParseForm()
+
+	oauthApp := authenticateOAuthClient(c, r, "revokeAccessToken")
+	if oauthApp == nil {
+		return
+	}
+
+	if err := app.RevokeOAuthToken(oauthApp.Id, r.FormValue("token")); err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("client_id=" + oauthApp.Id)
+	ReturnStatusOK(w)
+}
+
 func loginWithOAuth(c *Context, w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
 	service := params["service"]
@@ -690,8 +810,12 @@ func signupWithOAuth(c *Context, w http.ResponseWriter, r *http.Request) {
 
 func GetAuthorizationCode(c *Context, service string, props map[string]string, loginHint string) (string, *model.AppError) {
 
-	sso := utils.Cfg.GetSSOService(service)
-	if sso != nil && !sso.Enable {
+	sso, err := app.GetSSOSettings(service)
+	if err != nil {
+		return "", err
+	}
+
+	if sso == nil || !sso.Enable {
 		return "", model.NewLocAppError("GetAuthorizationCode", "api.user.get_authorization_code.unsupported.app_error", nil, "service="+service)
 	}
 
@@ -718,7 +842,11 @@ func GetAuthorizationCode(c *Context, service string, props map[string]string, l
 }
 
 func AuthorizeOAuthUser(service, code, state, redirectUri string) (io.ReadCloser, string, map[string]string, *model.AppError) {
-	sso := utils.Cfg.GetSSOService(service)
+	sso, appErr := app.GetSSOSettings(service)
+	if appErr != nil {
+		return nil, "", nil, appErr
+	}
+
 	if sso == nil || !sso.Enable {
 		return nil, "", nil, model.NewLocAppError("AuthorizeOAuthUser", "api.user.authorize_oauth_user.unsupported.app_error", nil, "service="+service)
 	}
@@ -777,6 +905,14 @@ func AuthorizeOAuthUser(service, code, state, redirectUri string) (io.ReadCloser
 		return nil, "", nil, model.NewLocAppError("AuthorizeOAuthUser", "api.user.authorize_oauth_user.missing.app_error", nil, "")
 	}
 
+	if service == model.SERVICE_OPENID {
+		if userData, err := app.GetOpenIdUserData(ar.IdToken, ar.AccessToken); err != nil {
+			return nil, "", nil, err
+		} else {
+			return userData, teamId, stateProps, nil
+		}
+	}
+
 	p = url.Values{}
 	p.Set("access_token", ar.AccessToken)
 	req, _ = http.NewRequest("GET", sso.UserApiEndpoint, strings.NewReader(""))
@@ -887,6 +1023,10 @@ func deleteOAuthApp(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := app.DeactivateOAuthAppUser(id); err != nil {
+		l4g.Error(err.Error())
+	}
+
 	c.LogAudit("success")
 	ReturnStatusOK(w)
 }
@@ -965,13 +1105,14 @@ func regenerateOAuthSecret(c *Context, w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func newSession(appName string, user *model.User) (*model.Session, *model.AppError) {
+func newSession(appName string, user *model.User, scope string) (*model.Session, *model.AppError) {
 	// set new token an session
 	session := &model.Session{UserId: user.Id, Roles: user.Roles, IsOAuth: true}
 	session.SetExpireInDays(*utils.Cfg.ServiceSettings.SessionLengthSSOInDays)
 	session.AddProp(model.SESSION_PROP_PLATFORM, appName)
 	session.AddProp(model.SESSION_PROP_OS, "OAuth2")
 	session.AddProp(model.SESSION_PROP_BROWSER, "OAuth2")
+	session.AddProp(model.SESSION_PROP_OAUTH_SCOPE, scope)
 
 	if result := <-app.Srv.Store.Session().Save(session); result.Err != nil {
 		return nil, model.NewLocAppError("getAccessToken", "api.oauth.get_access_token.internal_session.app_error", nil, "")
@@ -987,7 +1128,7 @@ func newSessionUpdateToken(appName string, accessData *model.AccessData, user *m
 	var session *model.Session
 	<-app.Srv.Store.Session().Remove(accessData.Token) //remove the previous session
 
-	if result, err := newSession(appName, user); err != nil {
+	if result, err := newSession(appName, user, accessData.Scope); err != nil {
 		return nil, err
 	} else {
 		session = result
@@ -1003,6 +1144,7 @@ func newSessionUpdateToken(appName string, accessData *model.AccessData, user *m
 		AccessToken: session.Token,
 		TokenType:   model.ACCESS_TOKEN_TYPE,
 		ExpiresIn:   int32(*utils.Cfg.ServiceSettings.SessionLengthSSOInDays * 60 * 60 * 24),
+		Scope:       accessData.Scope,
 	}
 
 	return accessRsp, nil
@@ -845,3 +845,260 @@ func (m *MattermostTestProvider) GetAuthDataFromJson(data io.Reader) string {
 	authData := model.UserFromJson(data)
 	return authData.Email
 }
+
+func TestOAuthAccessTokenPKCE(t *testing.T) {
+	th := Setup().InitBasic()
+	Client := th.BasicClient
+
+	enableOAuth := utils.Cfg.ServiceSettings.EnableOAuthServiceProvider
+	defer func() {
+		utils.Cfg.ServiceSettings.EnableOAuthServiceProvider = enableOAuth
+	}()
+	utils.Cfg.ServiceSettings.EnableOAuthServiceProvider = true
+
+	oauthApp := &model.OAuthApp{Name: "TestApp6" + model.NewId(), Homepage: "https://nowhere.com", Description: "test", CallbackUrls: []string{"https://nowhere.com"}}
+	oauthApp = Client.Must(Client.RegisterApp(oauthApp)).Data.(*model.OAuthApp)
+
+	// Example from RFC 7636, appendix B
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	challenge := "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+
+	allow := func(challenge, method string) *url.URL {
+		r, err := Client.DoApiGet("/oauth/allow?response_type="+model.AUTHCODE_RESPONSE_TYPE+"&client_id="+oauthApp.Id+"&redirect_uri="+url.QueryEscape(oauthApp.CallbackUrls[0])+
+			"&scope=user&state=123&code_challenge="+challenge+"&code_challenge_method="+method, "", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer closeBody(r)
+
+		rurl, _ := url.Parse(model.MapFromJson(r.Body)["redirect"])
+		return rurl
+	}
+
+	if rurl := allow("short", model.PKCE_METHOD_S256); rurl.Query().Get("error") != "invalid_request" {
+		t.Fatal("should have failed - invalid code challenge")
+	}
+
+	rurl := allow(challenge, model.PKCE_METHOD_S256)
+	data := url.Values{"grant_type": []string{model.ACCESS_TOKEN_GRANT_TYPE}, "client_id": []string{oauthApp.Id}, "code": []string{rurl.Query().Get("code")}, "redirect_uri": []string{oauthApp.CallbackUrls[0]}}
+
+	if _, err := Client.GetAccessToken(data); err == nil {
+		t.Fatal("should have failed - no client secret or code verifier")
+	}
+
+	data.Set("code_verifier", challenge)
+	if _, err := Client.GetAccessToken(data); err == nil {
+		t.Fatal("should have failed - wrong code verifier")
+	}
+
+	data.Set("code_verifier", verifier)
+	if result, err := Client.GetAccessToken(data); err != nil {
+		t.Fatal(err)
+	} else if len(result.Data.(*model.AccessResponse).AccessToken) == 0 {
+		t.Fatal("access token not returned")
+	}
+
+	// codes without a challenge still need the client secret
+	rurl = allow("", "")
+	data.Set("code", rurl.Query().Get("code"))
+	if _, err := Client.GetAccessToken(data); err == nil {
+		t.Fatal("should have failed - code verifier without a code challenge")
+	}
+
+	data.Set("client_secret", oauthApp.ClientSecret)
+	data.Del("code_verifier")
+	if _, err := Client.GetAccessToken(data); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOAuthAccessTokenScope(t *testing.T) {
+	th := Setup().InitBasic()
+	Client := th.BasicClient
+
+	enableOAuth := utils.Cfg.ServiceSettings.EnableOAuthServiceProvider
+	defer func() {
+		utils.Cfg.ServiceSettings.EnableOAuthServiceProvider = enableOAuth
+	}()
+	utils.Cfg.ServiceSettings.EnableOAuthServiceProvider = true
+
+	oauthApp := &model.OAuthApp{Name: "TestApp7" + model.NewId(), Homepage: "https://nowhere.com", Description: "test", CallbackUrls: []string{"https://nowhere.com"}}
+	oauthApp = Client.Must(Client.RegisterApp(oauthApp)).Data.(*model.OAuthApp)
+
+	redirect := Client.Must(Client.AllowOAuth(model.AUTHCODE_RESPONSE_TYPE, oauthApp.Id, oauthApp.CallbackUrls[0], "read", "123")).Data.(map[string]string)["redirect"]
+	rurl, _ := url.Parse(redirect)
+
+	data := url.Values{"grant_type": []string{model.ACCESS_TOKEN_GRANT_TYPE}, "client_id": []string{oauthApp.Id}, "client_secret": []string{oauthApp.ClientSecret}, "code": []string{rurl.Query().Get("code")}, "redirect_uri": []string{oauthApp.CallbackUrls[0]}}
+
+	var rsp *model.AccessResponse
+	if result, err := Client.GetAccessToken(data); err != nil {
+		t.Fatal(err)
+	} else {
+		rsp = result.Data.(*model.AccessResponse)
+	}
+
+	if rsp.Scope != model.OAUTH_SCOPE_READ {
+		t.Fatal("should have been granted the read scope", rsp.Scope)
+	}
+
+	oauthClient := model.NewClient(Client.Url)
+	oauthClient.SetOAuthToken(rsp.AccessToken)
+	if _, err := oauthClient.GetMe(""); err == nil || err.StatusCode != http.StatusForbidden {
+		t.Fatal("should have failed - tokens with the read scope can't use APIv3")
+	}
+
+	// refreshing keeps the scope
+	data = url.Values{"grant_type": []string{model.REFRESH_TOKEN_GRANT_TYPE}, "client_id": []string{oauthApp.Id}, "client_secret": []string{oauthApp.ClientSecret}, "refresh_token": []string{rsp.RefreshToken}, "redirect_uri": []string{oauthApp.CallbackUrls[0]}}
+	if result, err := Client.GetAccessToken(data); err != nil {
+		t.Fatal(err)
+	} else if result.Data.(*model.AccessResponse).Scope != model.OAUTH_SCOPE_READ {
+		t.Fatal("should have kept the read scope")
+	}
+
+	otherApp := &model.OAuthApp{Name: "TestApp8" + model.NewId(), Homepage: "https://nowhere.com", Description: "test", CallbackUrls: []string{"https://nowhere.com"}}
+	otherApp = Client.Must(Client.RegisterApp(otherApp)).Data.(*model.OAuthApp)
+
+	data.Set("client_id", otherApp.Id)
+	data.Set("client_secret", otherApp.ClientSecret)
+	if _, err := Client.GetAccessToken(data); err == nil {
+		t.Fatal("should have failed - refresh token issued to another app")
+	}
+}
+
+func TestOAuthClientCredentials(t *testing.T) {
+	th := Setup().InitBasic()
+	Client := th.BasicClient
+
+	enableOAuth := utils.Cfg.ServiceSettings.EnableOAuthServiceProvider
+	defer func() {
+		utils.Cfg.ServiceSettings.EnableOAuthServiceProvider = enableOAuth
+	}()
+	utils.Cfg.ServiceSettings.EnableOAuthServiceProvider = true
+
+	oauthApp := &model.OAuthApp{Name: "TestApp9" + model.NewId(), Homepage: "https://nowhere.com", Description: "test", CallbackUrls: []string{"https://nowhere.com"}}
+	oauthApp = Client.Must(Client.RegisterApp(oauthApp)).Data.(*model.OAuthApp)
+
+	data := url.Values{"grant_type": []string{model.CLIENT_CREDENTIALS_GRANT_TYPE}, "client_id": []string{oauthApp.Id}, "client_secret": []string{"junk"}, "scope": []string{"post"}}
+	if _, err := Client.GetAccessToken(data); err == nil {
+		t.Fatal("should have failed - bad client secret")
+	}
+
+	data.Set("client_secret", oauthApp.ClientSecret)
+	var rsp *model.AccessResponse
+	if result, err := Client.GetAccessToken(data); err != nil {
+		t.Fatal(err)
+	} else {
+		rsp = result.Data.(*model.AccessResponse)
+	}
+
+	if len(rsp.AccessToken) == 0 || len(rsp.RefreshToken) != 0 || rsp.Scope != model.OAUTH_SCOPE_POST {
+		t.Fatal("should have returned an access token with the post scope and no refresh token")
+	}
+
+	clientId := oauthApp.Id
+	appUser, err := app.GetUserByAuth(&clientId, model.USER_AUTH_SERVICE_OAUTH_APP)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if session, err := app.GetSession(rsp.AccessToken); err != nil {
+		t.Fatal(err)
+	} else if session.UserId != appUser.Id || !session.IsOAuth {
+		t.Fatal("token should belong to the app user")
+	}
+
+	if result, err := Client.GetAccessToken(data); err != nil {
+		t.Fatal(err)
+	} else if result.Data.(*model.AccessResponse).AccessToken != rsp.AccessToken {
+		t.Fatal("should have returned the same token")
+	}
+
+	Client.Must(Client.DeleteOAuthApp(oauthApp.Id))
+	if user, err := app.GetUser(appUser.Id); err != nil {
+		t.Fatal(err)
+	} else if user.DeleteAt == 0 {
+		t.Fatal("app user should have been deactivated with the app")
+	}
+}
+
+func TestOAuthIntrospectAndRevoke(t *testing.T) {
+	th := Setup().InitBasic()
+	Client := th.BasicClient
+
+	enableOAuth := utils.Cfg.ServiceSettings.EnableOAuthServiceProvider
+	defer func() {
+		utils.Cfg.ServiceSettings.EnableOAuthServiceProvider = enableOAuth
+	}()
+	utils.Cfg.ServiceSettings.EnableOAuthServiceProvider = true
+
+	oauthApp := &model.OAuthApp{Name: "TestApp10" + model.NewId(), Homepage: "https://nowhere.com", Description: "test", CallbackUrls: []string{"https://nowhere.com"}}
+	oauthApp = Client.Must(Client.RegisterApp(oauthApp)).Data.(*model.OAuthApp)
+
+	otherApp := &model.OAuthApp{Name: "TestApp11" + model.NewId(), Homepage: "https://nowhere.com", Description: "test", CallbackUrls: []string{"https://nowhere.com"}}
+	otherApp = Client.Must(Client.RegisterApp(otherApp)).Data.(*model.OAuthApp)
+
+	redirect := Client.Must(Client.AllowOAuth(model.AUTHCODE_RESPONSE_TYPE, oauthApp.Id, oauthApp.CallbackUrls[0], "post", "123")).Data.(map[string]string)["redirect"]
+	rurl, _ := url.Parse(redirect)
+
+	data := url.Values{"grant_type": []string{model.ACCESS_TOKEN_GRANT_TYPE}, "client_id": []string{oauthApp.Id}, "client_secret": []string{oauthApp.ClientSecret}, "code": []string{rurl.Query().Get("code")}, "redirect_uri": []string{oauthApp.CallbackUrls[0]}}
+	rsp := Client.Must(Client.GetAccessToken(data)).Data.(*model.AccessResponse)
+
+	data = url.Values{"client_id": []string{oauthApp.Id}, "client_secret": []string{"junk"}, "token": []string{rsp.AccessToken}}
+	if _, err := Client.IntrospectAccessToken(data); err == nil {
+		t.Fatal("should have failed - bad client secret")
+	}
+
+	data.Set("client_secret", oauthApp.ClientSecret)
+	if result, err := Client.IntrospectAccessToken(data); err != nil {
+		t.Fatal(err)
+	} else if ir := result.Data.(*model.IntrospectionResponse); !ir.Active || ir.Scope != model.OAUTH_SCOPE_POST || ir.Subject != th.BasicUser.Id ||
+		ir.Username != th.BasicUser.Username || ir.ClientId != oauthApp.Id || ir.TokenType != model.TOKEN_TYPE_HINT_ACCESS_TOKEN || ir.ExpiresAt == 0 {
+		t.Fatal("should have described the access token", ir)
+	}
+
+	data.Set("token", rsp.RefreshToken)
+	if result, err := Client.IntrospectAccessToken(data); err != nil {
+		t.Fatal(err)
+	} else if ir := result.Data.(*model.IntrospectionResponse); !ir.Active || ir.TokenType != model.TOKEN_TYPE_HINT_REFRESH_TOKEN {
+		t.Fatal("should have described the refresh token", ir)
+	}
+
+	otherData := url.Values{"client_id": []string{otherApp.Id}, "client_secret": []string{otherApp.ClientSecret}, "token": []string{rsp.AccessToken}}
+	if result, err := Client.IntrospectAccessToken(otherData); err != nil {
+		t.Fatal(err)
+	} else if result.Data.(*model.IntrospectionResponse).Active {
+		t.Fatal("tokens of other apps should be inactive")
+	}
+
+	if _, err := Client.RevokeAccessToken(otherData); err != nil {
+		t.Fatal(err)
+	}
+
+	data.Set("token", rsp.AccessToken)
+	if result, err := Client.IntrospectAccessToken(data); err != nil {
+		t.Fatal(err)
+	} else if !result.Data.(*model.IntrospectionResponse).Active {
+		t.Fatal("other apps shouldn't be able to revoke the token")
+	}
+
+	data.Set("token", rsp.RefreshToken)
+	if _, err := Client.RevokeAccessToken(data); err != nil {
+		t.Fatal(err)
+	}
+
+	data.Set("token", rsp.AccessToken)
+	if result, err := Client.IntrospectAccessToken(data); err != nil {
+		t.Fatal(err)
+	} else if result.Data.(*model.IntrospectionResponse).Active {
+		t.Fatal("revoking the refresh token should have revoked the access token")
+	}
+
+	if _, err := app.GetSession(rsp.AccessToken); err == nil {
+		t.Fatal("session should have been revoked")
+	}
+
+	data.Set("token", "junk")
+	if _, err := Client.RevokeAccessToken(data); err != nil {
+		t.Fatal("unknown tokens should be ignored", err)
+	}
+}
@@ -66,7 +66,7 @@ func createPost(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	w.Write([]byte(rp.ToJson()))
+	w.Write([]byte(app.PreparePostForUser(rp, c.Session.UserId).ToJson()))
 }
 
 func updatePost(c *Context, w http.ResponseWriter, r *http.Request) {
@@ -90,7 +90,7 @@ func updatePost(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	w.Write([]byte(rpost.ToJson()))
+	w.Write([]byte(app.PreparePostForUser(rpost, c.Session.UserId).ToJson()))
 }
 
 func saveIsPinnedPost(c *Context, w http.ResponseWriter, r *http.Request, isPinned bool) {
@@ -133,7 +133,7 @@ func saveIsPinnedPost(c *Context, w http.ResponseWriter, r *http.Request, isPinn
 
 			app.InvalidateCacheForChannelPosts(rpost.ChannelId)
 
-			w.Write([]byte(rpost.ToJson()))
+			w.Write([]byte(app.PreparePostForUser(rpost, c.Session.UserId).ToJson()))
 		}
 	}
 }
@@ -170,7 +170,7 @@ func getFlaggedPosts(c *Context, w http.ResponseWriter, r *http.Request) {
 		c.Err = err
 		return
 	} else {
-		w.Write([]byte(posts.ToJson()))
+		w.Write([]byte(app.PreparePostListForUser(posts, c.Session.UserId).ToJson()))
 	}
 }
 
@@ -211,7 +211,7 @@ func getPosts(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	} else {
 		w.Header().Set(model.HEADER_ETAG_SERVER, etag)
-		w.Write([]byte(list.ToJson()))
+		w.Write([]byte(app.PreparePostListForUser(list, c.Session.UserId).ToJson()))
 	}
 
 }
@@ -240,7 +240,7 @@ func getPostsSince(c *Context, w http.ResponseWriter, r *http.Request) {
 		c.Err = err
 		return
 	} else {
-		w.Write([]byte(list.ToJson()))
+		w.Write([]byte(app.PreparePostListForUser(list, c.Session.UserId).ToJson()))
 	}
 
 }
@@ -278,7 +278,7 @@ func getPost(c *Context, w http.ResponseWriter, r *http.Request) {
 		}
 
 		w.Header().Set(model.HEADER_ETAG_SERVER, list.Etag())
-		w.Write([]byte(list.ToJson()))
+		w.Write([]byte(app.PreparePostListForUser(list, c.Session.UserId).ToJson()))
 	}
 }
 
@@ -311,7 +311,7 @@ func getPostById(c *Context, w http.ResponseWriter, r *http.Request) {
 		}
 
 		w.Header().Set(model.HEADER_ETAG_SERVER, list.Etag())
-		w.Write([]byte(list.ToJson()))
+		w.Write([]byte(app.PreparePostListForUser(list, c.Session.UserId).ToJson()))
 	}
 }
 
@@ -351,7 +351,7 @@ func getPermalinkTmp(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	} else {
 		w.Header().Set(model.HEADER_ETAG_SERVER, list.Etag())
-		w.Write([]byte(list.ToJson()))
+		w.Write([]byte(app.PreparePostListForUser(list, c.Session.UserId).ToJson()))
 	}
 }
 
@@ -448,7 +448,7 @@ func getPostsBeforeOrAfter(c *Context, w http.ResponseWriter, r *http.Request, b
 		return
 	} else {
 		w.Header().Set(model.HEADER_ETAG_SERVER, etag)
-		w.Write([]byte(list.ToJson()))
+		w.Write([]byte(app.PreparePostListForUser(list, c.Session.UserId).ToJson()))
 	}
 }
 
@@ -473,7 +473,7 @@ func searchPosts(c *Context, w http.ResponseWriter, r *http.Request) {
 	}
 
 	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
-	w.Write([]byte(posts.ToJson()))
+	w.Write([]byte(app.PreparePostListForUser(posts, c.Session.UserId).ToJson()))
 }
 
 func getFileInfosForPost(c *Context, w http.ResponseWriter, r *http.Request) {
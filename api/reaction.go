@@ -53,6 +53,19 @@ func saveReaction(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := app.CheckChannelIsNotArchived("saveReaction", channelId); err != nil {
+		c.Err = err
+		return
+	}
+
+	if channel, err := app.GetChannel(channelId); err != nil {
+		c.Err = err
+		return
+	} else if err := app.CheckUserCanPostInChannel("saveReaction", c.Session.UserId, channel, true); err != nil {
+		c.Err = err
+		return
+	}
+
 	var post *model.Post
 
 	if result := <-app.Srv.Store.Post().Get(reaction.PostId); result.Err != nil {
@@ -111,6 +124,19 @@ func deleteReaction(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := app.CheckChannelIsNotArchived("deleteReaction", channelId); err != nil {
+		c.Err = err
+		return
+	}
+
+	if channel, err := app.GetChannel(channelId); err != nil {
+		c.Err = err
+		return
+	} else if err := app.CheckUserCanPostInChannel("deleteReaction", c.Session.UserId, channel, true); err != nil {
+		c.Err = err
+		return
+	}
+
 	var post *model.Post
 
 	if result := <-app.Srv.Store.Post().Get(reaction.PostId); result.Err != nil {
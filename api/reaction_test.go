@@ -6,6 +6,7 @@ package api
 import (
 	"testing"
 
+	"github.com/mattermost/platform/app"
 	"github.com/mattermost/platform/model"
 )
 
@@ -138,6 +139,24 @@ func TestSaveReaction(t *testing.T) {
 	if _, err := Client.SaveReaction(channel.Id, reaction9); err == nil {
 		t.Fatal("should've failed to save reaction to a post that isn't in the given channel")
 	}
+
+	// saving a reaction in a channel where only channel admins can post
+	channel.PostRole = model.ROLE_CHANNEL_ADMIN.Id
+	if _, err := app.UpdateChannel(channel); err != nil {
+		t.Fatal(err)
+	}
+
+	th.LoginBasic2()
+	Client.Must(Client.JoinChannel(channel.Id))
+
+	reaction10 := &model.Reaction{
+		UserId:    user2.Id,
+		PostId:    post.Id,
+		EmojiName: "tada",
+	}
+	if _, err := Client.SaveReaction(channel.Id, reaction10); err == nil {
+		t.Fatal("should've failed to save reaction to a channel we can't post in")
+	}
 }
 
 func TestDeleteReaction(t *testing.T) {
@@ -194,6 +213,21 @@ func TestDeleteReaction(t *testing.T) {
 	Client.Must(Client.JoinChannel(channel.Id))
 	reaction3 = Client.MustGeneric(Client.SaveReaction(channel.Id, reaction3)).(*model.Reaction)
 
+	// deleting a reaction in a channel where only channel admins can post
+	channel.PostRole = model.ROLE_CHANNEL_ADMIN.Id
+	if _, err := app.UpdateChannel(channel); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Client.DeleteReaction(channel.Id, reaction3); err == nil {
+		t.Fatal("should've failed to delete a reaction in a channel we can't post in")
+	}
+
+	channel.PostRole = ""
+	if _, err := app.UpdateChannel(channel); err != nil {
+		t.Fatal(err)
+	}
+
 	th.LoginBasic()
 	if err := Client.DeleteReaction(channel.Id, reaction3); err == nil {
 		t.Fatal("should've failed to delete another user's reaction")
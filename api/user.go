@@ -113,7 +113,7 @@ func login(c *Context, w http.ResponseWriter, r *http.Request) {
 	ldapOnly := props["ldap_only"] == "true"
 
 	c.LogAudit("attempt - user_id=" + id + " login_id=" + loginId)
-	user, err := app.AuthenticateUserForLogin(id, loginId, password, mfaToken, deviceId, ldapOnly)
+	user, err := app.AuthenticateUserForLogin(id, loginId, password, mfaToken, deviceId, app.GetLoginThrottleIpAddress(r), ldapOnly)
 	if err != nil {
 		c.LogAudit("failure - user_id=" + id + " login_id=" + loginId)
 		c.Err = err
@@ -362,6 +362,11 @@ func getUser(c *Context, w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
 	id := params["user_id"]
 
+	if !app.SessionCanSeeUsers(c.Session, []string{id}) {
+		c.SetPermissionError(model.PERMISSION_VIEW_MEMBERS)
+		return
+	}
+
 	var user *model.User
 	var err *model.AppError
 
@@ -392,6 +397,9 @@ func getByUsername(c *Context, w http.ResponseWriter, r *http.Request) {
 	if user, err = app.GetUserByUsername(username); err != nil {
 		c.Err = err
 		return
+	} else if !app.SessionCanSeeUsers(c.Session, []string{user.Id}) {
+		c.Err = model.NewAppError("SqlUserStore.GetByUsername", "store.sql_user.get_by_username.app_error", nil, "", http.StatusNotFound)
+		return
 	} else if HandleEtag(user.Etag(utils.Cfg.PrivacySettings.ShowFullName, utils.Cfg.PrivacySettings.ShowEmailAddress), "Get By Username", w, r) {
 		return
 	} else {
@@ -410,6 +418,9 @@ func getByEmail(c *Context, w http.ResponseWriter, r *http.Request) {
 	if user, err := app.GetUserByEmail(email); err != nil {
 		c.Err = err
 		return
+	} else if !app.SessionCanSeeUsers(c.Session, []string{user.Id}) {
+		c.Err = model.NewAppError("SqlUserStore.GetByEmail", store.MISSING_ACCOUNT_ERROR, nil, "", http.StatusNotFound)
+		return
 	} else if HandleEtag(user.Etag(utils.Cfg.PrivacySettings.ShowFullName, utils.Cfg.PrivacySettings.ShowEmailAddress), "Get By Email", w, r) {
 		return
 	} else {
@@ -424,6 +435,11 @@ func getByEmail(c *Context, w http.ResponseWriter, r *http.Request) {
 func getProfiles(c *Context, w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
 
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_VIEW_MEMBERS) {
+		c.SetPermissionError(model.PERMISSION_VIEW_MEMBERS)
+		return
+	}
+
 	offset, err := strconv.Atoi(params["offset"])
 	if err != nil {
 		c.SetInvalidParam("getProfiles", "offset")
@@ -454,6 +470,11 @@ func getProfilesInTeam(c *Context, w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
 	teamId := params["team_id"]
 
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_VIEW_MEMBERS) {
+		c.SetPermissionError(model.PERMISSION_VIEW_MEMBERS)
+		return
+	}
+
 	if c.Session.GetTeamByTeamId(teamId) == nil {
 		if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
 			return
@@ -526,6 +547,11 @@ func getProfilesNotInChannel(c *Context, w http.ResponseWriter, r *http.Request)
 	params := mux.Vars(r)
 	channelId := params["channel_id"]
 
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_VIEW_MEMBERS) {
+		c.SetPermissionError(model.PERMISSION_VIEW_MEMBERS)
+		return
+	}
+
 	if c.Session.GetTeamByTeamId(c.TeamId) == nil {
 		if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
 			c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
@@ -1297,7 +1323,7 @@ func updateMfa(c *Context, w http.ResponseWriter, r *http.Request) {
 }
 
 func checkMfa(c *Context, w http.ResponseWriter, r *http.Request) {
-	if !utils.IsLicensed || !*utils.License.Features.MFA || !*utils.Cfg.ServiceSettings.EnableMultifactorAuthentication {
+	if !*utils.Cfg.ServiceSettings.EnableMultifactorAuthentication {
 		rdata := map[string]string{}
 		rdata["mfa_required"] = "false"
 		w.Write([]byte(model.MapToJson(rdata)))
@@ -1466,6 +1492,11 @@ func searchUsers(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if (props.InChannelId == "" || props.WithoutTeam) && !app.SessionHasPermissionTo(c.Session, model.PERMISSION_VIEW_MEMBERS) {
+		c.SetPermissionError(model.PERMISSION_VIEW_MEMBERS)
+		return
+	}
+
 	if props.InChannelId != "" && !app.SessionHasPermissionToChannel(c.Session, props.InChannelId, model.PERMISSION_READ_CHANNEL) {
 		c.SetPermissionError(model.PERMISSION_READ_CHANNEL)
 		return
@@ -1508,6 +1539,13 @@ func getProfilesByIds(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Users that can't view all members, such as guests, only get back the users they share a channel with
+	userIds = app.GetUserIdsVisibleToSession(c.Session, userIds)
+	if len(userIds) == 0 {
+		w.Write([]byte(model.UserMapToJson(map[string]*model.User{})))
+		return
+	}
+
 	if profiles, err := app.GetUsersByIds(userIds, c.IsSystemAdmin()); err != nil {
 		c.Err = err
 		return
@@ -1553,6 +1591,10 @@ func autocompleteUsersInChannel(c *Context, w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_VIEW_MEMBERS) {
+		autocomplete.OutOfChannel = []*model.User{}
+	}
+
 	w.Write([]byte(autocomplete.ToJson()))
 }
 
@@ -1562,6 +1604,11 @@ func autocompleteUsersInTeam(c *Context, w http.ResponseWriter, r *http.Request)
 
 	term := r.URL.Query().Get("term")
 
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_VIEW_MEMBERS) {
+		c.SetPermissionError(model.PERMISSION_VIEW_MEMBERS)
+		return
+	}
+
 	if c.Session.GetTeamByTeamId(teamId) == nil {
 		if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
 			return
@@ -1589,6 +1636,11 @@ func autocompleteUsersInTeam(c *Context, w http.ResponseWriter, r *http.Request)
 func autocompleteUsers(c *Context, w http.ResponseWriter, r *http.Request) {
 	term := r.URL.Query().Get("term")
 
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_VIEW_MEMBERS) {
+		c.SetPermissionError(model.PERMISSION_VIEW_MEMBERS)
+		return
+	}
+
 	searchOptions := map[string]bool{}
 
 	hideFullName := !utils.Cfg.PrivacySettings.ShowFullName
@@ -1831,11 +1831,11 @@ func TestGenerateMfaSecret(t *testing.T) {
 
 	Client.Login(user.Email, user.Password)
 
-	if _, err := Client.GenerateMfaSecret(); err == nil {
-		t.Fatal("should have failed - not licensed")
+	if result, err := Client.GenerateMfaSecret(); err != nil {
+		t.Fatal(err)
+	} else if secret := result.Data.(map[string]string); secret["secret"] == "" || secret["qr_code"] == "" {
+		t.Fatal("should have returned the secret and its QR code")
 	}
-
-	// need to add more test cases when license and config can be configured for tests
 }
 
 func TestUpdateMfa(t *testing.T) {
@@ -1877,7 +1877,7 @@ func TestUpdateMfa(t *testing.T) {
 	}
 
 	if _, err := Client.UpdateMfa(true, "123456"); err == nil {
-		t.Fatal("should have failed - not licensed")
+		t.Fatal("should have failed - no secret generated")
 	}
 
 	utils.IsLicensed = true
@@ -48,6 +48,22 @@ type Routes struct {
 	Post            *mux.Router // 'api/v4/posts/{post_id:[A-Za-z0-9]+}'
 	PostsForChannel *mux.Router // 'api/v4/channels/{channel_id:[A-Za-z0-9]+}/posts'
 
+	ThreadsForUser *mux.Router // 'api/v4/users/{user_id:[A-Za-z0-9]+}/threads'
+	ThreadForUser  *mux.Router // 'api/v4/users/{user_id:[A-Za-z0-9]+}/threads/{post_id:[A-Za-z0-9]+}'
+
+	ScheduledPosts        *mux.Router // 'api/v4/scheduled_posts'
+	ScheduledPost         *mux.Router // 'api/v4/scheduled_posts/{scheduled_post_id:[A-Za-z0-9]+}'
+	ScheduledPostsForUser *mux.Router // 'api/v4/users/{user_id:[A-Za-z0-9]+}/scheduled_posts'
+
+	PostReminders        *mux.Router // 'api/v4/post_reminders'
+	PostReminder         *mux.Router // 'api/v4/post_reminders/{reminder_id:[A-Za-z0-9]+}'
+	PostRemindersForPost *mux.Router // 'api/v4/posts/{post_id:[A-Za-z0-9]+}/reminders'
+	PostRemindersForUser *mux.Router // 'api/v4/users/{user_id:[A-Za-z0-9]+}/post_reminders'
+
+	PrivacyRequests        *mux.Router // 'api/v4/privacy_requests'
+	PrivacyRequest         *mux.Router // 'api/v4/privacy_requests/{privacy_request_id:[A-Za-z0-9]+}'
+	PrivacyRequestsForUser *mux.Router // 'api/v4/users/{user_id:[A-Za-z0-9]+}/privacy_requests'
+
 	Files *mux.Router // 'api/v4/files'
 	File  *mux.Router // 'api/v4/files/{file_id:[A-Za-z0-9]+}'
 
@@ -63,6 +79,12 @@ type Routes struct {
 	OutgoingHooks *mux.Router // 'api/v4/hooks/outgoing'
 	OutgoingHook  *mux.Router // 'api/v4/hooks/outgoing/{hook_id:[A-Za-z0-9]+}'
 
+	MentionGroups        *mux.Router // 'api/v4/mention_groups'
+	MentionGroup         *mux.Router // 'api/v4/mention_groups/{mention_group_id:[A-Za-z0-9]+}'
+	MentionGroupsForTeam *mux.Router // 'api/v4/teams/{team_id:[A-Za-z0-9]+}/mention_groups'
+	MentionGroupMembers  *mux.Router // 'api/v4/mention_groups/{mention_group_id:[A-Za-z0-9]+}/members'
+	MentionGroupMember   *mux.Router // 'api/v4/mention_groups/{mention_group_id:[A-Za-z0-9]+}/members/{user_id:[A-Za-z0-9]+}'
+
 	Admin      *mux.Router // 'api/v4/admin'
 	OAuth      *mux.Router // 'api/v4/oauth'
 	SAML       *mux.Router // 'api/v4/saml'
@@ -127,6 +149,22 @@ func InitApi(full bool) {
 	BaseRoutes.Post = BaseRoutes.Posts.PathPrefix("/{post_id:[A-Za-z0-9]+}").Subrouter()
 	BaseRoutes.PostsForChannel = BaseRoutes.Channel.PathPrefix("/posts").Subrouter()
 
+	BaseRoutes.ThreadsForUser = BaseRoutes.User.PathPrefix("/threads").Subrouter()
+	BaseRoutes.ThreadForUser = BaseRoutes.ThreadsForUser.PathPrefix("/{post_id:[A-Za-z0-9]+}").Subrouter()
+
+	BaseRoutes.ScheduledPosts = BaseRoutes.ApiRoot.PathPrefix("/scheduled_posts").Subrouter()
+	BaseRoutes.ScheduledPost = BaseRoutes.ScheduledPosts.PathPrefix("/{scheduled_post_id:[A-Za-z0-9]+}").Subrouter()
+	BaseRoutes.ScheduledPostsForUser = BaseRoutes.User.PathPrefix("/scheduled_posts").Subrouter()
+
+	BaseRoutes.PostReminders = BaseRoutes.ApiRoot.PathPrefix("/post_reminders").Subrouter()
+	BaseRoutes.PostReminder = BaseRoutes.PostReminders.PathPrefix("/{reminder_id:[A-Za-z0-9]+}").Subrouter()
+	BaseRoutes.PostRemindersForPost = BaseRoutes.Post.PathPrefix("/reminders").Subrouter()
+	BaseRoutes.PostRemindersForUser = BaseRoutes.User.PathPrefix("/post_reminders").Subrouter()
+
+	BaseRoutes.PrivacyRequests = BaseRoutes.ApiRoot.PathPrefix("/privacy_requests").Subrouter()
+	BaseRoutes.PrivacyRequest = BaseRoutes.PrivacyRequests.PathPrefix("/{privacy_request_id:[A-Za-z0-9]+}").Subrouter()
+	BaseRoutes.PrivacyRequestsForUser = BaseRoutes.User.PathPrefix("/privacy_requests").Subrouter()
+
 	BaseRoutes.Files = BaseRoutes.ApiRoot.PathPrefix("/files").Subrouter()
 	BaseRoutes.File = BaseRoutes.Files.PathPrefix("/{file_id:[A-Za-z0-9]+}").Subrouter()
 	BaseRoutes.PublicFile = BaseRoutes.Root.PathPrefix("/files/{file_id:[A-Za-z0-9]+}/public").Subrouter()
@@ -141,6 +179,12 @@ func InitApi(full bool) {
 	BaseRoutes.OutgoingHooks = BaseRoutes.Hooks.PathPrefix("/outgoing").Subrouter()
 	BaseRoutes.OutgoingHook = BaseRoutes.OutgoingHooks.PathPrefix("/{hook_id:[A-Za-z0-9]+}").Subrouter()
 
+	BaseRoutes.MentionGroups = BaseRoutes.ApiRoot.PathPrefix("/mention_groups").Subrouter()
+	BaseRoutes.MentionGroup = BaseRoutes.MentionGroups.PathPrefix("/{mention_group_id:[A-Za-z0-9]+}").Subrouter()
+	BaseRoutes.MentionGroupsForTeam = BaseRoutes.Team.PathPrefix("/mention_groups").Subrouter()
+	BaseRoutes.MentionGroupMembers = BaseRoutes.MentionGroup.PathPrefix("/members").Subrouter()
+	BaseRoutes.MentionGroupMember = BaseRoutes.MentionGroupMembers.PathPrefix("/{user_id:[A-Za-z0-9]+}").Subrouter()
+
 	BaseRoutes.SAML = BaseRoutes.ApiRoot.PathPrefix("/saml").Subrouter()
 	BaseRoutes.OAuth = BaseRoutes.ApiRoot.PathPrefix("/oauth").Subrouter()
 	BaseRoutes.Admin = BaseRoutes.ApiRoot.PathPrefix("/admin").Subrouter()
@@ -162,9 +206,16 @@ func InitApi(full bool) {
 	InitTeam()
 	InitChannel()
 	InitPost()
+	InitPoll()
+	InitPostAcknowledgement()
+	InitThread()
+	InitScheduledPost()
+	InitPostReminder()
+	InitPrivacyRequest()
 	InitFile()
 	InitSystem()
 	InitWebhook()
+	InitMentionGroup()
 	InitPreference()
 	InitSaml()
 	InitCompliance()
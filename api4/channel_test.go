@@ -911,6 +911,138 @@ func TestDeleteChannel(t *testing.T) {
 	CheckNoError(t, resp)
 }
 
+func TestRestoreChannel(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	enableIncomingHooks := utils.Cfg.ServiceSettings.EnableIncomingWebhooks
+	defer func() {
+		utils.Cfg.ServiceSettings.EnableIncomingWebhooks = enableIncomingHooks
+	}()
+	utils.Cfg.ServiceSettings.EnableIncomingWebhooks = true
+
+	publicChannel := th.CreatePublicChannel()
+	post := th.CreatePostWithClient(Client, publicChannel)
+
+	hook, resp := th.SystemAdminClient.CreateIncomingWebhook(&model.IncomingWebhook{ChannelId: publicChannel.Id})
+	CheckNoError(t, resp)
+
+	_, resp = Client.RestoreChannel(publicChannel.Id)
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.DeleteChannel(publicChannel.Id)
+	CheckNoError(t, resp)
+
+	// archived channels are read-only
+	_, resp = Client.CreatePost(&model.Post{ChannelId: publicChannel.Id, Message: "a" + GenerateTestId() + "a"})
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.PatchPost(post.Id, &model.PostPatch{Message: new(string)})
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.DeletePost(post.Id)
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.AddChannelMember(publicChannel.Id, th.BasicUser2.Id)
+	CheckBadRequestStatus(t, resp)
+
+	// but can still be read, even by users who weren't members
+	_, resp = Client.GetPostsForChannel(publicChannel.Id, 0, 60, "")
+	CheckNoError(t, resp)
+
+	Client.Logout()
+	Client.Login(th.BasicUser2.Email, th.BasicUser2.Password)
+
+	posts, resp := Client.GetPostsForChannel(publicChannel.Id, 0, 60, "")
+	CheckNoError(t, resp)
+
+	if _, ok := posts.Posts[post.Id]; !ok {
+		t.Fatal("should have returned the post from the archived channel")
+	}
+
+	_, resp = Client.RestoreChannel(publicChannel.Id)
+	CheckForbiddenStatus(t, resp)
+
+	th.LoginBasic()
+	channel, resp := Client.RestoreChannel(publicChannel.Id)
+	CheckNoError(t, resp)
+
+	if channel.DeleteAt != 0 {
+		t.Fatal("channel should have been restored")
+	}
+
+	_, resp = Client.CreatePost(&model.Post{ChannelId: publicChannel.Id, Message: "a" + GenerateTestId() + "a"})
+	CheckNoError(t, resp)
+
+	_, resp = th.SystemAdminClient.GetIncomingWebhook(hook.Id, "")
+	CheckNoError(t, resp)
+
+	_, resp = Client.RestoreChannel("junk")
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.RestoreChannel(th.BasicChannel.Id)
+	CheckBadRequestStatus(t, resp)
+
+	privateChannel := th.CreatePrivateChannel()
+	_, resp = Client.DeleteChannel(privateChannel.Id)
+	CheckNoError(t, resp)
+
+	_, resp = th.SystemAdminClient.RestoreChannel(privateChannel.Id)
+	CheckNoError(t, resp)
+
+	Client.Logout()
+	_, resp = Client.RestoreChannel(privateChannel.Id)
+	CheckUnauthorizedStatus(t, resp)
+}
+
+func TestGetArchivedChannelsForTeam(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+	team := th.BasicTeam
+
+	publicChannel := th.CreatePublicChannel()
+	_, resp := Client.DeleteChannel(publicChannel.Id)
+	CheckNoError(t, resp)
+
+	privateChannel := th.CreatePrivateChannel()
+	_, resp = Client.DeleteChannel(privateChannel.Id)
+	CheckNoError(t, resp)
+
+	channels, resp := Client.GetArchivedChannelsForTeam(team.Id, 0, 100, "")
+	CheckNoError(t, resp)
+
+	if len(*channels) != 2 {
+		t.Fatal("should have returned both archived channels")
+	}
+
+	channels, resp = Client.GetArchivedChannelsForTeam(team.Id, 1, 1, "")
+	CheckNoError(t, resp)
+
+	if len(*channels) != 1 {
+		t.Fatal("should have returned one channel")
+	}
+
+	Client.Logout()
+	Client.Login(th.BasicUser2.Email, th.BasicUser2.Password)
+
+	channels, resp = Client.GetArchivedChannelsForTeam(team.Id, 0, 100, "")
+	CheckNoError(t, resp)
+
+	if len(*channels) != 1 || (*channels)[0].Id != publicChannel.Id {
+		t.Fatal("should only have returned the public channel")
+	}
+
+	otherTeam := th.CreateTeamWithClient(th.SystemAdminClient)
+	_, resp = Client.GetArchivedChannelsForTeam(otherTeam.Id, 0, 100, "")
+	CheckForbiddenStatus(t, resp)
+
+	Client.Logout()
+	_, resp = Client.GetArchivedChannelsForTeam(team.Id, 0, 100, "")
+	CheckUnauthorizedStatus(t, resp)
+}
+
 func TestGetChannelByName(t *testing.T) {
 	th := Setup().InitBasic().InitSystemAdmin()
 	defer TearDown()
@@ -1468,6 +1600,29 @@ func TestUpdateChannelNotifyProps(t *testing.T) {
 		t.Fatal("bad update")
 	}
 
+	props = map[string]string{}
+	props[model.MUTE_NOTIFY_PROP] = "true"
+	props[model.IGNORE_CHANNEL_MENTIONS_NOTIFY_PROP] = model.IGNORE_CHANNEL_MENTIONS_ON
+
+	_, resp = Client.UpdateChannelNotifyProps(th.BasicChannel.Id, th.BasicUser.Id, props)
+	CheckNoError(t, resp)
+
+	member, err = app.GetChannelMember(th.BasicChannel.Id, th.BasicUser.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !member.IsMuted() {
+		t.Fatal("channel should be muted")
+	} else if member.NotifyProps[model.IGNORE_CHANNEL_MENTIONS_NOTIFY_PROP] != model.IGNORE_CHANNEL_MENTIONS_ON {
+		t.Fatal("bad update")
+	} else if member.NotifyProps[model.DESKTOP_NOTIFY_PROP] != model.CHANNEL_NOTIFY_MENTION {
+		t.Fatal("other notify props should be unchanged")
+	}
+
+	_, resp = Client.UpdateChannelNotifyProps(th.BasicChannel.Id, th.BasicUser.Id, map[string]string{model.MUTE_NOTIFY_PROP: "junk"})
+	CheckBadRequestStatus(t, resp)
+
 	_, resp = Client.UpdateChannelNotifyProps("junk", th.BasicUser.Id, props)
 	CheckBadRequestStatus(t, resp)
 
@@ -1884,3 +2039,240 @@ func TestRemoveChannelMember(t *testing.T) {
 	_, resp = th.SystemAdminClient.RemoveUserFromChannel(privateChannel.Id, user2.Id)
 	CheckNoError(t, resp)
 }
+
+func TestMoveChannel(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	enableIncomingHooks := utils.Cfg.ServiceSettings.EnableIncomingWebhooks
+	defer func() {
+		utils.Cfg.ServiceSettings.EnableIncomingWebhooks = enableIncomingHooks
+	}()
+	utils.Cfg.ServiceSettings.EnableIncomingWebhooks = true
+
+	channel := th.CreatePublicChannel()
+	otherTeam := th.CreateTeamWithClient(th.SystemAdminClient)
+
+	hook, resp := th.SystemAdminClient.CreateIncomingWebhook(&model.IncomingWebhook{ChannelId: channel.Id})
+	CheckNoError(t, resp)
+
+	_, resp = Client.MoveChannel(channel.Id, otherTeam.Id, false)
+	CheckForbiddenStatus(t, resp)
+
+	// the team admin of the channel's team isn't an admin of the other team
+	th.LoginTeamAdmin()
+	_, resp = Client.MoveChannel(channel.Id, otherTeam.Id, false)
+	CheckForbiddenStatus(t, resp)
+
+	// the basic user isn't on the other team
+	_, resp = th.SystemAdminClient.MoveChannel(channel.Id, otherTeam.Id, false)
+	CheckBadRequestStatus(t, resp)
+
+	LinkUserToTeam(th.BasicUser, otherTeam)
+
+	moved, resp := th.SystemAdminClient.MoveChannel(channel.Id, otherTeam.Id, false)
+	CheckNoError(t, resp)
+
+	if moved.TeamId != otherTeam.Id {
+		t.Fatal("channel should have been moved to the other team")
+	}
+
+	if _, err := app.GetChannelByName(channel.Name, otherTeam.Id); err != nil {
+		t.Fatal("should have found the channel on the other team")
+	}
+
+	if rhook, resp := th.SystemAdminClient.GetIncomingWebhook(hook.Id, ""); resp.Error != nil {
+		t.Fatal(resp.Error)
+	} else if rhook.TeamId != otherTeam.Id {
+		t.Fatal("webhook should have been moved with the channel")
+	}
+
+	_, resp = th.SystemAdminClient.MoveChannel(channel.Id, otherTeam.Id, false)
+	CheckBadRequestStatus(t, resp)
+
+	// members who aren't on the team are removed when forced
+	_, resp = th.SystemAdminClient.MoveChannel(th.BasicChannel.Id, otherTeam.Id, true)
+	CheckNoError(t, resp)
+
+	if _, err := app.GetChannelMember(th.BasicChannel.Id, th.BasicUser2.Id); err == nil {
+		t.Fatal("user who isn't on the team should have been removed from the channel")
+	}
+
+	if _, err := app.GetChannelMember(th.BasicChannel.Id, th.BasicUser.Id); err != nil {
+		t.Fatal("user on the team should still be in the channel")
+	}
+
+	// channel names must be unique on the team
+	duplicate := &model.Channel{TeamId: otherTeam.Id, Name: th.BasicChannel2.Name, DisplayName: "Duplicate", Type: model.CHANNEL_OPEN}
+	_, resp = th.SystemAdminClient.CreateChannel(duplicate)
+	CheckNoError(t, resp)
+
+	_, resp = th.SystemAdminClient.MoveChannel(th.BasicChannel2.Id, otherTeam.Id, true)
+	CheckBadRequestStatus(t, resp)
+
+	defaultChannel, _ := app.GetChannelByName(model.DEFAULT_CHANNEL, th.BasicTeam.Id)
+	_, resp = th.SystemAdminClient.MoveChannel(defaultChannel.Id, otherTeam.Id, true)
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.MoveChannel(th.BasicChannel2.Id, "junk", true)
+	CheckBadRequestStatus(t, resp)
+
+	Client.Logout()
+	_, resp = Client.MoveChannel(th.BasicChannel2.Id, otherTeam.Id, true)
+	CheckUnauthorizedStatus(t, resp)
+}
+
+func TestConvertChannel(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	channel := th.CreatePublicChannel()
+
+	_, resp := Client.ConvertChannel(channel.Id, model.CHANNEL_PRIVATE)
+	CheckForbiddenStatus(t, resp)
+
+	th.LoginTeamAdmin()
+	converted, resp := Client.ConvertChannel(channel.Id, model.CHANNEL_PRIVATE)
+	CheckNoError(t, resp)
+
+	if converted.Type != model.CHANNEL_PRIVATE {
+		t.Fatal("channel should have been made private")
+	}
+
+	if rchannel, err := app.GetChannel(channel.Id); err != nil {
+		t.Fatal(err)
+	} else if rchannel.Type != model.CHANNEL_PRIVATE {
+		t.Fatal("channel should have been private after clearing the cache")
+	}
+
+	_, resp = Client.ConvertChannel(channel.Id, model.CHANNEL_PRIVATE)
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.ConvertChannel(channel.Id, model.CHANNEL_DIRECT)
+	CheckBadRequestStatus(t, resp)
+
+	converted, resp = Client.ConvertChannel(channel.Id, model.CHANNEL_OPEN)
+	CheckNoError(t, resp)
+
+	if converted.Type != model.CHANNEL_OPEN {
+		t.Fatal("channel should have been made public")
+	}
+
+	defaultChannel, _ := app.GetChannelByName(model.DEFAULT_CHANNEL, th.BasicTeam.Id)
+	_, resp = Client.ConvertChannel(defaultChannel.Id, model.CHANNEL_PRIVATE)
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.ConvertChannel(th.BasicPrivateChannel.Id, model.CHANNEL_OPEN)
+	CheckNoError(t, resp)
+
+	Client.Logout()
+	_, resp = Client.ConvertChannel(channel.Id, model.CHANNEL_PRIVATE)
+	CheckUnauthorizedStatus(t, resp)
+}
+
+func TestChannelPostRole(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	postRole := model.ROLE_CHANNEL_ADMIN.Id
+	patch := &model.ChannelPatch{PostRole: &postRole}
+
+	_, resp := Client.PatchChannel(th.BasicChannel.Id, patch)
+	CheckForbiddenStatus(t, resp)
+
+	th.LoginTeamAdmin()
+	channel, resp := Client.PatchChannel(th.BasicChannel.Id, patch)
+	CheckNoError(t, resp)
+
+	if channel.PostRole != model.ROLE_CHANNEL_ADMIN.Id {
+		t.Fatal("should have restricted posting to channel admins")
+	}
+
+	_, resp = Client.CreatePost(&model.Post{ChannelId: channel.Id, Message: "announcement"})
+	CheckNoError(t, resp)
+
+	th.LoginBasic()
+	_, resp = Client.CreatePost(&model.Post{ChannelId: channel.Id, Message: "chatter"})
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = Client.CreatePost(&model.Post{ChannelId: channel.Id, RootId: th.BasicPost.Id, Message: "reply"})
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.CreatePost(&model.Post{ChannelId: channel.Id, Message: "announcement"})
+	CheckNoError(t, resp)
+
+	app.UpdateChannelMemberRoles(channel.Id, th.BasicUser.Id, model.ROLE_CHANNEL_USER.Id+" "+model.ROLE_CHANNEL_ADMIN.Id)
+	_, resp = Client.CreatePost(&model.Post{ChannelId: channel.Id, Message: "announcement"})
+	CheckNoError(t, resp)
+
+	postRole = "junk"
+	_, resp = th.SystemAdminClient.PatchChannel(channel.Id, patch)
+	CheckErrorMessage(t, resp, "model.channel.is_valid.post_role.app_error")
+
+	postRole = ""
+	_, resp = th.SystemAdminClient.PatchChannel(channel.Id, patch)
+	CheckNoError(t, resp)
+
+	Client.Login(th.BasicUser2.Email, th.BasicUser2.Password)
+	_, resp = Client.CreatePost(&model.Post{ChannelId: channel.Id, Message: "chatter"})
+	CheckNoError(t, resp)
+}
+
+func TestGetReadReceipts(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	dm, resp := Client.CreateDirectChannel(th.BasicUser.Id, th.BasicUser2.Id)
+	CheckNoError(t, resp)
+
+	post, resp := Client.CreatePost(&model.Post{ChannelId: dm.Id, Message: "are you there?"})
+	CheckNoError(t, resp)
+
+	receipts, resp := Client.GetReadReceipts(dm.Id, 0, 60)
+	CheckNoError(t, resp)
+
+	if userIds, ok := receipts[post.Id]; !ok || len(userIds) != 0 {
+		t.Fatal("nobody should have seen the post yet")
+	}
+
+	Client2 := th.CreateClient()
+	Client2.Login(th.BasicUser2.Email, th.BasicUser2.Password)
+
+	_, resp = Client2.ViewChannel(th.BasicUser2.Id, &model.ChannelView{ChannelId: dm.Id})
+	CheckNoError(t, resp)
+
+	receipts, resp = Client.GetReadReceipts(dm.Id, 0, 60)
+	CheckNoError(t, resp)
+
+	if userIds := receipts[post.Id]; len(userIds) != 1 || userIds[0] != th.BasicUser2.Id {
+		t.Fatal("the other user should have seen the post")
+	}
+
+	preferences := &model.Preferences{{UserId: th.BasicUser2.Id, Category: model.PREFERENCE_CATEGORY_PRIVACY, Name: model.PREFERENCE_NAME_SEND_READ_RECEIPTS, Value: "false"}}
+	_, resp = Client2.UpdatePreferences(th.BasicUser2.Id, preferences)
+	CheckNoError(t, resp)
+
+	receipts, resp = Client.GetReadReceipts(dm.Id, 0, 60)
+	CheckNoError(t, resp)
+
+	if userIds := receipts[post.Id]; len(userIds) != 0 {
+		t.Fatal("shouldn't show read receipts for a user who turned them off")
+	}
+
+	_, resp = Client.GetReadReceipts(th.BasicChannel.Id, 0, 60)
+	CheckBadRequestStatus(t, resp)
+
+	otherDm, resp := th.SystemAdminClient.CreateDirectChannel(th.SystemAdminUser.Id, th.BasicUser2.Id)
+	CheckNoError(t, resp)
+
+	_, resp = Client.GetReadReceipts(otherDm.Id, 0, 60)
+	CheckForbiddenStatus(t, resp)
+
+	Client.Logout()
+	_, resp = Client.GetReadReceipts(dm.Id, 0, 60)
+	CheckUnauthorizedStatus(t, resp)
+}
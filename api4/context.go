@@ -4,6 +4,7 @@
 package api4
 
 import (
+	"crypto/subtle"
 	"fmt"
 	"net/http"
 	"strings"
@@ -47,6 +48,19 @@ func ApiSessionRequired(h func(*Context, http.ResponseWriter, *http.Request)) ht
 	}
 }
 
+// ApiSessionRequiredWithScope is like ApiSessionRequired but lets OAuth
+// tokens with the given scope through, for requests that need a different
+// scope than their HTTP method implies.
+func ApiSessionRequiredWithScope(h func(*Context, http.ResponseWriter, *http.Request), scope string) http.Handler {
+	return &handler{
+		handleFunc:     h,
+		requireSession: true,
+		trustRequester: false,
+		requireMfa:     true,
+		requiredScope:  scope,
+	}
+}
+
 func ApiSessionRequiredMfa(h func(*Context, http.ResponseWriter, *http.Request)) http.Handler {
 	return &handler{
 		handleFunc:     h,
@@ -74,11 +88,36 @@ func ApiSessionRequiredTrustRequester(h func(*Context, http.ResponseWriter, *htt
 	}
 }
 
+// UserActivity marks requests to a handler as made by the user, so that they keep the session from being
+// considered idle. Other requests, such as polling for statuses, only count if the client sends the
+// X-User-Activity header.
+func UserActivity(h http.Handler) http.Handler {
+	h.(*handler).isUserActivity = true
+	return h
+}
+
 type handler struct {
 	handleFunc     func(*Context, http.ResponseWriter, *http.Request)
 	requireSession bool
 	trustRequester bool
 	requireMfa     bool
+	requiredScope  string
+	isUserActivity bool
+}
+
+// oauthScope returns the scope an OAuth token needs for the request. Unless
+// the handler says otherwise, reads need the read scope and anything else
+// needs the user scope.
+func (h handler) oauthScope(r *http.Request) string {
+	if len(h.requiredScope) > 0 {
+		return h.requiredScope
+	}
+
+	if r.Method == "GET" || r.Method == "HEAD" {
+		return model.OAUTH_SCOPE_READ
+	}
+
+	return model.OAUTH_SCOPE_USER
 }
 
 func (h handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -90,8 +129,10 @@ func (h handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	c.RequestId = model.NewId()
 	c.IpAddress = utils.GetIpAddress(r)
 	c.Params = ApiParamsFromRequest(r)
+	c.Path = r.URL.Path
 
 	token := ""
+	isTokenFromCookie := false
 	isTokenFromQueryString := false
 
 	// Attempt to parse token out of the header
@@ -109,6 +150,7 @@ func (h handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if len(token) == 0 {
 		if cookie, err := r.Cookie(model.SESSION_COOKIE_TOKEN); err == nil {
 			token = cookie.Value
+			isTokenFromCookie = true
 
 			if h.requireSession && !h.trustRequester {
 				if r.Header.Get(model.HEADER_REQUESTED_WITH) != model.HEADER_REQUESTED_WITH_XML {
@@ -147,6 +189,15 @@ func (h handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if len(token) != 0 {
 		session, err := app.GetSession(token)
 
+		if err == nil && isTokenFromCookie && !session.IsOAuth && len(session.GetCSRF()) == 0 {
+			// sessions from before CSRF tokens existed are given one rather than making their users log in again
+			if updated, csrfErr := app.AddSessionCSRFToken(w, r, session); csrfErr != nil {
+				l4g.Error(csrfErr.Error())
+			} else {
+				session = updated
+			}
+		}
+
 		if err != nil {
 			l4g.Error(utils.T("api.context.invalid_session.error"), err.Error())
 			c.RemoveSessionCookie(w, r)
@@ -157,17 +208,29 @@ func (h handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		} else if !session.IsOAuth && isTokenFromQueryString {
 			c.Err = model.NewLocAppError("ServeHTTP", "api.context.token_provided.app_error", nil, "token="+token)
 			c.Err.StatusCode = http.StatusUnauthorized
+		} else if isTokenFromCookie && h.requireSession && !h.trustRequester && !session.IsOAuth && !isCSRFTokenValid(r, session) {
+			c.Err = model.NewLocAppError("ServeHTTP", "api.context.csrf_token.app_error", nil, "session_id="+session.Id)
+			c.Err.StatusCode = http.StatusUnauthorized
+			c.LogAuditWithUserId(session.UserId, "failure - csrf token mismatch")
+			if einterfaces.GetMetricsInterface() != nil {
+				einterfaces.GetMetricsInterface().IncrementCsrfViolation()
+			}
 		} else {
+			if h.isUserActivity || r.Header.Get(model.HEADER_USER_ACTIVITY) == "true" {
+				app.UpdateLastActivityAtIfNeeded(session)
+			}
 			c.Session = *session
 		}
 	}
 
-	c.Path = r.URL.Path
-
 	if c.Err == nil && h.requireSession {
 		c.SessionRequired()
 	}
 
+	if c.Err == nil && h.requireSession {
+		c.OAuthScopeRequired(h.oauthScope(r))
+	}
+
 	if c.Err == nil && h.requireMfa {
 		c.MfaRequired()
 	}
@@ -253,9 +316,16 @@ func (c *Context) SessionRequired() {
 	}
 }
 
+func (c *Context) OAuthScopeRequired(scope string) {
+	if !c.Session.HasOAuthScope(scope) {
+		c.Err = model.NewLocAppError("", "api.context.oauth_scope.app_error", map[string]interface{}{"Scope": scope}, "")
+		c.Err.StatusCode = http.StatusForbidden
+	}
+}
+
 func (c *Context) MfaRequired() {
-	// Must be licensed for MFA and have it configured for enforcement
-	if !utils.IsLicensed || !*utils.License.Features.MFA || !*utils.Cfg.ServiceSettings.EnableMultifactorAuthentication || !*utils.Cfg.ServiceSettings.EnforceMultifactorAuthentication {
+	// Must have MFA configured for enforcement
+	if !*utils.Cfg.ServiceSettings.EnableMultifactorAuthentication || !*utils.Cfg.ServiceSettings.EnforceMultifactorAuthentication {
 		return
 	}
 
@@ -284,6 +354,21 @@ func (c *Context) MfaRequired() {
 	}
 }
 
+// isCSRFTokenValid checks the CSRF token of a request authenticated by the
+// session cookie. Requests that can't change anything don't need one.
+func isCSRFTokenValid(r *http.Request, session *model.Session) bool {
+	if r.Method == "GET" || r.Method == "HEAD" || r.Method == "OPTIONS" {
+		return true
+	}
+
+	expected := session.GetCSRF()
+	if len(expected) == 0 {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(r.Header.Get(model.HEADER_CSRF_TOKEN)), []byte(expected)) == 1
+}
+
 func (c *Context) RemoveSessionCookie(w http.ResponseWriter, r *http.Request) {
 	cookie := &http.Cookie{
 		Name:     model.SESSION_COOKIE_TOKEN,
@@ -294,6 +379,13 @@ func (c *Context) RemoveSessionCookie(w http.ResponseWriter, r *http.Request) {
 	}
 
 	http.SetCookie(w, cookie)
+
+	http.SetCookie(w, &http.Cookie{
+		Name:   model.SESSION_COOKIE_CSRF,
+		Value:  "",
+		Path:   "/",
+		MaxAge: -1,
+	})
 }
 
 func (c *Context) SetInvalidParam(parameter string) {
@@ -388,6 +480,17 @@ func (c *Context) RequirePostId() *Context {
 	return c
 }
 
+func (c *Context) RequireRevisionId() *Context {
+	if c.Err != nil {
+		return c
+	}
+
+	if len(c.Params.RevisionId) != 26 {
+		c.SetInvalidUrlParam("revision_id")
+	}
+	return c
+}
+
 func (c *Context) RequireFileId() *Context {
 	if c.Err != nil {
 		return c
@@ -482,3 +585,51 @@ func (c *Context) RequireHookId() *Context {
 
 	return c
 }
+
+func (c *Context) RequireMentionGroupId() *Context {
+	if c.Err != nil {
+		return c
+	}
+
+	if len(c.Params.MentionGroupId) != 26 {
+		c.SetInvalidUrlParam("mention_group_id")
+	}
+
+	return c
+}
+
+func (c *Context) RequireScheduledPostId() *Context {
+	if c.Err != nil {
+		return c
+	}
+
+	if len(c.Params.ScheduledPostId) != 26 {
+		c.SetInvalidUrlParam("scheduled_post_id")
+	}
+
+	return c
+}
+
+func (c *Context) RequireReminderId() *Context {
+	if c.Err != nil {
+		return c
+	}
+
+	if len(c.Params.ReminderId) != 26 {
+		c.SetInvalidUrlParam("reminder_id")
+	}
+
+	return c
+}
+
+func (c *Context) RequirePrivacyRequestId() *Context {
+	if c.Err != nil {
+		return c
+	}
+
+	if len(c.Params.PrivacyRequestId) != 26 {
+		c.SetInvalidUrlParam("privacy_request_id")
+	}
+
+	return c
+}
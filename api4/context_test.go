@@ -3,6 +3,8 @@ package api4
 import (
 	"net/http"
 	"testing"
+
+	"github.com/mattermost/platform/model"
 )
 
 func TestRequireHookId(t *testing.T) {
@@ -29,3 +31,64 @@ func TestRequireHookId(t *testing.T) {
 		}
 	})
 }
+
+func TestHandlerOAuthScope(t *testing.T) {
+	h := handler{}
+	if scope := h.oauthScope(&http.Request{Method: "GET"}); scope != model.OAUTH_SCOPE_READ {
+		t.Fatal("reads should need the read scope", scope)
+	}
+	if scope := h.oauthScope(&http.Request{Method: "PUT"}); scope != model.OAUTH_SCOPE_USER {
+		t.Fatal("writes should need the user scope", scope)
+	}
+
+	h.requiredScope = model.OAUTH_SCOPE_POST
+	if scope := h.oauthScope(&http.Request{Method: "POST"}); scope != model.OAUTH_SCOPE_POST {
+		t.Fatal("should use the handler's scope", scope)
+	}
+}
+
+func TestOAuthScopeRequired(t *testing.T) {
+	c := &Context{}
+	c.OAuthScopeRequired(model.OAUTH_SCOPE_ADMIN)
+	if c.Err != nil {
+		t.Fatal("sessions that aren't OAuth should have every scope")
+	}
+
+	c.Session.IsOAuth = true
+	c.Session.AddProp(model.SESSION_PROP_OAUTH_SCOPE, model.OAUTH_SCOPE_POST)
+	c.OAuthScopeRequired(model.OAUTH_SCOPE_READ)
+	if c.Err != nil {
+		t.Fatal("post scope should include read")
+	}
+
+	c.OAuthScopeRequired(model.OAUTH_SCOPE_USER)
+	if c.Err == nil || c.Err.StatusCode != http.StatusForbidden {
+		t.Fatal("should have failed with 403")
+	}
+}
+
+func TestIsCSRFTokenValid(t *testing.T) {
+	session := &model.Session{}
+
+	r, _ := http.NewRequest("GET", "/api/v4/users/me", nil)
+	if !isCSRFTokenValid(r, session) {
+		t.Fatal("reads shouldn't need a CSRF token")
+	}
+
+	r, _ = http.NewRequest("POST", "/api/v4/posts", nil)
+	r.Header.Set(model.HEADER_CSRF_TOKEN, "")
+	if isCSRFTokenValid(r, session) {
+		t.Fatal("sessions without a CSRF token should fail")
+	}
+
+	token := session.GenerateCSRF()
+	r.Header.Set(model.HEADER_CSRF_TOKEN, model.NewId())
+	if isCSRFTokenValid(r, session) {
+		t.Fatal("wrong CSRF token should fail")
+	}
+
+	r.Header.Set(model.HEADER_CSRF_TOKEN, token)
+	if !isCSRFTokenValid(r, session) {
+		t.Fatal("should be valid")
+	}
+}
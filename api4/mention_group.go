@@ -0,0 +1,247 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"net/http"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func InitMentionGroup() {
+	l4g.Debug(utils.T("api.mention_group.init.debug"))
+
+	BaseRoutes.MentionGroups.Handle("", ApiSessionRequired(createMentionGroup)).Methods("POST")
+	BaseRoutes.MentionGroupsForTeam.Handle("", ApiSessionRequired(getMentionGroupsForTeam)).Methods("GET")
+	BaseRoutes.MentionGroup.Handle("", ApiSessionRequired(getMentionGroup)).Methods("GET")
+	BaseRoutes.MentionGroup.Handle("/patch", ApiSessionRequired(patchMentionGroup)).Methods("PUT")
+	BaseRoutes.MentionGroup.Handle("", ApiSessionRequired(deleteMentionGroup)).Methods("DELETE")
+
+	BaseRoutes.MentionGroupMembers.Handle("", ApiSessionRequired(getMentionGroupMembers)).Methods("GET")
+	BaseRoutes.MentionGroupMembers.Handle("", ApiSessionRequired(addMentionGroupMember)).Methods("POST")
+	BaseRoutes.MentionGroupMember.Handle("", ApiSessionRequired(removeMentionGroupMember)).Methods("DELETE")
+}
+
+func createMentionGroup(c *Context, w http.ResponseWriter, r *http.Request) {
+	group := model.MentionGroupFromJson(r.Body)
+	if group == nil {
+		c.SetInvalidParam("mention_group")
+		return
+	}
+
+	if !app.SessionHasPermissionToTeam(c.Session, group.TeamId, model.PERMISSION_MANAGE_MENTION_GROUPS) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_MENTION_GROUPS)
+		return
+	}
+
+	c.LogAudit("attempt")
+
+	group.CreatorId = c.Session.UserId
+	group.CreateAt = 0
+	group.DeleteAt = 0
+
+	if rgroup, err := app.CreateMentionGroup(group); err != nil {
+		c.Err = err
+		return
+	} else {
+		c.LogAudit("success")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(rgroup.ToJson()))
+	}
+}
+
+func getMentionGroupsForTeam(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireTeamId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionToTeam(c.Session, c.Params.TeamId, model.PERMISSION_VIEW_TEAM) {
+		c.SetPermissionError(model.PERMISSION_VIEW_TEAM)
+		return
+	}
+
+	if groups, err := app.GetMentionGroupsForTeam(c.Params.TeamId); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(model.MentionGroupListToJson(groups)))
+	}
+}
+
+func getMentionGroup(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireMentionGroupId()
+	if c.Err != nil {
+		return
+	}
+
+	group, err := app.GetMentionGroup(c.Params.MentionGroupId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if !app.SessionHasPermissionToTeam(c.Session, group.TeamId, model.PERMISSION_VIEW_TEAM) {
+		c.SetPermissionError(model.PERMISSION_VIEW_TEAM)
+		return
+	}
+
+	w.Write([]byte(group.ToJson()))
+}
+
+func patchMentionGroup(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireMentionGroupId()
+	if c.Err != nil {
+		return
+	}
+
+	patch := model.MentionGroupPatchFromJson(r.Body)
+	if patch == nil {
+		c.SetInvalidParam("mention_group")
+		return
+	}
+
+	group, err := app.GetMentionGroup(c.Params.MentionGroupId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if !app.SessionHasPermissionToTeam(c.Session, group.TeamId, model.PERMISSION_MANAGE_MENTION_GROUPS) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_MENTION_GROUPS)
+		return
+	}
+
+	c.LogAudit("attempt")
+
+	if rgroup, err := app.PatchMentionGroup(group, patch); err != nil {
+		c.Err = err
+		return
+	} else {
+		c.LogAudit("success")
+		w.Write([]byte(rgroup.ToJson()))
+	}
+}
+
+func deleteMentionGroup(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireMentionGroupId()
+	if c.Err != nil {
+		return
+	}
+
+	group, err := app.GetMentionGroup(c.Params.MentionGroupId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if !app.SessionHasPermissionToTeam(c.Session, group.TeamId, model.PERMISSION_MANAGE_MENTION_GROUPS) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_MENTION_GROUPS)
+		return
+	}
+
+	c.LogAudit("attempt")
+
+	if err := app.DeleteMentionGroup(group.Id); err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("success")
+	ReturnStatusOK(w)
+}
+
+func getMentionGroupMembers(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireMentionGroupId()
+	if c.Err != nil {
+		return
+	}
+
+	group, err := app.GetMentionGroup(c.Params.MentionGroupId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if !app.SessionHasPermissionToTeam(c.Session, group.TeamId, model.PERMISSION_VIEW_TEAM) {
+		c.SetPermissionError(model.PERMISSION_VIEW_TEAM)
+		return
+	}
+
+	if members, err := app.GetMentionGroupMembersPage(group.Id, c.Params.Page, c.Params.PerPage); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(model.MentionGroupMembersToJson(members)))
+	}
+}
+
+func addMentionGroupMember(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireMentionGroupId()
+	if c.Err != nil {
+		return
+	}
+
+	props := model.MapFromJson(r.Body)
+	userId := props["user_id"]
+	if len(userId) != 26 {
+		c.SetInvalidParam("user_id")
+		return
+	}
+
+	group, err := app.GetMentionGroup(c.Params.MentionGroupId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if !app.SessionHasPermissionToTeam(c.Session, group.TeamId, model.PERMISSION_MANAGE_MENTION_GROUPS) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_MENTION_GROUPS)
+		return
+	}
+
+	c.LogAudit("attempt")
+
+	if member, err := app.AddMentionGroupMember(group, userId); err != nil {
+		c.Err = err
+		return
+	} else {
+		c.LogAudit("success")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(member.ToJson()))
+	}
+}
+
+func removeMentionGroupMember(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireMentionGroupId().RequireUserId()
+	if c.Err != nil {
+		return
+	}
+
+	group, err := app.GetMentionGroup(c.Params.MentionGroupId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if c.Session.UserId != c.Params.UserId {
+		if !app.SessionHasPermissionToTeam(c.Session, group.TeamId, model.PERMISSION_MANAGE_MENTION_GROUPS) {
+			c.SetPermissionError(model.PERMISSION_MANAGE_MENTION_GROUPS)
+			return
+		}
+	}
+
+	c.LogAudit("attempt")
+
+	if err := app.RemoveMentionGroupMember(group.Id, c.Params.UserId); err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("success")
+	ReturnStatusOK(w)
+}
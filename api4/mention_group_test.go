@@ -0,0 +1,183 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestCreateMentionGroup(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	group := &model.MentionGroup{TeamId: th.BasicTeam.Id, Name: "oncall" + model.NewId()[:8], DisplayName: "On Call"}
+
+	_, resp := Client.CreateMentionGroup(group)
+	CheckForbiddenStatus(t, resp)
+
+	th.LoginTeamAdmin()
+	rgroup, resp := Client.CreateMentionGroup(group)
+	CheckNoError(t, resp)
+	CheckCreatedStatus(t, resp)
+
+	if rgroup.Name != group.Name {
+		t.Fatal("names did not match")
+	}
+
+	if rgroup.CreatorId != th.TeamAdminUser.Id {
+		t.Fatal("creator id should be the team admin")
+	}
+
+	forged := &model.MentionGroup{TeamId: th.BasicTeam.Id, Name: "forged" + model.NewId()[:8], CreateAt: 1, DeleteAt: 1}
+	if rforged, resp := Client.CreateMentionGroup(forged); resp.Error != nil {
+		t.Fatal(resp.Error)
+	} else if rforged.CreateAt == 1 || rforged.DeleteAt != 0 {
+		t.Fatal("shouldn't have used the create and delete times from the request")
+	}
+
+	_, resp = Client.CreateMentionGroup(group)
+	CheckBadRequestStatus(t, resp)
+
+	group.Name = "channel"
+	_, resp = Client.CreateMentionGroup(group)
+	CheckBadRequestStatus(t, resp)
+
+	group.Name = th.BasicUser.Username
+	_, resp = Client.CreateMentionGroup(group)
+	CheckBadRequestStatus(t, resp)
+
+	group.Name = "sysadmins" + model.NewId()[:8]
+	_, resp = th.SystemAdminClient.CreateMentionGroup(group)
+	CheckNoError(t, resp)
+
+	Client.Logout()
+	_, resp = Client.CreateMentionGroup(group)
+	CheckUnauthorizedStatus(t, resp)
+}
+
+func TestGetMentionGroups(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	group := &model.MentionGroup{TeamId: th.BasicTeam.Id, Name: "frontend" + model.NewId()[:8]}
+	rgroup, resp := th.SystemAdminClient.CreateMentionGroup(group)
+	CheckNoError(t, resp)
+
+	groups, resp := Client.GetMentionGroupsForTeam(th.BasicTeam.Id, "")
+	CheckNoError(t, resp)
+
+	if len(groups) != 1 || groups[0].Id != rgroup.Id {
+		t.Fatal("should have returned the group")
+	}
+
+	g, resp := Client.GetMentionGroup(rgroup.Id, "")
+	CheckNoError(t, resp)
+
+	if g.Name != rgroup.Name {
+		t.Fatal("names did not match")
+	}
+
+	_, resp = Client.GetMentionGroup(model.NewId(), "")
+	CheckNotFoundStatus(t, resp)
+
+	_, resp = Client.GetMentionGroup("junk", "")
+	CheckBadRequestStatus(t, resp)
+
+	otherTeam := th.CreateTeamWithClient(th.SystemAdminClient)
+	_, resp = Client.GetMentionGroupsForTeam(otherTeam.Id, "")
+	CheckForbiddenStatus(t, resp)
+}
+
+func TestPatchAndDeleteMentionGroup(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	group := &model.MentionGroup{TeamId: th.BasicTeam.Id, Name: "backend" + model.NewId()[:8]}
+	rgroup, resp := th.SystemAdminClient.CreateMentionGroup(group)
+	CheckNoError(t, resp)
+
+	patch := &model.MentionGroupPatch{DisplayName: new(string)}
+	*patch.DisplayName = "Backend Team"
+
+	_, resp = Client.PatchMentionGroup(rgroup.Id, patch)
+	CheckForbiddenStatus(t, resp)
+
+	patched, resp := th.SystemAdminClient.PatchMentionGroup(rgroup.Id, patch)
+	CheckNoError(t, resp)
+
+	if patched.DisplayName != "Backend Team" || patched.Name != rgroup.Name {
+		t.Fatal("group was not patched correctly")
+	}
+
+	_, resp = Client.DeleteMentionGroup(rgroup.Id)
+	CheckForbiddenStatus(t, resp)
+
+	pass, resp := th.SystemAdminClient.DeleteMentionGroup(rgroup.Id)
+	CheckNoError(t, resp)
+
+	if !pass {
+		t.Fatal("should have returned true")
+	}
+
+	_, resp = Client.GetMentionGroup(rgroup.Id, "")
+	CheckNotFoundStatus(t, resp)
+}
+
+func TestMentionGroupMembers(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	group := &model.MentionGroup{TeamId: th.BasicTeam.Id, Name: "leads" + model.NewId()[:8]}
+	rgroup, resp := th.SystemAdminClient.CreateMentionGroup(group)
+	CheckNoError(t, resp)
+
+	_, resp = Client.AddMentionGroupMember(rgroup.Id, th.BasicUser2.Id)
+	CheckForbiddenStatus(t, resp)
+
+	member, resp := th.SystemAdminClient.AddMentionGroupMember(rgroup.Id, th.BasicUser.Id)
+	CheckNoError(t, resp)
+	CheckCreatedStatus(t, resp)
+
+	if member.GroupId != rgroup.Id || member.UserId != th.BasicUser.Id {
+		t.Fatal("member did not match")
+	}
+
+	_, resp = th.SystemAdminClient.AddMentionGroupMember(rgroup.Id, th.BasicUser2.Id)
+	CheckNoError(t, resp)
+
+	_, resp = th.SystemAdminClient.AddMentionGroupMember(rgroup.Id, th.SystemAdminUser.Id)
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.AddMentionGroupMember(rgroup.Id, "junk")
+	CheckBadRequestStatus(t, resp)
+
+	members, resp := Client.GetMentionGroupMembers(rgroup.Id, 0, 60, "")
+	CheckNoError(t, resp)
+
+	if len(members) != 2 {
+		t.Fatal("should have returned 2 members")
+	}
+
+	_, resp = Client.RemoveMentionGroupMember(rgroup.Id, th.BasicUser2.Id)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = Client.RemoveMentionGroupMember(rgroup.Id, th.BasicUser.Id)
+	CheckNoError(t, resp)
+
+	_, resp = th.SystemAdminClient.RemoveMentionGroupMember(rgroup.Id, th.BasicUser2.Id)
+	CheckNoError(t, resp)
+
+	members, resp = Client.GetMentionGroupMembers(rgroup.Id, 0, 60, "")
+	CheckNoError(t, resp)
+
+	if len(members) != 0 {
+		t.Fatal("should have removed all members")
+	}
+}
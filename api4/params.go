@@ -17,23 +17,28 @@ const (
 )
 
 type ApiParams struct {
-	UserId         string
-	TeamId         string
-	ChannelId      string
-	PostId         string
-	FileId         string
-	CommandId      string
-	HookId         string
-	ReportId       string
-	EmojiId        string
-	Email          string
-	Username       string
-	TeamName       string
-	ChannelName    string
-	PreferenceName string
-	Category       string
-	Page           int
-	PerPage        int
+	UserId           string
+	TeamId           string
+	ChannelId        string
+	PostId           string
+	RevisionId       string
+	FileId           string
+	CommandId        string
+	HookId           string
+	MentionGroupId   string
+	ScheduledPostId  string
+	ReminderId       string
+	PrivacyRequestId string
+	ReportId         string
+	EmojiId          string
+	Email            string
+	Username         string
+	TeamName         string
+	ChannelName      string
+	PreferenceName   string
+	Category         string
+	Page             int
+	PerPage          int
 }
 
 func ApiParamsFromRequest(r *http.Request) *ApiParams {
@@ -57,6 +62,10 @@ func ApiParamsFromRequest(r *http.Request) *ApiParams {
 		params.PostId = val
 	}
 
+	if val, ok := props["revision_id"]; ok {
+		params.RevisionId = val
+	}
+
 	if val, ok := props["file_id"]; ok {
 		params.FileId = val
 	}
@@ -69,6 +78,22 @@ func ApiParamsFromRequest(r *http.Request) *ApiParams {
 		params.HookId = val
 	}
 
+	if val, ok := props["mention_group_id"]; ok {
+		params.MentionGroupId = val
+	}
+
+	if val, ok := props["scheduled_post_id"]; ok {
+		params.ScheduledPostId = val
+	}
+
+	if val, ok := props["reminder_id"]; ok {
+		params.ReminderId = val
+	}
+
+	if val, ok := props["privacy_request_id"]; ok {
+		params.PrivacyRequestId = val
+	}
+
 	if val, ok := props["report_id"]; ok {
 		params.ReportId = val
 	}
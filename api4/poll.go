@@ -0,0 +1,80 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"net/http"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func InitPoll() {
+	l4g.Debug(utils.T("api.poll.init.debug"))
+
+	BaseRoutes.Post.Handle("/poll/votes", ApiSessionRequired(getPollVotes)).Methods("GET")
+	BaseRoutes.Post.Handle("/poll/vote", ApiSessionRequired(voteInPoll)).Methods("POST")
+	BaseRoutes.Post.Handle("/poll/close", ApiSessionRequired(closePoll)).Methods("POST")
+}
+
+func getPollVotes(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequirePostId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionToChannelByPost(c.Session, c.Params.PostId, model.PERMISSION_READ_CHANNEL) {
+		c.SetPermissionError(model.PERMISSION_READ_CHANNEL)
+		return
+	}
+
+	if votes, err := app.GetPollVotes(c.Params.PostId, c.Session.UserId); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(model.PollVotesToJson(votes)))
+	}
+}
+
+func voteInPoll(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequirePostId()
+	if c.Err != nil {
+		return
+	}
+
+	optionIds := model.ArrayFromJson(r.Body)
+
+	if !app.SessionHasPermissionToChannelByPost(c.Session, c.Params.PostId, model.PERMISSION_READ_CHANNEL) {
+		c.SetPermissionError(model.PERMISSION_READ_CHANNEL)
+		return
+	}
+
+	if post, err := app.VoteInPoll(c.Params.PostId, c.Session.UserId, optionIds); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(app.PreparePostForUser(post, c.Session.UserId).ToJson()))
+	}
+}
+
+func closePoll(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequirePostId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionToPost(c.Session, c.Params.PostId, model.PERMISSION_EDIT_OTHERS_POSTS) {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHERS_POSTS)
+		return
+	}
+
+	if post, err := app.ClosePoll(c.Params.PostId); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(app.PreparePostForUser(post, c.Session.UserId).ToJson()))
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestPolls(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	post := &model.Post{ChannelId: th.BasicChannel.Id, Message: "Lunch?", Type: model.POST_POLL}
+	post.AddProp(model.POST_PROPS_POLL, &model.Poll{Options: []*model.PollOption{{Text: "Pizza", VoteCount: 10}, {Text: "Thai"}}})
+
+	post, resp := Client.CreatePost(post)
+	CheckNoError(t, resp)
+
+	poll := model.PollFromPost(post)
+	if poll == nil || len(poll.Options) != 2 || poll.Options[0].Id == "" || poll.Options[0].VoteCount != 0 {
+		t.Fatal("should have started the poll without any votes")
+	}
+
+	_, resp = Client.CreatePost(&model.Post{ChannelId: th.BasicChannel.Id, Message: "Lunch?", Type: model.POST_POLL})
+	CheckBadRequestStatus(t, resp)
+
+	Client2 := th.CreateClient()
+	Client2.Login(th.BasicUser2.Email, th.BasicUser2.Password)
+
+	_, resp = Client2.VoteInPoll(post.Id, []string{poll.Options[0].Id, poll.Options[1].Id})
+	CheckBadRequestStatus(t, resp)
+	CheckErrorMessage(t, resp, "api.poll.single_choice.app_error")
+
+	_, resp = Client2.VoteInPoll(post.Id, []string{model.NewId()})
+	CheckBadRequestStatus(t, resp)
+
+	voted, resp := Client2.VoteInPoll(post.Id, []string{poll.Options[1].Id})
+	CheckNoError(t, resp)
+
+	if rpoll := model.PollFromPost(voted); rpoll.Options[1].VoteCount != 1 || rpoll.VoterCount != 1 {
+		t.Fatal("should have counted the vote")
+	}
+
+	voted, resp = Client.VoteInPoll(post.Id, []string{poll.Options[1].Id})
+	CheckNoError(t, resp)
+
+	voted, resp = Client2.VoteInPoll(post.Id, []string{poll.Options[0].Id})
+	CheckNoError(t, resp)
+
+	if rpoll := model.PollFromPost(voted); rpoll.Options[0].VoteCount != 1 || rpoll.Options[1].VoteCount != 1 || rpoll.VoterCount != 2 {
+		t.Fatal("should have changed the vote")
+	}
+
+	votes, resp := Client.GetPollVotes(post.Id)
+	CheckNoError(t, resp)
+
+	if len(votes) != 2 {
+		t.Fatal("should have returned everyone's votes")
+	}
+
+	// editing the post shouldn't change the poll
+	post.Message = "Dinner?"
+	post.AddProp(model.POST_PROPS_POLL, poll)
+	edited, resp := Client.UpdatePost(post.Id, post)
+	CheckNoError(t, resp)
+
+	if rpoll := model.PollFromPost(edited); rpoll.VoterCount != 2 {
+		t.Fatal("shouldn't have changed the poll by editing the post")
+	}
+
+	_, resp = Client2.ClosePoll(post.Id)
+	CheckForbiddenStatus(t, resp)
+
+	closed, resp := Client.ClosePoll(post.Id)
+	CheckNoError(t, resp)
+
+	if model.PollFromPost(closed).CloseAt == 0 {
+		t.Fatal("should have closed the poll")
+	}
+
+	_, resp = Client2.VoteInPoll(post.Id, []string{poll.Options[1].Id})
+	CheckBadRequestStatus(t, resp)
+	CheckErrorMessage(t, resp, "api.poll.closed.app_error")
+
+	_, resp = Client.VoteInPoll(th.CreatePost().Id, []string{poll.Options[1].Id})
+	CheckBadRequestStatus(t, resp)
+	CheckErrorMessage(t, resp, "api.poll.not_poll.app_error")
+
+	privatePost := &model.Post{ChannelId: th.CreatePrivateChannel().Id, Message: "Secret?", Type: model.POST_POLL}
+	privatePost.AddProp(model.POST_PROPS_POLL, &model.Poll{Options: []*model.PollOption{{Text: "Yes"}, {Text: "No"}}, Anonymous: true, MultipleChoice: true})
+	privatePost, resp = Client.CreatePost(privatePost)
+	CheckNoError(t, resp)
+
+	privatePoll := model.PollFromPost(privatePost)
+
+	_, resp = Client2.VoteInPoll(privatePost.Id, []string{privatePoll.Options[0].Id})
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = Client.VoteInPoll(privatePost.Id, []string{privatePoll.Options[0].Id, privatePoll.Options[1].Id})
+	CheckNoError(t, resp)
+
+	_, resp = th.SystemAdminClient.VoteInPoll(privatePost.Id, []string{privatePoll.Options[0].Id})
+	CheckNoError(t, resp)
+
+	votes, resp = Client.GetPollVotes(privatePost.Id)
+	CheckNoError(t, resp)
+
+	if len(votes) != 2 || votes[0].UserId != th.BasicUser.Id || votes[1].UserId != th.BasicUser.Id {
+		t.Fatal("should only have returned the user's own votes in an anonymous poll")
+	}
+}
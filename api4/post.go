@@ -16,16 +16,19 @@ import (
 func InitPost() {
 	l4g.Debug(utils.T("api.post.init.debug"))
 
-	BaseRoutes.Posts.Handle("", ApiSessionRequired(createPost)).Methods("POST")
+	BaseRoutes.Posts.Handle("", UserActivity(ApiSessionRequiredWithScope(createPost, model.OAUTH_SCOPE_POST))).Methods("POST")
 	BaseRoutes.Post.Handle("", ApiSessionRequired(getPost)).Methods("GET")
-	BaseRoutes.Post.Handle("", ApiSessionRequired(deletePost)).Methods("DELETE")
+	BaseRoutes.Post.Handle("", UserActivity(ApiSessionRequiredWithScope(deletePost, model.OAUTH_SCOPE_POST))).Methods("DELETE")
 	BaseRoutes.Post.Handle("/thread", ApiSessionRequired(getPostThread)).Methods("GET")
 	BaseRoutes.Post.Handle("/files/info", ApiSessionRequired(getFileInfosForPost)).Methods("GET")
 	BaseRoutes.PostsForChannel.Handle("", ApiSessionRequired(getPostsForChannel)).Methods("GET")
 
-	BaseRoutes.Team.Handle("/posts/search", ApiSessionRequired(searchPosts)).Methods("POST")
-	BaseRoutes.Post.Handle("", ApiSessionRequired(updatePost)).Methods("PUT")
-	BaseRoutes.Post.Handle("/patch", ApiSessionRequired(patchPost)).Methods("PUT")
+	BaseRoutes.Team.Handle("/posts/search", UserActivity(ApiSessionRequiredWithScope(searchPosts, model.OAUTH_SCOPE_READ))).Methods("POST")
+	BaseRoutes.Post.Handle("", UserActivity(ApiSessionRequiredWithScope(updatePost, model.OAUTH_SCOPE_POST))).Methods("PUT")
+	BaseRoutes.Post.Handle("/patch", UserActivity(ApiSessionRequiredWithScope(patchPost, model.OAUTH_SCOPE_POST))).Methods("PUT")
+	BaseRoutes.Post.Handle("/revisions", ApiSessionRequired(getPostRevisions)).Methods("GET")
+	BaseRoutes.Post.Handle("/revisions/{revision_id:[A-Za-z0-9]+}/restore", ApiSessionRequired(restorePostRevision)).Methods("POST")
+	BaseRoutes.Post.Handle("/forward", UserActivity(ApiSessionRequiredWithScope(forwardPost, model.OAUTH_SCOPE_POST))).Methods("POST")
 	BaseRoutes.Post.Handle("/pin", ApiSessionRequired(pinPost)).Methods("POST")
 	BaseRoutes.Post.Handle("/unpin", ApiSessionRequired(unpinPost)).Methods("POST")
 }
@@ -55,7 +58,7 @@ func createPost(c *Context, w http.ResponseWriter, r *http.Request) {
 	}
 
 	w.WriteHeader(http.StatusCreated)
-	w.Write([]byte(rp.ToJson()))
+	w.Write([]byte(app.PreparePostForUser(rp, c.Session.UserId).ToJson()))
 }
 
 func getPostsForChannel(c *Context, w http.ResponseWriter, r *http.Request) {
@@ -124,7 +127,7 @@ func getPostsForChannel(c *Context, w http.ResponseWriter, r *http.Request) {
 	if len(etag) > 0 {
 		w.Header().Set(model.HEADER_ETAG_SERVER, etag)
 	}
-	w.Write([]byte(list.ToJson()))
+	w.Write([]byte(app.PreparePostListForUser(list, c.Session.UserId).ToJson()))
 }
 
 func getPost(c *Context, w http.ResponseWriter, r *http.Request) {
@@ -145,7 +148,7 @@ func getPost(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	} else {
 		w.Header().Set(model.HEADER_ETAG_SERVER, post.Etag())
-		w.Write([]byte(post.ToJson()))
+		w.Write([]byte(app.PreparePostForUser(post, c.Session.UserId).ToJson()))
 	}
 }
 
@@ -186,7 +189,7 @@ func getPostThread(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	} else {
 		w.Header().Set(model.HEADER_ETAG_SERVER, list.Etag())
-		w.Write([]byte(list.ToJson()))
+		w.Write([]byte(app.PreparePostListForUser(list, c.Session.UserId).ToJson()))
 	}
 }
 
@@ -221,7 +224,7 @@ func searchPosts(c *Context, w http.ResponseWriter, r *http.Request) {
 	}
 
 	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
-	w.Write([]byte(posts.ToJson()))
+	w.Write([]byte(app.PreparePostListForUser(posts, c.Session.UserId).ToJson()))
 }
 
 func updatePost(c *Context, w http.ResponseWriter, r *http.Request) {
@@ -246,7 +249,7 @@ func updatePost(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	w.Write([]byte(rpost.ToJson()))
+	w.Write([]byte(app.PreparePostForUser(rpost, c.Session.UserId).ToJson()))
 }
 
 func patchPost(c *Context, w http.ResponseWriter, r *http.Request) {
@@ -273,7 +276,78 @@ func patchPost(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	w.Write([]byte(patchedPost.ToJson()))
+	w.Write([]byte(app.PreparePostForUser(patchedPost, c.Session.UserId).ToJson()))
+}
+
+func getPostRevisions(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequirePostId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionToPost(c.Session, c.Params.PostId, model.PERMISSION_EDIT_OTHERS_POSTS) {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHERS_POSTS)
+		return
+	}
+
+	if revisions, err := app.GetPostRevisions(c.Params.PostId); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(app.PreparePostListForUser(revisions, c.Session.UserId).ToJson()))
+	}
+}
+
+func restorePostRevision(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequirePostId().RequireRevisionId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionToPost(c.Session, c.Params.PostId, model.PERMISSION_EDIT_OTHERS_POSTS) {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHERS_POSTS)
+		return
+	}
+
+	if post, err := app.RestorePostRevision(c.Params.PostId, c.Params.RevisionId); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(app.PreparePostForUser(post, c.Session.UserId).ToJson()))
+	}
+}
+
+func forwardPost(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequirePostId()
+	if c.Err != nil {
+		return
+	}
+
+	props := model.MapFromJson(r.Body)
+
+	channelId := props["channel_id"]
+	if len(channelId) != 26 {
+		c.SetInvalidParam("channel_id")
+		return
+	}
+
+	if !app.SessionHasPermissionToChannelByPost(c.Session, c.Params.PostId, model.PERMISSION_READ_CHANNEL) {
+		c.SetPermissionError(model.PERMISSION_READ_CHANNEL)
+		return
+	}
+
+	if !app.SessionHasPermissionToChannel(c.Session, channelId, model.PERMISSION_CREATE_POST) {
+		c.SetPermissionError(model.PERMISSION_CREATE_POST)
+		return
+	}
+
+	if post, err := app.ForwardPost(c.Params.PostId, channelId, c.Session.UserId, props["message"], c.GetSiteURL()); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(app.PreparePostForUser(post, c.Session.UserId).ToJson()))
+	}
 }
 
 func saveIsPinnedPost(c *Context, w http.ResponseWriter, r *http.Request, isPinned bool) {
@@ -0,0 +1,99 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"net/http"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func InitPostAcknowledgement() {
+	l4g.Debug(utils.T("api.post_acknowledgement.init.debug"))
+
+	BaseRoutes.Post.Handle("/ack", ApiSessionRequired(acknowledgePost)).Methods("POST")
+	BaseRoutes.Post.Handle("/ack", ApiSessionRequired(unacknowledgePost)).Methods("DELETE")
+	BaseRoutes.Post.Handle("/acknowledgements", ApiSessionRequired(getPostAcknowledgements)).Methods("GET")
+	BaseRoutes.Post.Handle("/acknowledgements/pending", ApiSessionRequired(getUnacknowledgedUserIds)).Methods("GET")
+}
+
+func acknowledgePost(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequirePostId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionToChannelByPost(c.Session, c.Params.PostId, model.PERMISSION_READ_CHANNEL) {
+		c.SetPermissionError(model.PERMISSION_READ_CHANNEL)
+		return
+	}
+
+	if ack, err := app.AcknowledgePost(c.Params.PostId, c.Session.UserId); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(ack.ToJson()))
+	}
+}
+
+func unacknowledgePost(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequirePostId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionToChannelByPost(c.Session, c.Params.PostId, model.PERMISSION_READ_CHANNEL) {
+		c.SetPermissionError(model.PERMISSION_READ_CHANNEL)
+		return
+	}
+
+	if err := app.UnacknowledgePost(c.Params.PostId, c.Session.UserId); err != nil {
+		c.Err = err
+		return
+	}
+
+	ReturnStatusOK(w)
+}
+
+func getPostAcknowledgements(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequirePostId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionToChannelByPost(c.Session, c.Params.PostId, model.PERMISSION_READ_CHANNEL) {
+		c.SetPermissionError(model.PERMISSION_READ_CHANNEL)
+		return
+	}
+
+	if acks, err := app.GetPostAcknowledgements(c.Params.PostId); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(model.PostAcknowledgementsToJson(acks)))
+	}
+}
+
+func getUnacknowledgedUserIds(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequirePostId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionToChannelByPost(c.Session, c.Params.PostId, model.PERMISSION_READ_CHANNEL) {
+		c.SetPermissionError(model.PERMISSION_READ_CHANNEL)
+		return
+	}
+
+	if userIds, err := app.GetUnacknowledgedUserIds(c.Params.PostId); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(model.ArrayToJson(userIds)))
+	}
+}
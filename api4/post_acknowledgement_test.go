@@ -0,0 +1,118 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestPostAcknowledgements(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	post := &model.Post{ChannelId: th.BasicChannel.Id, Message: "Evacuate the building"}
+	post.AddProp(model.POST_PROPS_PRIORITY, model.POST_PRIORITY_URGENT)
+	post.AddProp(model.POST_PROPS_REQUESTED_ACK, true)
+
+	_, resp := Client.CreatePost(post)
+	CheckForbiddenStatus(t, resp)
+	CheckErrorMessage(t, resp, "api.post.create_post.priority_permission.app_error")
+
+	ackOnly := &model.Post{ChannelId: th.BasicChannel.Id, Message: "Please read"}
+	ackOnly.AddProp(model.POST_PROPS_REQUESTED_ACK, true)
+	_, resp = Client.CreatePost(ackOnly)
+	CheckForbiddenStatus(t, resp)
+
+	important := &model.Post{ChannelId: th.BasicChannel.Id, Message: "Heads up"}
+	important.AddProp(model.POST_PROPS_PRIORITY, model.POST_PRIORITY_IMPORTANT)
+	_, resp = Client.CreatePost(important)
+	CheckNoError(t, resp)
+
+	MakeUserChannelAdmin(th.BasicUser, th.BasicChannel)
+
+	post, resp = Client.CreatePost(post)
+	CheckNoError(t, resp)
+
+	if !post.IsUrgent() || !post.RequestsAcknowledgement() {
+		t.Fatal("should have kept the priority")
+	}
+
+	reply := &model.Post{ChannelId: th.BasicChannel.Id, RootId: post.Id, Message: "ok"}
+	reply.AddProp(model.POST_PROPS_REQUESTED_ACK, true)
+	_, resp = Client.CreatePost(reply)
+	CheckBadRequestStatus(t, resp)
+
+	pending, resp := Client.GetUnacknowledgedUserIds(post.Id)
+	CheckNoError(t, resp)
+
+	if len(pending) != 2 {
+		t.Fatal("should have been waiting on the other members of the channel")
+	}
+
+	Client2 := th.CreateClient()
+	Client2.Login(th.BasicUser2.Email, th.BasicUser2.Password)
+
+	ack, resp := Client2.AcknowledgePost(post.Id)
+	CheckNoError(t, resp)
+	CheckCreatedStatus(t, resp)
+
+	if ack.PostId != post.Id || ack.UserId != th.BasicUser2.Id || ack.AcknowledgedAt == 0 {
+		t.Fatal("returned the wrong acknowledgement")
+	}
+
+	if again, resp := Client2.AcknowledgePost(post.Id); resp.Error != nil || again.AcknowledgedAt != ack.AcknowledgedAt {
+		t.Fatal("acknowledging twice should return the first acknowledgement")
+	}
+
+	acks, resp := Client.GetPostAcknowledgements(post.Id)
+	CheckNoError(t, resp)
+
+	if len(acks) != 1 || acks[0].UserId != th.BasicUser2.Id {
+		t.Fatal("should have returned the acknowledgement")
+	}
+
+	pending, _ = Client.GetUnacknowledgedUserIds(post.Id)
+	for _, id := range pending {
+		if id == th.BasicUser2.Id || id == th.BasicUser.Id {
+			t.Fatal("shouldn't be waiting on the author or users who acknowledged the post")
+		}
+	}
+
+	post.Message = "Evacuate the building now"
+	post.AddProp(model.POST_PROPS_PRIORITY, model.POST_PRIORITY_IMPORTANT)
+	post, resp = Client.UpdatePost(post.Id, post)
+	CheckNoError(t, resp)
+
+	if !post.IsUrgent() {
+		t.Fatal("shouldn't have changed the priority of the post")
+	}
+
+	_, resp = Client2.UnacknowledgePost(post.Id)
+	CheckNoError(t, resp)
+
+	_, resp = Client2.UnacknowledgePost(post.Id)
+	CheckNotFoundStatus(t, resp)
+
+	if acks, _ := Client.GetPostAcknowledgements(post.Id); len(acks) != 0 {
+		t.Fatal("should have taken back the acknowledgement")
+	}
+
+	_, resp = Client.AcknowledgePost(th.BasicPost.Id)
+	CheckBadRequestStatus(t, resp)
+
+	privatePost := th.CreatePostWithClient(Client, th.CreatePrivateChannel())
+
+	_, resp = Client2.AcknowledgePost(privatePost.Id)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = Client2.GetPostAcknowledgements(privatePost.Id)
+	CheckForbiddenStatus(t, resp)
+
+	Client.Logout()
+	_, resp = Client.AcknowledgePost(post.Id)
+	CheckUnauthorizedStatus(t, resp)
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"net/http"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func InitPostReminder() {
+	l4g.Debug(utils.T("api.post_reminder.init.debug"))
+
+	BaseRoutes.PostRemindersForPost.Handle("", ApiSessionRequired(createPostReminder)).Methods("POST")
+	BaseRoutes.PostRemindersForUser.Handle("", ApiSessionRequired(getPostRemindersForUser)).Methods("GET")
+	BaseRoutes.PostReminder.Handle("", ApiSessionRequired(deletePostReminder)).Methods("DELETE")
+}
+
+func createPostReminder(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequirePostId()
+	if c.Err != nil {
+		return
+	}
+
+	props := model.StringInterfaceFromJson(r.Body)
+
+	remindAt, ok := props["remind_at"].(float64)
+	if !ok || remindAt <= 0 {
+		c.SetInvalidParam("remind_at")
+		return
+	}
+
+	if !app.SessionHasPermissionToChannelByPost(c.Session, c.Params.PostId, model.PERMISSION_READ_CHANNEL) {
+		c.SetPermissionError(model.PERMISSION_READ_CHANNEL)
+		return
+	}
+
+	reminder := &model.PostReminder{
+		UserId:   c.Session.UserId,
+		PostId:   c.Params.PostId,
+		RemindAt: int64(remindAt),
+	}
+
+	if rreminder, err := app.CreatePostReminder(reminder); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(rreminder.ToJson()))
+	}
+}
+
+func getPostRemindersForUser(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionToUser(c.Session, c.Params.UserId) {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return
+	}
+
+	if reminders, err := app.GetPostRemindersForUser(c.Params.UserId); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(model.PostReminderListToJson(reminders)))
+	}
+}
+
+func deletePostReminder(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireReminderId()
+	if c.Err != nil {
+		return
+	}
+
+	if reminder, err := app.GetPostReminder(c.Params.ReminderId); err != nil {
+		c.Err = err
+		return
+	} else if reminder.UserId != c.Session.UserId {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return
+	}
+
+	if err := app.DeletePostReminder(c.Params.ReminderId); err != nil {
+		c.Err = err
+		return
+	}
+
+	ReturnStatusOK(w)
+}
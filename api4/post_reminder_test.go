@@ -0,0 +1,101 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+)
+
+func TestPostReminders(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	post := th.CreatePost()
+	remindAt := model.GetMillis() + int64(time.Hour/time.Millisecond)
+
+	reminder, resp := Client.CreatePostReminder(post.Id, remindAt)
+	CheckNoError(t, resp)
+	CheckCreatedStatus(t, resp)
+
+	if reminder.UserId != th.BasicUser.Id || reminder.PostId != post.Id || reminder.RemindAt != remindAt {
+		t.Fatal("created the wrong reminder")
+	}
+
+	_, resp = Client.CreatePostReminder(post.Id, model.GetMillis()-1000)
+	CheckBadRequestStatus(t, resp)
+	CheckErrorMessage(t, resp, "api.post_reminder.past.app_error")
+
+	_, resp = Client.CreatePostReminder(post.Id, 0)
+	CheckBadRequestStatus(t, resp)
+
+	privatePost := th.CreatePostWithClient(Client, th.CreatePrivateChannel())
+
+	Client2 := th.CreateClient()
+	Client2.Login(th.BasicUser2.Email, th.BasicUser2.Password)
+
+	_, resp = Client2.CreatePostReminder(privatePost.Id, remindAt)
+	CheckForbiddenStatus(t, resp)
+
+	reminders, resp := Client.GetPostRemindersForUser(th.BasicUser.Id)
+	CheckNoError(t, resp)
+
+	if len(reminders) != 1 || reminders[0].Id != reminder.Id {
+		t.Fatal("returned the wrong reminders")
+	}
+
+	_, resp = Client2.GetPostRemindersForUser(th.BasicUser.Id)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = Client2.DeletePostReminder(reminder.Id)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = Client.DeletePostReminder(reminder.Id)
+	CheckNoError(t, resp)
+
+	_, resp = Client.DeletePostReminder(reminder.Id)
+	CheckNotFoundStatus(t, resp)
+}
+
+func TestSendPostReminders(t *testing.T) {
+	th := Setup().InitBasic()
+	defer TearDown()
+
+	post := th.CreatePost()
+
+	reminder, err := app.CreatePostReminder(&model.PostReminder{UserId: th.BasicUser.Id, PostId: post.Id, RemindAt: model.GetMillis() + 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(200 * time.Millisecond)
+	app.ProcessDuePostReminders()
+
+	if _, err := app.GetPostReminder(reminder.Id); err == nil {
+		t.Fatal("should have deleted the reminder once it was sent")
+	}
+
+	channel, err := app.CreateDirectChannel(th.BasicUser.Id, th.BasicUser.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	posts, err := app.GetPostsPage(channel.Id, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sent := false
+	for _, reminderPost := range posts.Posts {
+		sent = sent || (reminderPost.Type == model.POST_REMINDER && strings.Contains(reminderPost.Message, "/pl/"+post.Id))
+	}
+	if !sent {
+		t.Fatal("should have sent the reminder to the user's direct channel with themselves")
+	}
+}
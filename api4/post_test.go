@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"reflect"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -101,6 +102,48 @@ func TestCreatePost(t *testing.T) {
 	}
 }
 
+func TestCreatePostOAuthScope(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+
+	oauthSession := func(user *model.User, scope string) *model.Client4 {
+		session := &model.Session{UserId: user.Id, Roles: user.GetRawRoles(), IsOAuth: true}
+		session.AddProp(model.SESSION_PROP_OAUTH_SCOPE, scope)
+		session, err := app.CreateSession(session)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		client := th.CreateClient()
+		client.SetOAuthToken(session.Token)
+		return client
+	}
+
+	post := &model.Post{ChannelId: th.BasicChannel.Id, Message: "a" + model.NewId() + "a"}
+
+	Client := oauthSession(th.BasicUser, model.OAUTH_SCOPE_READ)
+	_, resp := Client.GetPost(th.BasicPost.Id, "")
+	CheckNoError(t, resp)
+
+	_, resp = Client.CreatePost(post)
+	CheckForbiddenStatus(t, resp)
+
+	Client = oauthSession(th.BasicUser, model.OAUTH_SCOPE_POST)
+	_, resp = Client.CreatePost(post)
+	CheckNoError(t, resp)
+
+	_, resp = Client.UpdateUser(th.BasicUser)
+	CheckForbiddenStatus(t, resp)
+
+	Client = oauthSession(th.SystemAdminUser, model.OAUTH_SCOPE_USER)
+	_, resp = Client.GetConfig()
+	CheckForbiddenStatus(t, resp)
+
+	Client = oauthSession(th.SystemAdminUser, model.OAUTH_SCOPE_ADMIN)
+	_, resp = Client.GetConfig()
+	CheckNoError(t, resp)
+}
+
 func TestUpdatePost(t *testing.T) {
 	th := Setup().InitBasic().InitSystemAdmin()
 	defer TearDown()
@@ -270,6 +313,64 @@ func TestPatchPost(t *testing.T) {
 	CheckNoError(t, resp)
 }
 
+func TestPostRevisions(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	post := th.CreateMessagePost("first")
+
+	patch := &model.PostPatch{Message: new(string)}
+	*patch.Message = "second"
+	_, resp := Client.PatchPost(post.Id, patch)
+	CheckNoError(t, resp)
+
+	revisions, resp := Client.GetPostRevisions(post.Id)
+	CheckNoError(t, resp)
+
+	if len(revisions.Order) != 1 {
+		t.Fatal("should have kept the original version of the post")
+	}
+
+	revision := revisions.Posts[revisions.Order[0]]
+	if revision.Message != "first" || revision.OriginalId != post.Id {
+		t.Fatal("returned the wrong revision")
+	}
+
+	Client2 := th.CreateClient()
+	Client2.Login(th.BasicUser2.Email, th.BasicUser2.Password)
+
+	_, resp = Client2.GetPostRevisions(post.Id)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = Client2.RestorePostRevision(post.Id, revision.Id)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.GetPostRevisions(post.Id)
+	CheckNoError(t, resp)
+
+	_, resp = Client.RestorePostRevision(post.Id, model.NewId())
+	CheckNotFoundStatus(t, resp)
+
+	restored, resp := Client.RestorePostRevision(post.Id, revision.Id)
+	CheckNoError(t, resp)
+
+	if restored.Id != post.Id || restored.Message != "first" || restored.EditAt == 0 {
+		t.Fatal("should have restored the original message")
+	}
+
+	revisions, resp = Client.GetPostRevisions(post.Id)
+	CheckNoError(t, resp)
+
+	if len(revisions.Order) != 2 || revisions.Posts[revisions.Order[0]].Message != "second" {
+		t.Fatal("should have kept the replaced version as a revision")
+	}
+
+	Client.Logout()
+	_, resp = Client.GetPostRevisions(post.Id)
+	CheckUnauthorizedStatus(t, resp)
+}
+
 func TestPinPost(t *testing.T) {
 	th := Setup().InitBasic().InitSystemAdmin()
 	defer TearDown()
@@ -917,3 +1018,119 @@ func TestGetFileInfosForPost(t *testing.T) {
 	_, resp = th.SystemAdminClient.GetFileInfosForPost(th.BasicPost.Id, "")
 	CheckNoError(t, resp)
 }
+
+func TestPermalinkEmbed(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	privateChannel := th.CreatePrivateChannel()
+	privatePost := th.CreatePostWithClient(Client, privateChannel)
+	permalink := "http://localhost:8065/" + th.BasicTeam.Name + "/pl/" + privatePost.Id
+
+	post, resp := Client.CreatePost(&model.Post{ChannelId: th.BasicChannel.Id, Message: "see " + permalink})
+	CheckNoError(t, resp)
+
+	if embed := model.PermalinkEmbedFromPost(post); embed == nil || embed.Unavailable || embed.PostId != privatePost.Id || embed.Message != privatePost.Message {
+		t.Fatal("should have embedded the linked post")
+	}
+
+	if stored, err := app.GetSinglePost(post.Id); err != nil {
+		t.Fatal(err)
+	} else if embed := model.PermalinkEmbedFromPost(stored); embed == nil || embed.PostId != privatePost.Id || embed.Message != "" || len(embed.FileIds) != 0 {
+		t.Fatal("should only have stored the id of the linked post")
+	}
+
+	Client2 := th.CreateClient()
+	Client2.Login(th.BasicUser2.Email, th.BasicUser2.Password)
+
+	rpost, resp := Client2.GetPost(post.Id, "")
+	CheckNoError(t, resp)
+
+	if embed := model.PermalinkEmbedFromPost(rpost); embed == nil || !embed.Unavailable || embed.Message != "" {
+		t.Fatal("should not have shown a post that the viewer can't read")
+	}
+
+	list, resp := Client2.GetPostsForChannel(th.BasicChannel.Id, 0, 60, "")
+	CheckNoError(t, resp)
+
+	if embed := model.PermalinkEmbedFromPost(list.Posts[post.Id]); embed == nil || !embed.Unavailable || embed.Message != "" {
+		t.Fatal("should not have shown a post that the viewer can't read in a list of posts")
+	}
+
+	privatePost.Message = "changed"
+	_, resp = Client.UpdatePost(privatePost.Id, privatePost)
+	CheckNoError(t, resp)
+
+	rpost, resp = Client.GetPost(post.Id, "")
+	CheckNoError(t, resp)
+
+	if embed := model.PermalinkEmbedFromPost(rpost); embed == nil || embed.Unavailable || embed.Message != "changed" {
+		t.Fatal("should have shown the current version of the linked post")
+	}
+
+	forged := &model.Post{ChannelId: th.BasicChannel.Id, Message: "see " + permalink}
+	forged.AddProp(model.POST_PROPS_PERMALINK_EMBED, &model.PermalinkEmbed{PostId: privatePost.Id, Message: "forged"})
+
+	post, resp = Client2.CreatePost(forged)
+	CheckNoError(t, resp)
+
+	if embed := model.PermalinkEmbedFromPost(post); embed == nil || !embed.Unavailable || embed.Message != "" {
+		t.Fatal("should not have embedded a post that the author can't read")
+	}
+
+	rpost, resp = Client.GetPost(post.Id, "")
+	CheckNoError(t, resp)
+
+	if embed := model.PermalinkEmbedFromPost(rpost); embed == nil || embed.Unavailable || embed.Message != "changed" {
+		t.Fatal("should have embedded the linked post for a viewer who can read it")
+	}
+
+	post.Message = "nothing to see here"
+	post, resp = Client2.UpdatePost(post.Id, post)
+	CheckNoError(t, resp)
+
+	if model.PermalinkEmbedFromPost(post) != nil {
+		t.Fatal("should have removed the embed")
+	}
+}
+
+func TestForwardPost(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	forwarded, resp := Client.ForwardPost(th.BasicPost.Id, th.BasicChannel2.Id, "have a look")
+	CheckNoError(t, resp)
+	CheckCreatedStatus(t, resp)
+
+	if forwarded.ChannelId != th.BasicChannel2.Id || forwarded.UserId != th.BasicUser.Id || !strings.HasPrefix(forwarded.Message, "have a look\n") {
+		t.Fatal("should have forwarded the post with the comment")
+	}
+
+	if embed := model.PermalinkEmbedFromPost(forwarded); embed == nil || embed.Unavailable || embed.PostId != th.BasicPost.Id || embed.UserId != th.BasicPost.UserId || embed.ChannelId != th.BasicPost.ChannelId {
+		t.Fatal("should have embedded the forwarded post")
+	}
+
+	privateChannel := th.CreatePrivateChannel()
+	privatePost := th.CreatePostWithClient(Client, privateChannel)
+
+	Client2 := th.CreateClient()
+	Client2.Login(th.BasicUser2.Email, th.BasicUser2.Password)
+
+	_, resp = Client2.ForwardPost(privatePost.Id, th.BasicChannel.Id, "")
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = Client.ForwardPost(th.BasicPost.Id, th.CreatePrivateChannel().Id, "")
+	CheckNoError(t, resp)
+
+	_, resp = Client2.ForwardPost(th.BasicPost.Id, privateChannel.Id, "")
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = Client.ForwardPost(th.BasicPost.Id, "junk", "")
+	CheckBadRequestStatus(t, resp)
+
+	Client.Logout()
+	_, resp = Client.ForwardPost(th.BasicPost.Id, th.BasicChannel.Id, "")
+	CheckUnauthorizedStatus(t, resp)
+}
@@ -0,0 +1,153 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func InitPrivacyRequest() {
+	l4g.Debug(utils.T("api.privacy_request.init.debug"))
+
+	BaseRoutes.PrivacyRequestsForUser.Handle("", ApiSessionRequired(createPrivacyRequest)).Methods("POST")
+	BaseRoutes.PrivacyRequestsForUser.Handle("", ApiSessionRequired(getPrivacyRequestsForUser)).Methods("GET")
+	BaseRoutes.PrivacyRequest.Handle("", ApiSessionRequired(getPrivacyRequest)).Methods("GET")
+	BaseRoutes.PrivacyRequest.Handle("", ApiSessionRequired(cancelPrivacyRequest)).Methods("DELETE")
+	BaseRoutes.PrivacyRequest.Handle("/download", ApiSessionRequiredTrustRequester(downloadDataExport)).Methods("GET")
+}
+
+func createPrivacyRequest(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId()
+	if c.Err != nil {
+		return
+	}
+
+	request := model.PrivacyRequestFromJson(r.Body)
+	if request == nil {
+		c.SetInvalidParam("privacy_request")
+		return
+	}
+
+	if !app.SessionHasPermissionToUser(c.Session, c.Params.UserId) {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return
+	}
+
+	request.Id = ""
+	request.UserId = c.Params.UserId
+	request.RequesterId = c.Session.UserId
+
+	if rrequest, err := app.CreatePrivacyRequest(request); err != nil {
+		c.Err = err
+		return
+	} else {
+		c.LogAuditWithUserId(rrequest.UserId, "request_id="+rrequest.Id+" type="+rrequest.Type+" anonymize="+strconv.FormatBool(rrequest.Anonymize))
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(rrequest.ToJson()))
+	}
+}
+
+func getPrivacyRequestsForUser(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionToUser(c.Session, c.Params.UserId) {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return
+	}
+
+	if requests, err := app.GetPrivacyRequestsForUser(c.Params.UserId); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(model.PrivacyRequestListToJson(requests)))
+	}
+}
+
+func getPrivacyRequest(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequirePrivacyRequestId()
+	if c.Err != nil {
+		return
+	}
+
+	request, err := getPrivacyRequestForSession(c)
+	if err != nil {
+		return
+	}
+
+	w.Write([]byte(request.ToJson()))
+}
+
+func cancelPrivacyRequest(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequirePrivacyRequestId()
+	if c.Err != nil {
+		return
+	}
+
+	request, err := getPrivacyRequestForSession(c)
+	if err != nil {
+		return
+	}
+
+	if rrequest, err := app.CancelPrivacyRequest(request); err != nil {
+		c.Err = err
+		return
+	} else {
+		c.LogAuditWithUserId(rrequest.UserId, "request_id="+rrequest.Id+" type="+rrequest.Type)
+		w.Write([]byte(rrequest.ToJson()))
+	}
+}
+
+func downloadDataExport(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequirePrivacyRequestId()
+	if c.Err != nil {
+		return
+	}
+
+	request, err := getPrivacyRequestForSession(c)
+	if err != nil {
+		return
+	}
+
+	export, err := app.GetDataExport(request)
+	if err != nil {
+		c.Err = err
+		return
+	}
+	defer export.Close()
+
+	c.LogAuditWithUserId(request.UserId, "request_id="+request.Id)
+
+	// exports hold personal data so they must never be kept by shared caches
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", "attachment;filename=\"data_export_"+request.Id+".zip\"")
+
+	io.Copy(w, export)
+}
+
+// Privacy requests can be seen and changed by the users they belong to and by the admins allowed to manage them.
+func getPrivacyRequestForSession(c *Context) (*model.PrivacyRequest, *model.AppError) {
+	request, err := app.GetPrivacyRequest(c.Params.PrivacyRequestId)
+	if err != nil {
+		c.Err = err
+		return nil, err
+	}
+
+	if !app.SessionHasPermissionToUser(c.Session, request.UserId) {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return nil, c.Err
+	}
+
+	return request, nil
+}
@@ -0,0 +1,261 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func TestPrivacyRequests(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	post := th.CreatePost()
+
+	export, resp := Client.CreatePrivacyRequest(th.BasicUser.Id, model.PRIVACY_REQUEST_TYPE_EXPORT, false)
+	CheckNoError(t, resp)
+	CheckCreatedStatus(t, resp)
+
+	if export.UserId != th.BasicUser.Id || export.RequesterId != th.BasicUser.Id || export.Status != model.PRIVACY_REQUEST_STATUS_PENDING {
+		t.Fatal("created the wrong privacy request")
+	}
+
+	_, resp = Client.CreatePrivacyRequest(th.BasicUser.Id, model.PRIVACY_REQUEST_TYPE_EXPORT, true)
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.CreatePrivacyRequest(th.BasicUser2.Id, model.PRIVACY_REQUEST_TYPE_EXPORT, false)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = Client.DownloadDataExport(export.Id)
+	CheckBadRequestStatus(t, resp)
+	CheckErrorMessage(t, resp, "app.privacy_request.export_not_ready.app_error")
+
+	app.ProcessDuePrivacyRequests()
+
+	export, resp = Client.GetPrivacyRequest(export.Id)
+	CheckNoError(t, resp)
+
+	if export.Status != model.PRIVACY_REQUEST_STATUS_FINISHED || export.ProcessedAt == 0 {
+		t.Fatal("should have built the export", export.Status, export.ErrorCode)
+	}
+
+	data, resp := Client.DownloadDataExport(export.Id)
+	CheckNoError(t, resp)
+
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string][]byte{}
+	for _, f := range reader.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		buf := &bytes.Buffer{}
+		buf.ReadFrom(rc)
+		rc.Close()
+
+		files[f.Name] = buf.Bytes()
+	}
+
+	for _, name := range []string{"user.json", "preferences.json", "posts.json", "files.json", "audits.json"} {
+		if _, ok := files[name]; !ok {
+			t.Fatal("export is missing " + name)
+		}
+	}
+
+	if user := model.UserFromJson(bytes.NewReader(files["user.json"])); user == nil || user.Id != th.BasicUser.Id || user.Password != "" {
+		t.Fatal("export has the wrong profile")
+	}
+
+	var posts []*model.Post
+	if err := json.Unmarshal(files["posts.json"], &posts); err != nil {
+		t.Fatal(err)
+	}
+
+	found := false
+	for _, p := range posts {
+		found = found || (p.Id == post.Id && p.Message == post.Message)
+	}
+	if !found {
+		t.Fatal("export is missing the user's posts")
+	}
+
+	if audits := model.AuditsFromJson(bytes.NewReader(files["audits.json"])); len(audits) == 0 {
+		t.Fatal("export is missing the user's audits")
+	}
+
+	Client2 := th.CreateClient()
+	th.LoginBasic2WithClient(Client2)
+
+	_, resp = Client2.GetPrivacyRequest(export.Id)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = Client2.DownloadDataExport(export.Id)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = Client2.GetPrivacyRequestsForUser(th.BasicUser.Id)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.DownloadDataExport(export.Id)
+	CheckNoError(t, resp)
+
+	deletion, resp := Client.CreatePrivacyRequest(th.BasicUser.Id, model.PRIVACY_REQUEST_TYPE_DELETE, true)
+	CheckNoError(t, resp)
+
+	if deletion.ScheduledAt < deletion.CreateAt+int64(*utils.Cfg.PrivacySettings.AccountDeletionCoolingOffInDays)*24*60*60*1000 {
+		t.Fatal("deletion should wait for the cooling-off period")
+	}
+
+	_, resp = Client.CreatePrivacyRequest(th.BasicUser.Id, model.PRIVACY_REQUEST_TYPE_DELETE, false)
+	CheckBadRequestStatus(t, resp)
+	CheckErrorMessage(t, resp, "app.privacy_request.deletion_pending.app_error")
+
+	requests, resp := Client.GetPrivacyRequestsForUser(th.BasicUser.Id)
+	CheckNoError(t, resp)
+
+	if len(requests) != 2 || requests[0].Id != deletion.Id || requests[1].Id != export.Id {
+		t.Fatal("returned the wrong privacy requests")
+	}
+
+	app.ProcessDuePrivacyRequests()
+
+	if _, err := app.GetUser(th.BasicUser.Id); err != nil {
+		t.Fatal("shouldn't delete the user during the cooling-off period")
+	}
+
+	_, resp = Client2.CancelPrivacyRequest(deletion.Id)
+	CheckForbiddenStatus(t, resp)
+
+	deletion, resp = Client.CancelPrivacyRequest(deletion.Id)
+	CheckNoError(t, resp)
+
+	if deletion.Status != model.PRIVACY_REQUEST_STATUS_CANCELED {
+		t.Fatal("should have canceled the deletion")
+	}
+
+	_, resp = Client.CancelPrivacyRequest(deletion.Id)
+	CheckBadRequestStatus(t, resp)
+	CheckErrorMessage(t, resp, "app.privacy_request.cancel.not_pending.app_error")
+
+	_, resp = Client.GetPrivacyRequest(model.NewId())
+	CheckNotFoundStatus(t, resp)
+
+	Client.Logout()
+	_, resp = Client.GetPrivacyRequestsForUser(th.BasicUser.Id)
+	CheckUnauthorizedStatus(t, resp)
+}
+
+func TestPrivacyRequestDeletion(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+
+	coolingOff := *utils.Cfg.PrivacySettings.AccountDeletionCoolingOffInDays
+	defer func() {
+		*utils.Cfg.PrivacySettings.AccountDeletionCoolingOffInDays = coolingOff
+	}()
+	*utils.Cfg.PrivacySettings.AccountDeletionCoolingOffInDays = 0
+
+	createUserWithPost := func() (*model.User, *model.Client4, *model.Post) {
+		user := th.CreateUser()
+		LinkUserToTeam(user, th.BasicTeam)
+		th.Client.AddChannelMember(th.BasicChannel.Id, user.Id)
+
+		client := th.CreateClient()
+		client.Login(user.Email, user.Password)
+
+		return user, client, th.CreatePostWithClient(client, th.BasicChannel)
+	}
+
+	user, client, post := createUserWithPost()
+
+	deletion, resp := client.CreatePrivacyRequest(user.Id, model.PRIVACY_REQUEST_TYPE_DELETE, true)
+	CheckNoError(t, resp)
+
+	app.ProcessDuePrivacyRequests()
+
+	if deletion, err := app.GetPrivacyRequest(deletion.Id); err != nil {
+		t.Fatal(err)
+	} else if deletion.Status != model.PRIVACY_REQUEST_STATUS_FINISHED {
+		t.Fatal("should have deleted the user", deletion.Status, deletion.ErrorCode)
+	}
+
+	if _, err := app.GetUser(user.Id); err == nil {
+		t.Fatal("should have deleted the user")
+	}
+
+	placeholder, err := app.GetDeletedUserPlaceholder()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if placeholder.DeleteAt == 0 {
+		t.Fatal("placeholder should be deactivated")
+	}
+
+	if rpost, err := app.GetSinglePost(post.Id); err != nil {
+		t.Fatal("should have kept the user's posts", err)
+	} else if rpost.UserId != placeholder.Id {
+		t.Fatal("post should be attributed to the deleted user placeholder")
+	}
+
+	if result := <-app.Srv.Store.Audit().Get(user.Id, 0, 100); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if audits := result.Data.(model.Audits); len(audits) != 1 || audits[0].Action != "privacy_request/delete" {
+		t.Fatal("should only have kept the audit of the deletion")
+	}
+
+	user, client, post = createUserWithPost()
+
+	data, fileErr := readTestFile("test.png")
+	if fileErr != nil {
+		t.Fatal(fileErr)
+	}
+
+	uploaded, resp := client.UploadFile(data, th.BasicChannel.Id, "test.png")
+	CheckNoError(t, resp)
+	info := uploaded.FileInfos[0]
+
+	if _, err := app.ReadFile(info.Path); err != nil {
+		t.Fatal(err)
+	}
+
+	_, resp = client.CreatePrivacyRequest(user.Id, model.PRIVACY_REQUEST_TYPE_DELETE, false)
+	CheckNoError(t, resp)
+
+	app.ProcessDuePrivacyRequests()
+
+	if _, err := app.GetUser(user.Id); err == nil {
+		t.Fatal("should have deleted the user")
+	}
+
+	if _, err := app.GetSinglePost(post.Id); err == nil {
+		t.Fatal("should have deleted the user's posts")
+	}
+
+	if _, err := app.GetFileInfo(info.Id); err == nil {
+		t.Fatal("should have deleted the user's file infos")
+	}
+
+	if _, err := app.ReadFile(info.Path); err == nil {
+		t.Fatal("should have deleted the user's files")
+	}
+
+	if rplaceholder, err := app.GetDeletedUserPlaceholder(); err != nil {
+		t.Fatal(err)
+	} else if rplaceholder.Id != placeholder.Id {
+		t.Fatal("should reuse the deleted user placeholder")
+	}
+}
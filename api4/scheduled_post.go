@@ -0,0 +1,133 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"net/http"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func InitScheduledPost() {
+	l4g.Debug(utils.T("api.scheduled_post.init.debug"))
+
+	BaseRoutes.ScheduledPosts.Handle("", ApiSessionRequired(createScheduledPost)).Methods("POST")
+	BaseRoutes.ScheduledPostsForUser.Handle("", ApiSessionRequired(getScheduledPostsForUser)).Methods("GET")
+	BaseRoutes.ScheduledPost.Handle("", ApiSessionRequired(updateScheduledPost)).Methods("PUT")
+	BaseRoutes.ScheduledPost.Handle("", ApiSessionRequired(deleteScheduledPost)).Methods("DELETE")
+}
+
+func createScheduledPost(c *Context, w http.ResponseWriter, r *http.Request) {
+	scheduledPost := model.ScheduledPostFromJson(r.Body)
+	if scheduledPost == nil {
+		c.SetInvalidParam("scheduled_post")
+		return
+	}
+
+	scheduledPost.UserId = c.Session.UserId
+
+	if !app.SessionHasPermissionToChannel(c.Session, scheduledPost.ChannelId, model.PERMISSION_CREATE_POST) {
+		c.SetPermissionError(model.PERMISSION_CREATE_POST)
+		return
+	}
+
+	if rscheduledPost, err := app.CreateScheduledPost(scheduledPost); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(rscheduledPost.ToJson()))
+	}
+}
+
+func getScheduledPostsForUser(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionToUser(c.Session, c.Params.UserId) {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return
+	}
+
+	if scheduledPosts, err := app.GetScheduledPostsForUser(c.Params.UserId); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(model.ScheduledPostListToJson(scheduledPosts)))
+	}
+}
+
+func updateScheduledPost(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireScheduledPostId()
+	if c.Err != nil {
+		return
+	}
+
+	updated := model.ScheduledPostFromJson(r.Body)
+	if updated == nil {
+		c.SetInvalidParam("scheduled_post")
+		return
+	}
+
+	scheduledPost, err := getScheduledPostForSession(c)
+	if err != nil {
+		return
+	}
+
+	scheduledPost.ChannelId = updated.ChannelId
+	scheduledPost.RootId = updated.RootId
+	scheduledPost.Message = updated.Message
+	scheduledPost.ScheduledAt = updated.ScheduledAt
+
+	if !app.SessionHasPermissionToChannel(c.Session, scheduledPost.ChannelId, model.PERMISSION_CREATE_POST) {
+		c.SetPermissionError(model.PERMISSION_CREATE_POST)
+		return
+	}
+
+	if rscheduledPost, err := app.UpdateScheduledPost(scheduledPost); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(rscheduledPost.ToJson()))
+	}
+}
+
+func deleteScheduledPost(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireScheduledPostId()
+	if c.Err != nil {
+		return
+	}
+
+	if _, err := getScheduledPostForSession(c); err != nil {
+		return
+	}
+
+	if err := app.DeleteScheduledPost(c.Params.ScheduledPostId); err != nil {
+		c.Err = err
+		return
+	}
+
+	ReturnStatusOK(w)
+}
+
+// Scheduled posts can only be seen and changed by the users that wrote them.
+func getScheduledPostForSession(c *Context) (*model.ScheduledPost, *model.AppError) {
+	scheduledPost, err := app.GetScheduledPost(c.Params.ScheduledPostId)
+	if err != nil {
+		c.Err = err
+		return nil, err
+	}
+
+	if scheduledPost.UserId != c.Session.UserId {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return nil, c.Err
+	}
+
+	return scheduledPost, nil
+}
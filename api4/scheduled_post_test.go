@@ -0,0 +1,136 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+)
+
+func TestScheduledPosts(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	scheduledAt := model.GetMillis() + int64(time.Hour/time.Millisecond)
+
+	scheduledPost, resp := Client.CreateScheduledPost(&model.ScheduledPost{ChannelId: th.BasicChannel.Id, Message: "later", ScheduledAt: scheduledAt})
+	CheckNoError(t, resp)
+	CheckCreatedStatus(t, resp)
+
+	if scheduledPost.UserId != th.BasicUser.Id || scheduledPost.Message != "later" || scheduledPost.ScheduledAt != scheduledAt {
+		t.Fatal("created the wrong scheduled post")
+	}
+
+	_, resp = Client.CreateScheduledPost(&model.ScheduledPost{ChannelId: th.BasicChannel.Id, Message: "past", ScheduledAt: model.GetMillis() - 1000})
+	CheckBadRequestStatus(t, resp)
+	CheckErrorMessage(t, resp, "api.scheduled_post.past.app_error")
+
+	privateChannel := th.CreatePrivateChannel()
+
+	Client2 := th.CreateClient()
+	Client2.Login(th.BasicUser2.Email, th.BasicUser2.Password)
+
+	_, resp = Client2.CreateScheduledPost(&model.ScheduledPost{ChannelId: privateChannel.Id, Message: "later", ScheduledAt: scheduledAt})
+	CheckForbiddenStatus(t, resp)
+
+	scheduledPosts, resp := Client.GetScheduledPostsForUser(th.BasicUser.Id)
+	CheckNoError(t, resp)
+
+	if len(scheduledPosts) != 1 || scheduledPosts[0].Id != scheduledPost.Id {
+		t.Fatal("returned the wrong scheduled posts")
+	}
+
+	_, resp = Client2.GetScheduledPostsForUser(th.BasicUser.Id)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.GetScheduledPostsForUser(th.BasicUser.Id)
+	CheckNoError(t, resp)
+
+	scheduledPost.Message = "edited"
+	scheduledPost.ScheduledAt = scheduledAt + 1000
+	updated, resp := Client.UpdateScheduledPost(scheduledPost)
+	CheckNoError(t, resp)
+
+	if updated.Message != "edited" || updated.ScheduledAt != scheduledAt+1000 {
+		t.Fatal("should have updated the scheduled post")
+	}
+
+	_, resp = Client2.UpdateScheduledPost(scheduledPost)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = Client2.DeleteScheduledPost(scheduledPost.Id)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = Client.DeleteScheduledPost(scheduledPost.Id)
+	CheckNoError(t, resp)
+
+	_, resp = Client.DeleteScheduledPost(scheduledPost.Id)
+	CheckNotFoundStatus(t, resp)
+
+	Client.Logout()
+	_, resp = Client.GetScheduledPostsForUser(th.BasicUser.Id)
+	CheckUnauthorizedStatus(t, resp)
+}
+
+func TestSendScheduledPosts(t *testing.T) {
+	th := Setup().InitBasic()
+	defer TearDown()
+
+	scheduledPost, err := app.CreateScheduledPost(&model.ScheduledPost{
+		UserId:      th.BasicUser.Id,
+		ChannelId:   th.BasicChannel.Id,
+		Message:     "scheduled " + model.NewId(),
+		ScheduledAt: model.GetMillis() + 100,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := app.CreateScheduledPost(&model.ScheduledPost{
+		UserId:      th.BasicUser2.Id,
+		ChannelId:   th.BasicChannel.Id,
+		Message:     "removed " + model.NewId(),
+		ScheduledAt: model.GetMillis() + 100,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.RemoveUserFromChannel(th.BasicUser2.Id, th.BasicUser2.Id, th.BasicChannel, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(200 * time.Millisecond)
+	app.ProcessDueScheduledPosts()
+
+	posts, err := app.GetPostsPage(th.BasicChannel.Id, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sent := false
+	for _, post := range posts.Posts {
+		if post.Message == removed.Message {
+			t.Fatal("shouldn't have posted for a user that left the channel")
+		}
+		sent = sent || (post.Message == scheduledPost.Message && post.UserId == th.BasicUser.Id)
+	}
+	if !sent {
+		t.Fatal("should have posted the scheduled post")
+	}
+
+	if _, err := app.GetScheduledPost(scheduledPost.Id); err == nil {
+		t.Fatal("should have deleted the scheduled post once it was posted")
+	}
+
+	if failed, err := app.GetScheduledPost(removed.Id); err != nil {
+		t.Fatal(err)
+	} else if failed.ProcessedAt == 0 || failed.ErrorCode != "api.scheduled_post.permissions.app_error" {
+		t.Fatal("should have kept the reason the scheduled post wasn't posted")
+	}
+}
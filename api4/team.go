@@ -24,7 +24,7 @@ func InitTeam() {
 
 	BaseRoutes.Teams.Handle("", ApiSessionRequired(createTeam)).Methods("POST")
 	BaseRoutes.Teams.Handle("", ApiSessionRequired(getAllTeams)).Methods("GET")
-	BaseRoutes.Teams.Handle("/search", ApiSessionRequired(searchTeams)).Methods("POST")
+	BaseRoutes.Teams.Handle("/search", ApiSessionRequiredWithScope(searchTeams, model.OAUTH_SCOPE_READ)).Methods("POST")
 	BaseRoutes.TeamsForUser.Handle("", ApiSessionRequired(getTeamsForUser)).Methods("GET")
 	BaseRoutes.TeamsForUser.Handle("/unread", ApiSessionRequired(getTeamsUnreadForUser)).Methods("GET")
 
@@ -34,7 +34,7 @@ func InitTeam() {
 	BaseRoutes.Team.Handle("/patch", ApiSessionRequired(patchTeam)).Methods("PUT")
 	BaseRoutes.Team.Handle("/stats", ApiSessionRequired(getTeamStats)).Methods("GET")
 	BaseRoutes.TeamMembers.Handle("", ApiSessionRequired(getTeamMembers)).Methods("GET")
-	BaseRoutes.TeamMembers.Handle("/ids", ApiSessionRequired(getTeamMembersByIds)).Methods("POST")
+	BaseRoutes.TeamMembers.Handle("/ids", ApiSessionRequiredWithScope(getTeamMembersByIds, model.OAUTH_SCOPE_READ)).Methods("POST")
 	BaseRoutes.TeamMembersForUser.Handle("", ApiSessionRequired(getTeamMembersForUser)).Methods("GET")
 	BaseRoutes.TeamMembers.Handle("", ApiSessionRequired(addTeamMember)).Methods("POST")
 	BaseRoutes.TeamMembers.Handle("/batch", ApiSessionRequired(addTeamMembers)).Methods("POST")
@@ -48,6 +48,7 @@ func InitTeam() {
 	BaseRoutes.TeamMember.Handle("/roles", ApiSessionRequired(updateTeamMemberRoles)).Methods("PUT")
 
 	BaseRoutes.Team.Handle("/import", ApiSessionRequired(importTeam)).Methods("POST")
+	BaseRoutes.Team.Handle("/invite-guests/email", ApiSessionRequired(inviteGuestsToTeam)).Methods("POST")
 }
 
 func createTeam(c *Context, w http.ResponseWriter, r *http.Request) {
@@ -648,3 +649,47 @@ func importTeam(c *Context, w http.ResponseWriter, r *http.Request) {
 	}
 	io.Copy(w, bytes.NewReader(log.Bytes()))
 }
+
+func inviteGuestsToTeam(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireTeamId()
+	if c.Err != nil {
+		return
+	}
+
+	invite := model.GuestsInviteFromJson(r.Body)
+	if invite == nil {
+		c.SetInvalidParam("guests_invite")
+		return
+	}
+
+	if !app.SessionHasPermissionToTeam(c.Session, c.Params.TeamId, model.PERMISSION_INVITE_GUEST) {
+		c.SetPermissionError(model.PERMISSION_INVITE_GUEST)
+		return
+	}
+
+	for _, channelId := range invite.ChannelIds {
+		// Channels that are missing or belong to another team are rejected by InviteGuestsToTeam
+		channel, err := app.GetChannel(channelId)
+		if err != nil || channel.TeamId != c.Params.TeamId {
+			continue
+		}
+
+		permission := model.PERMISSION_MANAGE_PUBLIC_CHANNEL_MEMBERS
+		if channel.Type == model.CHANNEL_PRIVATE {
+			permission = model.PERMISSION_MANAGE_PRIVATE_CHANNEL_MEMBERS
+		}
+
+		if !app.SessionHasPermissionToChannel(c.Session, channel.Id, permission) {
+			c.SetPermissionError(permission)
+			return
+		}
+	}
+
+	if err := app.InviteGuestsToTeam(c.Params.TeamId, invite, c.Session.UserId, c.GetSiteURL()); err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("")
+	ReturnStatusOK(w)
+}
@@ -1372,3 +1372,54 @@ func TestImportTeam(t *testing.T) {
 		CheckForbiddenStatus(t, resp)
 	})
 }
+
+func TestInviteGuestsToTeam(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	invite := &model.GuestsInvite{
+		Emails:     []string{GenerateTestEmail()},
+		ChannelIds: []string{th.BasicChannel.Id, th.BasicPrivateChannel.Id},
+	}
+
+	_, resp := Client.InviteGuestsToTeam(th.BasicTeam.Id, invite)
+	CheckForbiddenStatus(t, resp)
+
+	privateChannel := th.CreatePrivateChannel()
+
+	th.LoginTeamAdmin()
+
+	_, resp = Client.InviteGuestsToTeam(th.BasicTeam.Id, &model.GuestsInvite{Emails: invite.Emails, ChannelIds: []string{privateChannel.Id}})
+	CheckForbiddenStatus(t, resp)
+
+	ok, resp := Client.InviteGuestsToTeam(th.BasicTeam.Id, invite)
+	CheckNoError(t, resp)
+
+	if !ok {
+		t.Fatal("should have invited the guests")
+	}
+
+	if invite.ExpiresAt != 0 {
+		t.Fatal("shouldn't have changed the invite")
+	}
+
+	_, resp = Client.InviteGuestsToTeam(th.BasicTeam.Id, &model.GuestsInvite{Emails: invite.Emails})
+	CheckBadRequestStatus(t, resp)
+
+	expired := &model.GuestsInvite{Emails: invite.Emails, ChannelIds: invite.ChannelIds, ExpiresAt: model.GetMillis() - 1000}
+	_, resp = Client.InviteGuestsToTeam(th.BasicTeam.Id, expired)
+	CheckBadRequestStatus(t, resp)
+
+	otherTeam := th.CreateTeamWithClient(th.SystemAdminClient)
+	otherChannel, _ := app.CreateChannel(&model.Channel{DisplayName: "dn_" + model.NewId(), Name: GenerateTestChannelName(), Type: model.CHANNEL_OPEN, TeamId: otherTeam.Id}, false)
+
+	_, resp = Client.InviteGuestsToTeam(th.BasicTeam.Id, &model.GuestsInvite{Emails: invite.Emails, ChannelIds: []string{otherChannel.Id}})
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.InviteGuestsToTeam(th.BasicTeam.Id, &model.GuestsInvite{Emails: invite.Emails, ChannelIds: []string{model.NewId()}})
+	CheckNotFoundStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.InviteGuestsToTeam(th.BasicTeam.Id, invite)
+	CheckNoError(t, resp)
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"net/http"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func InitThread() {
+	l4g.Debug(utils.T("api.thread.init.debug"))
+
+	BaseRoutes.ThreadsForUser.Handle("", ApiSessionRequired(getFollowedThreads)).Methods("GET")
+	BaseRoutes.ThreadForUser.Handle("/follow", ApiSessionRequired(followThread)).Methods("POST")
+	BaseRoutes.ThreadForUser.Handle("/unfollow", ApiSessionRequired(unfollowThread)).Methods("POST")
+	BaseRoutes.ThreadForUser.Handle("/view", ApiSessionRequired(viewThread)).Methods("POST")
+}
+
+func getFollowedThreads(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionToUser(c.Session, c.Params.UserId) {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return
+	}
+
+	if threads, err := app.GetFollowedThreads(c.Params.UserId, c.Params.Page, c.Params.PerPage); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(model.ThreadListToJson(app.PrepareThreadsForUser(threads, c.Session.UserId))))
+	}
+}
+
+func followThread(c *Context, w http.ResponseWriter, r *http.Request) {
+	setThreadFollowing(c, w, true)
+}
+
+func unfollowThread(c *Context, w http.ResponseWriter, r *http.Request) {
+	setThreadFollowing(c, w, false)
+}
+
+func setThreadFollowing(c *Context, w http.ResponseWriter, following bool) {
+	if !canAccessThread(c) {
+		return
+	}
+
+	if membership, err := app.SetThreadFollowing(c.Params.PostId, c.Params.UserId, following); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(membership.ToJson()))
+	}
+}
+
+func viewThread(c *Context, w http.ResponseWriter, r *http.Request) {
+	if !canAccessThread(c) {
+		return
+	}
+
+	if membership, err := app.ViewThread(c.Params.PostId, c.Params.UserId); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(membership.ToJson()))
+	}
+}
+
+func canAccessThread(c *Context) bool {
+	c.RequireUserId().RequirePostId()
+	if c.Err != nil {
+		return false
+	}
+
+	if !app.SessionHasPermissionToUser(c.Session, c.Params.UserId) {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return false
+	}
+
+	if !app.SessionHasPermissionToChannelByPost(c.Session, c.Params.PostId, model.PERMISSION_READ_CHANNEL) {
+		c.SetPermissionError(model.PERMISSION_READ_CHANNEL)
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestFollowedThreads(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	root := th.CreatePost()
+
+	Client2 := th.CreateClient()
+	Client2.Login(th.BasicUser2.Email, th.BasicUser2.Password)
+
+	reply, resp := Client2.CreatePost(&model.Post{ChannelId: th.BasicChannel.Id, RootId: root.Id, Message: "reply"})
+	CheckNoError(t, resp)
+
+	threads, resp := Client.GetFollowedThreads(th.BasicUser.Id, 0, 60)
+	CheckNoError(t, resp)
+
+	if len(threads) != 1 || threads[0].PostId != root.Id {
+		t.Fatal("the author of the thread should follow it")
+	} else if threads[0].Post.ReplyCount != 1 || threads[0].Post.LastReplyAt != reply.CreateAt || len(threads[0].Post.Participants) != 2 {
+		t.Fatal("should have updated the thread statistics")
+	} else if threads[0].UnreadReplies != 1 || threads[0].UnreadMentions != 0 {
+		t.Fatal("the reply should be unread", threads[0].UnreadReplies, threads[0].UnreadMentions)
+	}
+
+	threads, resp = Client2.GetFollowedThreads(th.BasicUser2.Id, 0, 60)
+	CheckNoError(t, resp)
+
+	if len(threads) != 1 || threads[0].UnreadReplies != 0 {
+		t.Fatal("the author of the reply should follow the thread without unread replies")
+	}
+
+	_, resp = Client2.CreatePost(&model.Post{ChannelId: th.BasicChannel.Id, RootId: root.Id, Message: "@" + th.BasicUser.Username + " ping"})
+	CheckNoError(t, resp)
+
+	threads, _ = Client.GetFollowedThreads(th.BasicUser.Id, 0, 60)
+	if threads[0].UnreadReplies != 2 || threads[0].UnreadMentions != 1 {
+		t.Fatal("the mention should be unread", threads[0].UnreadReplies, threads[0].UnreadMentions)
+	}
+
+	membership, resp := Client.ViewThread(th.BasicUser.Id, root.Id)
+	CheckNoError(t, resp)
+
+	if membership.UnreadMentions != 0 || membership.LastViewedAt == 0 {
+		t.Fatal("should have marked the thread as read")
+	}
+
+	threads, _ = Client.GetFollowedThreads(th.BasicUser.Id, 0, 60)
+	if threads[0].UnreadReplies != 0 || threads[0].UnreadMentions != 0 {
+		t.Fatal("the thread should have been read")
+	}
+
+	membership, resp = Client.UnfollowThread(th.BasicUser.Id, root.Id)
+	CheckNoError(t, resp)
+
+	if membership.Following {
+		t.Fatal("should have unfollowed the thread")
+	}
+
+	threads, _ = Client.GetFollowedThreads(th.BasicUser.Id, 0, 60)
+	if len(threads) != 0 {
+		t.Fatal("shouldn't list unfollowed threads")
+	}
+
+	_, resp = Client.FollowThread(th.BasicUser.Id, root.Id)
+	CheckNoError(t, resp)
+
+	threads, _ = Client.GetFollowedThreads(th.BasicUser.Id, 0, 60)
+	if len(threads) != 1 {
+		t.Fatal("should list followed threads")
+	}
+
+	_, resp = Client.FollowThread(th.BasicUser.Id, reply.Id)
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.FollowThread(th.BasicUser.Id, model.NewId())
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = Client.GetFollowedThreads(th.BasicUser2.Id, 0, 60)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.GetFollowedThreads(th.BasicUser.Id, 0, 60)
+	CheckNoError(t, resp)
+
+	privateChannel := th.CreatePrivateChannel()
+	privateRoot := th.CreatePostWithClient(Client, privateChannel)
+	_, resp = Client2.FollowThread(th.BasicUser2.Id, privateRoot.Id)
+	CheckForbiddenStatus(t, resp)
+
+	Client.Logout()
+	_, resp = Client.GetFollowedThreads(th.BasicUser.Id, 0, 60)
+	CheckUnauthorizedStatus(t, resp)
+}
@@ -21,8 +21,8 @@ func InitUser() {
 
 	BaseRoutes.Users.Handle("", ApiHandler(createUser)).Methods("POST")
 	BaseRoutes.Users.Handle("", ApiSessionRequired(getUsers)).Methods("GET")
-	BaseRoutes.Users.Handle("/ids", ApiSessionRequired(getUsersByIds)).Methods("POST")
-	BaseRoutes.Users.Handle("/search", ApiSessionRequired(searchUsers)).Methods("POST")
+	BaseRoutes.Users.Handle("/ids", ApiSessionRequiredWithScope(getUsersByIds, model.OAUTH_SCOPE_READ)).Methods("POST")
+	BaseRoutes.Users.Handle("/search", ApiSessionRequiredWithScope(searchUsers, model.OAUTH_SCOPE_READ)).Methods("POST")
 	BaseRoutes.Users.Handle("/autocomplete", ApiSessionRequired(autocompleteUsers)).Methods("GET")
 
 	BaseRoutes.User.Handle("", ApiSessionRequired(getUser)).Methods("GET")
@@ -41,6 +41,8 @@ func InitUser() {
 	BaseRoutes.Users.Handle("/mfa", ApiHandler(checkUserMfa)).Methods("POST")
 	BaseRoutes.User.Handle("/mfa", ApiSessionRequired(updateUserMfa)).Methods("PUT")
 	BaseRoutes.User.Handle("/mfa/generate", ApiSessionRequired(generateMfaSecret)).Methods("POST")
+	BaseRoutes.User.Handle("/mfa/recovery_codes", ApiSessionRequired(generateMfaRecoveryCodes)).Methods("POST")
+	BaseRoutes.User.Handle("/mfa/recovery_codes", ApiSessionRequired(getMfaRecoveryCodeCount)).Methods("GET")
 
 	BaseRoutes.Users.Handle("/login", ApiHandler(login)).Methods("POST")
 	BaseRoutes.Users.Handle("/logout", ApiHandler(logout)).Methods("POST")
@@ -50,6 +52,8 @@ func InitUser() {
 
 	BaseRoutes.User.Handle("/sessions", ApiSessionRequired(getSessions)).Methods("GET")
 	BaseRoutes.User.Handle("/sessions/revoke", ApiSessionRequired(revokeSession)).Methods("POST")
+	BaseRoutes.User.Handle("/sessions/revoke/all", ApiSessionRequired(revokeAllSessionsForUser)).Methods("POST")
+	BaseRoutes.Users.Handle("/sessions/revoke/all", ApiSessionRequired(revokeSessionsFromAllUsers)).Methods("POST")
 	BaseRoutes.Users.Handle("/sessions/device", ApiSessionRequired(attachDeviceId)).Methods("PUT")
 	BaseRoutes.User.Handle("/audits", ApiSessionRequired(getUserAudits)).Methods("GET")
 }
@@ -93,7 +97,10 @@ func getUser(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// No permission check required
+	if !app.SessionCanSeeUsers(c.Session, []string{c.Params.UserId}) {
+		c.SetPermissionError(model.PERMISSION_VIEW_MEMBERS)
+		return
+	}
 
 	var user *model.User
 	var err *model.AppError
@@ -121,8 +128,6 @@ func getUserByUsername(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// No permission check required
-
 	var user *model.User
 	var err *model.AppError
 
@@ -131,6 +136,12 @@ func getUserByUsername(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Users the session can't see are reported as missing so that their usernames can't be probed
+	if !app.SessionCanSeeUsers(c.Session, []string{user.Id}) {
+		c.Err = model.NewAppError("SqlUserStore.GetByUsername", "store.sql_user.get_by_username.app_error", nil, "", http.StatusNotFound)
+		return
+	}
+
 	etag := user.Etag(utils.Cfg.PrivacySettings.ShowFullName, utils.Cfg.PrivacySettings.ShowEmailAddress)
 
 	if HandleEtag(etag, "Get User", w, r) {
@@ -149,8 +160,6 @@ func getUserByEmail(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// No permission check required
-
 	var user *model.User
 	var err *model.AppError
 
@@ -159,6 +168,12 @@ func getUserByEmail(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Users the session can't see are reported as missing so that their emails can't be probed
+	if !app.SessionCanSeeUsers(c.Session, []string{user.Id}) {
+		c.Err = model.NewAppError("SqlUserStore.GetByEmail", store.MISSING_ACCOUNT_ERROR, nil, "", http.StatusNotFound)
+		return
+	}
+
 	etag := user.Etag(utils.Cfg.PrivacySettings.ShowFullName, utils.Cfg.PrivacySettings.ShowEmailAddress)
 
 	if HandleEtag(etag, "Get User", w, r) {
@@ -276,6 +291,15 @@ func getUsers(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Users that can't view all members, such as guests, can only list the members of their channels
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_VIEW_MEMBERS) {
+		withoutTeamBool, _ := strconv.ParseBool(withoutTeam)
+		if len(inChannelId) == 0 || len(inTeamId) > 0 || len(notInTeamId) > 0 || len(notInChannelId) > 0 || withoutTeamBool {
+			c.SetPermissionError(model.PERMISSION_VIEW_MEMBERS)
+			return
+		}
+	}
+
 	var profiles []*model.User
 	var err *model.AppError
 	etag := ""
@@ -355,7 +379,12 @@ func getUsersByIds(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// No permission check required
+	// Users that can't view all members, such as guests, only get back the users they share a channel with
+	userIds = app.GetUserIdsVisibleToSession(c.Session, userIds)
+	if len(userIds) == 0 {
+		w.Write([]byte(model.UserListToJson([]*model.User{})))
+		return
+	}
 
 	if users, err := app.GetUsersByIds(userIds, c.IsSystemAdmin()); err != nil {
 		c.Err = err
@@ -382,6 +411,11 @@ func searchUsers(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if (props.InChannelId == "" || props.WithoutTeam) && !app.SessionHasPermissionTo(c.Session, model.PERMISSION_VIEW_MEMBERS) {
+		c.SetPermissionError(model.PERMISSION_VIEW_MEMBERS)
+		return
+	}
+
 	if props.InChannelId != "" && !app.SessionHasPermissionToChannel(c.Session, props.InChannelId, model.PERMISSION_READ_CHANNEL) {
 		c.SetPermissionError(model.PERMISSION_READ_CHANNEL)
 		return
@@ -443,6 +477,12 @@ func autocompleteUsers(c *Context, w http.ResponseWriter, r *http.Request) {
 		searchOptions[store.USER_SEARCH_OPTION_NAMES_ONLY] = true
 	}
 
+	canViewMembers := app.SessionHasPermissionTo(c.Session, model.PERMISSION_VIEW_MEMBERS)
+	if (len(teamId) == 0 || len(channelId) == 0) && !canViewMembers {
+		c.SetPermissionError(model.PERMISSION_VIEW_MEMBERS)
+		return
+	}
+
 	if len(teamId) > 0 {
 		if len(channelId) > 0 {
 			if !app.SessionHasPermissionToChannel(c.Session, channelId, model.PERMISSION_READ_CHANNEL) {
@@ -452,7 +492,10 @@ func autocompleteUsers(c *Context, w http.ResponseWriter, r *http.Request) {
 
 			result, _ := app.AutocompleteUsersInChannel(teamId, channelId, name, searchOptions, c.IsSystemAdmin())
 			autocomplete.Users = result.InChannel
-			autocomplete.OutOfChannel = result.OutOfChannel
+			if canViewMembers {
+				autocomplete.OutOfChannel = result.OutOfChannel
+			}
+			autocomplete.MentionGroups = result.MentionGroups
 		} else {
 			if !app.SessionHasPermissionToTeam(c.Session, teamId, model.PERMISSION_VIEW_TEAM) {
 				c.SetPermissionError(model.PERMISSION_VIEW_TEAM)
@@ -461,6 +504,7 @@ func autocompleteUsers(c *Context, w http.ResponseWriter, r *http.Request) {
 
 			result, _ := app.AutocompleteUsersInTeam(teamId, name, searchOptions, c.IsSystemAdmin())
 			autocomplete.Users = result.InTeam
+			autocomplete.MentionGroups = result.MentionGroups
 		}
 	} else {
 		// No permission check required
@@ -598,7 +642,7 @@ func checkUserMfa(c *Context, w http.ResponseWriter, r *http.Request) {
 	resp := map[string]interface{}{}
 	resp["mfa_required"] = false
 
-	if !utils.IsLicensed || !*utils.License.Features.MFA || !*utils.Cfg.ServiceSettings.EnableMultifactorAuthentication {
+	if !*utils.Cfg.ServiceSettings.EnableMultifactorAuthentication {
 		w.Write([]byte(model.StringInterfaceToJson(resp)))
 		return
 	}
@@ -672,6 +716,60 @@ func generateMfaSecret(c *Context, w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(secret.ToJson()))
 }
 
+func generateMfaRecoveryCodes(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionToUser(c.Session, c.Params.UserId) {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return
+	}
+
+	props := model.MapFromJson(r.Body)
+
+	token := props["token"]
+	if len(token) == 0 {
+		c.SetInvalidParam("token")
+		return
+	}
+
+	c.LogAudit("attempt")
+
+	codes, err := app.GenerateMfaRecoveryCodes(c.Params.UserId, token)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("success - mfa recovery codes generated")
+
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Pragma", "no-cache")
+	w.Header().Set("Expires", "0")
+	w.Write([]byte(model.MfaRecoveryCodesToJson(codes)))
+}
+
+func getMfaRecoveryCodeCount(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionToUser(c.Session, c.Params.UserId) {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return
+	}
+
+	if count, err := app.GetMfaRecoveryCodeCount(c.Params.UserId); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(model.MapToJson(map[string]string{"count": strconv.FormatInt(count, 10)})))
+	}
+}
+
 func updatePassword(c *Context, w http.ResponseWriter, r *http.Request) {
 	c.RequireUserId()
 	if c.Err != nil {
@@ -763,7 +861,7 @@ func login(c *Context, w http.ResponseWriter, r *http.Request) {
 	ldapOnly := props["ldap_only"] == "true"
 
 	c.LogAuditWithUserId(id, "attempt - login_id="+loginId)
-	user, err := app.AuthenticateUserForLogin(id, loginId, password, mfaToken, deviceId, ldapOnly)
+	user, err := app.AuthenticateUserForLogin(id, loginId, password, mfaToken, deviceId, app.GetLoginThrottleIpAddress(r), ldapOnly)
 	if err != nil {
 		c.LogAuditWithUserId(id, "failure - login_id="+loginId)
 		c.Err = err
@@ -772,6 +870,10 @@ func login(c *Context, w http.ResponseWriter, r *http.Request) {
 
 	c.LogAuditWithUserId(user.Id, "authenticated")
 
+	if deadline := app.PasswordChangeRequiredBy(user); deadline != 0 {
+		w.Header().Set(model.HEADER_PASSWORD_CHANGE_REQUIRED_BY, strconv.FormatInt(deadline, 10))
+	}
+
 	var session *model.Session
 	session, err = app.DoLogin(w, r, user, deviceId)
 	if err != nil {
@@ -859,6 +961,47 @@ func revokeSession(c *Context, w http.ResponseWriter, r *http.Request) {
 	ReturnStatusOK(w)
 }
 
+func revokeAllSessionsForUser(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	props := model.MapFromJson(r.Body)
+	reason := props["reason"]
+
+	if err := app.RevokeAllSessionsWithReason(c.Params.UserId, reason, c.GetSiteURL()); err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("user_id=" + c.Params.UserId + " reason=" + reason)
+	ReturnStatusOK(w)
+}
+
+func revokeSessionsFromAllUsers(c *Context, w http.ResponseWriter, r *http.Request) {
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	props := model.MapFromJson(r.Body)
+	reason := props["reason"]
+
+	if err := app.RevokeSessionsFromAllUsers(reason, c.GetSiteURL()); err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("reason=" + reason)
+	ReturnStatusOK(w)
+}
+
 func attachDeviceId(c *Context, w http.ResponseWriter, r *http.Request) {
 	props := model.MapFromJson(r.Body)
 
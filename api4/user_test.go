@@ -4,13 +4,17 @@
 package api4
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/dgryski/dgoogauth"
 	"github.com/mattermost/platform/app"
 	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/store"
 	"github.com/mattermost/platform/utils"
 )
 
@@ -745,6 +749,11 @@ func TestPatchUser(t *testing.T) {
 	patch.LastName = new(string)
 	*patch.LastName = "Wilander"
 	patch.Position = new(string)
+	patch.Timezone = &model.StringMap{
+		model.USE_AUTOMATIC_TIMEZONE: "false",
+		model.AUTOMATIC_TIMEZONE:     "",
+		model.MANUAL_TIMEZONE:        "America/Toronto",
+	}
 
 	ruser, resp := Client.PatchUser(user.Id, patch)
 	CheckNoError(t, resp)
@@ -765,6 +774,9 @@ func TestPatchUser(t *testing.T) {
 	if ruser.Username != user.Username {
 		t.Fatal("Username should not have updated")
 	}
+	if ruser.GetPreferredTimezone() != "America/Toronto" {
+		t.Fatal("Timezone did not update properly")
+	}
 
 	_, resp = Client.PatchUser("junk", patch)
 	CheckBadRequestStatus(t, resp)
@@ -1222,8 +1234,12 @@ func TestGenerateMfaSecret(t *testing.T) {
 	defer TearDown()
 	Client := th.Client
 
-	_, resp := Client.GenerateMfaSecret(th.BasicUser.Id)
-	CheckNotImplementedStatus(t, resp)
+	secret, resp := Client.GenerateMfaSecret(th.BasicUser.Id)
+	CheckNoError(t, resp)
+
+	if secret.Secret == "" || secret.QRCode == "" {
+		t.Fatal("should have returned the secret and its QR code")
+	}
 
 	_, resp = Client.GenerateMfaSecret("junk")
 	CheckBadRequestStatus(t, resp)
@@ -1237,7 +1253,304 @@ func TestGenerateMfaSecret(t *testing.T) {
 	CheckUnauthorizedStatus(t, resp)
 
 	_, resp = th.SystemAdminClient.GenerateMfaSecret(th.BasicUser.Id)
-	CheckNotImplementedStatus(t, resp)
+	CheckNoError(t, resp)
+}
+
+func TestUpdateUserPasswordHistory(t *testing.T) {
+	th := Setup().InitBasic()
+	defer TearDown()
+	Client := th.Client
+
+	historyCount := *utils.Cfg.PasswordSettings.HistoryCount
+	defer func() {
+		*utils.Cfg.PasswordSettings.HistoryCount = historyCount
+	}()
+	*utils.Cfg.PasswordSettings.HistoryCount = 2
+
+	user := th.BasicUser
+
+	_, resp := Client.UpdateUserPassword(user.Id, user.Password, "password1")
+	CheckNoError(t, resp)
+
+	_, resp = Client.UpdateUserPassword(user.Id, "password1", "password1")
+	CheckErrorMessage(t, resp, "app.password_policy.reused.app_error")
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.UpdateUserPassword(user.Id, "password1", "password2")
+	CheckNoError(t, resp)
+
+	_, resp = Client.UpdateUserPassword(user.Id, "password2", "password1")
+	CheckErrorMessage(t, resp, "app.password_policy.reused.app_error")
+
+	_, resp = Client.UpdateUserPassword(user.Id, "password2", "password3")
+	CheckNoError(t, resp)
+
+	// password1 is no longer one of the last 2 passwords
+	_, resp = Client.UpdateUserPassword(user.Id, "password3", "password1")
+	CheckNoError(t, resp)
+}
+
+func TestLoginPasswordExpiry(t *testing.T) {
+	th := Setup().InitBasic()
+	defer TearDown()
+	Client := th.CreateClient()
+
+	expiryInDays := *utils.Cfg.PasswordSettings.ExpiryInDays
+	gracePeriodInDays := *utils.Cfg.PasswordSettings.ExpiryGracePeriodInDays
+	defer func() {
+		*utils.Cfg.PasswordSettings.ExpiryInDays = expiryInDays
+		*utils.Cfg.PasswordSettings.ExpiryGracePeriodInDays = gracePeriodInDays
+	}()
+	*utils.Cfg.PasswordSettings.ExpiryInDays = 10
+	*utils.Cfg.PasswordSettings.ExpiryGracePeriodInDays = 5
+
+	user := th.BasicUser2
+	day := int64(24 * 60 * 60 * 1000)
+
+	setLastPasswordUpdate := func(lastPasswordUpdate int64) {
+		app.Srv.Store.(*store.SqlStore).GetMaster().Exec("UPDATE Users SET LastPasswordUpdate = :LastPasswordUpdate WHERE Id = :Id",
+			map[string]interface{}{"LastPasswordUpdate": lastPasswordUpdate, "Id": user.Id})
+	}
+
+	_, resp := Client.Login(user.Email, user.Password)
+	CheckNoError(t, resp)
+	if resp.PasswordChangeRequiredBy != "" {
+		t.Fatal("shouldn't have required a password change")
+	}
+
+	lastPasswordUpdate := model.GetMillis() - 12*day
+	setLastPasswordUpdate(lastPasswordUpdate)
+
+	_, resp = Client.Login(user.Email, user.Password)
+	CheckNoError(t, resp)
+	if resp.PasswordChangeRequiredBy != strconv.FormatInt(lastPasswordUpdate+15*day, 10) {
+		t.Fatal("should have required a password change by the end of the grace period")
+	}
+
+	setLastPasswordUpdate(model.GetMillis() - 16*day)
+
+	_, resp = Client.Login(user.Email, user.Password)
+	CheckErrorMessage(t, resp, "app.password_policy.expired.app_error")
+
+	_, resp = Client.Login(user.Email, "wrongpassword")
+	CheckErrorMessage(t, resp, "api.user.check_user_password.invalid.app_error")
+}
+
+func TestLoginThrottling(t *testing.T) {
+	th := Setup().InitBasic()
+	defer TearDown()
+	Client := th.CreateClient()
+
+	enableLoginThrottling := *utils.Cfg.ServiceSettings.EnableLoginThrottling
+	attemptsPerLoginId := *utils.Cfg.ServiceSettings.LoginThrottleAttemptsPerLoginId
+	trustedProxies := *utils.Cfg.ServiceSettings.LoginThrottleTrustedProxies
+	defer func() {
+		*utils.Cfg.ServiceSettings.EnableLoginThrottling = enableLoginThrottling
+		*utils.Cfg.ServiceSettings.LoginThrottleAttemptsPerLoginId = attemptsPerLoginId
+		*utils.Cfg.ServiceSettings.LoginThrottleTrustedProxies = trustedProxies
+	}()
+	*utils.Cfg.ServiceSettings.EnableLoginThrottling = true
+	*utils.Cfg.ServiceSettings.LoginThrottleAttemptsPerLoginId = 2
+	*utils.Cfg.ServiceSettings.LoginThrottleTrustedProxies = model.SERVICE_SETTINGS_DEFAULT_LOGIN_THROTTLE_TRUSTED_PROXIES
+
+	user := th.BasicUser2
+
+	_, resp := Client.Login(user.Email, "wrongpassword")
+	CheckUnauthorizedStatus(t, resp)
+	_, resp = Client.Login(user.Email, "wrongpassword")
+	CheckUnauthorizedStatus(t, resp)
+
+	_, resp = Client.Login(user.Email, user.Password)
+	CheckErrorMessage(t, resp, "api.user.login.throttled.app_error")
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Fatal("wrong status code", resp.StatusCode)
+	}
+
+	_, resp = Client.Login(strings.ToUpper(user.Email), user.Password)
+	CheckErrorMessage(t, resp, "api.user.login.throttled.app_error")
+
+	// unknown login IDs are throttled too
+	loginId := model.NewId() + "@simulator.amazonses.com"
+	Client.Login(loginId, "wrongpassword")
+	Client.Login(loginId, "wrongpassword")
+	_, resp = Client.Login(loginId, "wrongpassword")
+	CheckErrorMessage(t, resp, "api.user.login.throttled.app_error")
+
+	time.Sleep(1100 * time.Millisecond)
+
+	_, resp = Client.Login(user.Email, user.Password)
+	CheckNoError(t, resp)
+
+	if result := <-app.Srv.Store.LoginAttempt().Get(model.LoginAttemptIdForLoginId(user.Id)); result.Err == nil {
+		t.Fatal("should have forgotten the failed logins")
+	}
+
+	// logging in shouldn't reset the attempts left for the IP address
+	found := false
+	for _, ipAddress := range []string{"127.0.0.1", "::1"} {
+		if result := <-app.Srv.Store.LoginAttempt().Get(model.LoginAttemptIdForIpAddress(ipAddress)); result.Err == nil {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatal("should have kept the failed logins from the IP address")
+	}
+
+	// the username and email of a user share the same attempts
+	Client.Logout()
+	Client.Login(user.Username, "wrongpassword")
+	Client.Login(user.Email, "wrongpassword")
+	_, resp = Client.Login(user.Username, user.Password)
+	CheckErrorMessage(t, resp, "api.user.login.throttled.app_error")
+
+	time.Sleep(1100 * time.Millisecond)
+
+	_, resp = Client.Login(user.Email, user.Password)
+	CheckNoError(t, resp)
+
+	// forwarding headers from untrusted clients don't change the IP address that's throttled
+	r, _ := http.NewRequest("POST", "http://example.com", nil)
+	r.RemoteAddr = "203.0.113.1:1234"
+	r.Header.Set(model.HEADER_FORWARDED, "198.51.100.1")
+	if ipAddress := app.GetLoginThrottleIpAddress(r); ipAddress != "203.0.113.1" {
+		t.Fatal("shouldn't have trusted the forwarding header", ipAddress)
+	}
+
+	// a reverse proxy on a private network is trusted by default
+	r.RemoteAddr = "10.0.0.1:1234"
+	if ipAddress := app.GetLoginThrottleIpAddress(r); ipAddress != "198.51.100.1" {
+		t.Fatal("should have trusted the forwarding header from the proxy", ipAddress)
+	}
+
+	*utils.Cfg.ServiceSettings.EnableLoginThrottling = false
+
+	Client.Logout()
+	Client.Login(user.Email, "wrongpassword")
+	Client.Login(user.Email, "wrongpassword")
+	_, resp = Client.Login(user.Email, user.Password)
+	CheckNoError(t, resp)
+}
+
+func TestLoginLockoutCooldown(t *testing.T) {
+	th := Setup().InitBasic()
+	defer TearDown()
+	Client := th.CreateClient()
+
+	passwordAttempts := utils.Cfg.ServiceSettings.MaximumLoginAttempts
+	defer func() {
+		utils.Cfg.ServiceSettings.MaximumLoginAttempts = passwordAttempts
+	}()
+	utils.Cfg.ServiceSettings.MaximumLoginAttempts = 2
+
+	user := th.BasicUser2
+
+	Client.Login(user.Email, "wrongpassword")
+	Client.Login(user.Email, "wrongpassword")
+
+	_, resp := Client.Login(user.Email, user.Password)
+	CheckErrorMessage(t, resp, "api.user.check_user_login_attempts.too_many.app_error")
+
+	// pretend the last failure happened before the cooldown
+	store.Must(app.Srv.Store.LoginAttempt().Delete(model.LoginAttemptIdForUser(user.Id)))
+	store.Must(app.Srv.Store.LoginAttempt().RecordFailure(model.LoginAttemptIdForUser(user.Id), 1000, 0))
+
+	_, resp = Client.Login(user.Email, user.Password)
+	CheckNoError(t, resp)
+
+	if ruser, _ := app.GetUser(user.Id); ruser.FailedAttempts != 0 {
+		t.Fatal("should have reset the failed attempts")
+	}
+}
+
+func TestMfaLogin(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	enableMfa := *utils.Cfg.ServiceSettings.EnableMultifactorAuthentication
+	defer func() {
+		*utils.Cfg.ServiceSettings.EnableMultifactorAuthentication = enableMfa
+	}()
+	*utils.Cfg.ServiceSettings.EnableMultifactorAuthentication = true
+
+	_, resp := Client.GenerateMfaRecoveryCodes(th.BasicUser.Id, "000000")
+	CheckBadRequestStatus(t, resp)
+
+	secret, resp := Client.GenerateMfaSecret(th.BasicUser.Id)
+	CheckNoError(t, resp)
+
+	step := time.Now().Unix() / app.MFA_TOKEN_STEP
+	token := fmt.Sprintf("%06d", dgoogauth.ComputeCode(secret.Secret, step))
+
+	_, resp = Client.UpdateUserMfa(th.BasicUser.Id, "000000", true)
+	CheckUnauthorizedStatus(t, resp)
+
+	_, resp = Client.UpdateUserMfa(th.BasicUser.Id, token, true)
+	CheckNoError(t, resp)
+
+	// the secret of an active authenticator can't be replaced
+	_, resp = Client.GenerateMfaSecret(th.BasicUser.Id)
+	CheckBadRequestStatus(t, resp)
+	CheckErrorMessage(t, resp, "app.mfa.already_active.app_error")
+
+	_, resp = Client.UpdateUserMfa(th.BasicUser.Id, token, true)
+	CheckBadRequestStatus(t, resp)
+
+	required, _ := Client.CheckUserMfa(th.BasicUser.Email)
+	if !required {
+		t.Fatal("should require mfa")
+	}
+
+	_, resp = Client.Login(th.BasicUser.Email, th.BasicUser.Password)
+	CheckErrorMessage(t, resp, "api.user.check_user_mfa.bad_code.app_error")
+
+	_, resp = Client.LoginWithMFA(th.BasicUser.Email, th.BasicUser.Password, token)
+	CheckErrorMessage(t, resp, "api.user.check_user_mfa.bad_code.app_error")
+
+	nextToken := fmt.Sprintf("%06d", dgoogauth.ComputeCode(secret.Secret, step+1))
+	_, resp = Client.LoginWithMFA(th.BasicUser.Email, th.BasicUser.Password, nextToken)
+	CheckNoError(t, resp)
+
+	_, resp = Client.GenerateMfaRecoveryCodes(th.BasicUser.Id, "")
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.GenerateMfaRecoveryCodes(th.BasicUser.Id, "000000")
+	CheckErrorMessage(t, resp, "app.mfa.generate_recovery_codes.bad_token.app_error")
+
+	_, resp = Client.GenerateMfaRecoveryCodes(th.BasicUser.Id, nextToken)
+	CheckErrorMessage(t, resp, "app.mfa.generate_recovery_codes.bad_token.app_error")
+
+	recoveryToken := fmt.Sprintf("%06d", dgoogauth.ComputeCode(secret.Secret, step-1))
+	codes, resp := Client.GenerateMfaRecoveryCodes(th.BasicUser.Id, recoveryToken)
+	CheckNoError(t, resp)
+
+	if len(codes) != model.MFA_RECOVERY_CODE_COUNT {
+		t.Fatal("should have generated recovery codes")
+	}
+
+	Client.Logout()
+
+	_, resp = Client.LoginWithMFA(th.BasicUser.Email, th.BasicUser.Password, strings.ToUpper(codes[0]))
+	CheckNoError(t, resp)
+
+	if count, resp := Client.GetMfaRecoveryCodeCount(th.BasicUser.Id); resp.Error != nil || count != model.MFA_RECOVERY_CODE_COUNT-1 {
+		t.Fatal("should have used up the recovery code")
+	}
+
+	Client.Logout()
+
+	_, resp = Client.LoginWithMFA(th.BasicUser.Email, th.BasicUser.Password, codes[0])
+	CheckErrorMessage(t, resp, "api.user.check_user_mfa.bad_code.app_error")
+
+	_, resp = th.SystemAdminClient.UpdateUserMfa(th.BasicUser.Id, "", false)
+	CheckNoError(t, resp)
+
+	_, resp = Client.Login(th.BasicUser.Email, th.BasicUser.Password)
+	CheckNoError(t, resp)
+
+	if count, _ := Client.GetMfaRecoveryCodeCount(th.BasicUser.Id); count != 0 {
+		t.Fatal("should have deleted the recovery codes when resetting mfa")
+	}
 }
 
 func TestUpdateUserPassword(t *testing.T) {
@@ -1508,6 +1821,174 @@ func TestRevokeSessions(t *testing.T) {
 	CheckNoError(t, resp)
 }
 
+func TestRevokeAllSessions(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	_, resp := Client.RevokeAllSessions(th.BasicUser2.Id, "")
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.RevokeAllSessions(model.NewId(), "")
+	CheckNotFoundStatus(t, resp)
+
+	ok, resp := th.SystemAdminClient.RevokeAllSessions(th.BasicUser.Id, "compromised password")
+	CheckNoError(t, resp)
+	if !ok {
+		t.Fatal("should have revoked the sessions")
+	}
+
+	_, resp = Client.GetMe("")
+	CheckUnauthorizedStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.GetMe("")
+	CheckNoError(t, resp)
+
+	if sessions, _ := th.SystemAdminClient.GetSessions(th.BasicUser.Id, ""); len(sessions) != 0 {
+		t.Fatal("should have removed the sessions")
+	}
+
+	Client.Logout()
+	_, resp = Client.RevokeAllSessions(th.BasicUser.Id, "")
+	CheckUnauthorizedStatus(t, resp)
+}
+
+func TestRevokeSessionsFromAllUsers(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	_, resp := Client.RevokeSessionsFromAllUsers("")
+	CheckForbiddenStatus(t, resp)
+
+	ok, resp := th.SystemAdminClient.RevokeSessionsFromAllUsers("server maintenance")
+	CheckNoError(t, resp)
+	if !ok {
+		t.Fatal("should have revoked the sessions")
+	}
+
+	_, resp = Client.GetMe("")
+	CheckUnauthorizedStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.GetMe("")
+	CheckUnauthorizedStatus(t, resp)
+}
+
+func TestSessionIdleTimeout(t *testing.T) {
+	th := Setup().InitBasic()
+	defer TearDown()
+	Client := th.Client
+
+	idleTimeout := *utils.Cfg.ServiceSettings.SessionIdleTimeoutInMinutes
+	defer func() {
+		*utils.Cfg.ServiceSettings.SessionIdleTimeoutInMinutes = idleTimeout
+	}()
+	*utils.Cfg.ServiceSettings.SessionIdleTimeoutInMinutes = 5
+
+	_, resp := Client.GetMe("")
+	CheckNoError(t, resp)
+
+	session, _ := app.GetSession(Client.AuthToken)
+	store.Must(app.Srv.Store.Session().UpdateLastActivityAt(session.Id, model.GetMillis()-4*60*1000))
+	app.ClearSessionCacheForUser(th.BasicUser.Id)
+
+	_, resp = Client.GetMe("")
+	CheckNoError(t, resp)
+
+	store.Must(app.Srv.Store.Session().UpdateLastActivityAt(session.Id, model.GetMillis()-6*60*1000))
+	app.ClearSessionCacheForUser(th.BasicUser.Id)
+
+	_, resp = Client.GetMe("")
+	CheckUnauthorizedStatus(t, resp)
+
+	if result := <-app.Srv.Store.Session().Get(session.Id); result.Err == nil {
+		t.Fatal("should have removed the idle session")
+	}
+}
+
+func TestSessionActivity(t *testing.T) {
+	th := Setup().InitBasic()
+	defer TearDown()
+	Client := th.Client
+
+	session, _ := app.GetSession(Client.AuthToken)
+	lastActivityAt := model.GetMillis() - 2*model.SESSION_ACTIVITY_UPDATE_INTERVAL
+
+	getLastActivityAt := func() int64 {
+		return store.Must(app.Srv.Store.Session().Get(session.Id)).(*model.Session).LastActivityAt
+	}
+
+	store.Must(app.Srv.Store.Session().UpdateLastActivityAt(session.Id, lastActivityAt))
+	app.ClearSessionCacheForUser(th.BasicUser.Id)
+
+	_, resp := Client.GetUserStatus(th.BasicUser.Id, "")
+	CheckNoError(t, resp)
+
+	if getLastActivityAt() != lastActivityAt {
+		t.Fatal("background requests shouldn't keep the session active")
+	}
+
+	th.CreatePost()
+
+	if getLastActivityAt() == lastActivityAt {
+		t.Fatal("posting should keep the session active")
+	}
+
+	store.Must(app.Srv.Store.Session().UpdateLastActivityAt(session.Id, lastActivityAt))
+	app.ClearSessionCacheForUser(th.BasicUser.Id)
+
+	r, err := http.NewRequest("GET", Client.ApiUrl+"/users/me", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Header.Set(model.HEADER_AUTH, "BEARER "+Client.AuthToken)
+	r.Header.Set(model.HEADER_USER_ACTIVITY, "true")
+
+	if rp, err := Client.HttpClient.Do(r); err != nil {
+		t.Fatal(err)
+	} else {
+		rp.Body.Close()
+	}
+
+	if getLastActivityAt() == lastActivityAt {
+		t.Fatal("requests marked as user activity should keep the session active")
+	}
+}
+
+func TestMaximumSessionsPerUser(t *testing.T) {
+	th := Setup().InitBasic()
+	defer TearDown()
+	Client := th.Client
+
+	maxSessions := *utils.Cfg.ServiceSettings.MaximumSessionsPerUser
+	defer func() {
+		*utils.Cfg.ServiceSettings.MaximumSessionsPerUser = maxSessions
+	}()
+	*utils.Cfg.ServiceSettings.MaximumSessionsPerUser = 2
+
+	Client2 := th.CreateClient()
+	th.LoginBasicWithClient(Client2)
+
+	_, resp := Client.GetMe("")
+	CheckNoError(t, resp)
+
+	Client3 := th.CreateClient()
+	th.LoginBasicWithClient(Client3)
+
+	_, resp = Client.GetMe("")
+	CheckUnauthorizedStatus(t, resp)
+
+	_, resp = Client2.GetMe("")
+	CheckNoError(t, resp)
+
+	_, resp = Client3.GetMe("")
+	CheckNoError(t, resp)
+
+	if sessions, _ := Client3.GetSessions(th.BasicUser.Id, ""); len(sessions) != 2 {
+		t.Fatal("should have kept two sessions")
+	}
+}
+
 func TestAttachDeviceId(t *testing.T) {
 	th := Setup().InitBasic()
 	defer TearDown()
@@ -1651,3 +2132,233 @@ func TestSetProfileImage(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestCookieAuthCSRF(t *testing.T) {
+	th := Setup().InitBasic()
+	defer TearDown()
+
+	props := map[string]string{"login_id": th.BasicUser.Email, "password": th.BasicUser.Password}
+	rp, err := http.Post(th.Client.ApiUrl+"/users/login", "application/json", strings.NewReader(model.MapToJson(props)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rp.Body.Close()
+
+	var sessionCookie, csrfCookie *http.Cookie
+	for _, cookie := range rp.Cookies() {
+		switch cookie.Name {
+		case model.SESSION_COOKIE_TOKEN:
+			sessionCookie = cookie
+		case model.SESSION_COOKIE_CSRF:
+			csrfCookie = cookie
+		}
+	}
+
+	if sessionCookie == nil || csrfCookie == nil {
+		t.Fatal("should have set the session and CSRF cookies")
+	}
+	if csrfCookie.HttpOnly || csrfCookie.Value != rp.Header.Get(model.HEADER_CSRF_TOKEN) {
+		t.Fatal("CSRF token should be readable by the webapp")
+	}
+
+	doRequest := func(method string, csrfToken string) int {
+		rq, _ := http.NewRequest(method, th.Client.ApiUrl+"/users/"+th.BasicUser.Id+"/patch", strings.NewReader(`{"nickname":"csrf"}`))
+		if method == "GET" {
+			rq, _ = http.NewRequest(method, th.Client.ApiUrl+"/users/me", nil)
+		}
+		rq.AddCookie(sessionCookie)
+		rq.Header.Set(model.HEADER_REQUESTED_WITH, model.HEADER_REQUESTED_WITH_XML)
+		if len(csrfToken) > 0 {
+			rq.Header.Set(model.HEADER_CSRF_TOKEN, csrfToken)
+		}
+
+		rp, err := http.DefaultClient.Do(rq)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rp.Body.Close()
+		return rp.StatusCode
+	}
+
+	if status := doRequest("GET", ""); status != http.StatusOK {
+		t.Fatal("reads shouldn't need a CSRF token", status)
+	}
+
+	if status := doRequest("PUT", ""); status != http.StatusUnauthorized {
+		t.Fatal("should have failed without a CSRF token", status)
+	}
+
+	if status := doRequest("PUT", model.NewId()); status != http.StatusUnauthorized {
+		t.Fatal("should have failed with the wrong CSRF token", status)
+	}
+
+	if status := doRequest("PUT", csrfCookie.Value); status != http.StatusOK {
+		t.Fatal("should have passed with the CSRF token", status)
+	}
+
+	// sessions from before CSRF tokens existed are given one
+	session, _ := app.GetSession(sessionCookie.Value)
+	oldProps := model.StringMap{}
+	for key, value := range session.Props {
+		oldProps[key] = value
+	}
+	delete(oldProps, model.SESSION_PROP_CSRF)
+	store.Must(app.Srv.Store.Session().UpdateProps(session.Id, oldProps))
+	app.ClearSessionCacheForUser(th.BasicUser.Id)
+
+	rq, _ := http.NewRequest("GET", th.Client.ApiUrl+"/users/me", nil)
+	rq.AddCookie(sessionCookie)
+	rq.Header.Set(model.HEADER_REQUESTED_WITH, model.HEADER_REQUESTED_WITH_XML)
+	if rp, err = http.DefaultClient.Do(rq); err != nil {
+		t.Fatal(err)
+	}
+	rp.Body.Close()
+
+	csrfCookie = nil
+	for _, cookie := range rp.Cookies() {
+		if cookie.Name == model.SESSION_COOKIE_CSRF {
+			csrfCookie = cookie
+		}
+	}
+
+	if csrfCookie == nil || len(csrfCookie.Value) == 0 {
+		t.Fatal("should have given the old session a CSRF token")
+	}
+
+	if status := doRequest("PUT", csrfCookie.Value); status != http.StatusOK {
+		t.Fatal("should have passed with the new CSRF token", status)
+	}
+
+	rq, _ = http.NewRequest("POST", th.Client.ApiUrl+"/users/logout", nil)
+	rq.AddCookie(sessionCookie)
+	rq.Header.Set(model.HEADER_REQUESTED_WITH, model.HEADER_REQUESTED_WITH_XML)
+	rq.Header.Set(model.HEADER_CSRF_TOKEN, csrfCookie.Value)
+	if rp, err = http.DefaultClient.Do(rq); err != nil {
+		t.Fatal(err)
+	}
+	rp.Body.Close()
+
+	expired := false
+	for _, cookie := range rp.Cookies() {
+		if cookie.Name == model.SESSION_COOKIE_CSRF && cookie.MaxAge < 0 {
+			expired = true
+		}
+	}
+
+	if !expired {
+		t.Fatal("should have expired the CSRF cookie on logout")
+	}
+
+	// clients using the header don't need a CSRF token
+	th.LoginBasic()
+	patch := &model.UserPatch{}
+	patch.Nickname = new(string)
+	*patch.Nickname = "header"
+	_, resp := th.Client.PatchUser(th.BasicUser.Id, patch)
+	CheckNoError(t, resp)
+}
+
+func TestGuestAccess(t *testing.T) {
+	th := Setup().InitBasic()
+	defer TearDown()
+
+	props := map[string]string{
+		"email":      GenerateTestEmail(),
+		"id":         th.BasicTeam.Id,
+		"time":       fmt.Sprintf("%v", model.GetMillis()),
+		"guest":      "true",
+		"channels":   th.BasicPrivateChannel.Id,
+		"expires_at": fmt.Sprintf("%v", model.GetMillis()+model.GUEST_INVITE_DEFAULT_EXPIRY),
+	}
+	data := model.MapToJson(props)
+	hash := model.HashPassword(fmt.Sprintf("%v:%v", data, utils.Cfg.EmailSettings.InviteSalt))
+
+	guest, err := app.CreateUserWithHash(&model.User{Username: GenerateTestUsername(), Password: "Password1"}, hash, data, "http://localhost:8065")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !guest.IsGuest() {
+		t.Fatal("should have created a guest", guest.Roles)
+	}
+
+	if member, err := app.GetTeamMember(th.BasicTeam.Id, guest.Id); err != nil {
+		t.Fatal(err)
+	} else if member.Roles != model.ROLE_TEAM_GUEST.Id {
+		t.Fatal("should be a team guest", member.Roles)
+	}
+
+	if member, err := app.GetChannelMember(th.BasicPrivateChannel.Id, guest.Id); err != nil {
+		t.Fatal(err)
+	} else if member.Roles != model.ROLE_CHANNEL_GUEST.Id {
+		t.Fatal("should be a channel guest", member.Roles)
+	}
+
+	if _, err := app.GetChannelMember(th.BasicChannel.Id, guest.Id); err == nil {
+		t.Fatal("shouldn't have joined the default channels")
+	}
+
+	Client := th.CreateClient()
+	_, resp := Client.Login(guest.Email, "Password1")
+	CheckNoError(t, resp)
+
+	_, resp = Client.GetUsers(0, 60, "")
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = Client.GetUsersInTeam(th.BasicTeam.Id, 0, 60, "")
+	CheckForbiddenStatus(t, resp)
+
+	users, resp := Client.GetUsersInChannel(th.BasicPrivateChannel.Id, 0, 60, "")
+	CheckNoError(t, resp)
+	if len(users) != 4 {
+		t.Fatal("should have listed the channel members", len(users))
+	}
+
+	_, resp = Client.SearchUsers(&model.UserSearch{Term: th.BasicUser.Username, TeamId: th.BasicTeam.Id})
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = Client.SearchUsers(&model.UserSearch{Term: th.BasicUser.Username, InChannelId: th.BasicPrivateChannel.Id})
+	CheckNoError(t, resp)
+
+	autocomplete, resp := Client.AutocompleteUsersInChannel(th.BasicTeam.Id, th.BasicPrivateChannel.Id, "", "")
+	CheckNoError(t, resp)
+	if len(autocomplete.OutOfChannel) != 0 {
+		t.Fatal("shouldn't have returned users outside the channel")
+	}
+
+	_, resp = Client.GetPublicChannelsForTeam(th.BasicTeam.Id, 0, 60, "")
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = Client.GetChannel(th.BasicChannel.Id, "")
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = Client.CreateChannel(&model.Channel{DisplayName: "guest", Name: GenerateTestChannelName(), Type: model.CHANNEL_OPEN, TeamId: th.BasicTeam.Id})
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = Client.CreateDirectChannel(guest.Id, th.BasicUser.Id)
+	CheckNoError(t, resp)
+
+	otherUser := th.CreateUser()
+	LinkUserToTeam(otherUser, th.BasicTeam)
+
+	_, resp = Client.CreateDirectChannel(guest.Id, otherUser.Id)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = Client.GetUser(th.BasicUser.Id, "")
+	CheckNoError(t, resp)
+
+	_, resp = Client.GetUser(otherUser.Id, "")
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = Client.GetUserByUsername(otherUser.Username, "")
+	CheckNotFoundStatus(t, resp)
+
+	_, resp = Client.GetUserByEmail(otherUser.Email, "")
+	CheckNotFoundStatus(t, resp)
+
+	users, resp = Client.GetUsersByIds([]string{th.BasicUser.Id, otherUser.Id})
+	CheckNoError(t, resp)
+	if len(users) != 1 || users[0].Id != th.BasicUser.Id {
+		t.Fatal("should only have returned the users the guest shares a channel with")
+	}
+}
@@ -21,6 +21,10 @@ func CheckPasswordAndAllCriteria(user *model.User, password string, mfaToken str
 		return err
 	}
 
+	if err := checkPasswordNotExpired(user); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -43,6 +47,8 @@ func checkUserPassword(user *model.User, password string) *model.AppError {
 			return result.Err
 		}
 
+		recordUserPasswordFailure(user.Id)
+
 		return model.NewLocAppError("checkUserPassword", "api.user.check_user_password.invalid.app_error", nil, "user_id="+user.Id)
 	} else {
 		if result := <-Srv.Store.User().UpdateFailedPasswordAttempts(user.Id, 0); result.Err != nil {
@@ -103,26 +109,44 @@ func CheckUserAdditionalAuthenticationCriteria(user *model.User, mfaToken string
 }
 
 func CheckUserMfa(user *model.User, token string) *model.AppError {
-	if !user.MfaActive || !utils.IsLicensed || !*utils.License.Features.MFA || !*utils.Cfg.ServiceSettings.EnableMultifactorAuthentication {
+	if !user.MfaActive || !*utils.Cfg.ServiceSettings.EnableMultifactorAuthentication {
 		return nil
 	}
 
-	mfaInterface := einterfaces.GetMfaInterface()
-	if mfaInterface == nil {
-		return model.NewLocAppError("checkUserMfa", "api.user.check_user_mfa.not_available.app_error", nil, "")
+	if model.IsMfaRecoveryCode(token) {
+		if ok, err := useMfaRecoveryCode(user.Id, token); err != nil {
+			return err
+		} else if !ok {
+			return model.NewLocAppError("checkUserMfa", "api.user.check_user_mfa.bad_code.app_error", nil, "")
+		}
+
+		return nil
 	}
 
-	if ok, err := mfaInterface.ValidateToken(user.MfaSecret, token); err != nil {
+	if ok, err := getMfaInterface().ValidateToken(user.MfaSecret, token); err != nil {
 		return err
 	} else if !ok {
 		return model.NewLocAppError("checkUserMfa", "api.user.check_user_mfa.bad_code.app_error", nil, "")
 	}
 
+	if err := markMfaTokenUsed(user.Id, token); err != nil {
+		return model.NewLocAppError("checkUserMfa", "api.user.check_user_mfa.bad_code.app_error", nil, err.Error())
+	}
+
 	return nil
 }
 
 func checkUserLoginAttempts(user *model.User) *model.AppError {
 	if user.FailedAttempts >= utils.Cfg.ServiceSettings.MaximumLoginAttempts {
+		if isUserLockoutExpired(user.Id) {
+			if result := <-Srv.Store.User().UpdateFailedPasswordAttempts(user.Id, 0); result.Err != nil {
+				return result.Err
+			}
+
+			user.FailedAttempts = 0
+			return nil
+		}
+
 		return model.NewAppError("checkUserLoginAttempts", "api.user.check_user_login_attempts.too_many.app_error", nil, "user_id="+user.Id, http.StatusForbidden)
 	}
 
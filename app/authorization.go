@@ -49,6 +49,10 @@ func SessionHasPermissionToChannel(session model.Session, channelId string, perm
 
 	channel, err := GetChannel(channelId)
 	if err == nil {
+		if sessionCanReadArchivedChannel(session, channel, permission) {
+			return true
+		}
+
 		return SessionHasPermissionToTeam(session, channel.TeamId, permission)
 	}
 
@@ -67,12 +71,22 @@ func SessionHasPermissionToChannelByPost(session model.Session, postId string, p
 
 	if result := <-Srv.Store.Channel().GetForPost(postId); result.Err == nil {
 		channel := result.Data.(*model.Channel)
+		if sessionCanReadArchivedChannel(session, channel, permission) {
+			return true
+		}
+
 		return SessionHasPermissionToTeam(session, channel.TeamId, permission)
 	}
 
 	return SessionHasPermissionTo(session, permission)
 }
 
+// Archived public channels can't be joined, so anyone who could have joined one can read it instead.
+func sessionCanReadArchivedChannel(session model.Session, channel *model.Channel, permission *model.Permission) bool {
+	return channel.DeleteAt != 0 && channel.Type == model.CHANNEL_OPEN && permission.Id == model.PERMISSION_READ_CHANNEL.Id &&
+		SessionHasPermissionToTeam(session, channel.TeamId, model.PERMISSION_READ_PUBLIC_CHANNEL)
+}
+
 func SessionHasPermissionToUser(session model.Session, userId string) bool {
 	if userId == "" {
 		return false
@@ -89,6 +103,47 @@ func SessionHasPermissionToUser(session model.Session, userId string) bool {
 	return false
 }
 
+// SessionCanSeeUsers checks that the session is allowed to look up the given
+// users. Sessions without the view_members permission, such as guests, can
+// only see the users they share a channel with.
+func SessionCanSeeUsers(session model.Session, userIds []string) bool {
+	return len(GetUserIdsVisibleToSession(session, userIds)) == len(userIds)
+}
+
+// GetUserIdsVisibleToSession returns the ids out of the given ones that the
+// session is allowed to look up. See SessionCanSeeUsers.
+func GetUserIdsVisibleToSession(session model.Session, userIds []string) []string {
+	if SessionHasPermissionTo(session, model.PERMISSION_VIEW_MEMBERS) {
+		return userIds
+	}
+
+	otherUserIds := []string{}
+	for _, userId := range userIds {
+		if userId != session.UserId {
+			otherUserIds = append(otherUserIds, userId)
+		}
+	}
+
+	visible := map[string]bool{session.UserId: true}
+
+	if len(otherUserIds) > 0 {
+		if result := <-Srv.Store.Channel().GetUsersSharingChannels(session.UserId, otherUserIds); result.Err == nil {
+			for _, userId := range result.Data.([]string) {
+				visible[userId] = true
+			}
+		}
+	}
+
+	visibleUserIds := []string{}
+	for _, userId := range userIds {
+		if visible[userId] {
+			visibleUserIds = append(visibleUserIds, userId)
+		}
+	}
+
+	return visibleUserIds
+}
+
 func SessionHasPermissionToPost(session model.Session, postId string, permission *model.Permission) bool {
 	post, err := GetSinglePost(postId)
 	if err != nil {
@@ -13,6 +13,7 @@ import (
 	"github.com/mattermost/platform/model"
 	"github.com/mattermost/platform/store"
 	"github.com/mattermost/platform/utils"
+	goi18n "github.com/nicksnyder/go-i18n/i18n"
 )
 
 func CreateDefaultChannels(teamId string) ([]*model.Channel, *model.AppError) {
@@ -172,6 +173,15 @@ func CreateDirectChannel(userId string, otherUserId string) (*model.Channel, *mo
 	}
 }
 
+// channelMemberRoles returns the roles a user gets when added to a channel.
+func channelMemberRoles(user *model.User) string {
+	if user.IsGuest() {
+		return model.ROLE_CHANNEL_GUEST.Id
+	}
+
+	return model.ROLE_CHANNEL_USER.Id
+}
+
 func WaitForChannelMembership(channelId string, userId string) {
 	if len(utils.Cfg.SqlSettings.DataSourceReplicas) > 0 {
 		now := model.GetMillis()
@@ -233,7 +243,7 @@ func CreateGroupChannel(userIds []string) (*model.Channel, *model.AppError) {
 				UserId:      user.Id,
 				ChannelId:   group.Id,
 				NotifyProps: model.GetDefaultChannelNotifyProps(),
-				Roles:       model.ROLE_CHANNEL_USER.Id,
+				Roles:       channelMemberRoles(user),
 			}
 
 			if result := <-Srv.Store.Channel().SaveMember(cm); result.Err != nil {
@@ -306,6 +316,21 @@ func UpdateChannelMemberNotifyProps(data map[string]string, channelId string, us
 		member.NotifyProps[model.PUSH_NOTIFY_PROP] = push
 	}
 
+	if mute, exists := data[model.MUTE_NOTIFY_PROP]; exists {
+		member.NotifyProps[model.MUTE_NOTIFY_PROP] = mute
+
+		// an expiry only applies to the mute it was set with
+		member.NotifyProps[model.MUTE_UNTIL_NOTIFY_PROP] = "0"
+	}
+
+	if muteUntil, exists := data[model.MUTE_UNTIL_NOTIFY_PROP]; exists {
+		member.NotifyProps[model.MUTE_UNTIL_NOTIFY_PROP] = muteUntil
+	}
+
+	if ignoreChannelMentions, exists := data[model.IGNORE_CHANNEL_MENTIONS_NOTIFY_PROP]; exists {
+		member.NotifyProps[model.IGNORE_CHANNEL_MENTIONS_NOTIFY_PROP] = ignoreChannelMentions
+	}
+
 	if result := <-Srv.Store.Channel().UpdateMember(member); result.Err != nil {
 		return nil, result.Err
 	} else {
@@ -373,7 +398,8 @@ func DeleteChannel(channel *model.Channel, userId string, siteURL string) *model
 			}
 		}
 
-		if dresult := <-Srv.Store.Channel().Delete(channel.Id, model.GetMillis()); dresult.Err != nil {
+		// The channel is archived at the same time as its webhooks so that they can be restored with it
+		if dresult := <-Srv.Store.Channel().Delete(channel.Id, now); dresult.Err != nil {
 			return dresult.Err
 		}
 		InvalidateCacheForChannel(channel)
@@ -387,9 +413,78 @@ func DeleteChannel(channel *model.Channel, userId string, siteURL string) *model
 	return nil
 }
 
+// CheckChannelIsNotArchived returns an error if the channel has been archived, since archived channels are read-only.
+func CheckChannelIsNotArchived(where string, channelId string) *model.AppError {
+	if channel, err := GetChannel(channelId); err != nil {
+		return err
+	} else if channel.DeleteAt != 0 {
+		return model.NewAppError(where, "api.channel.archived.app_error", nil, "channel_id="+channelId, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+// RestoreChannel unarchives a channel along with the webhooks that were removed when it was archived.
+func RestoreChannel(channel *model.Channel, userId string, siteURL string) (*model.Channel, *model.AppError) {
+	if channel.DeleteAt == 0 {
+		return nil, model.NewAppError("RestoreChannel", "api.channel.restore_channel.not_archived.app_error", nil, "id="+channel.Id, http.StatusBadRequest)
+	}
+
+	var user *model.User
+	if result := <-Srv.Store.User().Get(userId); result.Err != nil {
+		return nil, result.Err
+	} else {
+		user = result.Data.(*model.User)
+	}
+
+	deleteAt := channel.DeleteAt
+	now := model.GetMillis()
+
+	if result := <-Srv.Store.Channel().SetDeleteAt(channel.Id, 0, now); result.Err != nil {
+		return nil, result.Err
+	}
+	InvalidateCacheForChannel(channel)
+
+	if result := <-Srv.Store.Webhook().RestoreIncomingByChannel(channel.Id, deleteAt, now); result.Err != nil {
+		l4g.Error(utils.T("api.channel.restore_channel.incoming_webhooks.error"), channel.Id, result.Err)
+	}
+
+	if result := <-Srv.Store.Webhook().RestoreOutgoingByChannel(channel.Id, deleteAt, now); result.Err != nil {
+		l4g.Error(utils.T("api.channel.restore_channel.outgoing_webhooks.error"), channel.Id, result.Err)
+	}
+
+	restored := &model.Channel{}
+	*restored = *channel
+	restored.DeleteAt = 0
+	restored.UpdateAt = now
+
+	T := utils.GetUserTranslations(user.Locale)
+
+	post := &model.Post{
+		ChannelId: channel.Id,
+		Message:   fmt.Sprintf(T("api.channel.restore_channel.unarchived"), user.Username),
+		Type:      model.POST_CHANNEL_RESTORED,
+		UserId:    userId,
+		Props: model.StringInterface{
+			"username": user.Username,
+		},
+	}
+
+	if _, err := CreatePost(post, channel.TeamId, false, siteURL); err != nil {
+		l4g.Error(utils.T("api.channel.restore_channel.failed_post.error"), err)
+	}
+
+	message := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_CHANNEL_RESTORED, channel.TeamId, "", "", nil)
+	message.Add("channel_id", channel.Id)
+
+	Publish(message)
+
+	return restored, nil
+}
+
 func addUserToChannel(user *model.User, channel *model.Channel) (*model.ChannelMember, *model.AppError) {
 	if channel.DeleteAt > 0 {
-		return nil, model.NewLocAppError("AddUserToChannel", "api.channel.add_user_to_channel.deleted.app_error", nil, "")
+		return nil, model.NewAppError("AddUserToChannel", "api.channel.add_user_to_channel.deleted.app_error", nil, "", http.StatusBadRequest)
 	}
 
 	if channel.Type != model.CHANNEL_OPEN && channel.Type != model.CHANNEL_PRIVATE {
@@ -421,7 +516,7 @@ func addUserToChannel(user *model.User, channel *model.Channel) (*model.ChannelM
 		ChannelId:   channel.Id,
 		UserId:      user.Id,
 		NotifyProps: model.GetDefaultChannelNotifyProps(),
-		Roles:       model.ROLE_CHANNEL_USER.Id,
+		Roles:       channelMemberRoles(user),
 	}
 	if result := <-Srv.Store.Channel().SaveMember(newMember); result.Err != nil {
 		l4g.Error("Failed to add member user_id=%v channel_id=%v err=%v", user.Id, channel.Id, result.Err)
@@ -692,6 +787,16 @@ func GetPublicChannelsForTeam(teamId string, offset int, limit int) (*model.Chan
 	}
 }
 
+// GetArchivedChannelsForTeam returns the archived public channels on a team along with the archived private
+// channels that the user is a member of.
+func GetArchivedChannelsForTeam(teamId string, userId string, offset int, limit int) (*model.ChannelList, *model.AppError) {
+	if result := <-Srv.Store.Channel().GetDeleted(teamId, userId, offset, limit); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.ChannelList), nil
+	}
+}
+
 func GetChannelMember(channelId string, userId string) (*model.ChannelMember, *model.AppError) {
 	if result := <-Srv.Store.Channel().GetMember(channelId, userId); result.Err != nil {
 		return nil, result.Err
@@ -751,12 +856,17 @@ func GetChannelUnread(channelId, userId string) (*model.ChannelUnread, *model.Ap
 		channelUnread.MsgCount = 0
 	}
 
+	if model.IsChannelMuted(channelUnread.NotifyProps) {
+		channelUnread.MsgCount = 0
+		channelUnread.MentionCount = 0
+	}
+
 	return channelUnread, nil
 }
 
 func JoinChannel(channel *model.Channel, userId string, siteURL string) *model.AppError {
 	if channel.DeleteAt > 0 {
-		return model.NewLocAppError("JoinChannel", "api.channel.join_channel.already_deleted.app_error", nil, "")
+		return model.NewAppError("JoinChannel", "api.channel.join_channel.already_deleted.app_error", nil, "", http.StatusBadRequest)
 	}
 
 	userChan := Srv.Store.User().Get(userId)
@@ -1035,6 +1145,10 @@ func ViewChannel(view *model.ChannelView, userId string, clearPushNotifications
 		return result.Err
 	}
 
+	for _, channelId := range channelIds {
+		go sendReadReceiptEvent(channelId, userId)
+	}
+
 	return nil
 }
 
@@ -1061,3 +1175,279 @@ func GetPinnedPosts(channelId string) (*model.PostList, *model.AppError) {
 		return result.Data.(*model.PostList), nil
 	}
 }
+
+// MoveChannel moves a channel to another team. If any members of the channel aren't on the new team, the channel
+// is only moved when removeNonTeamMembers is set, in which case they're removed from the channel.
+func MoveChannel(channel *model.Channel, team *model.Team, userId string, removeNonTeamMembers bool, siteURL string) (*model.Channel, *model.AppError) {
+	if channel.Type != model.CHANNEL_OPEN && channel.Type != model.CHANNEL_PRIVATE {
+		return nil, model.NewAppError("MoveChannel", "api.channel.move_channel.type.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if channel.Name == model.DEFAULT_CHANNEL {
+		return nil, model.NewAppError("MoveChannel", "api.channel.move_channel.default.app_error", map[string]interface{}{"Channel": model.DEFAULT_CHANNEL}, "", http.StatusBadRequest)
+	}
+
+	if channel.DeleteAt != 0 {
+		return nil, model.NewAppError("MoveChannel", "api.channel.archived.app_error", nil, "channel_id="+channel.Id, http.StatusBadRequest)
+	}
+
+	if channel.TeamId == team.Id {
+		return nil, model.NewAppError("MoveChannel", "api.channel.move_channel.same_team.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	var oldTeam *model.Team
+	if result := <-Srv.Store.Team().Get(channel.TeamId); result.Err != nil {
+		return nil, result.Err
+	} else {
+		oldTeam = result.Data.(*model.Team)
+	}
+
+	var memberIds []string
+	if result := <-Srv.Store.Channel().GetAllChannelMembersNotifyPropsForChannel(channel.Id, false); result.Err != nil {
+		return nil, result.Err
+	} else {
+		for memberId := range result.Data.(map[string]model.StringMap) {
+			memberIds = append(memberIds, memberId)
+		}
+	}
+
+	onTeam := map[string]bool{}
+	if len(memberIds) > 0 {
+		if result := <-Srv.Store.Team().GetMembersByIds(team.Id, memberIds); result.Err != nil {
+			return nil, result.Err
+		} else {
+			for _, teamMember := range result.Data.([]*model.TeamMember) {
+				onTeam[teamMember.UserId] = true
+			}
+		}
+	}
+
+	var nonTeamMemberIds []string
+	for _, memberId := range memberIds {
+		if !onTeam[memberId] {
+			nonTeamMemberIds = append(nonTeamMemberIds, memberId)
+		}
+	}
+
+	if len(nonTeamMemberIds) > 0 && !removeNonTeamMembers {
+		return nil, model.NewAppError("MoveChannel", "api.channel.move_channel.members_not_on_team.app_error", map[string]interface{}{"Count": len(nonTeamMemberIds)}, "user_ids="+strings.Join(nonTeamMemberIds, ","), http.StatusBadRequest)
+	}
+
+	moved := &model.Channel{}
+	*moved = *channel
+	moved.TeamId = team.Id
+
+	if result := <-Srv.Store.Channel().Update(moved); result.Err != nil {
+		if result.Err.Id == "store.sql_channel.update.exists.app_error" || result.Err.Id == "store.sql_channel.update.previously.app_error" {
+			result.Err.StatusCode = http.StatusBadRequest
+		}
+		return nil, result.Err
+	}
+
+	InvalidateCacheForChannel(channel)
+	InvalidateCacheForChannel(moved)
+
+	for _, memberId := range nonTeamMemberIds {
+		if err := removeUserFromChannel(memberId, userId, moved); err != nil {
+			l4g.Error(utils.T("api.channel.move_channel.remove_member.error"), memberId, moved.Id, err)
+		}
+	}
+
+	// Webhooks belong to the team of their channel. Slash commands are registered per team, so the channel picks
+	// up the commands of its new team without needing any changes.
+	if result := <-Srv.Store.Webhook().GetIncomingByChannel(channel.Id); result.Err != nil {
+		l4g.Error(utils.T("api.channel.move_channel.incoming_webhooks.error"), channel.Id, result.Err)
+	} else {
+		for _, hook := range result.Data.([]*model.IncomingWebhook) {
+			hook.TeamId = team.Id
+			if result := <-Srv.Store.Webhook().UpdateIncoming(hook); result.Err != nil {
+				l4g.Error(utils.T("api.channel.move_channel.incoming_webhooks.error"), channel.Id, result.Err)
+			}
+			InvalidateCacheForWebhook(hook.Id)
+		}
+	}
+
+	if result := <-Srv.Store.Webhook().GetOutgoingByChannel(channel.Id, -1, -1); result.Err != nil {
+		l4g.Error(utils.T("api.channel.move_channel.outgoing_webhooks.error"), channel.Id, result.Err)
+	} else {
+		for _, hook := range result.Data.([]*model.OutgoingWebhook) {
+			hook.TeamId = team.Id
+			if result := <-Srv.Store.Webhook().UpdateOutgoing(hook); result.Err != nil {
+				l4g.Error(utils.T("api.channel.move_channel.outgoing_webhooks.error"), channel.Id, result.Err)
+			}
+		}
+	}
+
+	if len(userId) > 0 {
+		if user, err := GetUser(userId); err != nil {
+			l4g.Error(utils.T("api.channel.move_channel.post.error"), err)
+		} else {
+			T := utils.GetUserTranslations(user.Locale)
+
+			post := &model.Post{
+				ChannelId: moved.Id,
+				Message:   fmt.Sprintf(T("api.channel.move_channel.moved"), user.Username, oldTeam.DisplayName, team.DisplayName),
+				Type:      model.POST_CHANNEL_MOVED,
+				UserId:    userId,
+				Props: model.StringInterface{
+					"username":    user.Username,
+					"old_team_id": oldTeam.Id,
+					"new_team_id": team.Id,
+				},
+			}
+
+			if _, err := CreatePost(post, team.Id, false, siteURL); err != nil {
+				l4g.Error(utils.T("api.channel.move_channel.post.error"), err)
+			}
+		}
+	}
+
+	publishChannelUpdated(moved)
+
+	return moved, nil
+}
+
+// ConvertChannel makes a public channel private or a private channel public.
+func ConvertChannel(channel *model.Channel, channelType string, userId string, siteURL string) (*model.Channel, *model.AppError) {
+	if channel.Type != model.CHANNEL_OPEN && channel.Type != model.CHANNEL_PRIVATE {
+		return nil, model.NewAppError("ConvertChannel", "api.channel.convert_channel.type.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if channelType != model.CHANNEL_OPEN && channelType != model.CHANNEL_PRIVATE {
+		return nil, model.NewAppError("ConvertChannel", "api.channel.convert_channel.invalid_type.app_error", nil, "type="+channelType, http.StatusBadRequest)
+	}
+
+	if channel.Type == channelType {
+		return nil, model.NewAppError("ConvertChannel", "api.channel.convert_channel.same_type.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if channel.Name == model.DEFAULT_CHANNEL {
+		return nil, model.NewAppError("ConvertChannel", "api.channel.convert_channel.default.app_error", map[string]interface{}{"Channel": model.DEFAULT_CHANNEL}, "", http.StatusBadRequest)
+	}
+
+	if channel.DeleteAt != 0 {
+		return nil, model.NewAppError("ConvertChannel", "api.channel.archived.app_error", nil, "channel_id="+channel.Id, http.StatusBadRequest)
+	}
+
+	converted := &model.Channel{}
+	*converted = *channel
+	converted.Type = channelType
+
+	if result := <-Srv.Store.Channel().Update(converted); result.Err != nil {
+		return nil, result.Err
+	}
+
+	InvalidateCacheForChannel(converted)
+
+	if len(userId) > 0 {
+		if user, err := GetUser(userId); err != nil {
+			l4g.Error(utils.T("api.channel.convert_channel.post.error"), err)
+		} else {
+			T := utils.GetUserTranslations(user.Locale)
+
+			message := T("api.channel.convert_channel.to_private")
+			if channelType == model.CHANNEL_OPEN {
+				message = T("api.channel.convert_channel.to_public")
+			}
+
+			post := &model.Post{
+				ChannelId: converted.Id,
+				Message:   fmt.Sprintf(message, user.Username),
+				Type:      model.POST_CHANNEL_TYPE_CHANGE,
+				UserId:    userId,
+				Props: model.StringInterface{
+					"username": user.Username,
+					"old_type": channel.Type,
+					"new_type": channelType,
+				},
+			}
+
+			if _, err := CreatePost(post, converted.TeamId, false, siteURL); err != nil {
+				l4g.Error(utils.T("api.channel.convert_channel.post.error"), err)
+			}
+		}
+	}
+
+	publishChannelUpdated(converted)
+
+	return converted, nil
+}
+
+func publishChannelUpdated(channel *model.Channel) {
+	message := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_CHANNEL_UPDATED, "", channel.Id, "", nil)
+	message.Add("channel", channel.ToJson())
+	Publish(message)
+}
+
+// UserCanPostInChannel returns whether a user is allowed to post, reply and react in a channel. When the channel
+// is restricted to a role, team admins and system admins are always allowed to do so as well.
+func UserCanPostInChannel(userId string, channel *model.Channel) bool {
+	if channel.PostRole == "" {
+		return true
+	}
+
+	var roles []string
+
+	if user, err := GetUser(userId); err == nil {
+		roles = append(roles, user.GetRoles()...)
+	}
+
+	if channel.TeamId != "" {
+		if teamMember, err := GetTeamMember(channel.TeamId, userId); err == nil {
+			roles = append(roles, teamMember.GetRoles()...)
+		}
+	}
+
+	if channelMember, err := GetChannelMember(channel.Id, userId); err == nil {
+		roles = append(roles, channelMember.GetRoles()...)
+	}
+
+	for _, role := range roles {
+		if role == channel.PostRole || role == model.ROLE_SYSTEM_ADMIN.Id {
+			return true
+		}
+
+		if role == model.ROLE_TEAM_ADMIN.Id && channel.PostRole != model.ROLE_SYSTEM_ADMIN.Id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CheckUserCanPostInChannel returns an error if the user isn't allowed to post, reply or react in the channel,
+// and optionally sends them an ephemeral message explaining who is.
+func CheckUserCanPostInChannel(where string, userId string, channel *model.Channel, sendEphemeral bool) *model.AppError {
+	if UserCanPostInChannel(userId, channel) {
+		return nil
+	}
+
+	if sendEphemeral {
+		locale := model.DEFAULT_LOCALE
+		if user, err := GetUser(userId); err == nil {
+			locale = user.Locale
+		}
+
+		T := utils.GetUserTranslations(locale)
+
+		SendEphemeralPost(
+			channel.TeamId,
+			userId,
+			&model.Post{
+				ChannelId: channel.Id,
+				Message:   T("api.channel.post_role.message", map[string]interface{}{"Role": channelPostRoleDisplayName(T, channel.PostRole)}),
+			},
+		)
+	}
+
+	return model.NewAppError(where, "api.channel.post_role.app_error", map[string]interface{}{"Role": channel.PostRole}, "channel_id="+channel.Id+", user_id="+userId, http.StatusForbidden)
+}
+
+func channelPostRoleDisplayName(T goi18n.TranslateFunc, role string) string {
+	switch role {
+	case model.ROLE_CHANNEL_ADMIN.Id, model.ROLE_TEAM_ADMIN.Id, model.ROLE_SYSTEM_ADMIN.Id:
+		return T("api.channel.post_role." + role)
+	default:
+		return T("api.channel.post_role.other", map[string]interface{}{"Role": role})
+	}
+}
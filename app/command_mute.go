@@ -0,0 +1,99 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mattermost/platform/model"
+	goi18n "github.com/nicksnyder/go-i18n/i18n"
+)
+
+type MuteProvider struct {
+}
+
+const (
+	CMD_MUTE = "mute"
+)
+
+func init() {
+	RegisterCommandProvider(&MuteProvider{})
+}
+
+func (me *MuteProvider) GetTrigger() string {
+	return CMD_MUTE
+}
+
+func (me *MuteProvider) GetCommand(T goi18n.TranslateFunc) *model.Command {
+	return &model.Command{
+		Trigger:          CMD_MUTE,
+		AutoComplete:     true,
+		AutoCompleteDesc: T("api.command_mute.desc"),
+		AutoCompleteHint: T("api.command_mute.hint"),
+		DisplayName:      T("api.command_mute.name"),
+	}
+}
+
+func (me *MuteProvider) DoCommand(args *model.CommandArgs, message string) *model.CommandResponse {
+	channelId := args.ChannelId
+	var duration time.Duration
+	durationText := ""
+
+	for _, arg := range strings.Fields(message) {
+		if d, err := time.ParseDuration(arg); err == nil {
+			if d <= 0 {
+				return &model.CommandResponse{ResponseType: model.COMMAND_RESPONSE_TYPE_EPHEMERAL, Text: args.T("api.command_mute.duration.app_error", map[string]interface{}{"Duration": arg})}
+			}
+
+			duration = d
+			durationText = arg
+			continue
+		}
+
+		channelName := strings.TrimPrefix(arg, "~")
+		if channel, err := GetChannelByName(channelName, args.TeamId); err != nil {
+			return &model.CommandResponse{ResponseType: model.COMMAND_RESPONSE_TYPE_EPHEMERAL, Text: args.T("api.command_mute.no_channel.app_error", map[string]interface{}{"Channel": channelName})}
+		} else {
+			channelId = channel.Id
+		}
+	}
+
+	channel, err := GetChannel(channelId)
+	if err != nil {
+		return &model.CommandResponse{ResponseType: model.COMMAND_RESPONSE_TYPE_EPHEMERAL, Text: args.T("api.command_mute.error")}
+	}
+
+	member, err := GetChannelMember(channel.Id, args.UserId)
+	if err != nil {
+		return &model.CommandResponse{ResponseType: model.COMMAND_RESPONSE_TYPE_EPHEMERAL, Text: args.T("api.command_mute.not_member.app_error", map[string]interface{}{"Channel": channel.DisplayName})}
+	}
+
+	// Without a duration the command toggles the mute, otherwise it always mutes for that long
+	props := map[string]string{}
+	if duration == 0 && member.IsMuted() {
+		props[model.MUTE_NOTIFY_PROP] = "false"
+	} else {
+		props[model.MUTE_NOTIFY_PROP] = "true"
+		if duration > 0 {
+			props[model.MUTE_UNTIL_NOTIFY_PROP] = strconv.FormatInt(model.GetMillis()+int64(duration/time.Millisecond), 10)
+		}
+	}
+
+	if _, err := UpdateChannelMemberNotifyProps(props, channel.Id, args.UserId); err != nil {
+		return &model.CommandResponse{ResponseType: model.COMMAND_RESPONSE_TYPE_EPHEMERAL, Text: args.T("api.command_mute.error")}
+	}
+
+	var text string
+	if props[model.MUTE_NOTIFY_PROP] == "false" {
+		text = args.T("api.command_mute.unmuted", map[string]interface{}{"Channel": channel.DisplayName})
+	} else if duration > 0 {
+		text = args.T("api.command_mute.muted_for", map[string]interface{}{"Channel": channel.DisplayName, "Duration": durationText})
+	} else {
+		text = args.T("api.command_mute.muted", map[string]interface{}{"Channel": channel.DisplayName})
+	}
+
+	return &model.CommandResponse{ResponseType: model.COMMAND_RESPONSE_TYPE_EPHEMERAL, Text: text}
+}
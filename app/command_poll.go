@@ -0,0 +1,141 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/mattermost/platform/model"
+	goi18n "github.com/nicksnyder/go-i18n/i18n"
+)
+
+type PollProvider struct {
+}
+
+const (
+	CMD_POLL = "poll"
+)
+
+func init() {
+	RegisterCommandProvider(&PollProvider{})
+}
+
+func (me *PollProvider) GetTrigger() string {
+	return CMD_POLL
+}
+
+func (me *PollProvider) GetCommand(T goi18n.TranslateFunc) *model.Command {
+	return &model.Command{
+		Trigger:          CMD_POLL,
+		AutoComplete:     true,
+		AutoCompleteDesc: T("api.command_poll.desc"),
+		AutoCompleteHint: T("api.command_poll.hint"),
+		DisplayName:      T("api.command_poll.name"),
+	}
+}
+
+func (me *PollProvider) DoCommand(args *model.CommandArgs, message string) *model.CommandResponse {
+	user, err := GetUser(args.UserId)
+	if err != nil {
+		return &model.CommandResponse{ResponseType: model.COMMAND_RESPONSE_TYPE_EPHEMERAL, Text: args.T("api.command_poll.error", map[string]interface{}{"Error": err.Error()})}
+	}
+
+	question, poll, ok := parsePollCommand(message, time.Now().In(user.GetTimezoneLocation()))
+	if !ok {
+		return &model.CommandResponse{ResponseType: model.COMMAND_RESPONSE_TYPE_EPHEMERAL, Text: args.T("api.command_poll.usage.app_error")}
+	}
+
+	post := &model.Post{
+		ChannelId: args.ChannelId,
+		RootId:    args.RootId,
+		ParentId:  args.ParentId,
+		UserId:    args.UserId,
+		Message:   question,
+		Type:      model.POST_POLL,
+	}
+	post.AddProp(model.POST_PROPS_POLL, poll)
+
+	if _, err := CreatePost(post, args.TeamId, true, args.SiteURL); err != nil {
+		err.Translate(args.T)
+		return &model.CommandResponse{ResponseType: model.COMMAND_RESPONSE_TYPE_EPHEMERAL, Text: args.T("api.command_poll.error", map[string]interface{}{"Error": err.Message})}
+	}
+
+	return &model.CommandResponse{}
+}
+
+// Reads a poll written as a question followed by its options, quoting any of them that are more than one word, along
+// with the --multiple, --anonymous and --close <time> flags.
+func parsePollCommand(message string, now time.Time) (string, *model.Poll, bool) {
+	poll := &model.Poll{}
+
+	var values []string
+	for words := splitPollArguments(message); len(words) > 0; {
+		switch strings.ToLower(words[0]) {
+		case "--multiple":
+			poll.MultipleChoice = true
+			words = words[1:]
+		case "--anonymous":
+			poll.Anonymous = true
+			words = words[1:]
+		case "--close":
+			closeAt, rest, ok := parseScheduleTime(words[1:], now)
+			if !ok {
+				return "", nil, false
+			}
+
+			poll.CloseAt = closeAt.UnixNano() / int64(time.Millisecond)
+			words = rest
+		default:
+			values = append(values, words[0])
+			words = words[1:]
+		}
+	}
+
+	if len(values) < 1+model.POLL_OPTIONS_MIN {
+		return "", nil, false
+	}
+
+	for _, text := range values[1:] {
+		poll.Options = append(poll.Options, &model.PollOption{Text: text})
+	}
+
+	return values[0], poll, true
+}
+
+// Splits the arguments of a command into words, keeping text in double quotes together.
+func splitPollArguments(message string) []string {
+	var words []string
+	var word []rune
+	quoted := false
+	inWord := false
+
+	for _, r := range message {
+		switch {
+		case r == '"' || r == '“' || r == '”':
+			if quoted || inWord {
+				words = append(words, string(word))
+				word = nil
+				inWord = false
+			}
+			quoted = !quoted
+		case unicode.IsSpace(r) && !quoted:
+			if inWord {
+				words = append(words, string(word))
+				word = nil
+				inWord = false
+			}
+		default:
+			word = append(word, r)
+			inWord = true
+		}
+	}
+
+	if inWord || quoted {
+		words = append(words, string(word))
+	}
+
+	return words
+}
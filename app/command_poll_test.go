@@ -0,0 +1,59 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParsePollCommand(t *testing.T) {
+	now := time.Date(2017, time.July, 10, 14, 30, 0, 0, time.UTC)
+
+	question, poll, ok := parsePollCommand(`"Where should we eat?" Pizza "Thai food" --multiple --anonymous --close in 2 hours`, now)
+	if !ok {
+		t.Fatal("should have parsed the poll")
+	} else if question != "Where should we eat?" {
+		t.Fatal("parsed the wrong question", question)
+	} else if len(poll.Options) != 2 || poll.Options[0].Text != "Pizza" || poll.Options[1].Text != "Thai food" {
+		t.Fatal("parsed the wrong options")
+	} else if !poll.MultipleChoice || !poll.Anonymous {
+		t.Fatal("should have read the flags")
+	} else if poll.CloseAt != now.Add(2*time.Hour).UnixNano()/int64(time.Millisecond) {
+		t.Fatal("parsed the wrong close time")
+	}
+
+	if _, poll, ok := parsePollCommand(`Lunch? “Yes” “No”`, now); !ok {
+		t.Fatal("should have parsed the poll")
+	} else if poll.MultipleChoice || poll.Anonymous || poll.CloseAt != 0 {
+		t.Fatal("shouldn't have set any flags")
+	}
+
+	for _, invalid := range []string{
+		"",
+		`"Lunch?"`,
+		`"Lunch?" "Yes"`,
+		`"Lunch?" "Yes" "No" --close whenever`,
+	} {
+		if _, _, ok := parsePollCommand(invalid, now); ok {
+			t.Fatalf("%v: shouldn't have parsed the poll", invalid)
+		}
+	}
+}
+
+func TestSplitPollArguments(t *testing.T) {
+	for input, expected := range map[string]string{
+		`a b  c`:              "a|b|c",
+		`"a b" c`:             "a b|c",
+		`a"b c"d`:             "a|b c|d",
+		`"" a`:                "|a",
+		`"unterminated quote`: "unterminated quote",
+		"  leading\tspaces  ": "leading|spaces",
+	} {
+		if actual := strings.Join(splitPollArguments(input), "|"); actual != expected {
+			t.Fatalf("%v: expected %q, got %q", input, expected, actual)
+		}
+	}
+}
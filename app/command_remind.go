@@ -0,0 +1,87 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mattermost/platform/model"
+	goi18n "github.com/nicksnyder/go-i18n/i18n"
+)
+
+type RemindProvider struct {
+}
+
+const (
+	CMD_REMIND = "remind"
+)
+
+var remindPostRegex = regexp.MustCompile(`^(?:\S*/pl/)?([a-z0-9]{26})/?$`)
+
+func init() {
+	RegisterCommandProvider(&RemindProvider{})
+}
+
+func (me *RemindProvider) GetTrigger() string {
+	return CMD_REMIND
+}
+
+func (me *RemindProvider) GetCommand(T goi18n.TranslateFunc) *model.Command {
+	return &model.Command{
+		Trigger:          CMD_REMIND,
+		AutoComplete:     true,
+		AutoCompleteDesc: T("api.command_remind.desc"),
+		AutoCompleteHint: T("api.command_remind.hint"),
+		DisplayName:      T("api.command_remind.name"),
+	}
+}
+
+func (me *RemindProvider) DoCommand(args *model.CommandArgs, message string) *model.CommandResponse {
+	user, err := GetUser(args.UserId)
+	if err != nil {
+		return &model.CommandResponse{ResponseType: model.COMMAND_RESPONSE_TYPE_EPHEMERAL, Text: args.T("api.command_remind.error", map[string]interface{}{"Error": err.Error()})}
+	}
+
+	words := strings.Fields(message)
+	if len(words) > 0 && strings.ToLower(words[0]) == "me" {
+		words = words[1:]
+	}
+
+	// without a post, the command reminds the user about the thread it's used in
+	postId := args.RootId
+	if len(words) > 0 {
+		if match := remindPostRegex.FindStringSubmatch(words[0]); match != nil {
+			postId = match[1]
+			words = words[1:]
+		}
+	}
+
+	if postId == "" {
+		return &model.CommandResponse{ResponseType: model.COMMAND_RESPONSE_TYPE_EPHEMERAL, Text: args.T("api.command_remind.post.app_error")}
+	}
+
+	when, rest, ok := parseScheduleTime(words, time.Now().In(user.GetTimezoneLocation()))
+	if !ok || len(rest) > 0 {
+		return &model.CommandResponse{ResponseType: model.COMMAND_RESPONSE_TYPE_EPHEMERAL, Text: args.T("api.command_remind.time.app_error")}
+	}
+
+	if !HasPermissionToChannelByPost(args.UserId, postId, model.PERMISSION_READ_CHANNEL) {
+		return &model.CommandResponse{ResponseType: model.COMMAND_RESPONSE_TYPE_EPHEMERAL, Text: args.T("api.command_remind.post.app_error")}
+	}
+
+	reminder := &model.PostReminder{
+		UserId:   args.UserId,
+		PostId:   postId,
+		RemindAt: when.UnixNano() / int64(time.Millisecond),
+	}
+
+	if _, err := CreatePostReminder(reminder); err != nil {
+		err.Translate(args.T)
+		return &model.CommandResponse{ResponseType: model.COMMAND_RESPONSE_TYPE_EPHEMERAL, Text: args.T("api.command_remind.error", map[string]interface{}{"Error": err.Message})}
+	}
+
+	return &model.CommandResponse{ResponseType: model.COMMAND_RESPONSE_TYPE_EPHEMERAL, Text: args.T("api.command_remind.success", map[string]interface{}{"Time": formatScheduleTime(when)})}
+}
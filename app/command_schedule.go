@@ -0,0 +1,94 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/mattermost/platform/model"
+	goi18n "github.com/nicksnyder/go-i18n/i18n"
+)
+
+type ScheduleProvider struct {
+}
+
+const (
+	CMD_SCHEDULE = "schedule"
+)
+
+func init() {
+	RegisterCommandProvider(&ScheduleProvider{})
+}
+
+func (me *ScheduleProvider) GetTrigger() string {
+	return CMD_SCHEDULE
+}
+
+func (me *ScheduleProvider) GetCommand(T goi18n.TranslateFunc) *model.Command {
+	return &model.Command{
+		Trigger:          CMD_SCHEDULE,
+		AutoComplete:     true,
+		AutoCompleteDesc: T("api.command_schedule.desc"),
+		AutoCompleteHint: T("api.command_schedule.hint"),
+		DisplayName:      T("api.command_schedule.name"),
+	}
+}
+
+func (me *ScheduleProvider) DoCommand(args *model.CommandArgs, message string) *model.CommandResponse {
+	user, err := GetUser(args.UserId)
+	if err != nil {
+		return &model.CommandResponse{ResponseType: model.COMMAND_RESPONSE_TYPE_EPHEMERAL, Text: args.T("api.command_schedule.error", map[string]interface{}{"Error": err.Error()})}
+	}
+
+	words := strings.Fields(message)
+	when, rest, ok := parseScheduleTime(words, time.Now().In(user.GetTimezoneLocation()))
+	if !ok {
+		return &model.CommandResponse{ResponseType: model.COMMAND_RESPONSE_TYPE_EPHEMERAL, Text: args.T("api.command_schedule.time.app_error")}
+	}
+
+	text := trimLeadingWords(message, len(words)-len(rest))
+	if text == "" {
+		return &model.CommandResponse{ResponseType: model.COMMAND_RESPONSE_TYPE_EPHEMERAL, Text: args.T("api.command_schedule.message.app_error")}
+	}
+
+	if !HasPermissionToChannel(args.UserId, args.ChannelId, model.PERMISSION_CREATE_POST) {
+		return &model.CommandResponse{ResponseType: model.COMMAND_RESPONSE_TYPE_EPHEMERAL, Text: args.T("api.command_schedule.permissions.app_error")}
+	}
+
+	scheduledPost := &model.ScheduledPost{
+		UserId:      args.UserId,
+		ChannelId:   args.ChannelId,
+		RootId:      args.RootId,
+		Message:     text,
+		ScheduledAt: when.UnixNano() / int64(time.Millisecond),
+	}
+
+	if _, err := CreateScheduledPost(scheduledPost); err != nil {
+		err.Translate(args.T)
+		return &model.CommandResponse{ResponseType: model.COMMAND_RESPONSE_TYPE_EPHEMERAL, Text: args.T("api.command_schedule.error", map[string]interface{}{"Error": err.Message})}
+	}
+
+	return &model.CommandResponse{ResponseType: model.COMMAND_RESPONSE_TYPE_EPHEMERAL, Text: args.T("api.command_schedule.success", map[string]interface{}{"Time": formatScheduleTime(when)})}
+}
+
+// Returns what's left of a message after removing the given number of words from the start of it, keeping the
+// formatting of the rest of it.
+func trimLeadingWords(message string, count int) string {
+	rest := strings.TrimLeftFunc(message, unicode.IsSpace)
+	for i := 0; i < count; i++ {
+		if end := strings.IndexFunc(rest, unicode.IsSpace); end == -1 {
+			rest = ""
+		} else {
+			rest = strings.TrimLeftFunc(rest[end:], unicode.IsSpace)
+		}
+	}
+
+	return strings.TrimRightFunc(rest, unicode.IsSpace)
+}
+
+func formatScheduleTime(t time.Time) string {
+	return t.Format("Monday, January 2 at 3:04 PM MST")
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"html/template"
 	"net/url"
+	"strings"
 
 	l4g "github.com/alecthomas/log4go"
 	"github.com/mattermost/platform/model"
@@ -227,7 +228,70 @@ func SendMfaChangeEmail(email string, activated bool, locale, siteURL string) *m
 	return nil
 }
 
+func SendSessionsRevokedEmail(email, reason, locale, siteURL string) *model.AppError {
+	T := utils.GetUserTranslations(locale)
+
+	subject := T("api.templates.sessions_revoked_subject",
+		map[string]interface{}{"SiteName": utils.ClientCfg["SiteName"]})
+
+	bodyPage := utils.NewHTMLTemplate("sessions_revoked_body", locale)
+	bodyPage.Props["SiteURL"] = siteURL
+	bodyPage.Props["Title"] = T("api.templates.sessions_revoked_body.title")
+
+	if reason == "" {
+		bodyPage.Html["Info"] = template.HTML(T("api.templates.sessions_revoked_body.info",
+			map[string]interface{}{"SiteURL": siteURL}))
+	} else {
+		bodyPage.Html["Info"] = template.HTML(T("api.templates.sessions_revoked_body.info_reason",
+			map[string]interface{}{"SiteURL": siteURL, "Reason": template.HTMLEscapeString(reason)}))
+	}
+
+	if err := utils.SendMail(email, subject, bodyPage.Render()); err != nil {
+		return model.NewLocAppError("SendSessionsRevokedEmail", "api.user.send_sessions_revoked_email.error", nil, err.Error())
+	}
+
+	return nil
+}
+
+func SendLoginFailuresEmail(email, ipAddress string, attempts int, locale, siteURL string) *model.AppError {
+	T := utils.GetUserTranslations(locale)
+
+	subject := T("api.templates.login_failures_subject",
+		map[string]interface{}{"SiteName": utils.ClientCfg["SiteName"]})
+
+	bodyPage := utils.NewHTMLTemplate("login_failures_body", locale)
+	bodyPage.Props["SiteURL"] = siteURL
+	bodyPage.Props["Title"] = T("api.templates.login_failures_body.title")
+	bodyPage.Html["Info"] = template.HTML(T("api.templates.login_failures_body.info",
+		map[string]interface{}{"SiteURL": siteURL, "Attempts": attempts, "IpAddress": template.HTMLEscapeString(ipAddress)}))
+
+	if err := utils.SendMail(email, subject, bodyPage.Render()); err != nil {
+		return model.NewLocAppError("SendLoginFailuresEmail", "api.user.send_login_failures_email.error", nil, err.Error())
+	}
+
+	return nil
+}
+
 func SendInviteEmails(team *model.Team, senderName string, invites []string, siteURL string) {
+	sendInviteEmails(team, senderName, invites, nil, siteURL)
+}
+
+// SendGuestInviteEmails sends invitations to join the team as a guest who
+// is added to the given channels. The links stop working at expiresAt.
+func SendGuestInviteEmails(team *model.Team, channels []*model.Channel, senderName string, invites []string, expiresAt int64, siteURL string) {
+	channelIds := make([]string, len(channels))
+	for i, channel := range channels {
+		channelIds[i] = channel.Id
+	}
+
+	sendInviteEmails(team, senderName, invites, map[string]string{
+		"guest":      "true",
+		"channels":   strings.Join(channelIds, " "),
+		"expires_at": fmt.Sprintf("%v", expiresAt),
+	}, siteURL)
+}
+
+func sendInviteEmails(team *model.Team, senderName string, invites []string, extraProps map[string]string, siteURL string) {
 	for _, invite := range invites {
 		if len(invite) > 0 {
 			senderRole := utils.T("api.team.invite_members.member")
@@ -252,6 +316,9 @@ func SendInviteEmails(team *model.Team, senderName string, invites []string, sit
 			props["display_name"] = team.DisplayName
 			props["name"] = team.Name
 			props["time"] = fmt.Sprintf("%v", model.GetMillis())
+			for key, value := range extraProps {
+				props[key] = value
+			}
 			data := model.MapToJson(props)
 			hash := model.HashPassword(fmt.Sprintf("%v:%v", data, utils.Cfg.EmailSettings.InviteSalt))
 			bodyPage.Props["Link"] = fmt.Sprintf("%s/signup_user_complete/?d=%s&h=%s", siteURL, url.QueryEscape(data), url.QueryEscape(hash))
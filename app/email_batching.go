@@ -186,14 +186,16 @@ func sendBatchedEmailNotification(userId string, notifications []*batchedNotific
 		displayNameFormat = result.Data.(model.Preference).Value
 	}
 
+	location := user.GetTimezoneLocation()
+
 	var contents string
 	for _, notification := range notifications {
 		template := utils.NewHTMLTemplate("post_batched_post", user.Locale)
 
-		contents += renderBatchedPost(template, notification.post, notification.teamName, displayNameFormat, translateFunc)
+		contents += renderBatchedPost(template, notification.post, notification.teamName, displayNameFormat, location, translateFunc)
 	}
 
-	tm := time.Unix(notifications[0].post.CreateAt/1000, 0)
+	tm := time.Unix(notifications[0].post.CreateAt/1000, 0).In(location)
 
 	subject := translateFunc("api.email_batching.send_batched_email_notification.subject", len(notifications), map[string]interface{}{
 		"SiteName": utils.Cfg.TeamSettings.SiteName,
@@ -212,7 +214,7 @@ func sendBatchedEmailNotification(userId string, notifications []*batchedNotific
 	}
 }
 
-func renderBatchedPost(template *utils.HTMLTemplate, post *model.Post, teamName string, displayNameFormat string, translateFunc i18n.TranslateFunc) string {
+func renderBatchedPost(template *utils.HTMLTemplate, post *model.Post, teamName string, displayNameFormat string, location *time.Location, translateFunc i18n.TranslateFunc) string {
 	schan := Srv.Store.User().Get(post.UserId)
 	cchan := Srv.Store.Channel().Get(post.ChannelId, true)
 
@@ -220,7 +222,7 @@ func renderBatchedPost(template *utils.HTMLTemplate, post *model.Post, teamName
 	template.Props["PostMessage"] = GetMessageForNotification(post, translateFunc)
 	template.Props["PostLink"] = *utils.Cfg.ServiceSettings.SiteURL + "/" + teamName + "/pl/" + post.Id
 
-	tm := time.Unix(post.CreateAt/1000, 0)
+	tm := time.Unix(post.CreateAt/1000, 0).In(location)
 	timezone, _ := tm.Zone()
 
 	template.Props["Date"] = translateFunc("api.email_batching.render_batched_post.date", map[string]interface{}{
@@ -0,0 +1,353 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"html"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/http"
+	"net/mail"
+	"regexp"
+	"strings"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+const (
+	EMAIL_REPLY_SIGNATURE_LENGTH = 20
+	EMAIL_REPLY_MAX_ATTACHMENTS  = 5
+)
+
+var (
+	emailReplyQuoteHeaderRegexes = []*regexp.Regexp{
+		// Gmail and most other clients, which may wrap the attribution onto a second line
+		regexp.MustCompile(`(?m)^On\s[^\n]*(\n[^\n]*)?\swrote:[ \t]*$`),
+		// Outlook
+		regexp.MustCompile(`(?m)^-+\s*Original Message\s*-+[ \t]*$`),
+		regexp.MustCompile(`(?m)^(_{10,}\n)?From:\s[^\n]*\n(Sent|Date):\s`),
+	}
+	emailReplySentFromRegex = regexp.MustCompile(`(?m)^Sent from my [^\n]*$`)
+
+	emailReplyHtmlQuoteRegex   = regexp.MustCompile(`(?is)<blockquote.*</blockquote>`)
+	emailReplyHtmlBreakRegex   = regexp.MustCompile(`(?i)<br\s*/?>|</p>|</div>`)
+	emailReplyHtmlTagRegex     = regexp.MustCompile(`<[^>]*>`)
+	emailReplyHtmlCommentRegex = regexp.MustCompile(`(?s)<!--.*?-->`)
+)
+
+type emailReplyAttachment struct {
+	Filename string
+	Data     []byte
+}
+
+type emailReplyContent struct {
+	Text        string
+	Html        string
+	Attachments []*emailReplyAttachment
+}
+
+// GetEmailReplyAddress returns the address that replies to a notification email about the given post
+// should be sent to, or an empty string if replying by email is disabled. The address contains the id
+// of the post and a signature that ties it to the user who the email was sent to.
+func GetEmailReplyAddress(postId string, userId string) string {
+	if !*utils.Cfg.EmailSettings.EnableEmailReplies {
+		return ""
+	}
+
+	address := *utils.Cfg.EmailSettings.EmailReplyAddress
+	at := strings.LastIndex(address, "@")
+	if at <= 0 {
+		return ""
+	}
+
+	return address[:at] + "+" + postId + "." + generateEmailReplySignature(postId, userId) + address[at:]
+}
+
+func generateEmailReplySignature(postId string, userId string) string {
+	mac := hmac.New(sha256.New, []byte(*utils.Cfg.EmailSettings.EmailReplySalt))
+	mac.Write([]byte(postId))
+	mac.Write([]byte(userId))
+
+	return hex.EncodeToString(mac.Sum(nil))[:EMAIL_REPLY_SIGNATURE_LENGTH]
+}
+
+// Returns the post id and signature contained in an address generated by GetEmailReplyAddress.
+func parseEmailReplyAddress(address string) (string, string, bool) {
+	replyAddress := strings.ToLower(*utils.Cfg.EmailSettings.EmailReplyAddress)
+	at := strings.LastIndex(replyAddress, "@")
+	if at <= 0 {
+		return "", "", false
+	}
+
+	address = strings.ToLower(strings.TrimSpace(address))
+	prefix := replyAddress[:at] + "+"
+	suffix := replyAddress[at:]
+	if !strings.HasPrefix(address, prefix) || !strings.HasSuffix(address, suffix) || len(address) <= len(prefix)+len(suffix) {
+		return "", "", false
+	}
+
+	token := address[len(prefix) : len(address)-len(suffix)]
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 || len(parts[0]) != 26 || len(parts[1]) != EMAIL_REPLY_SIGNATURE_LENGTH {
+		return "", "", false
+	}
+
+	return parts[0], parts[1], true
+}
+
+func IsEmailReplyAddress(address string) bool {
+	_, _, ok := parseEmailReplyAddress(address)
+	return ok
+}
+
+// ProcessEmailReply reads a reply to a notification email and posts it as a reply to the post the email
+// was about. The recipients are the envelope recipients of the email if they're known, otherwise the
+// reply address is looked for in the headers of the email.
+func ProcessEmailReply(recipients []string, r io.Reader) (*model.Post, *model.AppError) {
+	msg, err := mail.ReadMessage(r)
+	if err != nil {
+		return nil, model.NewAppError("ProcessEmailReply", "app.email_reply.parse.app_error", nil, err.Error(), http.StatusBadRequest)
+	}
+
+	// Don't let vacation responders and the like reply to threads
+	if autoSubmitted := msg.Header.Get("Auto-Submitted"); autoSubmitted != "" && !strings.EqualFold(autoSubmitted, "no") {
+		return nil, model.NewAppError("ProcessEmailReply", "app.email_reply.auto_submitted.app_error", nil, "auto_submitted="+autoSubmitted, http.StatusBadRequest)
+	}
+
+	if len(recipients) == 0 {
+		for _, header := range []string{"Delivered-To", "X-Original-To", "To", "Cc"} {
+			if addresses, err := msg.Header.AddressList(header); err == nil {
+				for _, address := range addresses {
+					recipients = append(recipients, address.Address)
+				}
+			}
+		}
+	}
+
+	var postId, signature string
+	for _, recipient := range recipients {
+		if id, sig, ok := parseEmailReplyAddress(recipient); ok {
+			postId = id
+			signature = sig
+			break
+		}
+	}
+
+	if postId == "" {
+		return nil, model.NewAppError("ProcessEmailReply", "app.email_reply.no_reply_address.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	from, err := mail.ParseAddress(msg.Header.Get("From"))
+	if err != nil {
+		return nil, model.NewAppError("ProcessEmailReply", "app.email_reply.invalid_sender.app_error", nil, err.Error(), http.StatusForbidden)
+	}
+
+	var user *model.User
+	if result := <-Srv.Store.User().GetByEmail(from.Address); result.Err != nil {
+		return nil, model.NewAppError("ProcessEmailReply", "app.email_reply.invalid_sender.app_error", nil, result.Err.Error(), http.StatusForbidden)
+	} else {
+		user = result.Data.(*model.User)
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(generateEmailReplySignature(postId, user.Id))) {
+		return nil, model.NewAppError("ProcessEmailReply", "app.email_reply.invalid_signature.app_error", nil, "user_id="+user.Id+", post_id="+postId, http.StatusForbidden)
+	}
+
+	if user.DeleteAt != 0 {
+		return nil, model.NewAppError("ProcessEmailReply", "app.email_reply.invalid_sender.app_error", nil, "user_id="+user.Id, http.StatusForbidden)
+	}
+
+	post, appErr := GetSinglePost(postId)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	if !HasPermissionToChannel(user.Id, post.ChannelId, model.PERMISSION_CREATE_POST) {
+		return nil, model.NewAppError("ProcessEmailReply", "app.email_reply.permissions.app_error", nil, "user_id="+user.Id+", channel_id="+post.ChannelId, http.StatusForbidden)
+	}
+
+	channel, appErr := GetChannel(post.ChannelId)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	content := &emailReplyContent{}
+	if err := readEmailReplyPart(msg.Header.Get("Content-Type"), msg.Header.Get("Content-Transfer-Encoding"), "", msg.Body, content); err != nil {
+		return nil, model.NewAppError("ProcessEmailReply", "app.email_reply.parse.app_error", nil, err.Error(), http.StatusBadRequest)
+	}
+
+	text := content.Text
+	if text == "" && content.Html != "" {
+		text = convertEmailReplyHtmlToText(content.Html)
+	}
+
+	message := stripQuotedEmailReply(text)
+	if message == "" && len(content.Attachments) == 0 {
+		return nil, model.NewAppError("ProcessEmailReply", "app.email_reply.empty.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	teamId := channel.TeamId
+	if teamId == "" {
+		teamId = "noteam"
+	}
+
+	fileIds := []string{}
+	previewPathList := []string{}
+	thumbnailPathList := []string{}
+	imageDataList := [][]byte{}
+
+	for _, attachment := range content.Attachments {
+		if len(fileIds) >= EMAIL_REPLY_MAX_ATTACHMENTS {
+			l4g.Warn(utils.T("app.email_reply.too_many_attachments.warn"), user.Id, attachment.Filename)
+			continue
+		}
+
+		if int64(len(attachment.Data)) > *utils.Cfg.FileSettings.MaxFileSize {
+			l4g.Warn(utils.T("app.email_reply.attachment_too_large.warn"), user.Id, attachment.Filename)
+			continue
+		}
+
+		info, err := DoUploadFile(teamId, channel.Id, user.Id, attachment.Filename, attachment.Data)
+		if err != nil {
+			return nil, err
+		}
+
+		if info.PreviewPath != "" || info.ThumbnailPath != "" {
+			previewPathList = append(previewPathList, info.PreviewPath)
+			thumbnailPathList = append(thumbnailPathList, info.ThumbnailPath)
+			imageDataList = append(imageDataList, attachment.Data)
+		}
+
+		fileIds = append(fileIds, info.Id)
+	}
+
+	HandleImages(previewPathList, thumbnailPathList, imageDataList)
+
+	rootId := post.RootId
+	if rootId == "" {
+		rootId = post.Id
+	}
+
+	reply := &model.Post{
+		UserId:    user.Id,
+		ChannelId: channel.Id,
+		RootId:    rootId,
+		ParentId:  rootId,
+		Message:   message,
+		FileIds:   fileIds,
+	}
+
+	return CreatePostAsUser(reply, utils.GetSiteURL())
+}
+
+// Reads a part of an email into the given content, recursing into multipart parts. The first plain text
+// and HTML parts are kept as the body of the email and any parts with file names become attachments.
+func readEmailReplyPart(contentType string, transferEncoding string, disposition string, body io.Reader, content *emailReplyContent) error {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = "text/plain"
+		params = map[string]string{}
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		reader := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				return nil
+			} else if err != nil {
+				return err
+			}
+
+			// Quoted-printable parts are decoded by the multipart reader, which removes the header
+			if err := readEmailReplyPart(part.Header.Get("Content-Type"), part.Header.Get("Content-Transfer-Encoding"), part.Header.Get("Content-Disposition"), part, content); err != nil {
+				return err
+			}
+		}
+	}
+
+	switch strings.ToLower(strings.TrimSpace(transferEncoding)) {
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	}
+
+	data, err := ioutil.ReadAll(body)
+	if err != nil {
+		return err
+	}
+
+	filename := params["name"]
+	isAttachment := false
+	if dispositionType, dispositionParams, err := mime.ParseMediaType(disposition); err == nil {
+		isAttachment = dispositionType == "attachment"
+		if dispositionParams["filename"] != "" {
+			filename = dispositionParams["filename"]
+		}
+	}
+
+	if isAttachment || filename != "" {
+		if filename == "" {
+			filename = "attachment"
+		}
+
+		content.Attachments = append(content.Attachments, &emailReplyAttachment{Filename: filename, Data: data})
+	} else if mediaType == "text/plain" && content.Text == "" {
+		content.Text = string(data)
+	} else if mediaType == "text/html" && content.Html == "" {
+		content.Html = string(data)
+	}
+
+	return nil
+}
+
+func convertEmailReplyHtmlToText(body string) string {
+	body = emailReplyHtmlCommentRegex.ReplaceAllString(body, "")
+	body = emailReplyHtmlQuoteRegex.ReplaceAllString(body, "")
+	body = emailReplyHtmlBreakRegex.ReplaceAllString(body, "\n")
+	body = emailReplyHtmlTagRegex.ReplaceAllString(body, "")
+
+	return html.UnescapeString(body)
+}
+
+// Returns the text that was written in a reply to an email, without the quoted email being replied to
+// or the signature of the person replying.
+func stripQuotedEmailReply(text string) string {
+	text = strings.Replace(text, "\r\n", "\n", -1)
+
+	for _, regex := range emailReplyQuoteHeaderRegexes {
+		if loc := regex.FindStringIndex(text); loc != nil {
+			text = text[:loc[0]]
+		}
+	}
+
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		// Everything after the signature separator is the signature
+		if line == "-- " || line == "--" {
+			break
+		}
+
+		if strings.HasPrefix(line, ">") {
+			continue
+		}
+
+		lines = append(lines, strings.TrimRight(line, " \t"))
+	}
+
+	text = strings.Join(lines, "\n")
+	text = emailReplySentFromRegex.ReplaceAllString(text, "")
+
+	return strings.TrimSpace(text)
+}
@@ -0,0 +1,227 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+const (
+	EMAIL_REPLY_MAILDIR_TASK_NAME     = "Email Reply Maildir"
+	EMAIL_REPLY_MAILDIR_POLL_INTERVAL = 10 * time.Second
+	EMAIL_REPLY_SMTP_TIMEOUT          = 5 * time.Minute
+)
+
+var emailReplyListener net.Listener
+var emailReplyMaildirTask *model.ScheduledTask
+
+// StartEmailReplyIngestion starts accepting replies to notification emails over SMTP and from a maildir,
+// depending on which of them are configured.
+func StartEmailReplyIngestion() {
+	if !*utils.Cfg.EmailSettings.EnableEmailReplies {
+		return
+	}
+
+	if address := *utils.Cfg.EmailSettings.EmailReplyListenAddress; len(address) > 0 {
+		if listener, err := net.Listen("tcp", address); err != nil {
+			l4g.Error(utils.T("app.email_reply.listen.error"), address, err.Error())
+		} else {
+			l4g.Info(utils.T("app.email_reply.listen.info"), address)
+			emailReplyListener = listener
+			go acceptEmailReplyConnections(listener)
+		}
+	}
+
+	if maildir := *utils.Cfg.EmailSettings.EmailReplyMaildir; len(maildir) > 0 {
+		l4g.Info(utils.T("app.email_reply.maildir.info"), maildir)
+		emailReplyMaildirTask = model.CreateRecurringTask(EMAIL_REPLY_MAILDIR_TASK_NAME, func() {
+			readEmailReplyMaildir(maildir)
+		}, EMAIL_REPLY_MAILDIR_POLL_INTERVAL)
+	}
+}
+
+func StopEmailReplyIngestion() {
+	if emailReplyListener != nil {
+		emailReplyListener.Close()
+		emailReplyListener = nil
+	}
+
+	if emailReplyMaildirTask != nil {
+		emailReplyMaildirTask.Cancel()
+		emailReplyMaildirTask = nil
+	}
+}
+
+func acceptEmailReplyConnections(listener net.Listener) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			// the listener has been closed
+			return
+		}
+
+		go handleEmailReplyConnection(conn)
+	}
+}
+
+// Speaks just enough SMTP to receive mail for reply addresses from a local mail server.
+func handleEmailReplyConnection(conn net.Conn) {
+	defer conn.Close()
+
+	text := textproto.NewConn(conn)
+	reply := func(code int, message string) bool {
+		return text.PrintfLine("%d %s", code, message) == nil
+	}
+
+	hostname, _ := os.Hostname()
+	if !reply(220, hostname+" ESMTP") {
+		return
+	}
+
+	var recipients []string
+	hasSender := false
+
+	for {
+		conn.SetDeadline(time.Now().Add(EMAIL_REPLY_SMTP_TIMEOUT))
+
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+
+		command := strings.ToUpper(line)
+		if i := strings.Index(command, " "); i != -1 {
+			command = command[:i]
+		}
+		argument := strings.TrimSpace(line[len(command):])
+
+		switch command {
+		case "HELO", "EHLO":
+			recipients = nil
+			hasSender = false
+			reply(250, hostname)
+		case "MAIL":
+			recipients = nil
+			hasSender = true
+			reply(250, "OK")
+		case "RCPT":
+			if !hasSender {
+				reply(503, "Need MAIL command")
+			} else if address := parseSmtpPath(argument, "TO:"); !IsEmailReplyAddress(address) {
+				reply(550, "No such user")
+			} else {
+				recipients = append(recipients, address)
+				reply(250, "OK")
+			}
+		case "DATA":
+			if len(recipients) == 0 {
+				reply(503, "Need RCPT command")
+				continue
+			}
+
+			reply(354, "End data with <CR><LF>.<CR><LF>")
+
+			// leave room for the headers and the base64 encoding of attachments
+			maxSize := *utils.Cfg.FileSettings.MaxFileSize*2 + 1024*1024
+			dotReader := text.DotReader()
+			data, err := ioutil.ReadAll(io.LimitReader(dotReader, maxSize+1))
+			if err != nil {
+				return
+			}
+
+			if int64(len(data)) > maxSize {
+				// drain the rest of the message so that the next command can be read
+				if _, err := io.Copy(ioutil.Discard, dotReader); err != nil {
+					return
+				}
+				reply(552, "Message too large")
+			} else if _, err := ProcessEmailReply(recipients, bytes.NewReader(data)); err != nil {
+				l4g.Error(utils.T("app.email_reply.process.error"), err.Error())
+				reply(554, "Reply could not be posted")
+			} else {
+				reply(250, "OK")
+			}
+
+			recipients = nil
+			hasSender = false
+		case "RSET":
+			recipients = nil
+			hasSender = false
+			reply(250, "OK")
+		case "NOOP":
+			reply(250, "OK")
+		case "QUIT":
+			reply(221, "Bye")
+			return
+		default:
+			reply(502, "Command not implemented")
+		}
+	}
+}
+
+// Returns the address from the argument of a MAIL or RCPT command, such as "TO:<user@example.com>".
+func parseSmtpPath(argument string, prefix string) string {
+	if !strings.HasPrefix(strings.ToUpper(argument), prefix) {
+		return ""
+	}
+
+	path := strings.TrimSpace(argument[len(prefix):])
+	if end := strings.Index(path, ">"); strings.HasPrefix(path, "<") && end != -1 {
+		path = path[1:end]
+	}
+
+	return path
+}
+
+// Posts the replies that have been delivered to the new folder of a maildir and moves them into the cur
+// folder, marking the ones that couldn't be posted as trashed.
+func readEmailReplyMaildir(maildir string) {
+	newDir := filepath.Join(maildir, "new")
+	curDir := filepath.Join(maildir, "cur")
+
+	files, err := ioutil.ReadDir(newDir)
+	if err != nil {
+		l4g.Error(utils.T("app.email_reply.maildir.error"), maildir, err.Error())
+		return
+	}
+
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+
+		path := filepath.Join(newDir, file.Name())
+
+		flags := ":2,S"
+		if f, err := os.Open(path); err != nil {
+			l4g.Error(utils.T("app.email_reply.maildir.error"), maildir, err.Error())
+			continue
+		} else {
+			_, appErr := ProcessEmailReply(nil, bufio.NewReader(f))
+			f.Close()
+
+			if appErr != nil {
+				l4g.Error(utils.T("app.email_reply.process.error"), appErr.Error())
+				flags = ":2,T"
+			}
+		}
+
+		if err := os.Rename(path, filepath.Join(curDir, file.Name()+flags)); err != nil {
+			l4g.Error(utils.T("app.email_reply.maildir.error"), maildir, err.Error())
+		}
+	}
+}
@@ -0,0 +1,243 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func TestEmailReplyAddress(t *testing.T) {
+	utils.TranslationsPreInit()
+	utils.LoadConfig("config.json")
+
+	enabled := *utils.Cfg.EmailSettings.EnableEmailReplies
+	replyAddress := *utils.Cfg.EmailSettings.EmailReplyAddress
+	defer func() {
+		*utils.Cfg.EmailSettings.EnableEmailReplies = enabled
+		*utils.Cfg.EmailSettings.EmailReplyAddress = replyAddress
+	}()
+
+	postId := model.NewId()
+	userId := model.NewId()
+
+	*utils.Cfg.EmailSettings.EnableEmailReplies = false
+	*utils.Cfg.EmailSettings.EmailReplyAddress = "reply@example.com"
+	if address := GetEmailReplyAddress(postId, userId); address != "" {
+		t.Fatal("shouldn't have returned an address when email replies are disabled")
+	}
+
+	*utils.Cfg.EmailSettings.EnableEmailReplies = true
+	address := GetEmailReplyAddress(postId, userId)
+	if !strings.HasPrefix(address, "reply+"+postId+".") || !strings.HasSuffix(address, "@example.com") {
+		t.Fatal("returned the wrong address", address)
+	}
+
+	if id, signature, ok := parseEmailReplyAddress(strings.ToUpper(address)); !ok {
+		t.Fatal("should have parsed the address", address)
+	} else if id != postId {
+		t.Fatal("returned the wrong post id", id)
+	} else if signature != generateEmailReplySignature(postId, userId) {
+		t.Fatal("returned the wrong signature", signature)
+	}
+
+	if generateEmailReplySignature(postId, userId) == generateEmailReplySignature(postId, model.NewId()) {
+		t.Fatal("signatures for different users should differ")
+	}
+
+	for _, invalid := range []string{
+		"reply@example.com",
+		"reply+@example.com",
+		"reply+" + postId + "@example.com",
+		"reply+" + postId + ".abc@example.com",
+		"other+" + postId + "." + generateEmailReplySignature(postId, userId) + "@example.com",
+		"reply+" + postId + "." + generateEmailReplySignature(postId, userId) + "@example.org",
+	} {
+		if IsEmailReplyAddress(invalid) {
+			t.Fatal("shouldn't have accepted address", invalid)
+		}
+	}
+}
+
+func TestStripQuotedEmailReply(t *testing.T) {
+	for name, tc := range map[string]struct {
+		Input    string
+		Expected string
+	}{
+		"plain": {
+			Input:    "Sounds good to me.\r\n",
+			Expected: "Sounds good to me.",
+		},
+		"gmail": {
+			Input:    "Sounds good to me.\n\nOn Mon, Jul 3, 2017 at 10:15 AM, Mattermost <noreply@example.com> wrote:\n> You have a new mention\n",
+			Expected: "Sounds good to me.",
+		},
+		"gmail with a wrapped attribution": {
+			Input:    "Sounds good.\n\nOn Mon, Jul 3, 2017 at 10:15 AM, Mattermost\n<noreply@example.com> wrote:\n\n> You have a new mention\n",
+			Expected: "Sounds good.",
+		},
+		"outlook": {
+			Input:    "Sounds good.\r\n\r\n-----Original Message-----\r\nFrom: Mattermost\r\nSent: Monday\r\n",
+			Expected: "Sounds good.",
+		},
+		"outlook without a separator": {
+			Input:    "Sounds good.\n\n________________________________\nFrom: Mattermost <noreply@example.com>\nSent: Monday, July 3, 2017 10:15 AM\n",
+			Expected: "Sounds good.",
+		},
+		"signature": {
+			Input:    "Sounds good.\n\n-- \nJane Doe\nEngineering\n",
+			Expected: "Sounds good.",
+		},
+		"quoted lines": {
+			Input:    "> is this done?\nYes\n> and this?\nNo",
+			Expected: "Yes\nNo",
+		},
+		"sent from": {
+			Input:    "Sounds good.\n\nSent from my phone",
+			Expected: "Sounds good.",
+		},
+		"multiple paragraphs": {
+			Input:    "First paragraph.\n\nSecond paragraph.\n\nOn Monday, someone wrote:\n> hi",
+			Expected: "First paragraph.\n\nSecond paragraph.",
+		},
+		"on in a sentence": {
+			Input:    "On second thought, let's wait.",
+			Expected: "On second thought, let's wait.",
+		},
+	} {
+		if actual := stripQuotedEmailReply(tc.Input); actual != tc.Expected {
+			t.Fatalf("%v: expected %q, got %q", name, tc.Expected, actual)
+		}
+	}
+}
+
+func TestReadEmailReplyPart(t *testing.T) {
+	body := strings.Join([]string{
+		"--outer",
+		"Content-Type: multipart/alternative; boundary=inner",
+		"",
+		"--inner",
+		"Content-Type: text/plain; charset=utf-8",
+		"Content-Transfer-Encoding: quoted-printable",
+		"",
+		"Looks good =E2=80=94 thanks!",
+		"--inner",
+		"Content-Type: text/html; charset=utf-8",
+		"",
+		"<div>Looks good &mdash; thanks!</div>",
+		"--inner--",
+		"--outer",
+		"Content-Type: text/plain; name=notes.txt",
+		"Content-Disposition: attachment; filename=notes.txt",
+		"Content-Transfer-Encoding: base64",
+		"",
+		"c29tZSBu",
+		"b3Rlcw==",
+		"--outer--",
+		"",
+	}, "\r\n")
+
+	content := &emailReplyContent{}
+	if err := readEmailReplyPart("multipart/mixed; boundary=outer", "", "", strings.NewReader(body), content); err != nil {
+		t.Fatal(err)
+	}
+
+	if content.Text != "Looks good — thanks!" {
+		t.Fatalf("read the wrong text %q", content.Text)
+	}
+
+	if content.Html != "<div>Looks good &mdash; thanks!</div>" {
+		t.Fatalf("read the wrong html %q", content.Html)
+	}
+
+	if len(content.Attachments) != 1 {
+		t.Fatal("should have read one attachment")
+	} else if content.Attachments[0].Filename != "notes.txt" || string(content.Attachments[0].Data) != "some notes" {
+		t.Fatal("read the wrong attachment", content.Attachments[0].Filename, string(content.Attachments[0].Data))
+	}
+
+	if text := convertEmailReplyHtmlToText("<div>Looks good &amp; thanks!</div><blockquote>old message</blockquote>"); strings.TrimSpace(text) != "Looks good & thanks!" {
+		t.Fatalf("converted the html incorrectly %q", text)
+	}
+}
+
+func TestParseSmtpPath(t *testing.T) {
+	if path := parseSmtpPath("TO:<reply@example.com>", "TO:"); path != "reply@example.com" {
+		t.Fatal("parsed the wrong path", path)
+	}
+
+	if path := parseSmtpPath("to: <reply@example.com> SIZE=100", "TO:"); path != "reply@example.com" {
+		t.Fatal("parsed the wrong path", path)
+	}
+
+	if path := parseSmtpPath("FROM:<reply@example.com>", "TO:"); path != "" {
+		t.Fatal("shouldn't have parsed the path", path)
+	}
+}
+
+func TestEmailReplyConnectionMessageTooLarge(t *testing.T) {
+	utils.TranslationsPreInit()
+	utils.LoadConfig("config.json")
+
+	replyAddress := *utils.Cfg.EmailSettings.EmailReplyAddress
+	maxFileSize := *utils.Cfg.FileSettings.MaxFileSize
+	defer func() {
+		*utils.Cfg.EmailSettings.EmailReplyAddress = replyAddress
+		*utils.Cfg.FileSettings.MaxFileSize = maxFileSize
+	}()
+
+	*utils.Cfg.EmailSettings.EmailReplyAddress = "reply@example.com"
+	*utils.Cfg.FileSettings.MaxFileSize = 1
+
+	server, client := net.Pipe()
+	go handleEmailReplyConnection(server)
+	client.SetDeadline(time.Now().Add(10 * time.Second))
+
+	text := textproto.NewConn(client)
+	defer text.Close()
+
+	expect := func(code int) {
+		if _, _, err := text.ReadResponse(code); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	send := func(line string, code int) {
+		if err := text.PrintfLine("%s", line); err != nil {
+			t.Fatal(err)
+		}
+		expect(code)
+	}
+
+	postId := model.NewId()
+	address := "reply+" + postId + "." + generateEmailReplySignature(postId, model.NewId()) + "@example.com"
+
+	expect(220)
+	send("HELO localhost", 250)
+	send("MAIL FROM:<sender@example.com>", 250)
+	send("RCPT TO:<"+address+">", 250)
+	send("DATA", 354)
+
+	w := text.DotWriter()
+	line := strings.Repeat("a", 998) + "\r\n"
+	for written := 0; written < 2*1024*1024; written += len(line) {
+		if _, err := w.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	expect(552)
+
+	// the rest of the message shouldn't be read as commands
+	send("NOOP", 250)
+	send("QUIT", 221)
+}
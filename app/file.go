@@ -4,6 +4,7 @@
 package app
 
 import (
+	"bufio"
 	"bytes"
 	"crypto/sha256"
 	"encoding/base64"
@@ -56,7 +57,107 @@ const (
 	MaxImageSize = 6048 * 4032 // 24 megapixels, roughly 36MB as a raw image
 )
 
+// ReadFile reads a stored file, decrypting it if it was encrypted at rest.
 func ReadFile(path string) ([]byte, *model.AppError) {
+	data, err := readFileRaw(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if isWrappedPlaintextFileData(data) {
+		return data[filePlaintextHeaderSize:], nil
+	} else if !isEncryptedFileData(data) {
+		return data, nil
+	}
+
+	return decryptStoredFile(path, data)
+}
+
+func decryptStoredFile(path string, data []byte) ([]byte, *model.AppError) {
+	keys, err := GetFileEncryptionKeys()
+	if err != nil {
+		return nil, err
+	} else if len(keys) == 0 {
+		return nil, model.NewLocAppError("ReadFile", "app.file.encryption_key.missing.app_error", nil, "path="+path)
+	}
+
+	if decrypted, err := decryptFileData(keys, data); err != nil {
+		return nil, model.NewLocAppError("ReadFile", "api.file.read_file.decrypt.app_error", nil, "path="+path+", err="+err.Error())
+	} else {
+		return decrypted, nil
+	}
+}
+
+type fileReadStream struct {
+	io.Reader
+	io.Closer
+}
+
+// ReadFileStream opens a stored file for reading without loading it into memory. Encrypted files are the exception
+// since they're sealed as a whole and have to be decrypted in one go. The returned reader must be closed.
+func ReadFileStream(path string) (io.ReadCloser, *model.AppError) {
+	stream, err := openFileReadStream(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// S3 objects are fetched lazily so a missing file only shows up once it's read from
+	reader := bufio.NewReader(stream)
+	header, peekErr := reader.Peek(fileHeaderSize + fileGCMTagSize)
+	if peekErr != nil && peekErr != io.EOF {
+		stream.Close()
+		return nil, model.NewLocAppError("ReadFile", "api.file.read_file.reading_local.app_error", nil, "path="+path+", err="+peekErr.Error())
+	}
+
+	if isWrappedPlaintextFileData(header) {
+		reader.Discard(filePlaintextHeaderSize)
+		return &fileReadStream{reader, stream}, nil
+	} else if !isEncryptedFileData(header) {
+		return &fileReadStream{reader, stream}, nil
+	}
+
+	defer stream.Close()
+
+	data, readErr := ioutil.ReadAll(reader)
+	if readErr != nil {
+		return nil, model.NewLocAppError("ReadFile", "api.file.read_file.reading_local.app_error", nil, "path="+path+", err="+readErr.Error())
+	}
+
+	if decrypted, err := decryptStoredFile(path, data); err != nil {
+		return nil, err
+	} else {
+		return ioutil.NopCloser(bytes.NewReader(decrypted)), nil
+	}
+}
+
+func openFileReadStream(path string) (io.ReadCloser, *model.AppError) {
+	if utils.Cfg.FileSettings.DriverName == model.IMAGE_DRIVER_S3 {
+		endpoint := utils.Cfg.FileSettings.AmazonS3Endpoint
+		accessKey := utils.Cfg.FileSettings.AmazonS3AccessKeyId
+		secretKey := utils.Cfg.FileSettings.AmazonS3SecretAccessKey
+		secure := *utils.Cfg.FileSettings.AmazonS3SSL
+		s3Clnt, err := s3.New(endpoint, accessKey, secretKey, secure)
+		if err != nil {
+			return nil, model.NewLocAppError("ReadFile", "api.file.read_file.s3.app_error", nil, err.Error())
+		}
+		bucket := utils.Cfg.FileSettings.AmazonS3Bucket
+		if minioObject, err := s3Clnt.GetObject(bucket, path); err != nil {
+			return nil, model.NewLocAppError("ReadFile", "api.file.read_file.s3.app_error", nil, err.Error())
+		} else {
+			return minioObject, nil
+		}
+	} else if utils.Cfg.FileSettings.DriverName == model.IMAGE_DRIVER_LOCAL {
+		if f, err := os.Open(utils.Cfg.FileSettings.Directory + path); err != nil {
+			return nil, model.NewLocAppError("ReadFile", "api.file.read_file.reading_local.app_error", nil, err.Error())
+		} else {
+			return f, nil
+		}
+	} else {
+		return nil, model.NewLocAppError("ReadFile", "api.file.read_file.configured.app_error", nil, "")
+	}
+}
+
+func readFileRaw(path string) ([]byte, *model.AppError) {
 	if utils.Cfg.FileSettings.DriverName == model.IMAGE_DRIVER_S3 {
 		endpoint := utils.Cfg.FileSettings.AmazonS3Endpoint
 		accessKey := utils.Cfg.FileSettings.AmazonS3AccessKeyId
@@ -122,7 +223,79 @@ func MoveFile(oldPath, newPath string) *model.AppError {
 	return nil
 }
 
+// WriteFile stores a file, encrypting it first if encryption at rest is enabled.
 func WriteFile(f []byte, path string) *model.AppError {
+	if *utils.Cfg.FileSettings.EnableEncryption {
+		masterKey, err := GetFileEncryptionKey()
+		if err != nil {
+			return err
+		} else if masterKey == nil {
+			return model.NewLocAppError("WriteFile", "app.file.encryption_key.missing.app_error", nil, "path="+path)
+		}
+
+		if encrypted, err := encryptFileData(masterKey, f); err != nil {
+			return model.NewLocAppError("WriteFile", "api.file.write_file.encrypt.app_error", nil, "path="+path+", err="+err.Error())
+		} else {
+			f = encrypted
+		}
+	} else {
+		f = wrapPlaintextFileData(f)
+	}
+
+	return writeFileRaw(f, path)
+}
+
+// WriteFileFromReader stores a file without loading it into memory, unless it has to be encrypted since files are
+// encrypted as a whole.
+func WriteFileFromReader(r io.Reader, path string) *model.AppError {
+	if *utils.Cfg.FileSettings.EnableEncryption {
+		if data, err := ioutil.ReadAll(r); err != nil {
+			return model.NewLocAppError("WriteFile", "api.file.write_file_locally.writing.app_error", nil, "path="+path+", err="+err.Error())
+		} else {
+			return WriteFile(data, path)
+		}
+	}
+
+	// see wrapPlaintextFileData
+	reader := bufio.NewReader(r)
+	if header, _ := reader.Peek(len(fileEncryptionMagic)); string(header) == fileEncryptionMagic {
+		r = io.MultiReader(bytes.NewReader(append([]byte(fileEncryptionMagic), filePlaintextVersion)), reader)
+	} else {
+		r = reader
+	}
+
+	if utils.Cfg.FileSettings.DriverName == model.IMAGE_DRIVER_S3 {
+		endpoint := utils.Cfg.FileSettings.AmazonS3Endpoint
+		accessKey := utils.Cfg.FileSettings.AmazonS3AccessKeyId
+		secretKey := utils.Cfg.FileSettings.AmazonS3SecretAccessKey
+		secure := *utils.Cfg.FileSettings.AmazonS3SSL
+		s3Clnt, err := s3.New(endpoint, accessKey, secretKey, secure)
+		if err != nil {
+			return model.NewLocAppError("WriteFile", "api.file.write_file.s3.app_error", nil, err.Error())
+		}
+		bucket := utils.Cfg.FileSettings.AmazonS3Bucket
+
+		if _, err = s3Clnt.PutObject(bucket, path, r, "binary/octet-stream"); err != nil {
+			return model.NewLocAppError("WriteFile", "api.file.write_file.s3.app_error", nil, err.Error())
+		}
+	} else if utils.Cfg.FileSettings.DriverName == model.IMAGE_DRIVER_LOCAL {
+		fileHandle, err := openFileWriteStream(path)
+		if err != nil {
+			return err
+		}
+		defer closeFileWriteStream(fileHandle)
+
+		if _, err := io.Copy(fileHandle, r); err != nil {
+			return model.NewLocAppError("WriteFile", "api.file.write_file_locally.writing.app_error", nil, err.Error())
+		}
+	} else {
+		return model.NewLocAppError("WriteFile", "api.file.write_file.configured.app_error", nil, "")
+	}
+
+	return nil
+}
+
+func writeFileRaw(f []byte, path string) *model.AppError {
 	if utils.Cfg.FileSettings.DriverName == model.IMAGE_DRIVER_S3 {
 		endpoint := utils.Cfg.FileSettings.AmazonS3Endpoint
 		accessKey := utils.Cfg.FileSettings.AmazonS3AccessKeyId
@@ -154,6 +327,81 @@ func WriteFile(f []byte, path string) *model.AppError {
 	return nil
 }
 
+func RemoveFile(path string) *model.AppError {
+	if utils.Cfg.FileSettings.DriverName == model.IMAGE_DRIVER_S3 {
+		endpoint := utils.Cfg.FileSettings.AmazonS3Endpoint
+		accessKey := utils.Cfg.FileSettings.AmazonS3AccessKeyId
+		secretKey := utils.Cfg.FileSettings.AmazonS3SecretAccessKey
+		secure := *utils.Cfg.FileSettings.AmazonS3SSL
+		s3Clnt, err := s3.New(endpoint, accessKey, secretKey, secure)
+		if err != nil {
+			return model.NewLocAppError("RemoveFile", "api.file.remove_file.s3.app_error", nil, err.Error())
+		}
+		bucket := utils.Cfg.FileSettings.AmazonS3Bucket
+
+		if err := s3Clnt.RemoveObject(bucket, path); err != nil {
+			return model.NewLocAppError("RemoveFile", "api.file.remove_file.s3.app_error", nil, err.Error())
+		}
+	} else if utils.Cfg.FileSettings.DriverName == model.IMAGE_DRIVER_LOCAL {
+		if err := os.Remove(utils.Cfg.FileSettings.Directory + path); err != nil && !os.IsNotExist(err) {
+			return model.NewLocAppError("RemoveFile", "api.file.remove_file.local.app_error", nil, err.Error())
+		}
+	} else {
+		return model.NewLocAppError("RemoveFile", "api.file.remove_file.configured.app_error", nil, "")
+	}
+
+	return nil
+}
+
+// ListFiles returns the paths of every stored file.
+func ListFiles() ([]string, *model.AppError) {
+	paths := []string{}
+
+	if utils.Cfg.FileSettings.DriverName == model.IMAGE_DRIVER_S3 {
+		endpoint := utils.Cfg.FileSettings.AmazonS3Endpoint
+		accessKey := utils.Cfg.FileSettings.AmazonS3AccessKeyId
+		secretKey := utils.Cfg.FileSettings.AmazonS3SecretAccessKey
+		secure := *utils.Cfg.FileSettings.AmazonS3SSL
+		s3Clnt, err := s3.New(endpoint, accessKey, secretKey, secure)
+		if err != nil {
+			return nil, model.NewLocAppError("ListFiles", "api.file.list_files.s3.app_error", nil, err.Error())
+		}
+		bucket := utils.Cfg.FileSettings.AmazonS3Bucket
+
+		doneCh := make(chan struct{})
+		defer close(doneCh)
+
+		for object := range s3Clnt.ListObjects(bucket, "", true, doneCh) {
+			if object.Err != nil {
+				return nil, model.NewLocAppError("ListFiles", "api.file.list_files.s3.app_error", nil, object.Err.Error())
+			}
+			paths = append(paths, object.Key)
+		}
+	} else if utils.Cfg.FileSettings.DriverName == model.IMAGE_DRIVER_LOCAL {
+		root := filepath.Clean(utils.Cfg.FileSettings.Directory)
+		err := filepath.Walk(root, func(walkPath string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if info.Mode().IsRegular() {
+				relative, _ := filepath.Rel(root, walkPath)
+				paths = append(paths, filepath.ToSlash(relative))
+			}
+
+			return nil
+		})
+
+		if err != nil && !os.IsNotExist(err) {
+			return nil, model.NewLocAppError("ListFiles", "api.file.list_files.local.app_error", nil, err.Error())
+		}
+	} else {
+		return nil, model.NewLocAppError("ListFiles", "api.file.list_files.configured.app_error", nil, "")
+	}
+
+	return paths, nil
+}
+
 func writeFileLocally(f []byte, path string) *model.AppError {
 	if err := os.MkdirAll(filepath.Dir(path), 0774); err != nil {
 		directory, _ := filepath.Abs(filepath.Dir(path))
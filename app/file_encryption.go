@@ -0,0 +1,382 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+// Encrypted files start with a header holding the id of the master key, the
+// file's data key wrapped with the master key and the nonce used for the
+// file contents. Rotating the master key only needs the header rewritten.
+//
+//	magic (5) | version (1) | key id (8) | wrapped data key (60) | nonce (12) | ciphertext
+//
+// Plaintext files that happen to start with the magic are stored behind a
+// header with version 0 so that they can't be mistaken for encrypted ones.
+//
+//	magic (5) | version (0) | plaintext
+const (
+	fileEncryptionMagic     = "MMENC"
+	fileEncryptionVersion   = 1
+	filePlaintextVersion    = 0
+	filePlaintextHeaderSize = len(fileEncryptionMagic) + 1
+
+	// files being re-encrypted are written next to the original with this
+	// suffix and then renamed over it
+	fileReencryptTempSuffix = ".reencrypt.tmp"
+
+	fileKeyIdSize      = 8
+	fileDataKeySize    = 32
+	fileNonceSize      = 12
+	fileGCMTagSize     = 16
+	fileWrappedKeySize = fileNonceSize + fileDataKeySize + fileGCMTagSize
+	fileHeaderSize     = len(fileEncryptionMagic) + 1 + fileKeyIdSize + fileWrappedKeySize + fileNonceSize
+)
+
+// the directories in the file store that hold files written with WriteFile
+var reencryptableFilePrefixes = []string{"teams/", "users/", "emoji/", "brand/", "privacy/exports/"}
+
+// FileEncryptionKey derives a master key from a configured secret.
+func FileEncryptionKey(secret string) []byte {
+	key := sha256.Sum256([]byte(strings.TrimSpace(secret)))
+	return key[:]
+}
+
+// GetFileEncryptionKey returns the master key from the config, or from the
+// configured key file. nil is returned if no key is configured.
+func GetFileEncryptionKey() ([]byte, *model.AppError) {
+	return readFileEncryptionKey(*utils.Cfg.FileSettings.EncryptionKey, *utils.Cfg.FileSettings.EncryptionKeyFile)
+}
+
+// GetFileEncryptionKeys returns the current master key followed by the
+// previous one if it's configured, so that files can still be read while
+// they're being re-encrypted after the key was rotated.
+func GetFileEncryptionKeys() ([][]byte, *model.AppError) {
+	keys := [][]byte{}
+
+	if masterKey, err := GetFileEncryptionKey(); err != nil {
+		return nil, err
+	} else if masterKey != nil {
+		keys = append(keys, masterKey)
+	}
+
+	if previousKey, err := readFileEncryptionKey(*utils.Cfg.FileSettings.PreviousEncryptionKey, *utils.Cfg.FileSettings.PreviousEncryptionKeyFile); err != nil {
+		return nil, err
+	} else if previousKey != nil {
+		keys = append(keys, previousKey)
+	}
+
+	return keys, nil
+}
+
+func readFileEncryptionKey(key string, keyFile string) ([]byte, *model.AppError) {
+	if len(keyFile) > 0 {
+		secret, err := ioutil.ReadFile(keyFile)
+		if err != nil {
+			return nil, model.NewLocAppError("GetFileEncryptionKey", "app.file.encryption_key.reading.app_error", nil, err.Error())
+		}
+
+		if len(strings.TrimSpace(string(secret))) < 32 {
+			return nil, model.NewLocAppError("GetFileEncryptionKey", "app.file.encryption_key.short.app_error", nil, "")
+		}
+
+		return FileEncryptionKey(string(secret)), nil
+	}
+
+	if len(key) > 0 {
+		return FileEncryptionKey(key), nil
+	}
+
+	return nil, nil
+}
+
+func fileKeyId(masterKey []byte) []byte {
+	id := sha256.Sum256(append([]byte(fileEncryptionMagic), masterKey...))
+	return id[:fileKeyIdSize]
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+func isEncryptedFileData(data []byte) bool {
+	return len(data) >= fileHeaderSize+fileGCMTagSize &&
+		bytes.HasPrefix(data, []byte(fileEncryptionMagic)) &&
+		data[len(fileEncryptionMagic)] == fileEncryptionVersion
+}
+
+func isWrappedPlaintextFileData(data []byte) bool {
+	return len(data) >= filePlaintextHeaderSize &&
+		bytes.HasPrefix(data, []byte(fileEncryptionMagic)) &&
+		data[len(fileEncryptionMagic)] == filePlaintextVersion
+}
+
+// wrapPlaintextFileData adds a plaintext header to files that would otherwise
+// start like an encrypted or wrapped file.
+func wrapPlaintextFileData(data []byte) []byte {
+	if !bytes.HasPrefix(data, []byte(fileEncryptionMagic)) {
+		return data
+	}
+
+	wrapped := make([]byte, 0, filePlaintextHeaderSize+len(data))
+	wrapped = append(wrapped, fileEncryptionMagic...)
+	wrapped = append(wrapped, filePlaintextVersion)
+	return append(wrapped, data...)
+}
+
+func wrapFileDataKey(masterKey []byte, dataKey []byte) ([]byte, error) {
+	gcm, err := newGCM(masterKey)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, fileNonceSize)
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	return gcm.Seal(nonce, nonce, dataKey, []byte(fileEncryptionMagic)), nil
+}
+
+// unwrapFileDataKey finds the master key the data key was wrapped with and
+// uses it to unwrap the data key.
+func unwrapFileDataKey(masterKeys [][]byte, data []byte) ([]byte, error) {
+	offset := len(fileEncryptionMagic) + 1
+	keyId := data[offset : offset+fileKeyIdSize]
+	wrapped := data[offset+fileKeyIdSize : offset+fileKeyIdSize+fileWrappedKeySize]
+
+	for _, masterKey := range masterKeys {
+		if !bytes.Equal(fileKeyId(masterKey), keyId) {
+			continue
+		}
+
+		gcm, err := newGCM(masterKey)
+		if err != nil {
+			return nil, err
+		}
+
+		return gcm.Open(nil, wrapped[:fileNonceSize], wrapped[fileNonceSize:], []byte(fileEncryptionMagic))
+	}
+
+	return nil, errors.New("file was encrypted with an unknown key")
+}
+
+func encryptFileData(masterKey []byte, data []byte) ([]byte, error) {
+	dataKey := make([]byte, fileDataKeySize)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, err
+	}
+
+	wrapped, err := wrapFileDataKey(masterKey, dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, fileNonceSize)
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, 0, fileHeaderSize)
+	header = append(header, fileEncryptionMagic...)
+	header = append(header, fileEncryptionVersion)
+	header = append(header, fileKeyId(masterKey)...)
+	header = append(header, wrapped...)
+	header = append(header, nonce...)
+
+	return gcm.Seal(header, nonce, data, nil), nil
+}
+
+func decryptFileData(masterKeys [][]byte, data []byte) ([]byte, error) {
+	dataKey, err := unwrapFileDataKey(masterKeys, data)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := data[fileHeaderSize-fileNonceSize : fileHeaderSize]
+	return gcm.Open(nil, nonce, data[fileHeaderSize:], nil)
+}
+
+// rewrapFileData wraps the data key of an encrypted file with a new master
+// key, leaving the encrypted contents untouched.
+func rewrapFileData(masterKeys [][]byte, newMasterKey []byte, data []byte) ([]byte, error) {
+	dataKey, err := unwrapFileDataKey(masterKeys, data)
+	if err != nil {
+		return nil, err
+	}
+
+	wrapped, err := wrapFileDataKey(newMasterKey, dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	rewrapped := make([]byte, len(data))
+	copy(rewrapped, data)
+
+	offset := len(fileEncryptionMagic) + 1
+	copy(rewrapped[offset:], fileKeyId(newMasterKey))
+	copy(rewrapped[offset+fileKeyIdSize:], wrapped)
+
+	return rewrapped, nil
+}
+
+func fileKeyIdMatches(masterKey []byte, data []byte) bool {
+	offset := len(fileEncryptionMagic) + 1
+	return bytes.Equal(fileKeyId(masterKey), data[offset:offset+fileKeyIdSize])
+}
+
+// ReencryptFiles brings every stored file in line with the current file
+// encryption settings. Files encrypted with the previous key or one of the
+// old master keys get their data key wrapped with the current one, plaintext
+// files are encrypted and, if encryption has been disabled, encrypted files
+// are decrypted. Files that can't be rewritten are logged and skipped so that
+// the rest still get done. It returns the number of files that were rewritten
+// and an error if any were skipped.
+func ReencryptFiles(oldMasterKeys [][]byte) (int, *model.AppError) {
+	masterKey, err := GetFileEncryptionKey()
+	if err != nil {
+		return 0, err
+	}
+
+	enabled := *utils.Cfg.FileSettings.EnableEncryption
+	if enabled && masterKey == nil {
+		return 0, model.NewLocAppError("ReencryptFiles", "app.file.encryption_key.missing.app_error", nil, "")
+	}
+
+	keys, err := GetFileEncryptionKeys()
+	if err != nil {
+		return 0, err
+	}
+	keys = append(keys, oldMasterKeys...)
+
+	paths, err := ListFiles()
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	failed := 0
+	for _, path := range paths {
+		if strings.HasSuffix(path, fileReencryptTempSuffix) || !isReencryptableFilePath(path) {
+			continue
+		}
+
+		if rewritten, err := reencryptFile(path, enabled, masterKey, keys); err != nil {
+			l4g.Error(utils.T("app.file.reencrypt.file.error"), path, err.Error())
+			failed++
+		} else if rewritten {
+			count++
+		}
+	}
+
+	if failed > 0 {
+		return count, model.NewLocAppError("ReencryptFiles", "app.file.reencrypt.failed.app_error", map[string]interface{}{"Count": failed}, "")
+	}
+
+	return count, nil
+}
+
+// isReencryptableFilePath returns true for the files that the server stores with WriteFile. Anything else in the
+// file store, like compliance exports, is read directly and has to be left as it is.
+func isReencryptableFilePath(path string) bool {
+	for _, prefix := range reencryptableFilePrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Rewrites a single file for ReencryptFiles, returning false if it was
+// already stored the way the settings ask for.
+func reencryptFile(path string, enabled bool, masterKey []byte, keys [][]byte) (bool, *model.AppError) {
+	data, err := readFileRaw(path)
+	if err != nil {
+		return false, err
+	}
+
+	var rewritten []byte
+	var cryptErr error
+	if isEncryptedFileData(data) {
+		if !enabled {
+			if rewritten, cryptErr = decryptFileData(keys, data); cryptErr == nil {
+				rewritten = wrapPlaintextFileData(rewritten)
+			}
+		} else if !fileKeyIdMatches(masterKey, data) {
+			rewritten, cryptErr = rewrapFileData(keys, masterKey, data)
+		} else {
+			return false, nil
+		}
+	} else if enabled {
+		if isWrappedPlaintextFileData(data) {
+			data = data[filePlaintextHeaderSize:]
+		}
+		rewritten, cryptErr = encryptFileData(masterKey, data)
+	} else {
+		return false, nil
+	}
+
+	if cryptErr != nil {
+		return false, model.NewLocAppError("ReencryptFiles", "app.file.reencrypt.app_error", nil, "path="+path+", err="+cryptErr.Error())
+	}
+
+	if err := replaceFileRaw(rewritten, path); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Overwrites a stored file without leaving it half written if the server
+// stops part way through. Objects in S3 are always replaced as a whole, while
+// local files are written next to the original and then renamed over it.
+func replaceFileRaw(f []byte, path string) *model.AppError {
+	if utils.Cfg.FileSettings.DriverName != model.IMAGE_DRIVER_LOCAL {
+		return writeFileRaw(f, path)
+	}
+
+	fullPath := utils.Cfg.FileSettings.Directory + path
+	tempPath := fullPath + fileReencryptTempSuffix
+
+	if err := writeFileLocally(f, tempPath); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tempPath, fullPath); err != nil {
+		os.Remove(tempPath)
+		return model.NewLocAppError("WriteFile", "api.file.write_file_locally.writing.app_error", nil, err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,240 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func TestFileDataEncryption(t *testing.T) {
+	key := FileEncryptionKey(model.NewRandomString(32))
+	otherKey := FileEncryptionKey(model.NewRandomString(32))
+	data := []byte("the quick brown fox jumps over the lazy dog")
+
+	encrypted, err := encryptFileData(key, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !isEncryptedFileData(encrypted) || bytes.Contains(encrypted, data) {
+		t.Fatal("data should be encrypted")
+	}
+
+	if isEncryptedFileData(data) {
+		t.Fatal("plaintext shouldn't look encrypted")
+	}
+
+	if decrypted, err := decryptFileData([][]byte{otherKey, key}, encrypted); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(decrypted, data) {
+		t.Fatal("should have decrypted the data")
+	}
+
+	if _, err := decryptFileData([][]byte{otherKey}, encrypted); err == nil {
+		t.Fatal("should have failed with the wrong key")
+	}
+
+	tampered := make([]byte, len(encrypted))
+	copy(tampered, encrypted)
+	tampered[len(tampered)-1] ^= 1
+	if _, err := decryptFileData([][]byte{key}, tampered); err == nil {
+		t.Fatal("should have failed with tampered data")
+	}
+
+	rewrapped, err := rewrapFileData([][]byte{key}, otherKey, encrypted)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(rewrapped[fileHeaderSize:], encrypted[fileHeaderSize:]) {
+		t.Fatal("rewrapping shouldn't change the encrypted contents")
+	}
+
+	if decrypted, err := decryptFileData([][]byte{otherKey}, rewrapped); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(decrypted, data) {
+		t.Fatal("should have decrypted the rewrapped data")
+	}
+
+	if _, err := decryptFileData([][]byte{key}, rewrapped); err == nil {
+		t.Fatal("old key shouldn't work after rewrapping")
+	}
+}
+
+func TestReencryptFiles(t *testing.T) {
+	utils.TranslationsPreInit()
+	utils.LoadConfig("config.json")
+
+	directory, err := ioutil.TempDir("", "files")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(directory)
+
+	oldSettings := utils.Cfg.FileSettings
+	defer func() {
+		utils.Cfg.FileSettings = oldSettings
+	}()
+
+	utils.Cfg.FileSettings.DriverName = model.IMAGE_DRIVER_LOCAL
+	utils.Cfg.FileSettings.Directory = directory + "/"
+	utils.Cfg.FileSettings.EnableEncryption = new(bool)
+	utils.Cfg.FileSettings.EncryptionKey = new(string)
+	utils.Cfg.FileSettings.EncryptionKeyFile = new(string)
+	utils.Cfg.FileSettings.PreviousEncryptionKey = new(string)
+	utils.Cfg.FileSettings.PreviousEncryptionKeyFile = new(string)
+
+	data := []byte("hello world, this is a stored file")
+	if err := WriteFile(data, "teams/plain/file.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	// compliance exports are kept in the same directory by default but are read without going through ReadFile
+	if err := os.MkdirAll(filepath.Join(directory, "compliance"), 0750); err != nil {
+		t.Fatal(err)
+	} else if err := ioutil.WriteFile(filepath.Join(directory, "compliance/export.zip"), data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// an unencrypted upload that happens to start like an encrypted file
+	magic := append([]byte(fileEncryptionMagic), fileEncryptionVersion)
+	magic = append(magic, bytes.Repeat([]byte("x"), fileHeaderSize+fileGCMTagSize)...)
+	if err := WriteFile(magic, "teams/plain/magic.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	if read, err := ReadFile("teams/plain/magic.txt"); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(read, magic) {
+		t.Fatal("should read back an unencrypted file that starts like an encrypted one")
+	}
+
+	oldSecret := model.NewRandomString(32)
+	*utils.Cfg.FileSettings.EnableEncryption = true
+	*utils.Cfg.FileSettings.EncryptionKey = oldSecret
+	if err := WriteFile(data, "teams/encrypted/file.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	if stored, _ := ioutil.ReadFile(filepath.Join(directory, "teams/encrypted/file.txt")); !isEncryptedFileData(stored) {
+		t.Fatal("file should be encrypted on disk")
+	}
+
+	for _, path := range []string{"teams/plain/file.txt", "teams/encrypted/file.txt"} {
+		if read, err := ReadFile(path); err != nil {
+			t.Fatal(err)
+		} else if !bytes.Equal(read, data) {
+			t.Fatal("should read the original contents", path)
+		}
+	}
+
+	// rotate the key using a key file
+	keyFile := filepath.Join(directory, "..", model.NewId()+".key")
+	if err := ioutil.WriteFile(keyFile, []byte(model.NewRandomString(32)+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(keyFile)
+
+	*utils.Cfg.FileSettings.EncryptionKey = ""
+	*utils.Cfg.FileSettings.EncryptionKeyFile = keyFile
+
+	if _, err := ReadFile("teams/encrypted/file.txt"); err == nil {
+		t.Fatal("shouldn't be able to read the file before it is re-encrypted")
+	}
+
+	if count, err := ReencryptFiles(nil); err == nil {
+		t.Fatal("should have failed without the old key")
+	} else if count != 2 {
+		t.Fatal("should have kept encrypting the other files", count)
+	}
+
+	*utils.Cfg.FileSettings.PreviousEncryptionKey = oldSecret
+
+	if read, err := ReadFile("teams/encrypted/file.txt"); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(read, data) {
+		t.Fatal("should read the file with the previous key")
+	}
+
+	if count, err := ReencryptFiles(nil); err != nil {
+		t.Fatal(err)
+	} else if count != 1 {
+		t.Fatal("should have rewritten the file using the previous key", count)
+	}
+
+	if stored, _ := ioutil.ReadFile(filepath.Join(directory, "compliance/export.zip")); !bytes.Equal(stored, data) {
+		t.Fatal("shouldn't have encrypted the compliance export")
+	}
+
+	*utils.Cfg.FileSettings.PreviousEncryptionKey = ""
+
+	for _, path := range []string{"teams/plain/file.txt", "teams/plain/magic.txt", "teams/encrypted/file.txt"} {
+		if stored, _ := ioutil.ReadFile(filepath.Join(directory, path)); !isEncryptedFileData(stored) {
+			t.Fatal("file should be encrypted on disk", path)
+		}
+
+		if read, err := ReadFile(path); err != nil {
+			t.Fatal(err)
+		} else if path != "teams/plain/magic.txt" && !bytes.Equal(read, data) {
+			t.Fatal("should read the original contents", path)
+		}
+	}
+
+	if read, _ := ReadFile("teams/plain/magic.txt"); !bytes.Equal(read, magic) {
+		t.Fatal("should read the original contents of the encrypted file")
+	}
+
+	if count, err := ReencryptFiles(nil); err != nil {
+		t.Fatal(err)
+	} else if count != 0 {
+		t.Fatal("shouldn't rewrite files that use the current key", count)
+	}
+
+	// a file encrypted with a key that's been lost shouldn't stop the others from being decrypted
+	if err := os.MkdirAll(filepath.Join(directory, "users/legacy"), 0750); err != nil {
+		t.Fatal(err)
+	} else if err := ioutil.WriteFile(filepath.Join(directory, "users/legacy/file.txt"), magic, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	*utils.Cfg.FileSettings.EnableEncryption = false
+	if count, err := ReencryptFiles(nil); err == nil {
+		t.Fatal("should have failed to decrypt the file with an unknown key")
+	} else if count != 3 {
+		t.Fatal("should have decrypted the other files", count)
+	}
+
+	if stored, _ := ioutil.ReadFile(filepath.Join(directory, "teams/encrypted/file.txt")); !bytes.Equal(stored, data) {
+		t.Fatal("file should be decrypted on disk")
+	}
+
+	if stored, _ := ioutil.ReadFile(filepath.Join(directory, "users/legacy/file.txt")); !bytes.Equal(stored, magic) {
+		t.Fatal("shouldn't have changed a file that couldn't be decrypted")
+	}
+
+	if read, err := ReadFile("teams/plain/magic.txt"); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(read, magic) {
+		t.Fatal("should read the original contents of the decrypted file")
+	}
+
+	if stored, _ := ioutil.ReadFile(filepath.Join(directory, "compliance/export.zip")); !bytes.Equal(stored, data) {
+		t.Fatal("shouldn't have decrypted the compliance export")
+	}
+
+	filepath.Walk(directory, func(path string, info os.FileInfo, err error) error {
+		if strings.HasSuffix(path, fileReencryptTempSuffix) {
+			t.Fatal("shouldn't have left a temporary file behind", path)
+		}
+		return nil
+	})
+}
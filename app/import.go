@@ -57,6 +57,7 @@ type UserImportData struct {
 	Position    *string `json:"position"`
 	Roles       *string `json:"roles"`
 	Locale      *string `json:"locale"`
+	Timezone    *string `json:"timezone"`
 
 	Teams *[]UserTeamImportData `json:"teams"`
 
@@ -401,6 +402,13 @@ func ImportUser(data *UserImportData, dryRun bool) *model.AppError {
 		user.Locale = *utils.Cfg.LocalizationSettings.DefaultClientLocale
 	}
 
+	if data.Timezone != nil {
+		// An imported timezone is treated as one the user chose manually.
+		user.Timezone = model.DefaultUserTimezone()
+		user.Timezone[model.USE_AUTOMATIC_TIMEZONE] = "false"
+		user.Timezone[model.MANUAL_TIMEZONE] = *data.Timezone
+	}
+
 	var roles string
 	if data.Roles != nil {
 		roles = *data.Roles
@@ -655,6 +663,10 @@ func validateUserImportData(data *UserImportData) *model.AppError {
 		return model.NewAppError("BulkImport", "app.import.validate_user_import_data.roles_invalid.error", nil, "", http.StatusBadRequest)
 	}
 
+	if data.Timezone != nil && !model.IsValidTimezone(*data.Timezone) {
+		return model.NewAppError("BulkImport", "app.import.validate_user_import_data.timezone_invalid.error", nil, "", http.StatusBadRequest)
+	}
+
 	if data.Teams != nil {
 		return validateUserTeamsImportData(data.Teams)
 	} else {
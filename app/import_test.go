@@ -366,6 +366,16 @@ func TestImportValidateUserImportData(t *testing.T) {
 		t.Fatal("Validation failed but should have been valid.")
 	}
 	data.Roles = ptrStr("system_user")
+
+	data.Timezone = ptrStr("Nowhere/Atlantis")
+	if err := validateUserImportData(&data); err == nil {
+		t.Fatal("Validation should have failed due to an unknown timezone.")
+	}
+
+	data.Timezone = ptrStr("America/Toronto")
+	if err := validateUserImportData(&data); err != nil {
+		t.Fatal("Validation failed but should have been valid.")
+	}
 }
 
 func TestImportValidateUserTeamsImportData(t *testing.T) {
@@ -14,7 +14,7 @@ import (
 	"github.com/mssola/user_agent"
 )
 
-func AuthenticateUserForLogin(id, loginId, password, mfaToken, deviceId string, ldapOnly bool) (*model.User, *model.AppError) {
+func AuthenticateUserForLogin(id, loginId, password, mfaToken, deviceId, ipAddress string, ldapOnly bool) (*model.User, *model.AppError) {
 	if len(password) == 0 {
 		err := model.NewLocAppError("AuthenticateUserForLogin", "api.user.login.blank_pwd.app_error", nil, "")
 		err.StatusCode = http.StatusBadRequest
@@ -25,30 +25,52 @@ func AuthenticateUserForLogin(id, loginId, password, mfaToken, deviceId string,
 	var err *model.AppError
 
 	if len(id) != 0 {
-		if user, err = GetUser(id); err != nil {
+		user, err = GetUser(id)
+	} else {
+		user, err = GetUserForLogin(loginId, ldapOnly)
+	}
+
+	// failures are counted against the user rather than the login ID so that switching between their username and
+	// email doesn't allow more attempts
+	throttleId := loginId
+	if len(id) != 0 {
+		throttleId = id
+	}
+	if user != nil {
+		throttleId = user.Id
+	}
+
+	if err := checkLoginThrottle(ipAddress, throttleId); err != nil {
+		if einterfaces.GetMetricsInterface() != nil {
+			einterfaces.GetMetricsInterface().IncrementLoginFail()
+		}
+		return nil, err
+	}
+
+	if err != nil {
+		if len(id) != 0 {
 			err.StatusCode = http.StatusBadRequest
-			if einterfaces.GetMetricsInterface() != nil {
-				einterfaces.GetMetricsInterface().IncrementLoginFail()
-			}
-			return nil, err
 		}
-	} else {
-		if user, err = GetUserForLogin(loginId, ldapOnly); err != nil {
-			if einterfaces.GetMetricsInterface() != nil {
-				einterfaces.GetMetricsInterface().IncrementLoginFail()
-			}
-			return nil, err
+		if einterfaces.GetMetricsInterface() != nil {
+			einterfaces.GetMetricsInterface().IncrementLoginFail()
 		}
+		recordLoginFailure(nil, ipAddress, throttleId)
+		return nil, err
 	}
 
 	// and then authenticate them
-	if user, err = authenticateUser(user, password, mfaToken); err != nil {
+	if authenticatedUser, err := authenticateUser(user, password, mfaToken); err != nil {
 		if einterfaces.GetMetricsInterface() != nil {
 			einterfaces.GetMetricsInterface().IncrementLoginFail()
 		}
+		recordLoginFailure(user, ipAddress, throttleId)
 		return nil, err
+	} else {
+		user = authenticatedUser
 	}
 
+	clearLoginThrottle(throttleId)
+
 	if einterfaces.GetMetricsInterface() != nil {
 		einterfaces.GetMetricsInterface().IncrementLogin()
 	}
@@ -97,6 +119,7 @@ func DoLogin(w http.ResponseWriter, r *http.Request, user *model.User, deviceId
 	session.AddProp(model.SESSION_PROP_PLATFORM, plat)
 	session.AddProp(model.SESSION_PROP_OS, os)
 	session.AddProp(model.SESSION_PROP_BROWSER, fmt.Sprintf("%v/%v", bname, bversion))
+	csrfToken := session.GenerateCSRF()
 
 	var err *model.AppError
 	if session, err = CreateSession(session); err != nil {
@@ -123,10 +146,27 @@ func DoLogin(w http.ResponseWriter, r *http.Request, user *model.User, deviceId
 	}
 
 	http.SetCookie(w, sessionCookie)
+	setCSRFCookie(w, r, csrfToken, maxAge, expiresAt)
 
 	return session, nil
 }
 
+// setCSRFCookie sends a session's CSRF token to the client. The CSRF cookie is
+// readable by scripts so the webapp can echo it back in the X-CSRF-Token
+// header, which another site can't do.
+func setCSRFCookie(w http.ResponseWriter, r *http.Request, csrfToken string, maxAge int, expiresAt time.Time) {
+	w.Header().Set(model.HEADER_CSRF_TOKEN, csrfToken)
+
+	http.SetCookie(w, &http.Cookie{
+		Name:    model.SESSION_COOKIE_CSRF,
+		Value:   csrfToken,
+		Path:    "/",
+		MaxAge:  maxAge,
+		Expires: expiresAt,
+		Secure:  GetProtocol(r) == "https",
+	})
+}
+
 func GetProtocol(r *http.Request) string {
 	if r.Header.Get(model.HEADER_FORWARDED_PROTO) == "https" {
 		return "https"
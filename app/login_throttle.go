@@ -0,0 +1,133 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+	"time"
+
+	l4g "github.com/alecthomas/log4go"
+
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+const (
+	LOGIN_ATTEMPT_CLEANUP_TASK_NAME = "Login Attempt Cleanup"
+	LOGIN_ATTEMPT_CLEANUP_INTERVAL  = time.Hour
+)
+
+var loginAttemptCleanupTask *model.ScheduledTask
+
+// StartLoginAttemptCleanup starts periodically forgetting failed logins that are older than the lockout cooldown.
+func StartLoginAttemptCleanup() {
+	loginAttemptCleanupTask = model.CreateRecurringTask(LOGIN_ATTEMPT_CLEANUP_TASK_NAME, func() {
+		if result := <-Srv.Store.LoginAttempt().DeleteOlderThan(model.GetMillis() - loginLockoutCooldown()); result.Err != nil {
+			l4g.Error(result.Err.Error())
+		}
+	}, LOGIN_ATTEMPT_CLEANUP_INTERVAL)
+}
+
+func StopLoginAttemptCleanup() {
+	if loginAttemptCleanupTask != nil {
+		loginAttemptCleanupTask.Cancel()
+		loginAttemptCleanupTask = nil
+	}
+}
+
+// GetLoginThrottleIpAddress returns the IP address that failed logins from a request are counted against. Forwarding
+// headers are only used if the request came through one of the configured trusted proxies since anyone can set them.
+// Loopback and private addresses are trusted by default so that a reverse proxy doesn't get every client locked out.
+func GetLoginThrottleIpAddress(r *http.Request) string {
+	return utils.GetClientIpAddress(r, *utils.Cfg.ServiceSettings.LoginThrottleTrustedProxies)
+}
+
+func loginLockoutCooldown() int64 {
+	return int64(*utils.Cfg.ServiceSettings.LoginLockoutCooldownInMinutes) * 60 * 1000
+}
+
+// checkLoginThrottle returns an error if there have been too many recent failed logins from the IP address or for the
+// login ID and the client needs to wait before trying again.
+func checkLoginThrottle(ipAddress, loginId string) *model.AppError {
+	if !*utils.Cfg.ServiceSettings.EnableLoginThrottling {
+		return nil
+	}
+
+	retryAt := int64(0)
+
+	if ipAddress != "" {
+		if result := <-Srv.Store.LoginAttempt().Get(model.LoginAttemptIdForIpAddress(ipAddress)); result.Err == nil {
+			attempt := result.Data.(*model.LoginAttempt)
+			retryAt = attempt.RetryAt(*utils.Cfg.ServiceSettings.LoginThrottleAttemptsPerIp, loginLockoutCooldown())
+		}
+	}
+
+	if result := <-Srv.Store.LoginAttempt().Get(model.LoginAttemptIdForLoginId(loginId)); result.Err == nil {
+		attempt := result.Data.(*model.LoginAttempt)
+		if loginRetryAt := attempt.RetryAt(*utils.Cfg.ServiceSettings.LoginThrottleAttemptsPerLoginId, loginLockoutCooldown()); loginRetryAt > retryAt {
+			retryAt = loginRetryAt
+		}
+	}
+
+	if wait := retryAt - model.GetMillis(); wait > 0 {
+		seconds := (wait + 999) / 1000
+		return model.NewAppError("checkLoginThrottle", "api.user.login.throttled.app_error", map[string]interface{}{"Seconds": seconds}, "ip_address="+ipAddress+", login_id="+loginId, http.StatusTooManyRequests)
+	}
+
+	return nil
+}
+
+// recordLoginFailure counts a failed login against the IP address and the login ID. The user, if one was found for
+// the login ID, is emailed once their login ID starts being throttled.
+func recordLoginFailure(user *model.User, ipAddress, loginId string) {
+	if !*utils.Cfg.ServiceSettings.EnableLoginThrottling {
+		return
+	}
+
+	now := model.GetMillis()
+	resetBefore := now - loginLockoutCooldown()
+
+	if ipAddress != "" {
+		if result := <-Srv.Store.LoginAttempt().RecordFailure(model.LoginAttemptIdForIpAddress(ipAddress), now, resetBefore); result.Err != nil {
+			l4g.Error(result.Err.Error())
+		}
+	}
+
+	if result := <-Srv.Store.LoginAttempt().RecordFailure(model.LoginAttemptIdForLoginId(loginId), now, resetBefore); result.Err != nil {
+		l4g.Error(result.Err.Error())
+	} else if attempt := result.Data.(*model.LoginAttempt); user != nil && attempt.FailedAttempts == *utils.Cfg.ServiceSettings.LoginThrottleAttemptsPerLoginId {
+		go func() {
+			if err := SendLoginFailuresEmail(user.Email, ipAddress, attempt.FailedAttempts, user.Locale, utils.GetSiteURL()); err != nil {
+				l4g.Error(err.Error())
+			}
+		}()
+	}
+}
+
+// clearLoginThrottle forgets the failed logins for the login ID after a successful login. Failures from the IP address
+// are kept since otherwise logging into one account would reset the attempts left for guessing the passwords of others.
+func clearLoginThrottle(loginId string) {
+	if result := <-Srv.Store.LoginAttempt().Delete(model.LoginAttemptIdForLoginId(loginId)); result.Err != nil {
+		l4g.Error(result.Err.Error())
+	}
+}
+
+// recordUserPasswordFailure remembers when the user last entered the wrong password so that their account can be
+// unlocked once the lockout cooldown has passed.
+func recordUserPasswordFailure(userId string) {
+	now := model.GetMillis()
+	if result := <-Srv.Store.LoginAttempt().RecordFailure(model.LoginAttemptIdForUser(userId), now, now-loginLockoutCooldown()); result.Err != nil {
+		l4g.Error(result.Err.Error())
+	}
+}
+
+// isUserLockoutExpired returns true if the user's last wrong password was entered longer ago than the lockout cooldown.
+func isUserLockoutExpired(userId string) bool {
+	if result := <-Srv.Store.LoginAttempt().Get(model.LoginAttemptIdForUser(userId)); result.Err != nil {
+		// accounts locked out before failures were being recorded
+		return result.Err.StatusCode == http.StatusNotFound
+	} else {
+		return model.GetMillis()-result.Data.(*model.LoginAttempt).LastFailedAt > loginLockoutCooldown()
+	}
+}
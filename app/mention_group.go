@@ -0,0 +1,160 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+const (
+	MENTION_GROUP_AUTOCOMPLETE_LIMIT = 10
+)
+
+func CreateMentionGroup(group *model.MentionGroup) (*model.MentionGroup, *model.AppError) {
+	if IsUsernameTaken(group.Name) {
+		return nil, model.NewAppError("CreateMentionGroup", "app.mention_group.name_taken_by_user.app_error", nil, "name="+group.Name, http.StatusBadRequest)
+	}
+
+	if _, err := GetTeam(group.TeamId); err != nil {
+		return nil, err
+	}
+
+	if result := <-Srv.Store.MentionGroup().Save(group); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.MentionGroup), nil
+	}
+}
+
+func GetMentionGroup(groupId string) (*model.MentionGroup, *model.AppError) {
+	if result := <-Srv.Store.MentionGroup().Get(groupId); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.MentionGroup), nil
+	}
+}
+
+func GetMentionGroupsForTeam(teamId string) ([]*model.MentionGroup, *model.AppError) {
+	if result := <-Srv.Store.MentionGroup().GetForTeam(teamId); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.([]*model.MentionGroup), nil
+	}
+}
+
+func SearchMentionGroups(teamId string, term string) ([]*model.MentionGroup, *model.AppError) {
+	if result := <-Srv.Store.MentionGroup().Search(teamId, term, MENTION_GROUP_AUTOCOMPLETE_LIMIT); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.([]*model.MentionGroup), nil
+	}
+}
+
+func PatchMentionGroup(group *model.MentionGroup, patch *model.MentionGroupPatch) (*model.MentionGroup, *model.AppError) {
+	oldName := group.Name
+	group.Patch(patch)
+
+	if group.Name != oldName && IsUsernameTaken(group.Name) {
+		return nil, model.NewAppError("PatchMentionGroup", "app.mention_group.name_taken_by_user.app_error", nil, "name="+group.Name, http.StatusBadRequest)
+	}
+
+	if result := <-Srv.Store.MentionGroup().Update(group); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.MentionGroup), nil
+	}
+}
+
+func DeleteMentionGroup(groupId string) *model.AppError {
+	if result := <-Srv.Store.MentionGroup().Delete(groupId, model.GetMillis()); result.Err != nil {
+		return result.Err
+	}
+
+	return nil
+}
+
+func GetMentionGroupMembersPage(groupId string, page int, perPage int) ([]*model.MentionGroupMember, *model.AppError) {
+	if result := <-Srv.Store.MentionGroup().GetMembers(groupId, page*perPage, perPage); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.([]*model.MentionGroupMember), nil
+	}
+}
+
+func AddMentionGroupMember(group *model.MentionGroup, userId string) (*model.MentionGroupMember, *model.AppError) {
+	// Only members of the group's team can be mentioned through it
+	if _, err := GetTeamMember(group.TeamId, userId); err != nil {
+		return nil, model.NewAppError("AddMentionGroupMember", "app.mention_group.add_member.not_on_team.app_error", nil, "user_id="+userId+", "+err.Error(), http.StatusBadRequest)
+	}
+
+	member := &model.MentionGroupMember{
+		GroupId: group.Id,
+		UserId:  userId,
+	}
+
+	if result := <-Srv.Store.MentionGroup().SaveMember(member); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.MentionGroupMember), nil
+	}
+}
+
+func RemoveMentionGroupMember(groupId string, userId string) *model.AppError {
+	if result := <-Srv.Store.MentionGroup().RemoveMember(groupId, userId); result.Err != nil {
+		return result.Err
+	}
+
+	return nil
+}
+
+// Given a team and a map of user IDs to profiles for a channel on it, returns a map of mention keywords
+// for the team's mention groups to the members of each group who are in the channel. Groups with more
+// members in the channel than MaxNotificationsPerChannel are left out and their names returned instead.
+func GetMentionGroupKeywordsInChannel(teamId string, profiles map[string]*model.User) (map[string][]string, []string, *model.AppError) {
+	gchan := Srv.Store.MentionGroup().GetForTeam(teamId)
+	mchan := Srv.Store.MentionGroup().GetMembersForTeam(teamId)
+
+	var groups []*model.MentionGroup
+	if result := <-gchan; result.Err != nil {
+		return nil, nil, result.Err
+	} else {
+		groups = result.Data.([]*model.MentionGroup)
+	}
+
+	var members []*model.MentionGroupMember
+	if result := <-mchan; result.Err != nil {
+		return nil, nil, result.Err
+	} else {
+		members = result.Data.([]*model.MentionGroupMember)
+	}
+
+	membersInChannel := make(map[string][]string)
+	for _, member := range members {
+		if _, ok := profiles[member.UserId]; ok {
+			membersInChannel[member.GroupId] = append(membersInChannel[member.GroupId], member.UserId)
+		}
+	}
+
+	keywords := make(map[string][]string)
+	disabled := []string{}
+
+	for _, group := range groups {
+		ids := membersInChannel[group.Id]
+		if len(ids) == 0 {
+			continue
+		}
+
+		if int64(len(ids)) > *utils.Cfg.TeamSettings.MaxNotificationsPerChannel {
+			disabled = append(disabled, group.Name)
+			continue
+		}
+
+		keywords[group.MentionKeyword()] = ids
+	}
+
+	return keywords, disabled, nil
+}
@@ -0,0 +1,201 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dgryski/dgoogauth"
+	"github.com/mattermost/platform/einterfaces"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+	"github.com/mattermost/rsc/qr"
+)
+
+const (
+	MFA_SECRET_SIZE = 20 // 160 bits as recommended by RFC 4226
+	MFA_TOKEN_STEP  = 30 // seconds
+	MFA_TOKEN_SKEW  = 1  // steps accepted on either side of the current one to allow for clock drift
+)
+
+// totpMfa is the built-in implementation of MFA using RFC 6238 time-based one-time passwords, which is used unless
+// another implementation has been registered.
+type totpMfa struct{}
+
+func getMfaInterface() einterfaces.MfaInterface {
+	if mfaInterface := einterfaces.GetMfaInterface(); mfaInterface != nil {
+		return mfaInterface
+	}
+
+	return totpMfa{}
+}
+
+func (m totpMfa) GenerateSecret(user *model.User) (string, []byte, *model.AppError) {
+	// replacing the secret of a working authenticator would let anyone with a session take over the second factor
+	if user.MfaActive {
+		return "", nil, model.NewAppError("GenerateSecret", "app.mfa.already_active.app_error", nil, "user_id="+user.Id, http.StatusBadRequest)
+	}
+
+	b := make([]byte, MFA_SECRET_SIZE)
+	if _, err := rand.Read(b); err != nil {
+		return "", nil, model.NewAppError("GenerateSecret", "app.mfa.generate_secret.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	secret := base32.StdEncoding.EncodeToString(b)
+
+	issuer := utils.Cfg.TeamSettings.SiteName
+	if issuer == "" {
+		issuer = "Mattermost"
+	}
+
+	// the issuer is repeated in the label for authenticator apps that don't support the issuer parameter
+	label := url.PathEscape(issuer) + ":" + url.PathEscape(user.Email)
+	query := url.Values{"secret": {secret}, "issuer": {issuer}}
+
+	code, err := qr.Encode("otpauth://totp/"+label+"?"+query.Encode(), qr.M)
+	if err != nil {
+		return "", nil, model.NewAppError("GenerateSecret", "app.mfa.generate_secret.qr_code.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	if result := <-Srv.Store.User().UpdateMfaSecret(user.Id, secret); result.Err != nil {
+		return "", nil, result.Err
+	}
+
+	return secret, code.PNG(), nil
+}
+
+func (m totpMfa) Activate(user *model.User, token string) *model.AppError {
+	if user.MfaActive {
+		return model.NewAppError("Activate", "app.mfa.already_active.app_error", nil, "user_id="+user.Id, http.StatusBadRequest)
+	}
+
+	if user.MfaSecret == "" {
+		return model.NewAppError("Activate", "app.mfa.activate.no_secret.app_error", nil, "user_id="+user.Id, http.StatusBadRequest)
+	}
+
+	if ok, err := m.ValidateToken(user.MfaSecret, token); err != nil {
+		return err
+	} else if !ok {
+		return model.NewAppError("Activate", "app.mfa.activate.bad_token.app_error", nil, "user_id="+user.Id, http.StatusUnauthorized)
+	}
+
+	if result := <-Srv.Store.User().UpdateMfaActive(user.Id, true); result.Err != nil {
+		return result.Err
+	}
+
+	return nil
+}
+
+func (m totpMfa) Deactivate(userId string) *model.AppError {
+	achan := Srv.Store.User().UpdateMfaActive(userId, false)
+	schan := Srv.Store.User().UpdateMfaSecret(userId, "")
+
+	if result := <-achan; result.Err != nil {
+		return result.Err
+	}
+
+	if result := <-schan; result.Err != nil {
+		return result.Err
+	}
+
+	return nil
+}
+
+func (m totpMfa) ValidateToken(secret, token string) (bool, *model.AppError) {
+	return validateTotpToken(secret, token, time.Now()), nil
+}
+
+// Checks a token against the codes for the steps around the given time, with the number of steps on either side
+// given by MFA_TOKEN_SKEW.
+func validateTotpToken(secret string, token string, now time.Time) bool {
+	token = strings.TrimSpace(token)
+	if len(token) != 6 {
+		return false
+	}
+
+	code, err := strconv.Atoi(token)
+	if err != nil {
+		return false
+	}
+
+	step := now.Unix() / MFA_TOKEN_STEP
+	for i := int64(-MFA_TOKEN_SKEW); i <= MFA_TOKEN_SKEW; i++ {
+		if dgoogauth.ComputeCode(secret, step+i) == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Records that a user has used an MFA token so that it can't be replayed while it's still valid.
+func markMfaTokenUsed(userId string, token string) *model.AppError {
+	now := model.GetMillis()
+
+	if result := <-Srv.Store.Mfa().SaveUsedToken(userId, strings.TrimSpace(token), now, now-model.MFA_TOKEN_WINDOW); result.Err != nil {
+		return result.Err
+	}
+
+	return nil
+}
+
+// GenerateMfaRecoveryCodes replaces a user's MFA recovery codes with new ones, returning them so that they can be
+// shown to the user once. A current token from the user's authenticator is needed so that a session alone isn't
+// enough to get a new way past the second factor.
+func GenerateMfaRecoveryCodes(userId string, token string) ([]string, *model.AppError) {
+	user, err := GetUser(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.MfaActive {
+		return nil, model.NewAppError("GenerateMfaRecoveryCodes", "app.mfa.generate_recovery_codes.not_active.app_error", nil, "user_id="+userId, http.StatusBadRequest)
+	}
+
+	if ok, err := getMfaInterface().ValidateToken(user.MfaSecret, token); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, model.NewAppError("GenerateMfaRecoveryCodes", "app.mfa.generate_recovery_codes.bad_token.app_error", nil, "user_id="+userId, http.StatusBadRequest)
+	}
+
+	if err := markMfaTokenUsed(userId, token); err != nil {
+		return nil, model.NewAppError("GenerateMfaRecoveryCodes", "app.mfa.generate_recovery_codes.bad_token.app_error", nil, err.Error(), http.StatusBadRequest)
+	}
+
+	codes := make([]string, model.MFA_RECOVERY_CODE_COUNT)
+	hashes := make([]string, model.MFA_RECOVERY_CODE_COUNT)
+	for i := range codes {
+		codes[i] = model.NewMfaRecoveryCode()
+		hashes[i] = model.HashMfaRecoveryCode(codes[i])
+	}
+
+	if result := <-Srv.Store.Mfa().SaveRecoveryCodes(userId, hashes); result.Err != nil {
+		return nil, result.Err
+	}
+
+	return codes, nil
+}
+
+func GetMfaRecoveryCodeCount(userId string) (int64, *model.AppError) {
+	if result := <-Srv.Store.Mfa().GetRecoveryCodeCount(userId); result.Err != nil {
+		return 0, result.Err
+	} else {
+		return result.Data.(int64), nil
+	}
+}
+
+// Uses up one of a user's recovery codes, returning false if they don't have it.
+func useMfaRecoveryCode(userId string, code string) (bool, *model.AppError) {
+	if result := <-Srv.Store.Mfa().UseRecoveryCode(userId, model.HashMfaRecoveryCode(code)); result.Err != nil {
+		return false, result.Err
+	} else {
+		return result.Data.(bool), nil
+	}
+}
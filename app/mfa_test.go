@@ -0,0 +1,55 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func TestValidateTotpToken(t *testing.T) {
+	// test vectors from RFC 6238 using the last 6 digits of each code
+	secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+	if !validateTotpToken(secret, "287082", time.Unix(59, 0)) {
+		t.Fatal("should have accepted the token")
+	}
+
+	if !validateTotpToken(secret, " 081804 ", time.Unix(1111111109, 0)) {
+		t.Fatal("should have accepted the token with surrounding spaces")
+	}
+
+	if !validateTotpToken(secret, "081804", time.Unix(1111111109+MFA_TOKEN_STEP, 0)) {
+		t.Fatal("should have accepted the token from the previous step")
+	}
+
+	if validateTotpToken(secret, "081804", time.Unix(1111111109+3*MFA_TOKEN_STEP, 0)) {
+		t.Fatal("shouldn't have accepted an old token")
+	}
+
+	if validateTotpToken(secret, "081805", time.Unix(1111111109, 0)) {
+		t.Fatal("shouldn't have accepted the wrong token")
+	}
+
+	if validateTotpToken(secret, "", time.Unix(1111111109, 0)) || validateTotpToken(secret, "08180a", time.Unix(1111111109, 0)) {
+		t.Fatal("shouldn't have accepted an invalid token")
+	}
+}
+
+func TestTotpMfaAlreadyActive(t *testing.T) {
+	utils.TranslationsPreInit()
+
+	user := &model.User{Id: model.NewId(), Email: "test@example.com", MfaActive: true, MfaSecret: "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"}
+
+	if _, _, err := (totpMfa{}).GenerateSecret(user); err == nil || err.Id != "app.mfa.already_active.app_error" {
+		t.Fatal("shouldn't replace the secret of an active authenticator", err)
+	}
+
+	if err := (totpMfa{}).Activate(user, "287082"); err == nil || err.Id != "app.mfa.already_active.app_error" {
+		t.Fatal("shouldn't activate mfa twice", err)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"net/http"
 	"net/url"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strings"
 	"time"
@@ -53,6 +54,7 @@ func SendNotifications(post *model.Post, team *model.Team, channel *model.Channe
 	channelNotification := false
 	allNotification := false
 	updateMentionChans := []store.StoreChannel{}
+	explicitMentionIds := []string{}
 
 	if channel.Type == model.CHANNEL_DIRECT {
 		var otherUserId string
@@ -67,11 +69,32 @@ func SendNotifications(post *model.Post, team *model.Team, channel *model.Channe
 			mentionedUserIds[post.UserId] = true
 		}
 	} else {
-		keywords := GetMentionKeywordsInChannel(profileMap)
+		keywords := GetMentionKeywordsInChannel(profileMap, channelMemberNotifyPropsMap)
+
+		disabledMentionGroups := []string{}
+		if len(channel.TeamId) > 0 {
+			if groupKeywords, disabled, err := GetMentionGroupKeywordsInChannel(channel.TeamId, profileMap); err != nil {
+				l4g.Warn(utils.T("api.post.send_notifications.mention_groups.error"), post.Id, err)
+			} else {
+				for keyword, ids := range groupKeywords {
+					keywords[keyword] = append(keywords[keyword], ids...)
+				}
+				disabledMentionGroups = disabled
+			}
+		}
 
 		var potentialOtherMentions []string
 		mentionedUserIds, potentialOtherMentions, hereNotification, channelNotification, allNotification = GetExplicitMentions(post.Message, keywords)
 
+		for id := range mentionedUserIds {
+			explicitMentionIds = append(explicitMentionIds, id)
+		}
+
+		// mention groups that are too large to notify shouldn't be reported as users missing from the channel
+		if len(disabledMentionGroups) > 0 {
+			potentialOtherMentions = removeDisabledMentionGroups(sender, post, team.Id, potentialOtherMentions, disabledMentionGroups)
+		}
+
 		// get users that have comment thread mentions enabled
 		if len(post.RootId) > 0 {
 			if result := <-Srv.Store.Post().Get(post.RootId); result.Err != nil {
@@ -111,6 +134,22 @@ func SendNotifications(post *model.Post, team *model.Team, channel *model.Channe
 		}
 	}
 
+	// users who have muted the channel aren't notified of anything in it and don't get a mention badge
+	for id := range mentionedUserIds {
+		if model.IsChannelMuted(channelMemberNotifyPropsMap[id]) {
+			delete(mentionedUserIds, id)
+		}
+	}
+
+	// users who are mentioned by name follow the thread from then on
+	threadMentionIds := []string{}
+	for _, id := range explicitMentionIds {
+		if mentionedUserIds[id] {
+			threadMentionIds = append(threadMentionIds, id)
+		}
+	}
+	updateThreadMemberships(post, threadMentionIds)
+
 	mentionedUsersList := make([]string, 0, len(mentionedUserIds))
 	for id := range mentionedUserIds {
 		mentionedUsersList = append(mentionedUsersList, id)
@@ -227,8 +266,10 @@ func SendNotifications(post *model.Post, team *model.Team, channel *model.Channe
 
 			_, profileFound := profileMap[status.UserId]
 			_, alreadyMentioned := mentionedUserIds[status.UserId]
+			notifyProps := channelMemberNotifyPropsMap[status.UserId]
+			ignored := model.IsChannelMuted(notifyProps) || model.IsChannelIgnoringChannelMentions(notifyProps)
 
-			if status.Status == model.STATUS_ONLINE && profileFound && !alreadyMentioned {
+			if status.Status == model.STATUS_ONLINE && profileFound && !alreadyMentioned && !ignored {
 				mentionedUsersList = append(mentionedUsersList, status.UserId)
 				updateMentionChans = append(updateMentionChans, Srv.Store.Channel().IncrementMentionCount(post.ChannelId, status.UserId))
 			}
@@ -256,6 +297,8 @@ func SendNotifications(post *model.Post, team *model.Team, channel *model.Channe
 	}
 
 	if sendPushNotifications {
+		pushedUserIds := []string{}
+
 		for _, id := range mentionedUsersList {
 			var status *model.Status
 			var err *model.AppError
@@ -265,6 +308,7 @@ func SendNotifications(post *model.Post, team *model.Team, channel *model.Channe
 
 			if ShouldSendPushNotification(profileMap[id], channelMemberNotifyPropsMap[id], true, status, post) {
 				sendPushNotification(post, profileMap[id], channel, senderName, channelName, true)
+				pushedUserIds = append(pushedUserIds, id)
 			}
 		}
 
@@ -278,9 +322,12 @@ func SendNotifications(post *model.Post, team *model.Team, channel *model.Channe
 
 				if ShouldSendPushNotification(profileMap[id], channelMemberNotifyPropsMap[id], false, status, post) {
 					sendPushNotification(post, profileMap[id], channel, senderName, channelName, false)
+					pushedUserIds = append(pushedUserIds, id)
 				}
 			}
 		}
+
+		saveUrgentPostPushes(post, pushedUserIds)
 	}
 
 	message := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_POSTED, "", post.ChannelId, "", nil)
@@ -368,7 +415,7 @@ func sendNotificationEmail(post *model.Post, user *model.User, channel *model.Ch
 	var mailParameters map[string]interface{}
 
 	teamURL := siteURL + "/" + team.Name
-	tm := time.Unix(post.CreateAt/1000, 0)
+	tm := time.Unix(post.CreateAt/1000, 0).In(user.GetTimezoneLocation())
 
 	userLocale := utils.GetUserTranslations(user.Locale)
 	month := userLocale(tm.Month().String())
@@ -420,8 +467,13 @@ func sendNotificationEmail(post *model.Post, user *model.User, channel *model.Ch
 			"Hour": fmt.Sprintf("%02d", tm.Hour()), "Minute": fmt.Sprintf("%02d", tm.Minute()),
 			"TimeZone": zone, "Month": month, "Day": day}))
 
+	replyTo := GetEmailReplyAddress(post.Id, user.Id)
+	if len(replyTo) > 0 {
+		bodyPage.Props["ReplyText"] = userLocale("api.templates.post_body.reply")
+	}
+
 	go func() {
-		if err := utils.SendMail(user.Email, html.UnescapeString(subject), bodyPage.Render()); err != nil {
+		if err := utils.SendMailWithReplyTo(user.Email, replyTo, html.UnescapeString(subject), bodyPage.Render()); err != nil {
 			l4g.Error(utils.T("api.post.send_notifications_and_forget.send.error"), user.Email, err)
 		}
 	}()
@@ -630,6 +682,40 @@ func sendOutOfChannelMentions(sender *model.User, post *model.Post, teamId strin
 	return nil
 }
 
+// Removes the names of the given disabled mention groups from a list of potential mentions, letting the sender
+// know that those groups were too large to be notified.
+func removeDisabledMentionGroups(sender *model.User, post *model.Post, teamId string, potentialMentions []string, disabledGroups []string) []string {
+	disabled := make(map[string]bool)
+	for _, name := range disabledGroups {
+		disabled[name] = true
+	}
+
+	T := utils.GetUserTranslations(sender.Locale)
+
+	remaining := []string{}
+	for _, name := range potentialMentions {
+		if !disabled[name] {
+			remaining = append(remaining, name)
+			continue
+		}
+
+		SendEphemeralPost(
+			teamId,
+			post.UserId,
+			&model.Post{
+				ChannelId: post.ChannelId,
+				Message:   T("api.post.disabled_mention_group", map[string]interface{}{"Group": name, "Users": *utils.Cfg.TeamSettings.MaxNotificationsPerChannel}),
+				CreateAt:  post.CreateAt + 1,
+			},
+		)
+
+		// only warn once for each group
+		delete(disabled, name)
+	}
+
+	return remaining
+}
+
 // Given a message and a map mapping mention keywords to the users who use them, returns a map of mentioned
 // users and a slice of potential mention users not in the channel and whether or not @here was mentioned.
 func GetExplicitMentions(message string, keywords map[string][]string) (map[string]bool, []string, bool, bool, bool) {
@@ -646,7 +732,7 @@ func GetExplicitMentions(message string, keywords map[string][]string) (map[stri
 		}
 	}
 
-	for _, word := range strings.Fields(message) {
+	checkForMention := func(word string) bool {
 		isMention := false
 
 		if word == "@here" {
@@ -673,38 +759,30 @@ func GetExplicitMentions(message string, keywords map[string][]string) (map[stri
 			isMention = true
 		}
 
-		if !isMention {
-			// No matches were found with the string split just on whitespace so try further splitting
-			// the message on punctuation
-			splitWords := strings.FieldsFunc(word, func(c rune) bool {
-				return model.SplitRunes[c]
-			})
-
-			for _, splitWord := range splitWords {
-				if splitWord == "@here" {
-					hereMentioned = true
-				}
+		return isMention
+	}
 
-				if splitWord == "@all" {
-					allMentioned = true
-				}
+	for _, word := range strings.Fields(removeUnmentionableMarkdown(message)) {
+		if checkForMention(word) {
+			continue
+		}
 
-				if splitWord == "@channel" {
-					channelMentioned = true
-				}
+		// No matches were found with the string split just on whitespace so try further splitting
+		// the message on punctuation
+		for _, splitWord := range strings.FieldsFunc(word, isMentionSeparator) {
+			if checkForMention(splitWord) {
+				continue
+			}
 
-				// Non-case-sensitive check for regular keys
-				if ids, match := keywords[strings.ToLower(splitWord)]; match {
-					addMentionedUsers(ids)
-				}
+			// Usernames can contain periods, dashes and underscores, so only strip them from the ends
+			// of a word after checking whether they're part of a mention
+			trimmed := trimMentionPunctuation(splitWord)
+			if trimmed != splitWord && checkForMention(trimmed) {
+				continue
+			}
 
-				// Case-sensitive check for first name
-				if ids, match := keywords[splitWord]; match {
-					addMentionedUsers(ids)
-				} else if _, ok := systemMentions[word]; !ok && strings.HasPrefix(word, "@") {
-					username := word[1:len(splitWord)]
-					potentialOthersMentioned = append(potentialOthersMentioned, username)
-				}
+			if _, ok := systemMentions[trimmed]; !ok && len(trimmed) > 1 && strings.HasPrefix(trimmed, "@") {
+				potentialOthersMentioned = append(potentialOthersMentioned, trimmed[1:])
 			}
 		}
 	}
@@ -712,9 +790,165 @@ func GetExplicitMentions(message string, keywords map[string][]string) (map[stri
 	return mentioned, potentialOthersMentioned, hereMentioned, channelMentioned, allMentioned
 }
 
-// Given a map of user IDs to profiles, returns a list of mention
-// keywords for all users in the channel.
-func GetMentionKeywordsInChannel(profiles map[string]*model.User) map[string][]string {
+var (
+	mentionLinkDestinationRegex = regexp.MustCompile(`\]\([^)]*\)`)
+	mentionAutolinkRegex        = regexp.MustCompile(`<[A-Za-z][A-Za-z0-9.+-]*:[^<>\s]*>`)
+	mentionUrlRegex             = regexp.MustCompile(`(?i)\b(?:https?|ftp|mailto):\S+`)
+	mentionWwwUrlRegex          = regexp.MustCompile(`(?i)(^|[\s(\[<])www\.\S+`)
+)
+
+// Returns the text of a message with the parts of its Markdown that can't contain mentions blanked out.
+// These are fenced code blocks, block quotes, inline code and the destinations of links.
+func removeUnmentionableMarkdown(message string) string {
+	var result []string
+	var paragraph []string
+
+	flushParagraph := func() {
+		if len(paragraph) > 0 {
+			result = append(result, removeUnmentionableInlineMarkdown(strings.Join(paragraph, "\n")))
+			paragraph = nil
+		}
+	}
+
+	fence := ""
+	for _, line := range strings.Split(message, "\n") {
+		if fence != "" {
+			if isClosingCodeFence(line, fence) {
+				fence = ""
+			}
+			continue
+		}
+
+		if fence = getOpeningCodeFence(line); fence != "" {
+			flushParagraph()
+			continue
+		}
+
+		trimmed := strings.TrimLeft(line, " ")
+		if strings.HasPrefix(trimmed, ">") && len(line)-len(trimmed) < 4 {
+			flushParagraph()
+			continue
+		}
+
+		if strings.TrimSpace(line) == "" {
+			flushParagraph()
+			continue
+		}
+
+		paragraph = append(paragraph, line)
+	}
+	flushParagraph()
+
+	return strings.Join(result, "\n")
+}
+
+// Returns the backticks or tildes that open a fenced code block on the given line, if any.
+func getOpeningCodeFence(line string) string {
+	trimmed := strings.TrimLeft(line, " ")
+	if len(line)-len(trimmed) >= 4 || len(trimmed) < 3 || (trimmed[0] != '`' && trimmed[0] != '~') {
+		return ""
+	}
+
+	fence := trimmed[:countLeadingRune(trimmed, rune(trimmed[0]))]
+	if len(fence) < 3 {
+		return ""
+	}
+
+	// The info string of a backtick fence can't contain backticks since that would be inline code
+	if fence[0] == '`' && strings.Contains(trimmed[len(fence):], "`") {
+		return ""
+	}
+
+	return fence
+}
+
+func isClosingCodeFence(line string, fence string) bool {
+	trimmed := strings.TrimLeft(line, " ")
+	if len(line)-len(trimmed) >= 4 {
+		return false
+	}
+
+	length := countLeadingRune(trimmed, rune(fence[0]))
+
+	return length >= len(fence) && strings.TrimSpace(trimmed[length:]) == ""
+}
+
+func countLeadingRune(s string, r rune) int {
+	return len(s) - len(strings.TrimLeft(s, string(r)))
+}
+
+// Blanks out the inline code spans and link destinations in a paragraph of Markdown.
+func removeUnmentionableInlineMarkdown(text string) string {
+	text = removeInlineCode(text)
+	text = mentionLinkDestinationRegex.ReplaceAllString(text, "] ")
+	text = mentionAutolinkRegex.ReplaceAllString(text, " ")
+	text = mentionUrlRegex.ReplaceAllString(text, " ")
+	text = mentionWwwUrlRegex.ReplaceAllString(text, "$1 ")
+
+	return text
+}
+
+// Removes code spans from text. A code span starts with a run of backticks and ends with the next run
+// of the same length. Backticks that aren't closed are left as they are.
+func removeInlineCode(text string) string {
+	result := make([]byte, 0, len(text))
+
+	for i := 0; i < len(text); {
+		if text[i] != '`' {
+			result = append(result, text[i])
+			i++
+			continue
+		}
+
+		length := countLeadingRune(text[i:], '`')
+
+		end := -1
+		for j := i + length; j < len(text); {
+			if text[j] != '`' {
+				j++
+				continue
+			}
+
+			closingLength := countLeadingRune(text[j:], '`')
+			if closingLength == length {
+				end = j
+				break
+			}
+			j += closingLength
+		}
+
+		if end == -1 {
+			result = append(result, text[i:i+length]...)
+			i += length
+		} else {
+			result = append(result, ' ')
+			i = end + length
+		}
+	}
+
+	return string(result)
+}
+
+// Periods are left out since they can be part of a username
+func isMentionSeparator(c rune) bool {
+	if c == '.' {
+		return false
+	}
+
+	return model.SplitRunes[c] || c == '*' || c == '~' || c == '|' || c == '=' || c == '%' || c == '`'
+}
+
+// Strips sentence punctuation, quotes, Markdown emphasis and possessives from the ends of a word.
+func trimMentionPunctuation(word string) string {
+	word = strings.Trim(word, "._-\"'")
+	word = strings.TrimSuffix(word, "'s")
+
+	return strings.Trim(word, "._-\"'")
+}
+
+// Given a map of user IDs to profiles and a map of user IDs to their notify props for the channel,
+// returns a list of mention keywords for all users in the channel.
+func GetMentionKeywordsInChannel(profiles map[string]*model.User, channelMemberNotifyPropsMap map[string]model.StringMap) map[string][]string {
 	keywords := make(map[string][]string)
 
 	for id, profile := range profiles {
@@ -736,8 +970,9 @@ func GetMentionKeywordsInChannel(profiles map[string]*model.User) map[string][]s
 			keywords[profile.FirstName] = append(keywords[profile.FirstName], profile.Id)
 		}
 
-		// Add @channel and @all to keywords if user has them turned on
-		if int64(len(profiles)) < *utils.Cfg.TeamSettings.MaxNotificationsPerChannel && profile.NotifyProps["channel"] == "true" {
+		// Add @channel and @all to keywords if user has them turned on and isn't ignoring them in this channel
+		if int64(len(profiles)) < *utils.Cfg.TeamSettings.MaxNotificationsPerChannel && profile.NotifyProps["channel"] == "true" &&
+			!model.IsChannelIgnoringChannelMentions(channelMemberNotifyPropsMap[id]) {
 			keywords["@channel"] = append(keywords["@channel"], profile.Id)
 			keywords["@all"] = append(keywords["@all"], profile.Id)
 		}
@@ -747,8 +982,9 @@ func GetMentionKeywordsInChannel(profiles map[string]*model.User) map[string][]s
 }
 
 func ShouldSendPushNotification(user *model.User, channelNotifyProps model.StringMap, wasMentioned bool, status *model.Status, post *model.Post) bool {
+	// urgent posts are pushed even when the user is active
 	return DoesNotifyPropsAllowPushNotification(user, channelNotifyProps, post, wasMentioned) &&
-		DoesStatusAllowPushNotification(user.NotifyProps, status, post.ChannelId)
+		(post.IsUrgent() || DoesStatusAllowPushNotification(user.NotifyProps, status, post.ChannelId))
 }
 
 func DoesNotifyPropsAllowPushNotification(user *model.User, channelNotifyProps model.StringMap, post *model.Post, wasMentioned bool) bool {
@@ -760,6 +996,10 @@ func DoesNotifyPropsAllowPushNotification(user *model.User, channelNotifyProps m
 		return false
 	}
 
+	if model.IsChannelMuted(channelNotifyProps) {
+		return false
+	}
+
 	if channelNotify == model.USER_NOTIFY_NONE {
 		return false
 	}
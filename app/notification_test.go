@@ -4,6 +4,10 @@
 package app
 
 import (
+	"encoding/json"
+	"os"
+	"sort"
+	"strconv"
 	"testing"
 
 	"github.com/mattermost/platform/model"
@@ -125,38 +129,39 @@ func TestGetExplicitMentionsAtHere(t *testing.T) {
 		" @here ":   true,
 		"\t@here\t": true,
 		"\n@here\n": true,
-		// "!@here!": true,
+		"!@here!":   true,
 		// "@@here@": true,
-		// "#@here#": true,
-		// "$@here$": true,
-		// "%@here%": true,
-		// "^@here^": true,
-		// "&@here&": true,
-		// "*@here*": true,
-		"(@here(": true,
-		")@here)": true,
-		// "-@here-": true,
-		// "_@here_": true,
-		// "=@here=": true,
+		"#@here#":   true,
+		"$@here$":   true,
+		"%@here%":   true,
+		"^@here^":   true,
+		"&@here&":   true,
+		"*@here*":   true,
+		"(@here(":   true,
+		")@here)":   true,
+		"-@here-":   true,
+		"_@here_":   true,
+		"=@here=":   true,
 		"+@here+":   true,
 		"[@here[":   true,
 		"{@here{":   true,
 		"]@here]":   true,
 		"}@here}":   true,
 		"\\@here\\": true,
-		// "|@here|": true,
-		";@here;": true,
-		":@here:": true,
-		// "'@here'": true,
-		// "\"@here\"": true,
-		",@here,": true,
-		"<@here<": true,
-		".@here.": true,
-		">@here>": true,
-		"/@here/": true,
-		"?@here?": true,
-		// "`@here`": true,
-		// "~@here~": true,
+		"|@here|":   true,
+		";@here;":   true,
+		":@here:":   true,
+		"'@here'":   true,
+		"\"@here\"": true,
+		",@here,":   true,
+		"<@here<":   true,
+		".@here.":   true,
+		"a >@here>": true,
+		">@here>":   false, // block quote
+		"/@here/":   true,
+		"?@here?":   true,
+		"`@here`":   false,
+		"~@here~":   true,
 	}
 
 	for message, shouldMention := range cases {
@@ -178,6 +183,60 @@ func TestGetExplicitMentionsAtHere(t *testing.T) {
 	}
 }
 
+func TestGetExplicitMentionsCorpus(t *testing.T) {
+	file, err := os.Open("../tests/mention-parsing-corpus.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var corpus []struct {
+		Name      string   `json:"name"`
+		Message   string   `json:"message"`
+		Mentions  []string `json:"mentions"`
+		Potential []string `json:"potential"`
+		Here      bool     `json:"here"`
+		Channel   bool     `json:"channel"`
+		All       bool     `json:"all"`
+	}
+	if err := json.NewDecoder(file).Decode(&corpus); err != nil {
+		t.Fatal(err)
+	}
+
+	// The ids of the users are the names used by the corpus to refer to them
+	keywords := map[string][]string{
+		"@user":      {"user"},
+		"@user.name": {"user.name"},
+		"deploy":     {"deploy"},
+		"First":      {"first"},
+	}
+
+	for _, test := range corpus {
+		mentions, potential, here, channel, all := GetExplicitMentions(test.Message, keywords)
+
+		ids := []string{}
+		for id := range mentions {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+
+		expected := append([]string{}, test.Mentions...)
+		sort.Strings(expected)
+
+		if model.ArrayToJson(ids) != model.ArrayToJson(expected) {
+			t.Errorf("%v: expected mentions %v but got %v", test.Name, expected, ids)
+		}
+
+		if model.ArrayToJson(potential) != model.ArrayToJson(append([]string{}, test.Potential...)) {
+			t.Errorf("%v: expected potential mentions %v but got %v", test.Name, test.Potential, potential)
+		}
+
+		if here != test.Here || channel != test.Channel || all != test.All {
+			t.Errorf("%v: expected here=%v channel=%v all=%v but got here=%v channel=%v all=%v", test.Name, test.Here, test.Channel, test.All, here, channel, all)
+		}
+	}
+}
+
 func TestGetMentionKeywords(t *testing.T) {
 	Setup()
 	// user with username or custom mentions enabled
@@ -191,7 +250,7 @@ func TestGetMentionKeywords(t *testing.T) {
 	}
 
 	profiles := map[string]*model.User{user1.Id: user1}
-	mentions := GetMentionKeywordsInChannel(profiles)
+	mentions := GetMentionKeywordsInChannel(profiles, nil)
 	if len(mentions) != 3 {
 		t.Fatal("should've returned three mention keywords")
 	} else if ids, ok := mentions["user"]; !ok || ids[0] != user1.Id {
@@ -213,7 +272,7 @@ func TestGetMentionKeywords(t *testing.T) {
 	}
 
 	profiles = map[string]*model.User{user2.Id: user2}
-	mentions = GetMentionKeywordsInChannel(profiles)
+	mentions = GetMentionKeywordsInChannel(profiles, nil)
 	if len(mentions) != 2 {
 		t.Fatal("should've returned two mention keyword")
 	} else if ids, ok := mentions["First"]; !ok || ids[0] != user2.Id {
@@ -231,7 +290,7 @@ func TestGetMentionKeywords(t *testing.T) {
 	}
 
 	profiles = map[string]*model.User{user3.Id: user3}
-	mentions = GetMentionKeywordsInChannel(profiles)
+	mentions = GetMentionKeywordsInChannel(profiles, nil)
 	if len(mentions) != 3 {
 		t.Fatal("should've returned three mention keywords")
 	} else if ids, ok := mentions["@channel"]; !ok || ids[0] != user3.Id {
@@ -253,7 +312,7 @@ func TestGetMentionKeywords(t *testing.T) {
 	}
 
 	profiles = map[string]*model.User{user4.Id: user4}
-	mentions = GetMentionKeywordsInChannel(profiles)
+	mentions = GetMentionKeywordsInChannel(profiles, nil)
 	if len(mentions) != 6 {
 		t.Fatal("should've returned six mention keywords")
 	} else if ids, ok := mentions["user"]; !ok || ids[0] != user4.Id {
@@ -295,7 +354,7 @@ func TestGetMentionKeywords(t *testing.T) {
 		user3.Id: user3,
 		user4.Id: user4,
 	}
-	mentions = GetMentionKeywordsInChannel(profiles)
+	mentions = GetMentionKeywordsInChannel(profiles, nil)
 	if len(mentions) != 6 {
 		t.Fatal("should've returned six mention keywords")
 	} else if ids, ok := mentions["user"]; !ok || len(ids) != 2 || (ids[0] != user1.Id && ids[1] != user1.Id) || (ids[0] != user4.Id && ids[1] != user4.Id) {
@@ -311,6 +370,19 @@ func TestGetMentionKeywords(t *testing.T) {
 	} else if ids, ok := mentions["@all"]; !ok || len(ids) != 2 || (ids[0] != user3.Id && ids[1] != user3.Id) || (ids[0] != user4.Id && ids[1] != user4.Id) {
 		t.Fatal("should've mentioned user3 and user4 with @all")
 	}
+
+	// user ignoring @channel/@all mentions in the channel
+	channelMemberNotifyPropsMap := map[string]model.StringMap{
+		user4.Id: {model.IGNORE_CHANNEL_MENTIONS_NOTIFY_PROP: model.IGNORE_CHANNEL_MENTIONS_ON},
+	}
+	mentions = GetMentionKeywordsInChannel(profiles, channelMemberNotifyPropsMap)
+	if ids, ok := mentions["@channel"]; !ok || len(ids) != 1 || ids[0] != user3.Id {
+		t.Fatal("should've mentioned only user3 with @channel")
+	} else if ids, ok := mentions["@all"]; !ok || len(ids) != 1 || ids[0] != user3.Id {
+		t.Fatal("should've mentioned only user3 with @all")
+	} else if ids, ok := mentions["@user"]; !ok || len(ids) != 4 {
+		t.Fatal("should've still mentioned user1 and user4 with @user")
+	}
 }
 
 func TestDoesNotifyPropsAllowPushNotification(t *testing.T) {
@@ -507,6 +579,25 @@ func TestDoesNotifyPropsAllowPushNotification(t *testing.T) {
 	if DoesNotifyPropsAllowPushNotification(user, channelNotifyProps, post, true) {
 		t.Fatal("Should have returned false")
 	}
+
+	// WHEN default is ALL and channel is ALL but muted
+	userNotifyProps[model.PUSH_NOTIFY_PROP] = model.USER_NOTIFY_ALL
+	user.NotifyProps = userNotifyProps
+	channelNotifyProps[model.PUSH_NOTIFY_PROP] = model.CHANNEL_NOTIFY_ALL
+	channelNotifyProps[model.MUTE_NOTIFY_PROP] = "true"
+	if DoesNotifyPropsAllowPushNotification(user, channelNotifyProps, post, false) {
+		t.Fatal("Should have returned false")
+	}
+
+	if DoesNotifyPropsAllowPushNotification(user, channelNotifyProps, post, true) {
+		t.Fatal("Should have returned false")
+	}
+
+	// WHEN the mute has expired
+	channelNotifyProps[model.MUTE_UNTIL_NOTIFY_PROP] = strconv.FormatInt(model.GetMillis()-1000, 10)
+	if !DoesNotifyPropsAllowPushNotification(user, channelNotifyProps, post, true) {
+		t.Fatal("Should have returned true")
+	}
 }
 
 func TestDoesStatusAllowPushNotification(t *testing.T) {
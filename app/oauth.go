@@ -4,7 +4,11 @@
 package app
 
 import (
+	"net/http"
+
 	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/store"
+	"github.com/mattermost/platform/utils"
 )
 
 func RevokeAccessToken(token string) *model.AppError {
@@ -32,3 +36,113 @@ func RevokeAccessToken(token string) *model.AppError {
 
 	return nil
 }
+
+// GetOAuthAppUser returns the user that acts on behalf of an OAuth app when
+// it uses the client credentials grant, creating it the first time. The user
+// can't log in and only gets access to the teams and channels it is added to.
+func GetOAuthAppUser(oauthApp *model.OAuthApp) (*model.User, *model.AppError) {
+	clientId := oauthApp.Id
+	if user, err := GetUserByAuth(&clientId, model.USER_AUTH_SERVICE_OAUTH_APP); err == nil {
+		if user.DeleteAt > 0 {
+			return nil, model.NewAppError("GetOAuthAppUser", "app.oauth.get_oauth_app_user.deactivated.app_error", nil, "client_id="+clientId, http.StatusForbidden)
+		}
+		return user, nil
+	} else if err.Id != store.MISSING_AUTH_ACCOUNT_ERROR {
+		return nil, err
+	}
+
+	user := &model.User{
+		Username:      "app-" + clientId,
+		Nickname:      oauthApp.Name,
+		Email:         clientId + "@oauth-app.invalid",
+		EmailVerified: true,
+		AuthService:   model.USER_AUTH_SERVICE_OAUTH_APP,
+		AuthData:      &clientId,
+		Roles:         model.ROLE_SYSTEM_USER.Id,
+		Locale:        *utils.Cfg.LocalizationSettings.DefaultClientLocale,
+	}
+	user.SetDefaultNotifications()
+	user.NotifyProps["email"] = "false"
+
+	return createUser(user)
+}
+
+// DeactivateOAuthAppUser deactivates the client credentials user of an OAuth
+// app, if it has one, so that it doesn't outlive the app.
+func DeactivateOAuthAppUser(clientId string) *model.AppError {
+	user, err := GetUserByAuth(&clientId, model.USER_AUTH_SERVICE_OAUTH_APP)
+	if err != nil {
+		if err.Id == store.MISSING_AUTH_ACCOUNT_ERROR {
+			return nil
+		}
+		return err
+	}
+
+	if user.DeleteAt > 0 {
+		return nil
+	}
+
+	_, err = UpdateActive(user, false)
+	return err
+}
+
+// IntrospectOAuthToken describes an access or refresh token as defined by
+// RFC 7662. Tokens issued to other clients are reported as inactive.
+func IntrospectOAuthToken(clientId string, token string) *model.IntrospectionResponse {
+	accessData, tokenType := getOAuthAccessDataForToken(token)
+	if accessData == nil || accessData.ClientId != clientId {
+		return &model.IntrospectionResponse{Active: false}
+	}
+
+	if tokenType == model.TOKEN_TYPE_HINT_ACCESS_TOKEN && accessData.IsExpired() {
+		return &model.IntrospectionResponse{Active: false}
+	}
+
+	user, err := GetUser(accessData.UserId)
+	if err != nil || user.DeleteAt > 0 {
+		return &model.IntrospectionResponse{Active: false}
+	}
+
+	response := &model.IntrospectionResponse{
+		Active:    true,
+		Scope:     accessData.Scope,
+		ClientId:  accessData.ClientId,
+		Username:  user.Username,
+		TokenType: tokenType,
+		Subject:   user.Id,
+	}
+
+	if tokenType == model.TOKEN_TYPE_HINT_ACCESS_TOKEN && accessData.ExpiresAt > 0 {
+		response.ExpiresAt = accessData.ExpiresAt / 1000
+	}
+
+	return response
+}
+
+// RevokeOAuthToken revokes an access or refresh token issued to the client
+// as defined by RFC 7009. Revoking a refresh token also revokes its access
+// token. Unknown tokens are ignored.
+func RevokeOAuthToken(clientId string, token string) *model.AppError {
+	accessData, _ := getOAuthAccessDataForToken(token)
+	if accessData == nil || accessData.ClientId != clientId {
+		return nil
+	}
+
+	return RevokeAccessToken(accessData.Token)
+}
+
+func getOAuthAccessDataForToken(token string) (*model.AccessData, string) {
+	if len(token) == 0 {
+		return nil, ""
+	}
+
+	if result := <-Srv.Store.OAuth().GetAccessData(token); result.Err == nil {
+		return result.Data.(*model.AccessData), model.TOKEN_TYPE_HINT_ACCESS_TOKEN
+	}
+
+	if result := <-Srv.Store.OAuth().GetAccessDataByRefreshToken(token); result.Err == nil {
+		return result.Data.(*model.AccessData), model.TOKEN_TYPE_HINT_REFRESH_TOKEN
+	}
+
+	return nil, ""
+}
@@ -0,0 +1,376 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+	"gopkg.in/square/go-jose.v1"
+)
+
+const (
+	OPENID_CONFIGURATION_CACHE_MILLISECONDS = 60 * 60 * 1000
+	OPENID_KEYS_REFRESH_MILLISECONDS        = 60 * 1000
+	OPENID_CLOCK_SKEW_SECONDS               = 60
+)
+
+type openIdIssuer struct {
+	sync.Mutex
+	discoveryEndpoint string
+	configuration     *model.OpenIdConfiguration
+	fetchedAt         int64
+	keys              *jose.JsonWebKeySet
+	keysFetchedAt     int64
+}
+
+var openIdIssuerCache = &openIdIssuer{}
+
+// GetSSOSettings returns the endpoints and credentials for an OAuth based
+// login service. For OpenID Connect the endpoints are resolved from the
+// issuer's discovery document.
+func GetSSOSettings(service string) (*model.SSOSettings, *model.AppError) {
+	if service != model.SERVICE_OPENID {
+		return utils.Cfg.GetSSOService(service), nil
+	}
+
+	settings := utils.Cfg.OpenIdSettings
+	if !*settings.Enable {
+		return &model.SSOSettings{Enable: false}, nil
+	}
+
+	configuration, err := GetOpenIdConfiguration()
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.SSOSettings{
+		Enable:          true,
+		Secret:          *settings.Secret,
+		Id:              *settings.Id,
+		Scope:           *settings.Scope,
+		AuthEndpoint:    configuration.AuthorizationEndpoint,
+		TokenEndpoint:   configuration.TokenEndpoint,
+		UserApiEndpoint: configuration.UserinfoEndpoint,
+	}, nil
+}
+
+// GetOpenIdConfiguration returns the discovery document of the configured
+// issuer, fetching it again once the cached copy is an hour old or the
+// discovery endpoint has changed.
+func GetOpenIdConfiguration() (*model.OpenIdConfiguration, *model.AppError) {
+	issuer := openIdIssuerCache
+	issuer.Lock()
+	defer issuer.Unlock()
+
+	return issuer.getConfiguration()
+}
+
+func (issuer *openIdIssuer) getConfiguration() (*model.OpenIdConfiguration, *model.AppError) {
+	endpoint := *utils.Cfg.OpenIdSettings.DiscoveryEndpoint
+
+	if issuer.configuration != nil && issuer.discoveryEndpoint == endpoint && model.GetMillis()-issuer.fetchedAt < OPENID_CONFIGURATION_CACHE_MILLISECONDS {
+		return issuer.configuration, nil
+	}
+
+	body, err := openIdGet(endpoint, "")
+	if err != nil {
+		return nil, model.NewAppError("GetOpenIdConfiguration", "app.openid.discovery.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+	defer body.Close()
+
+	configuration := model.OpenIdConfigurationFromJson(body)
+	if configuration == nil || !configuration.IsValid() {
+		return nil, model.NewAppError("GetOpenIdConfiguration", "app.openid.discovery.app_error", nil, "endpoint="+endpoint, http.StatusInternalServerError)
+	}
+
+	issuer.discoveryEndpoint = endpoint
+	issuer.configuration = configuration
+	issuer.fetchedAt = model.GetMillis()
+	issuer.keys = nil
+	issuer.keysFetchedAt = 0
+
+	return configuration, nil
+}
+
+// getSigningKeys returns the issuer keys matching keyId. The key set is
+// refetched when no key matches so that rotated keys are picked up, but at
+// most once a minute so that forged key ids can't be used to flood the issuer.
+func (issuer *openIdIssuer) getSigningKeys(keyId string) ([]jose.JsonWebKey, *model.AppError) {
+	configuration, err := issuer.getConfiguration()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := issuer.findSigningKeys(keyId)
+	if len(keys) == 0 && model.GetMillis()-issuer.keysFetchedAt > OPENID_KEYS_REFRESH_MILLISECONDS {
+		body, err := openIdGet(configuration.JwksUri, "")
+		if err != nil {
+			return nil, model.NewAppError("ValidateOpenIdToken", "app.openid.keys.app_error", nil, err.Error(), http.StatusInternalServerError)
+		}
+		defer body.Close()
+
+		var keySet jose.JsonWebKeySet
+		if err := json.NewDecoder(body).Decode(&keySet); err != nil {
+			return nil, model.NewAppError("ValidateOpenIdToken", "app.openid.keys.app_error", nil, err.Error(), http.StatusInternalServerError)
+		}
+
+		issuer.keys = &keySet
+		issuer.keysFetchedAt = model.GetMillis()
+		keys = issuer.findSigningKeys(keyId)
+	}
+
+	return keys, nil
+}
+
+func (issuer *openIdIssuer) findSigningKeys(keyId string) []jose.JsonWebKey {
+	if issuer.keys == nil {
+		return nil
+	}
+
+	candidates := issuer.keys.Keys
+	if len(keyId) > 0 {
+		candidates = issuer.keys.Key(keyId)
+	}
+
+	keys := []jose.JsonWebKey{}
+	for _, key := range candidates {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+
+		// Only public keys are accepted, which also rules out HMAC
+		// signatures made with a shared secret.
+		switch key.Key.(type) {
+		case *rsa.PublicKey, *ecdsa.PublicKey:
+			keys = append(keys, key)
+		}
+	}
+
+	return keys
+}
+
+// ValidateOpenIdToken verifies the signature of an ID token against the
+// issuer's published keys and checks its issuer, audience and expiry. The
+// verified claims are returned.
+func ValidateOpenIdToken(rawToken string) (map[string]interface{}, *model.AppError) {
+	token, err := jose.ParseSigned(rawToken)
+	if err != nil || len(token.Signatures) != 1 {
+		return nil, model.NewAppError("ValidateOpenIdToken", "app.openid.invalid_token.app_error", nil, "", http.StatusUnauthorized)
+	}
+
+	issuer := openIdIssuerCache
+	issuer.Lock()
+	keys, appErr := issuer.getSigningKeys(token.Signatures[0].Header.KeyID)
+	configuration := issuer.configuration
+	issuer.Unlock()
+
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	var payload []byte
+	for _, key := range keys {
+		if payload, err = token.Verify(key.Key); err == nil {
+			break
+		}
+	}
+
+	if payload == nil {
+		return nil, model.NewAppError("ValidateOpenIdToken", "app.openid.invalid_signature.app_error", nil, "", http.StatusUnauthorized)
+	}
+
+	claims := map[string]interface{}{}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, model.NewAppError("ValidateOpenIdToken", "app.openid.invalid_token.app_error", nil, err.Error(), http.StatusUnauthorized)
+	}
+
+	if claimString(claims, "iss") != configuration.Issuer {
+		return nil, model.NewAppError("ValidateOpenIdToken", "app.openid.invalid_issuer.app_error", nil, "iss="+claimString(claims, "iss"), http.StatusUnauthorized)
+	}
+
+	clientId := *utils.Cfg.OpenIdSettings.Id
+	audience := claimStrings(claims, "aud")
+	if !utils.StringInSlice(clientId, audience) {
+		return nil, model.NewAppError("ValidateOpenIdToken", "app.openid.invalid_audience.app_error", nil, "", http.StatusUnauthorized)
+	}
+
+	if azp := claimString(claims, "azp"); len(audience) > 1 && azp != clientId {
+		return nil, model.NewAppError("ValidateOpenIdToken", "app.openid.invalid_audience.app_error", nil, "azp="+azp, http.StatusUnauthorized)
+	}
+
+	now := model.GetMillis() / 1000
+	if exp, ok := claims["exp"].(float64); !ok || now > int64(exp)+OPENID_CLOCK_SKEW_SECONDS {
+		return nil, model.NewAppError("ValidateOpenIdToken", "app.openid.expired_token.app_error", nil, "", http.StatusUnauthorized)
+	}
+
+	if nbf, ok := claims["nbf"].(float64); ok && now < int64(nbf)-OPENID_CLOCK_SKEW_SECONDS {
+		return nil, model.NewAppError("ValidateOpenIdToken", "app.openid.invalid_token.app_error", nil, "nbf", http.StatusUnauthorized)
+	}
+
+	if len(claimString(claims, "sub")) == 0 {
+		return nil, model.NewAppError("ValidateOpenIdToken", "app.openid.invalid_token.app_error", nil, "sub", http.StatusUnauthorized)
+	}
+
+	return claims, nil
+}
+
+// GetOpenIdUserData validates an ID token and maps its claims to the user
+// data read by the OpenID OAuth provider. Claims missing from the ID token
+// are looked up at the userinfo endpoint when the issuer provides one.
+func GetOpenIdUserData(idToken string, accessToken string) (io.ReadCloser, *model.AppError) {
+	if len(idToken) == 0 {
+		return nil, model.NewAppError("GetOpenIdUserData", "app.openid.missing_token.app_error", nil, "", http.StatusUnauthorized)
+	}
+
+	claims, err := ValidateOpenIdToken(idToken)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := utils.Cfg.OpenIdSettings
+	if _, ok := claims["email"]; !ok || claims[*settings.UsernameClaim] == nil {
+		if configuration, err := GetOpenIdConfiguration(); err == nil && len(configuration.UserinfoEndpoint) > 0 && len(accessToken) > 0 {
+			mergeOpenIdUserInfo(claims, configuration.UserinfoEndpoint, accessToken)
+		}
+	}
+
+	if verified, ok := claims["email_verified"].(bool); ok && !verified {
+		return nil, model.NewAppError("GetOpenIdUserData", "app.openid.email_not_verified.app_error", nil, "", http.StatusForbidden)
+	}
+
+	user := OpenIdUserFromClaims(claims)
+	return ioutil.NopCloser(strings.NewReader(user.ToJson())), nil
+}
+
+// OpenIdUserFromClaims maps verified claims to an OpenIdUser using the
+// username and groups claims from the config.
+func OpenIdUserFromClaims(claims map[string]interface{}) *model.OpenIdUser {
+	settings := utils.Cfg.OpenIdSettings
+
+	user := &model.OpenIdUser{
+		Subject:   claimString(claims, "sub"),
+		Email:     claimString(claims, "email"),
+		Username:  claimString(claims, *settings.UsernameClaim),
+		FirstName: claimString(claims, "given_name"),
+		LastName:  claimString(claims, "family_name"),
+	}
+
+	if len(user.FirstName) == 0 && len(user.LastName) == 0 {
+		name := strings.Fields(claimString(claims, "name"))
+		if len(name) > 0 {
+			user.FirstName = name[0]
+			user.LastName = strings.Join(name[1:], " ")
+		}
+	}
+
+	if len(*settings.GroupsClaim) > 0 {
+		user.Groups = claimStrings(claims, *settings.GroupsClaim)
+	}
+
+	if adminGroups := strings.TrimSpace(*settings.AdminGroups); len(adminGroups) > 0 {
+		isAdmin := false
+		for _, group := range strings.Split(adminGroups, ",") {
+			if utils.StringInSlice(strings.TrimSpace(group), user.Groups) {
+				isAdmin = true
+				break
+			}
+		}
+		user.IsAdmin = &isAdmin
+	}
+
+	return user
+}
+
+func mergeOpenIdUserInfo(claims map[string]interface{}, endpoint string, accessToken string) {
+	body, err := openIdGet(endpoint, accessToken)
+	if err != nil {
+		return
+	}
+	defer body.Close()
+
+	userInfo := map[string]interface{}{}
+	if err := json.NewDecoder(body).Decode(&userInfo); err != nil {
+		return
+	}
+
+	// The userinfo response is only trusted for the subject of the
+	// verified ID token and never overrides claims from the token.
+	if claimString(userInfo, "sub") != claimString(claims, "sub") {
+		return
+	}
+
+	for key, value := range userInfo {
+		if _, ok := claims[key]; !ok {
+			claims[key] = value
+		}
+	}
+}
+
+func openIdGet(url string, accessToken string) (io.ReadCloser, error) {
+	tr := &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: *utils.Cfg.ServiceSettings.EnableInsecureOutgoingConnections},
+	}
+	client := &http.Client{Transport: tr}
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "application/json")
+	if len(accessToken) > 0 {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		return nil, fmt.Errorf("url=%v, status=%v", url, resp.Status)
+	}
+
+	return resp.Body, nil
+}
+
+func claimString(claims map[string]interface{}, name string) string {
+	if value, ok := claims[name].(string); ok {
+		return value
+	}
+
+	return ""
+}
+
+// claimStrings reads a claim that may be either a single string or a list
+// of strings, as allowed for "aud" and common for group claims.
+func claimStrings(claims map[string]interface{}, name string) []string {
+	switch value := claims[name].(type) {
+	case string:
+		return []string{value}
+	case []interface{}:
+		values := []string{}
+		for _, v := range value {
+			if s, ok := v.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+
+	return nil
+}
@@ -0,0 +1,246 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+	"gopkg.in/square/go-jose.v1"
+)
+
+type mockOpenIdIssuer struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+	keyId  string
+}
+
+func newMockOpenIdIssuer(t *testing.T) *mockOpenIdIssuer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issuer := &mockOpenIdIssuer{key: key, keyId: model.NewId()}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		configuration := model.OpenIdConfiguration{
+			Issuer:                issuer.server.URL,
+			AuthorizationEndpoint: issuer.server.URL + "/auth",
+			TokenEndpoint:         issuer.server.URL + "/token",
+			UserinfoEndpoint:      issuer.server.URL + "/userinfo",
+			JwksUri:               issuer.server.URL + "/keys",
+		}
+		json.NewEncoder(w).Encode(configuration)
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		keys := jose.JsonWebKeySet{Keys: []jose.JsonWebKey{
+			{Key: &issuer.key.PublicKey, KeyID: issuer.keyId, Algorithm: string(jose.RS256), Use: "sig"},
+		}}
+		json.NewEncoder(w).Encode(keys)
+	})
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer access-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"sub": "subject", "email": "userinfo@example.com", "preferred_username": "userinfo"})
+	})
+	issuer.server = httptest.NewServer(mux)
+
+	return issuer
+}
+
+func (issuer *mockOpenIdIssuer) claims() map[string]interface{} {
+	return map[string]interface{}{
+		"iss":                issuer.server.URL,
+		"sub":                "subject",
+		"aud":                "clientid",
+		"exp":                time.Now().Add(time.Hour).Unix(),
+		"iat":                time.Now().Unix(),
+		"email":              "jane@example.com",
+		"email_verified":     true,
+		"preferred_username": "jane.doe",
+		"name":               "Jane Doe",
+		"groups":             []string{"users", "admins"},
+	}
+}
+
+func (issuer *mockOpenIdIssuer) sign(t *testing.T, claims map[string]interface{}, alg jose.SignatureAlgorithm, key interface{}) string {
+	signer, err := jose.NewSigner(alg, &jose.JsonWebKey{Key: key, KeyID: issuer.keyId})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payload, _ := json.Marshal(claims)
+	signed, err := signer.Sign(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := signed.CompactSerialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return token
+}
+
+func setupOpenId(issuer *mockOpenIdIssuer) func() {
+	oldSettings := utils.Cfg.OpenIdSettings
+	utils.Cfg.OpenIdSettings = model.OpenIdSettings{}
+	utils.Cfg.SetDefaults()
+	*utils.Cfg.OpenIdSettings.Enable = true
+	*utils.Cfg.OpenIdSettings.Id = "clientid"
+	*utils.Cfg.OpenIdSettings.Secret = "secret"
+	*utils.Cfg.OpenIdSettings.DiscoveryEndpoint = issuer.server.URL + "/.well-known/openid-configuration"
+
+	return func() {
+		utils.Cfg.OpenIdSettings = oldSettings
+		issuer.server.Close()
+	}
+}
+
+func TestGetSSOSettingsOpenId(t *testing.T) {
+	utils.TranslationsPreInit()
+	utils.LoadConfig("config.json")
+
+	issuer := newMockOpenIdIssuer(t)
+	defer setupOpenId(issuer)()
+
+	if sso, err := GetSSOSettings(model.SERVICE_OPENID); err != nil {
+		t.Fatal(err)
+	} else if sso.AuthEndpoint != issuer.server.URL+"/auth" || sso.TokenEndpoint != issuer.server.URL+"/token" || sso.Id != "clientid" || sso.Scope != "openid profile email" {
+		t.Fatal("endpoints should come from the discovery document", sso)
+	}
+
+	*utils.Cfg.OpenIdSettings.Enable = false
+	if sso, err := GetSSOSettings(model.SERVICE_OPENID); err != nil {
+		t.Fatal(err)
+	} else if sso.Enable {
+		t.Fatal("should be disabled")
+	}
+}
+
+func TestValidateOpenIdToken(t *testing.T) {
+	utils.TranslationsPreInit()
+	utils.LoadConfig("config.json")
+
+	issuer := newMockOpenIdIssuer(t)
+	defer setupOpenId(issuer)()
+
+	if claims, err := ValidateOpenIdToken(issuer.sign(t, issuer.claims(), jose.RS256, issuer.key)); err != nil {
+		t.Fatal(err)
+	} else if claims["email"] != "jane@example.com" {
+		t.Fatal("should return the claims")
+	}
+
+	otherKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	if _, err := ValidateOpenIdToken(issuer.sign(t, issuer.claims(), jose.RS256, otherKey)); err == nil || err.Id != "app.openid.invalid_signature.app_error" {
+		t.Fatal("should reject a token signed by an unknown key", err)
+	}
+
+	if _, err := ValidateOpenIdToken(issuer.sign(t, issuer.claims(), jose.HS256, []byte("clientid"))); err == nil || err.Id != "app.openid.invalid_signature.app_error" {
+		t.Fatal("should reject a token signed with a shared secret", err)
+	}
+
+	claims := issuer.claims()
+	claims["iss"] = "https://evil.example.com"
+	if _, err := ValidateOpenIdToken(issuer.sign(t, claims, jose.RS256, issuer.key)); err == nil || err.Id != "app.openid.invalid_issuer.app_error" {
+		t.Fatal("should reject another issuer", err)
+	}
+
+	claims = issuer.claims()
+	claims["aud"] = "otherclient"
+	if _, err := ValidateOpenIdToken(issuer.sign(t, claims, jose.RS256, issuer.key)); err == nil || err.Id != "app.openid.invalid_audience.app_error" {
+		t.Fatal("should reject another audience", err)
+	}
+
+	claims = issuer.claims()
+	claims["aud"] = []string{"clientid", "otherclient"}
+	claims["azp"] = "otherclient"
+	if _, err := ValidateOpenIdToken(issuer.sign(t, claims, jose.RS256, issuer.key)); err == nil || err.Id != "app.openid.invalid_audience.app_error" {
+		t.Fatal("should reject a token authorized for another client", err)
+	}
+
+	claims["azp"] = "clientid"
+	if _, err := ValidateOpenIdToken(issuer.sign(t, claims, jose.RS256, issuer.key)); err != nil {
+		t.Fatal(err)
+	}
+
+	claims = issuer.claims()
+	claims["exp"] = time.Now().Add(-time.Hour).Unix()
+	if _, err := ValidateOpenIdToken(issuer.sign(t, claims, jose.RS256, issuer.key)); err == nil || err.Id != "app.openid.expired_token.app_error" {
+		t.Fatal("should reject an expired token", err)
+	}
+
+	if _, err := ValidateOpenIdToken("not.a.token"); err == nil {
+		t.Fatal("should reject garbage")
+	}
+}
+
+func TestGetOpenIdUserData(t *testing.T) {
+	utils.TranslationsPreInit()
+	utils.LoadConfig("config.json")
+
+	issuer := newMockOpenIdIssuer(t)
+	defer setupOpenId(issuer)()
+
+	data, err := GetOpenIdUserData(issuer.sign(t, issuer.claims(), jose.RS256, issuer.key), "access-token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user := model.OpenIdUserFromJson(data)
+	if user.Subject != "subject" || user.Email != "jane@example.com" || user.Username != "jane.doe" {
+		t.Fatal("claims should be mapped", user)
+	}
+	if user.FirstName != "Jane" || user.LastName != "Doe" {
+		t.Fatal("name should be split", user)
+	}
+	if len(user.Groups) != 2 || user.IsAdmin != nil {
+		t.Fatal("groups should be mapped without managing roles", user)
+	}
+
+	*utils.Cfg.OpenIdSettings.AdminGroups = "staff, admins"
+	data, _ = GetOpenIdUserData(issuer.sign(t, issuer.claims(), jose.RS256, issuer.key), "access-token")
+	if user = model.OpenIdUserFromJson(data); user.IsAdmin == nil || !*user.IsAdmin {
+		t.Fatal("should be an admin", user)
+	}
+
+	*utils.Cfg.OpenIdSettings.AdminGroups = "staff"
+	data, _ = GetOpenIdUserData(issuer.sign(t, issuer.claims(), jose.RS256, issuer.key), "access-token")
+	if user = model.OpenIdUserFromJson(data); user.IsAdmin == nil || *user.IsAdmin {
+		t.Fatal("should not be an admin", user)
+	}
+
+	claims := issuer.claims()
+	delete(claims, "email")
+	delete(claims, "preferred_username")
+	data, err = GetOpenIdUserData(issuer.sign(t, claims, jose.RS256, issuer.key), "access-token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user = model.OpenIdUserFromJson(data); user.Email != "userinfo@example.com" || user.Username != "userinfo" {
+		t.Fatal("missing claims should come from the userinfo endpoint", user)
+	}
+
+	claims = issuer.claims()
+	claims["email_verified"] = false
+	if _, err := GetOpenIdUserData(issuer.sign(t, claims, jose.RS256, issuer.key), "access-token"); err == nil || err.Id != "app.openid.email_not_verified.app_error" {
+		t.Fatal("should reject unverified email addresses", err)
+	}
+
+	if _, err := GetOpenIdUserData("", "access-token"); err == nil {
+		t.Fatal("should require an ID token")
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+
+	l4g "github.com/alecthomas/log4go"
+
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+// checkPasswordHistory returns an error if the password is one of the last PasswordSettings.HistoryCount passwords
+// that the user has had, including their current one.
+func checkPasswordHistory(user *model.User, password string) *model.AppError {
+	count := *utils.Cfg.PasswordSettings.HistoryCount
+	if count <= 0 {
+		return nil
+	}
+
+	reusedErr := model.NewAppError("checkPasswordHistory", "app.password_policy.reused.app_error", map[string]interface{}{"Count": count}, "user_id="+user.Id, http.StatusBadRequest)
+
+	if user.Password != "" && model.ComparePassword(user.Password, password) {
+		return reusedErr
+	}
+
+	if result := <-Srv.Store.PasswordHistory().GetForUser(user.Id, count); result.Err != nil {
+		return result.Err
+	} else {
+		for _, history := range result.Data.([]*model.PasswordHistory) {
+			if model.ComparePassword(history.PasswordHash, password) {
+				return reusedErr
+			}
+		}
+	}
+
+	return nil
+}
+
+func savePasswordHistory(userId, hashedPassword string) {
+	count := *utils.Cfg.PasswordSettings.HistoryCount
+	if count <= 0 {
+		return
+	}
+
+	if result := <-Srv.Store.PasswordHistory().Save(&model.PasswordHistory{UserId: userId, PasswordHash: hashedPassword}, count); result.Err != nil {
+		l4g.Error(result.Err.Error())
+	}
+}
+
+// PasswordExpiresAt returns when the user's password expires, or 0 if it never does. Users can still log in for
+// PasswordSettings.ExpiryGracePeriodInDays after that so that they can change it.
+func PasswordExpiresAt(user *model.User) int64 {
+	days := *utils.Cfg.PasswordSettings.ExpiryInDays
+	if days <= 0 || user.AuthService != "" {
+		return 0
+	}
+
+	lastUpdate := user.LastPasswordUpdate
+	if lastUpdate == 0 {
+		lastUpdate = user.CreateAt
+	}
+
+	return lastUpdate + int64(days)*24*60*60*1000
+}
+
+// PasswordChangeRequiredBy returns when the user will no longer be able to log in without changing their expired
+// password, or 0 if their password hasn't expired.
+func PasswordChangeRequiredBy(user *model.User) int64 {
+	expiresAt := PasswordExpiresAt(user)
+	if expiresAt == 0 || model.GetMillis() < expiresAt {
+		return 0
+	}
+
+	return expiresAt + int64(*utils.Cfg.PasswordSettings.ExpiryGracePeriodInDays)*24*60*60*1000
+}
+
+func checkPasswordNotExpired(user *model.User) *model.AppError {
+	if deadline := PasswordChangeRequiredBy(user); deadline != 0 && model.GetMillis() > deadline {
+		return model.NewAppError("checkPasswordNotExpired", "app.password_policy.expired.app_error", nil, "user_id="+user.Id, http.StatusForbidden)
+	}
+
+	return nil
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func TestPasswordExpiry(t *testing.T) {
+	utils.TranslationsPreInit()
+	utils.LoadConfig("config.json")
+
+	expiryInDays := *utils.Cfg.PasswordSettings.ExpiryInDays
+	gracePeriodInDays := *utils.Cfg.PasswordSettings.ExpiryGracePeriodInDays
+	defer func() {
+		*utils.Cfg.PasswordSettings.ExpiryInDays = expiryInDays
+		*utils.Cfg.PasswordSettings.ExpiryGracePeriodInDays = gracePeriodInDays
+	}()
+
+	day := int64(24 * 60 * 60 * 1000)
+	now := model.GetMillis()
+	user := &model.User{Id: model.NewId(), CreateAt: now - 30*day, LastPasswordUpdate: now - 12*day}
+
+	*utils.Cfg.PasswordSettings.ExpiryInDays = 0
+	if PasswordExpiresAt(user) != 0 || PasswordChangeRequiredBy(user) != 0 || checkPasswordNotExpired(user) != nil {
+		t.Fatal("passwords shouldn't expire when expiry is disabled")
+	}
+
+	*utils.Cfg.PasswordSettings.ExpiryInDays = 20
+	*utils.Cfg.PasswordSettings.ExpiryGracePeriodInDays = 5
+	if PasswordExpiresAt(user) != user.LastPasswordUpdate+20*day {
+		t.Fatal("should have expired the password after 20 days")
+	} else if PasswordChangeRequiredBy(user) != 0 {
+		t.Fatal("shouldn't require a password change before the password expires")
+	}
+
+	*utils.Cfg.PasswordSettings.ExpiryInDays = 10
+	if PasswordChangeRequiredBy(user) != user.LastPasswordUpdate+15*day {
+		t.Fatal("should require a password change by the end of the grace period")
+	} else if checkPasswordNotExpired(user) != nil {
+		t.Fatal("should allow logging in during the grace period")
+	}
+
+	*utils.Cfg.PasswordSettings.ExpiryGracePeriodInDays = 1
+	if err := checkPasswordNotExpired(user); err == nil || err.Id != "app.password_policy.expired.app_error" {
+		t.Fatal("shouldn't allow logging in after the grace period")
+	}
+
+	user.LastPasswordUpdate = 0
+	if PasswordExpiresAt(user) != user.CreateAt+10*day {
+		t.Fatal("should have fallen back to when the user was created")
+	}
+
+	user.AuthService = model.USER_AUTH_SERVICE_GITLAB
+	if PasswordExpiresAt(user) != 0 {
+		t.Fatal("SSO users' passwords shouldn't expire")
+	}
+}
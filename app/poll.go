@@ -0,0 +1,133 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/store"
+	"github.com/mattermost/platform/utils"
+)
+
+// Checks the poll of a new poll post and starts it without any votes.
+func preparePollPost(post *model.Post) *model.AppError {
+	poll := model.PollFromPost(post)
+	if poll == nil {
+		return model.NewAppError("createPost", "api.poll.missing.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if len(strings.TrimSpace(post.Message)) == 0 {
+		return model.NewAppError("createPost", "api.poll.question.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	poll.PreSave()
+	if err := poll.IsValid(); err != nil {
+		return err
+	}
+
+	if poll.IsClosed(model.GetMillis()) {
+		return model.NewAppError("createPost", "api.poll.close_at.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	post.AddProp(model.POST_PROPS_POLL, poll)
+
+	return nil
+}
+
+func getPollPost(where string, postId string) (*model.Post, *model.Poll, *model.AppError) {
+	post, err := GetSinglePost(postId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	poll := model.PollFromPost(post)
+	if poll == nil {
+		return nil, nil, model.NewAppError(where, "api.poll.not_poll.app_error", nil, "post_id="+postId, http.StatusBadRequest)
+	}
+
+	return post, poll, nil
+}
+
+// VoteInPoll replaces a user's votes in a poll with votes for the given options, which can be empty to take back
+// their vote.
+func VoteInPoll(postId string, userId string, optionIds []string) (*model.Post, *model.AppError) {
+	post, poll, err := getPollPost("VoteInPoll", postId)
+	if err != nil {
+		return nil, err
+	}
+
+	if poll.IsClosed(model.GetMillis()) {
+		return nil, model.NewAppError("VoteInPoll", "api.poll.closed.app_error", nil, "post_id="+postId, http.StatusBadRequest)
+	}
+
+	if err := CheckChannelIsNotArchived("VoteInPoll", post.ChannelId); err != nil {
+		return nil, err
+	}
+
+	optionIds = utils.RemoveDuplicatesFromStringArray(optionIds)
+	if len(optionIds) > 1 && !poll.MultipleChoice {
+		return nil, model.NewAppError("VoteInPoll", "api.poll.single_choice.app_error", nil, "post_id="+postId, http.StatusBadRequest)
+	}
+
+	for _, optionId := range optionIds {
+		if !poll.HasOption(optionId) {
+			return nil, model.NewAppError("VoteInPoll", "api.poll.option.app_error", nil, "option_id="+optionId, http.StatusBadRequest)
+		}
+	}
+
+	// the poll may have closed since it was read, so the store checks again while it holds the post
+	return publishPoll(<-Srv.Store.Poll().SetVotes(postId, userId, optionIds, model.GetMillis()))
+}
+
+// ClosePoll stops any more votes from being made in a poll.
+func ClosePoll(postId string) (*model.Post, *model.AppError) {
+	post, poll, err := getPollPost("ClosePoll", postId)
+	if err != nil {
+		return nil, err
+	}
+
+	now := model.GetMillis()
+	if poll.IsClosed(now) {
+		return post, nil
+	}
+
+	return publishPoll(<-Srv.Store.Poll().Close(postId, now))
+}
+
+// GetPollVotes returns the votes in a poll that a user can see, which is only their own votes in anonymous polls.
+func GetPollVotes(postId string, userId string) ([]*model.PollVote, *model.AppError) {
+	_, poll, err := getPollPost("GetPollVotes", postId)
+	if err != nil {
+		return nil, err
+	}
+
+	var result store.StoreResult
+	if poll.Anonymous {
+		result = <-Srv.Store.Poll().GetVotesForUser(postId, userId)
+	} else {
+		result = <-Srv.Store.Poll().GetVotes(postId)
+	}
+
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	return result.Data.([]*model.PollVote), nil
+}
+
+// Lets everyone in the channel know about a poll that the store has updated.
+func publishPoll(result store.StoreResult) (*model.Post, *model.AppError) {
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	rpost := result.Data.(*model.Post)
+
+	sendUpdatedPostEvent(rpost)
+	InvalidateCacheForChannelPosts(rpost.ChannelId)
+
+	return rpost, nil
+}
@@ -88,11 +88,25 @@ func CreatePostAsUser(post *model.Post, siteURL string) (*model.Post, *model.App
 }
 
 func CreatePost(post *model.Post, teamId string, triggerWebhooks bool, siteURL string) (*model.Post, *model.AppError) {
+	cchan := Srv.Store.Channel().Get(post.ChannelId, true)
+
 	var pchan store.StoreChannel
 	if len(post.RootId) > 0 {
 		pchan = Srv.Store.Post().Get(post.RootId)
 	}
 
+	// Archived channels are read-only
+	if result := <-cchan; result.Err != nil {
+		return nil, result.Err
+	} else if channel := result.Data.(*model.Channel); channel.DeleteAt != 0 {
+		return nil, model.NewAppError("createPost", "api.post.create_post.can_not_post_to_deleted.error", nil, "channel_id="+post.ChannelId, http.StatusBadRequest)
+	} else if !post.IsSystemMessage() {
+		// Webhook posts have nobody watching the channel to tell why they were rejected
+		if err := CheckUserCanPostInChannel("createPost", post.UserId, channel, post.Props["from_webhook"] != "true"); err != nil {
+			return nil, err
+		}
+	}
+
 	// Verify the parent/child relationships are correct
 	if pchan != nil {
 		if presult := <-pchan; presult.Err != nil {
@@ -116,6 +130,22 @@ func CreatePost(post *model.Post, teamId string, triggerWebhooks bool, siteURL s
 		}
 	}
 
+	if post.Type == model.POST_POLL {
+		if err := preparePollPost(post); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := preparePostPriority(post); err != nil {
+		return nil, err
+	}
+
+	if err := checkPostPriorityPermission(post); err != nil {
+		return nil, err
+	}
+
+	setPermalinkEmbed(post)
+
 	post.Hashtags, _ = model.ParseHashtags(post.Message)
 
 	var rpost *model.Post
@@ -144,6 +174,10 @@ func CreatePost(post *model.Post, teamId string, triggerWebhooks bool, siteURL s
 		}
 	}
 
+	if len(rpost.RootId) > 0 {
+		updateThreadStats(rpost.RootId)
+	}
+
 	if err := handlePostEvents(rpost, teamId, triggerWebhooks, siteURL); err != nil {
 		return nil, err
 	}
@@ -286,6 +320,10 @@ func UpdatePost(post *model.Post) (*model.Post, *model.AppError) {
 			return nil, err
 		}
 
+		if err := CheckChannelIsNotArchived("updatePost", oldPost.ChannelId); err != nil {
+			return nil, err
+		}
+
 		if utils.IsLicensed {
 			if *utils.Cfg.ServiceSettings.AllowEditPost == model.ALLOW_EDIT_POST_TIME_LIMIT && model.GetMillis() > oldPost.CreateAt+int64(*utils.Cfg.ServiceSettings.PostEditTimeLimit*1000) {
 				err := model.NewLocAppError("updatePost", "api.post.update_post.permissions_time_limit.app_error", map[string]interface{}{"timeLimit": *utils.Cfg.ServiceSettings.PostEditTimeLimit}, "")
@@ -300,6 +338,25 @@ func UpdatePost(post *model.Post) (*model.Post, *model.AppError) {
 
 	newPost.Message = post.Message
 	newPost.Props = post.Props
+	if oldPost.Type == model.POST_POLL {
+		// polls only change when users vote in them or they're closed
+		newPost.AddProp(model.POST_PROPS_POLL, oldPost.Props[model.POST_PROPS_POLL])
+	}
+	// the priority of a post can't change once people have been notified about it
+	for _, key := range []string{model.POST_PROPS_PRIORITY, model.POST_PROPS_REQUESTED_ACK} {
+		if value, ok := oldPost.Props[key]; ok {
+			newPost.AddProp(key, value)
+		} else {
+			delete(newPost.Props, key)
+		}
+	}
+	if newPost.Message != oldPost.Message {
+		setPermalinkEmbed(newPost)
+	} else if embed := model.PermalinkEmbedFromPost(oldPost); embed != nil {
+		newPost.AddProp(model.POST_PROPS_PERMALINK_EMBED, &model.PermalinkEmbed{PostId: embed.PostId})
+	} else {
+		delete(newPost.Props, model.POST_PROPS_PERMALINK_EMBED)
+	}
 	newPost.EditAt = model.GetMillis()
 	newPost.Hashtags, _ = model.ParseHashtags(post.Message)
 	newPost.IsPinned = post.IsPinned
@@ -338,6 +395,39 @@ func PatchPost(postId string, patch *model.PostPatch) (*model.Post, *model.AppEr
 	return updatedPost, nil
 }
 
+// GetPostRevisions returns what a post said before each time that it was edited, starting with the most recent.
+func GetPostRevisions(postId string) (*model.PostList, *model.AppError) {
+	if result := <-Srv.Store.Post().GetRevisions(postId); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.PostList), nil
+	}
+}
+
+// RestorePostRevision edits a post back to what it said in one of its previous versions, which keeps the version
+// being replaced as another revision.
+func RestorePostRevision(postId string, revisionId string) (*model.Post, *model.AppError) {
+	post, err := GetSinglePost(postId)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions, err := GetPostRevisions(postId)
+	if err != nil {
+		return nil, err
+	}
+
+	revision, ok := revisions.Posts[revisionId]
+	if !ok {
+		return nil, model.NewAppError("RestorePostRevision", "api.post.restore_post_revision.not_found.app_error", nil, "post_id="+postId+", revision_id="+revisionId, http.StatusNotFound)
+	}
+
+	post.Message = revision.Message
+	post.FileIds = revision.FileIds
+
+	return UpdatePost(post)
+}
+
 func sendUpdatedPostEvent(post *model.Post) {
 	message := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_POST_EDITED, "", post.ChannelId, "", nil)
 	message.Add("post", post.ToJson())
@@ -422,8 +512,11 @@ func GetPermalinkPost(postId string, userId string, siteURL string) (*model.Post
 			return nil, err
 		}
 
-		if err = JoinChannel(channel, userId, siteURL); err != nil {
-			return nil, err
+		// Archived channels can't be joined, but can still be read by anyone allowed to join them
+		if channel.DeleteAt == 0 {
+			if err = JoinChannel(channel, userId, siteURL); err != nil {
+				return nil, err
+			}
 		}
 
 		return list, nil
@@ -468,10 +561,18 @@ func DeletePost(postId string) (*model.Post, *model.AppError) {
 	} else {
 		post := result.Data.(*model.Post)
 
+		if err := CheckChannelIsNotArchived("deletePost", post.ChannelId); err != nil {
+			return nil, err
+		}
+
 		if result := <-Srv.Store.Post().Delete(postId, model.GetMillis()); result.Err != nil {
 			return nil, result.Err
 		}
 
+		if len(post.RootId) > 0 {
+			updateThreadStats(post.RootId)
+		}
+
 		message := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_POST_DELETED, "", post.ChannelId, "", nil)
 		message.Add("post", post.ToJson())
 
@@ -0,0 +1,283 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+	"sort"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+// Checks the priority of a new post and whether it requests acknowledgements, which are only allowed on root posts.
+func preparePostPriority(post *model.Post) *model.AppError {
+	priority, hasPriority := post.Props[model.POST_PROPS_PRIORITY]
+	requestedAck, hasRequestedAck := post.Props[model.POST_PROPS_REQUESTED_ACK]
+
+	if !hasPriority && !hasRequestedAck {
+		return nil
+	}
+
+	if hasPriority {
+		if value, ok := priority.(string); !ok || (value != "" && !model.IsValidPostPriority(value)) {
+			return model.NewAppError("createPost", "api.post.create_post.priority.app_error", nil, "", http.StatusBadRequest)
+		} else if value == "" {
+			delete(post.Props, model.POST_PROPS_PRIORITY)
+		}
+	}
+
+	if hasRequestedAck {
+		if value, ok := requestedAck.(bool); !ok {
+			return model.NewAppError("createPost", "api.post.create_post.requested_ack.app_error", nil, "", http.StatusBadRequest)
+		} else if !value {
+			delete(post.Props, model.POST_PROPS_REQUESTED_ACK)
+		}
+	}
+
+	if post.RootId != "" && (post.GetPriority() != "" || post.RequestsAcknowledgement()) {
+		return model.NewAppError("createPost", "api.post.create_post.priority_reply.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+// Urgent posts and requests for acknowledgements keep notifying people until they respond, so only users allowed to
+// set the priority of posts in the channel can make them.
+func checkPostPriorityPermission(post *model.Post) *model.AppError {
+	if !post.IsUrgent() && !post.RequestsAcknowledgement() {
+		return nil
+	}
+
+	if !HasPermissionToChannel(post.UserId, post.ChannelId, model.PERMISSION_SET_POST_PRIORITY) {
+		return model.NewAppError("createPost", "api.post.create_post.priority_permission.app_error", nil, "user_id="+post.UserId, http.StatusForbidden)
+	}
+
+	return nil
+}
+
+func getPostRequestingAcknowledgement(where string, postId string) (*model.Post, *model.AppError) {
+	post, err := GetSinglePost(postId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !post.RequestsAcknowledgement() {
+		return nil, model.NewAppError(where, "api.post_acknowledgement.not_requested.app_error", nil, "post_id="+postId, http.StatusBadRequest)
+	}
+
+	return post, nil
+}
+
+// AcknowledgePost records that a user has acknowledged a post and stops any push notifications repeating for it.
+func AcknowledgePost(postId string, userId string) (*model.PostAcknowledgement, *model.AppError) {
+	post, err := getPostRequestingAcknowledgement("AcknowledgePost", postId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := CheckChannelIsNotArchived("AcknowledgePost", post.ChannelId); err != nil {
+		return nil, err
+	}
+
+	if result := <-Srv.Store.PostAcknowledgement().Get(postId, userId); result.Err == nil {
+		return result.Data.(*model.PostAcknowledgement), nil
+	} else if result.Err.StatusCode != http.StatusNotFound {
+		return nil, result.Err
+	}
+
+	var ack *model.PostAcknowledgement
+	if result := <-Srv.Store.PostAcknowledgement().Save(&model.PostAcknowledgement{PostId: postId, UserId: userId}); result.Err != nil {
+		return nil, result.Err
+	} else {
+		ack = result.Data.(*model.PostAcknowledgement)
+	}
+
+	if result := <-Srv.Store.PostAcknowledgement().DeleteUrgentPush(postId, userId); result.Err != nil {
+		l4g.Warn(utils.T("api.post_acknowledgement.delete_urgent_push.warn"), postId, userId, result.Err)
+	}
+
+	sendPostAcknowledgementEvent(model.WEBSOCKET_EVENT_POST_ACKNOWLEDGED, post, ack)
+
+	return ack, nil
+}
+
+// UnacknowledgePost takes back a user's acknowledgement of a post.
+func UnacknowledgePost(postId string, userId string) *model.AppError {
+	post, err := getPostRequestingAcknowledgement("UnacknowledgePost", postId)
+	if err != nil {
+		return err
+	}
+
+	if err := CheckChannelIsNotArchived("UnacknowledgePost", post.ChannelId); err != nil {
+		return err
+	}
+
+	var ack *model.PostAcknowledgement
+	if result := <-Srv.Store.PostAcknowledgement().Get(postId, userId); result.Err != nil {
+		return result.Err
+	} else {
+		ack = result.Data.(*model.PostAcknowledgement)
+	}
+
+	if result := <-Srv.Store.PostAcknowledgement().Delete(postId, userId); result.Err != nil {
+		return result.Err
+	}
+
+	sendPostAcknowledgementEvent(model.WEBSOCKET_EVENT_POST_UNACKNOWLEDGED, post, ack)
+
+	return nil
+}
+
+func sendPostAcknowledgementEvent(event string, post *model.Post, ack *model.PostAcknowledgement) {
+	message := model.NewWebSocketEvent(event, "", post.ChannelId, "", nil)
+	message.Add("acknowledgement", ack.ToJson())
+	Publish(message)
+}
+
+func GetPostAcknowledgements(postId string) ([]*model.PostAcknowledgement, *model.AppError) {
+	if result := <-Srv.Store.PostAcknowledgement().GetForPost(postId); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.([]*model.PostAcknowledgement), nil
+	}
+}
+
+// GetUnacknowledgedUserIds returns the ids of the members of a post's channel, other than its author, who haven't
+// acknowledged it yet.
+func GetUnacknowledgedUserIds(postId string) ([]string, *model.AppError) {
+	post, err := getPostRequestingAcknowledgement("GetUnacknowledgedUserIds", postId)
+	if err != nil {
+		return nil, err
+	}
+
+	pchan := Srv.Store.User().GetAllProfilesInChannel(post.ChannelId, true)
+
+	acks, err := GetPostAcknowledgements(postId)
+	if err != nil {
+		return nil, err
+	}
+
+	acknowledged := make(map[string]bool, len(acks))
+	for _, ack := range acks {
+		acknowledged[ack.UserId] = true
+	}
+
+	var profiles map[string]*model.User
+	if result := <-pchan; result.Err != nil {
+		return nil, result.Err
+	} else {
+		profiles = result.Data.(map[string]*model.User)
+	}
+
+	userIds := []string{}
+	for id := range profiles {
+		if id != post.UserId && !acknowledged[id] {
+			userIds = append(userIds, id)
+		}
+	}
+	sort.Strings(userIds)
+
+	return userIds, nil
+}
+
+// Starts repeating the push notifications sent about an urgent post until the users they were sent to acknowledge it.
+func saveUrgentPostPushes(post *model.Post, userIds []string) {
+	if !post.IsUrgent() || !post.RequestsAcknowledgement() || len(userIds) == 0 {
+		return
+	}
+
+	nextPushAt := model.GetMillis() + model.URGENT_POST_PUSH_INTERVAL
+
+	pushes := make([]*model.UrgentPostPush, 0, len(userIds))
+	for _, userId := range userIds {
+		if userId != post.UserId {
+			pushes = append(pushes, &model.UrgentPostPush{PostId: post.Id, UserId: userId, PushCount: 1, NextPushAt: nextPushAt})
+		}
+	}
+
+	if result := <-Srv.Store.PostAcknowledgement().SaveUrgentPushes(pushes); result.Err != nil {
+		l4g.Error(utils.T("api.post_acknowledgement.save_urgent_pushes.error"), post.Id, result.Err)
+	}
+}
+
+// ProcessDueUrgentPostPushes repeats the push notifications for urgent posts that haven't been acknowledged yet.
+func ProcessDueUrgentPostPushes() {
+	var pushes []*model.UrgentPostPush
+	if result := <-Srv.Store.PostAcknowledgement().GetDueUrgentPushes(model.GetMillis(), POST_SCHEDULER_BATCH_SIZE); result.Err != nil {
+		l4g.Error(utils.T("api.post_acknowledgement.get_due_urgent_pushes.error"), result.Err)
+		return
+	} else {
+		pushes = result.Data.([]*model.UrgentPostPush)
+	}
+
+	for _, push := range pushes {
+		// another server may have already claimed it
+		if result := <-Srv.Store.PostAcknowledgement().ClaimUrgentPush(push, model.GetMillis()+model.URGENT_POST_PUSH_INTERVAL); result.Err != nil {
+			l4g.Error(utils.T("api.post_acknowledgement.update_urgent_push.error"), push.PostId, push.UserId, result.Err)
+			continue
+		} else if !result.Data.(bool) {
+			continue
+		}
+
+		if repeatUrgentPostPush(push) && push.PushCount < model.URGENT_POST_MAX_PUSHES {
+			if result := <-Srv.Store.PostAcknowledgement().UpdateUrgentPush(push); result.Err == nil {
+				continue
+			} else {
+				l4g.Error(utils.T("api.post_acknowledgement.update_urgent_push.error"), push.PostId, push.UserId, result.Err)
+			}
+		}
+
+		if result := <-Srv.Store.PostAcknowledgement().DeleteUrgentPush(push.PostId, push.UserId); result.Err != nil {
+			l4g.Error(utils.T("api.post_acknowledgement.delete_urgent_push.warn"), push.PostId, push.UserId, result.Err)
+		}
+	}
+}
+
+// Sends another push notification about an urgent post, returning false if the user no longer needs to be reminded
+// of it because it has been acknowledged or deleted or the user can't see it anymore.
+func repeatUrgentPostPush(push *model.UrgentPostPush) bool {
+	if !*utils.Cfg.EmailSettings.SendPushNotifications {
+		return false
+	}
+
+	if result := <-Srv.Store.PostAcknowledgement().Get(push.PostId, push.UserId); result.Err == nil {
+		return false
+	}
+
+	post, err := GetSinglePost(push.PostId)
+	if err != nil {
+		return false
+	}
+
+	if result := <-Srv.Store.Channel().GetMember(post.ChannelId, push.UserId); result.Err != nil {
+		return false
+	}
+
+	channel, err := GetChannel(post.ChannelId)
+	if err != nil || channel.DeleteAt != 0 {
+		return false
+	}
+
+	user, err := GetUser(push.UserId)
+	if err != nil || user.DeleteAt != 0 {
+		return false
+	}
+
+	senderName := ""
+	if value, ok := post.Props["override_username"].(string); ok && post.Props["from_webhook"] == "true" {
+		senderName = value
+	} else if sender, err := GetUser(post.UserId); err == nil {
+		senderName = sender.Username
+	}
+
+	if err := sendPushNotification(post, user, channel, senderName, channel.DisplayName, true); err != nil {
+		l4g.Error(utils.T("api.post_acknowledgement.repeat_urgent_push.error"), post.Id, user.Id, err)
+	}
+
+	push.PushCount++
+
+	return true
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestPreparePostPriority(t *testing.T) {
+	post := &model.Post{}
+	post.AddProp(model.POST_PROPS_PRIORITY, model.POST_PRIORITY_URGENT)
+	post.AddProp(model.POST_PROPS_REQUESTED_ACK, true)
+	if err := preparePostPriority(post); err != nil || !post.IsUrgent() || !post.RequestsAcknowledgement() {
+		t.Fatal("should have accepted the priority")
+	}
+
+	post = &model.Post{}
+	post.AddProp(model.POST_PROPS_PRIORITY, "")
+	post.AddProp(model.POST_PROPS_REQUESTED_ACK, false)
+	if err := preparePostPriority(post); err != nil || len(post.Props) != 0 {
+		t.Fatal("should have removed the empty props")
+	}
+
+	post = &model.Post{}
+	post.AddProp(model.POST_PROPS_PRIORITY, "junk")
+	if err := preparePostPriority(post); err == nil {
+		t.Fatal("should have rejected the priority")
+	}
+
+	post = &model.Post{}
+	post.AddProp(model.POST_PROPS_REQUESTED_ACK, "yes")
+	if err := preparePostPriority(post); err == nil {
+		t.Fatal("should have rejected the request for acknowledgements")
+	}
+
+	post = &model.Post{RootId: model.NewId()}
+	post.AddProp(model.POST_PROPS_PRIORITY, model.POST_PRIORITY_IMPORTANT)
+	if err := preparePostPriority(post); err == nil {
+		t.Fatal("should have rejected a priority on a reply")
+	}
+}
+
+func TestShouldSendPushNotificationForUrgentPost(t *testing.T) {
+	user := &model.User{Id: model.NewId(), NotifyProps: map[string]string{model.PUSH_NOTIFY_PROP: model.USER_NOTIFY_ALL, "push_status": model.STATUS_AWAY}}
+	channelNotifyProps := map[string]string{model.PUSH_NOTIFY_PROP: model.CHANNEL_NOTIFY_DEFAULT}
+	online := &model.Status{UserId: user.Id, Status: model.STATUS_ONLINE, LastActivityAt: model.GetMillis()}
+
+	post := &model.Post{UserId: model.NewId(), ChannelId: model.NewId()}
+	if ShouldSendPushNotification(user, channelNotifyProps, true, online, post) {
+		t.Fatal("shouldn't push to an online user")
+	}
+
+	post.AddProp(model.POST_PROPS_PRIORITY, model.POST_PRIORITY_URGENT)
+	if !ShouldSendPushNotification(user, channelNotifyProps, true, online, post) {
+		t.Fatal("should push urgent posts to an online user")
+	}
+
+	channelNotifyProps[model.PUSH_NOTIFY_PROP] = model.USER_NOTIFY_NONE
+	if ShouldSendPushNotification(user, channelNotifyProps, true, online, post) {
+		t.Fatal("should still respect the channel's notification preferences")
+	}
+}
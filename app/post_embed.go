@@ -0,0 +1,182 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/mattermost/platform/model"
+)
+
+// Matches permalinks of the form <site url>/<team name>/pl/<post id>
+var permalinkRegex = regexp.MustCompile(`(?:^|[\s(<])\S*/[A-Za-z0-9_\-]+/pl/([a-z0-9]{26})\b`)
+
+// Returns the id of the first post linked to from a message or an empty string if it doesn't link to any.
+func parsePermalinkPostId(message string) string {
+	if !strings.Contains(message, "/pl/") {
+		return ""
+	}
+
+	if matches := permalinkRegex.FindStringSubmatch(message); matches != nil {
+		return matches[1]
+	}
+
+	return ""
+}
+
+// Replaces whatever the client sent for the permalink embed of a post with the id of the first post that its message
+// links to. Only the id is stored since what the embed shows depends on who is viewing the post, so it's filled in
+// by PreparePostForUser when the post is sent to a client.
+func setPermalinkEmbed(post *model.Post) {
+	if post.Props != nil {
+		delete(post.Props, model.POST_PROPS_PERMALINK_EMBED)
+	}
+
+	if post.IsSystemMessage() {
+		return
+	}
+
+	postId := parsePermalinkPostId(post.Message)
+	if postId == "" || postId == post.Id {
+		return
+	}
+
+	if result := <-Srv.Store.Post().GetSingle(postId); result.Err != nil {
+		// not a link to a post on this server
+		return
+	}
+
+	post.AddProp(model.POST_PROPS_PERMALINK_EMBED, &model.PermalinkEmbed{PostId: postId})
+}
+
+// Fills in the permalink embeds of posts for a single user, remembering what it has already looked up so that a list
+// of posts linking to the same post or channel only checks it once.
+type permalinkEmbedResolver struct {
+	userId  string
+	canRead map[string]bool
+	embeds  map[string]*model.PermalinkEmbed
+}
+
+func newPermalinkEmbedResolver(userId string) *permalinkEmbedResolver {
+	return &permalinkEmbedResolver{
+		userId:  userId,
+		canRead: make(map[string]bool),
+		embeds:  make(map[string]*model.PermalinkEmbed),
+	}
+}
+
+func (r *permalinkEmbedResolver) getEmbed(postId string) *model.PermalinkEmbed {
+	if embed, ok := r.embeds[postId]; ok {
+		return embed
+	}
+
+	embed := &model.PermalinkEmbed{PostId: postId, Unavailable: true}
+
+	if result := <-Srv.Store.Post().GetSingle(postId); result.Err == nil {
+		linked := result.Data.(*model.Post)
+
+		canRead, ok := r.canRead[linked.ChannelId]
+		if !ok {
+			canRead = HasPermissionToChannel(r.userId, linked.ChannelId, model.PERMISSION_READ_CHANNEL)
+			r.canRead[linked.ChannelId] = canRead
+		}
+
+		if canRead {
+			embed = model.NewPermalinkEmbed(linked)
+		}
+	}
+
+	r.embeds[postId] = embed
+	return embed
+}
+
+func (r *permalinkEmbedResolver) preparePost(post *model.Post) *model.Post {
+	if post == nil {
+		return nil
+	}
+
+	embed := model.PermalinkEmbedFromPost(post)
+	if embed == nil {
+		return post
+	}
+
+	// posts can be shared with other requests through the caches, so the embed is only ever set on a copy
+	rpost := *post
+	rpost.Props = make(model.StringInterface, len(post.Props))
+	for key, value := range post.Props {
+		rpost.Props[key] = value
+	}
+	rpost.Props[model.POST_PROPS_PERMALINK_EMBED] = r.getEmbed(embed.PostId)
+
+	return &rpost
+}
+
+// PreparePostForUser returns a post as it should be shown to a user, with its permalink embed showing the current
+// version of the linked post if the user can read it and marked as unavailable otherwise.
+func PreparePostForUser(post *model.Post, userId string) *model.Post {
+	return newPermalinkEmbedResolver(userId).preparePost(post)
+}
+
+// PreparePostListForUser returns a list of posts as they should be shown to a user. See PreparePostForUser.
+func PreparePostListForUser(list *model.PostList, userId string) *model.PostList {
+	if list == nil {
+		return nil
+	}
+
+	resolver := newPermalinkEmbedResolver(userId)
+
+	rlist := &model.PostList{
+		Order: list.Order,
+		Posts: make(map[string]*model.Post, len(list.Posts)),
+	}
+	for id, post := range list.Posts {
+		rlist.Posts[id] = resolver.preparePost(post)
+	}
+
+	return rlist
+}
+
+// PrepareThreadsForUser sets the root posts of a user's threads to how they should be shown to them. See
+// PreparePostForUser.
+func PrepareThreadsForUser(threads []*model.Thread, userId string) []*model.Thread {
+	resolver := newPermalinkEmbedResolver(userId)
+
+	for _, thread := range threads {
+		thread.Post = resolver.preparePost(thread.Post)
+	}
+
+	return threads
+}
+
+// Forwards a post into another channel by posting a link to it along with an optional comment, which embeds the
+// forwarded post with its original author and channel.
+func ForwardPost(postId string, channelId string, userId string, comment string, siteURL string) (*model.Post, *model.AppError) {
+	post, err := GetSinglePost(postId)
+	if err != nil {
+		return nil, err
+	}
+
+	if post.IsSystemMessage() {
+		return nil, model.NewAppError("ForwardPost", "api.post.forward_post.system_message.app_error", nil, "post_id="+postId, http.StatusBadRequest)
+	}
+
+	if !HasPermissionToChannel(userId, post.ChannelId, model.PERMISSION_READ_CHANNEL) {
+		return nil, model.NewAppError("ForwardPost", "api.post.forward_post.permissions.app_error", nil, "post_id="+postId, http.StatusForbidden)
+	}
+
+	message := getPostPermalink(post, userId)
+	if comment = strings.TrimSpace(comment); comment != "" {
+		message = comment + "\n" + message
+	}
+
+	forwarded := &model.Post{
+		ChannelId: channelId,
+		UserId:    userId,
+		Message:   message,
+	}
+
+	return CreatePostAsUser(forwarded, siteURL)
+}
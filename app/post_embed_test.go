@@ -0,0 +1,26 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"testing"
+)
+
+func TestParsePermalinkPostId(t *testing.T) {
+	id := "abcdefghijklmnopqrstuvwxyz"
+
+	for message, expected := range map[string]string{
+		"https://chat.example.com/team/pl/" + id:                      id,
+		"see http://localhost:8065/my-team/pl/" + id + " for details": id,
+		"(https://chat.example.com/team_1/pl/" + id + ")":             id,
+		"https://chat.example.com/team/pl/" + id + "0":                "",
+		"https://chat.example.com/team/pl/" + id[:20]:                 "",
+		"text" + id:     "",
+		"no links here": "",
+	} {
+		if actual := parsePermalinkPostId(message); actual != expected {
+			t.Fatalf("%v: expected %v, got %v", message, expected, actual)
+		}
+	}
+}
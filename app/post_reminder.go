@@ -0,0 +1,131 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func CreatePostReminder(reminder *model.PostReminder) (*model.PostReminder, *model.AppError) {
+	if reminder.RemindAt <= model.GetMillis() {
+		return nil, model.NewAppError("CreatePostReminder", "api.post_reminder.past.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if _, err := GetSinglePost(reminder.PostId); err != nil {
+		return nil, err
+	}
+
+	if result := <-Srv.Store.PostReminder().Save(reminder); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.PostReminder), nil
+	}
+}
+
+func GetPostReminder(id string) (*model.PostReminder, *model.AppError) {
+	if result := <-Srv.Store.PostReminder().Get(id); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.PostReminder), nil
+	}
+}
+
+func GetPostRemindersForUser(userId string) ([]*model.PostReminder, *model.AppError) {
+	if result := <-Srv.Store.PostReminder().GetForUser(userId); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.([]*model.PostReminder), nil
+	}
+}
+
+func DeletePostReminder(id string) *model.AppError {
+	if result := <-Srv.Store.PostReminder().Delete(id); result.Err != nil {
+		return result.Err
+	}
+
+	return nil
+}
+
+// ProcessDuePostReminders sends every post reminder that's due.
+func ProcessDuePostReminders() {
+	result := <-Srv.Store.PostReminder().GetDue(model.GetMillis(), POST_SCHEDULER_BATCH_SIZE)
+	if result.Err != nil {
+		l4g.Error(utils.T("app.post_reminder.process.error"), result.Err.Error())
+		return
+	}
+
+	for _, reminder := range result.Data.([]*model.PostReminder) {
+		sendPostReminder(reminder)
+	}
+}
+
+// Reminds a user about a post with a link to it in their direct channel with themselves, as long as they can still
+// read the post.
+func sendPostReminder(reminder *model.PostReminder) {
+	// another server may have already sent it
+	if result := <-Srv.Store.PostReminder().Delete(reminder.Id); result.Err != nil {
+		l4g.Error(utils.T("app.post_reminder.process.error"), result.Err.Error())
+		return
+	} else if !result.Data.(bool) {
+		return
+	}
+
+	user, err := GetUser(reminder.UserId)
+	if err != nil || user.DeleteAt != 0 {
+		return
+	}
+
+	post, err := GetSinglePost(reminder.PostId)
+	if err != nil || !HasPermissionToChannel(user.Id, post.ChannelId, model.PERMISSION_READ_CHANNEL) {
+		return
+	}
+
+	T := utils.GetUserTranslations(user.Locale)
+	message := T("app.post_reminder.message", map[string]interface{}{"Link": getPostPermalink(post, user.Id)})
+
+	if err := sendPostToSelf(user.Id, message, model.POST_REMINDER, model.StringInterface{"post_id": post.Id}); err != nil {
+		l4g.Error(utils.T("app.post_reminder.process.error"), err.Error())
+	}
+}
+
+// Returns a link to a post on the team of its channel, or on one of the user's teams for direct and group messages.
+func getPostPermalink(post *model.Post, userId string) string {
+	teamName := ""
+
+	if channel, err := GetChannel(post.ChannelId); err == nil && channel.TeamId != "" {
+		if team, err := GetTeam(channel.TeamId); err == nil {
+			teamName = team.Name
+		}
+	} else if teams, err := GetTeamsForUser(userId); err == nil && len(teams) > 0 {
+		teamName = teams[0].Name
+	}
+
+	return *utils.Cfg.ServiceSettings.SiteURL + "/" + teamName + "/pl/" + post.Id
+}
+
+// Posts a message in a user's direct channel with themselves, where it notifies them and stays until they've read it.
+func sendPostToSelf(userId string, message string, postType string, props model.StringInterface) *model.AppError {
+	channel, err := CreateDirectChannel(userId, userId)
+	if err != nil {
+		return err
+	}
+
+	post := &model.Post{
+		ChannelId: channel.Id,
+		UserId:    userId,
+		Message:   message,
+		Type:      postType,
+		Props:     props,
+	}
+
+	if _, err := CreatePost(post, "", false, *utils.Cfg.ServiceSettings.SiteURL); err != nil {
+		return err
+	}
+
+	return nil
+}
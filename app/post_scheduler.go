@@ -0,0 +1,221 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mattermost/platform/model"
+)
+
+const (
+	POST_SCHEDULER_TASK_NAME  = "Scheduled Posts"
+	POST_SCHEDULER_INTERVAL   = 30 * time.Second
+	POST_SCHEDULER_BATCH_SIZE = 100
+)
+
+var postSchedulerTask *model.ScheduledTask
+
+// StartPostScheduler starts sending scheduled posts, post reminders and repeated pushes for urgent posts once they're due.
+func StartPostScheduler() {
+	postSchedulerTask = model.CreateRecurringTask(POST_SCHEDULER_TASK_NAME, func() {
+		ProcessDueScheduledPosts()
+		ProcessDuePostReminders()
+		ProcessDueUrgentPostPushes()
+	}, POST_SCHEDULER_INTERVAL)
+}
+
+func StopPostScheduler() {
+	if postSchedulerTask != nil {
+		postSchedulerTask.Cancel()
+		postSchedulerTask = nil
+	}
+}
+
+var scheduleClockRegex = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?(am|pm)?$`)
+var scheduleDurationRegex = regexp.MustCompile(`^(\d+)([a-z]*)$`)
+
+var scheduleDurationUnits = map[string]time.Duration{
+	"m":       time.Minute,
+	"min":     time.Minute,
+	"mins":    time.Minute,
+	"minute":  time.Minute,
+	"minutes": time.Minute,
+	"h":       time.Hour,
+	"hr":      time.Hour,
+	"hrs":     time.Hour,
+	"hour":    time.Hour,
+	"hours":   time.Hour,
+	"d":       24 * time.Hour,
+	"day":     24 * time.Hour,
+	"days":    24 * time.Hour,
+	"w":       7 * 24 * time.Hour,
+	"week":    7 * 24 * time.Hour,
+	"weeks":   7 * 24 * time.Hour,
+}
+
+// Reads a time such as "in 2 hours", "at 9am", "at 17:30 tomorrow", "tomorrow" or "on 2017-07-10 at 9:30" from the
+// start of the given words, returning it along with the words that follow it. Times of day are read in the location
+// of now and days without a time start at 9am.
+func parseScheduleTime(words []string, now time.Time) (time.Time, []string, bool) {
+	if len(words) == 0 {
+		return time.Time{}, nil, false
+	}
+
+	switch strings.ToLower(words[0]) {
+	case "in":
+		duration, rest, ok := parseScheduleDuration(words[1:])
+		if !ok {
+			return time.Time{}, nil, false
+		}
+
+		return now.Add(duration), rest, true
+	case "at":
+		hour, minute, rest, ok := parseScheduleClock(words[1:])
+		if !ok {
+			return time.Time{}, nil, false
+		}
+
+		day := now
+		explicitDay := false
+		if len(rest) > 0 {
+			switch strings.ToLower(rest[0]) {
+			case "today":
+				explicitDay = true
+				rest = rest[1:]
+			case "tomorrow":
+				day = now.AddDate(0, 0, 1)
+				explicitDay = true
+				rest = rest[1:]
+			}
+		}
+
+		t := time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, now.Location())
+		if !explicitDay && !t.After(now) {
+			t = t.AddDate(0, 0, 1)
+		}
+
+		return t, rest, true
+	case "tomorrow":
+		return parseScheduleDay(now.AddDate(0, 0, 1), words[1:], now.Location())
+	case "on":
+		if len(words) < 2 {
+			return time.Time{}, nil, false
+		}
+
+		day, err := time.ParseInLocation("2006-01-02", words[1], now.Location())
+		if err != nil {
+			return time.Time{}, nil, false
+		}
+
+		return parseScheduleDay(day, words[2:], now.Location())
+	}
+
+	return time.Time{}, nil, false
+}
+
+// Reads an optional "at" time of day following a day, defaulting to 9am.
+func parseScheduleDay(day time.Time, words []string, location *time.Location) (time.Time, []string, bool) {
+	hour, minute := 9, 0
+
+	rest := words
+	if len(rest) > 0 && strings.ToLower(rest[0]) == "at" {
+		var ok bool
+		if hour, minute, rest, ok = parseScheduleClock(rest[1:]); !ok {
+			return time.Time{}, nil, false
+		}
+	}
+
+	return time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, location), rest, true
+}
+
+// Reads a duration such as "2 hours", "30m" or "1h30m".
+func parseScheduleDuration(words []string) (time.Duration, []string, bool) {
+	if len(words) == 0 {
+		return 0, nil, false
+	}
+
+	word := strings.ToLower(words[0])
+	rest := words[1:]
+
+	if match := scheduleDurationRegex.FindStringSubmatch(word); match != nil {
+		count, err := strconv.Atoi(match[1])
+		if err != nil || count <= 0 {
+			return 0, nil, false
+		}
+
+		unitName := match[2]
+		if unitName == "" {
+			if len(rest) == 0 {
+				return 0, nil, false
+			}
+
+			unitName = strings.ToLower(rest[0])
+			rest = rest[1:]
+		}
+
+		if unit, ok := scheduleDurationUnits[unitName]; ok {
+			return time.Duration(count) * unit, rest, true
+		}
+
+		return 0, nil, false
+	}
+
+	if duration, err := time.ParseDuration(word); err == nil && duration > 0 {
+		return duration, rest, true
+	}
+
+	return 0, nil, false
+}
+
+// Reads a time of day such as "9", "9am", "9:30 pm" or "17:30".
+func parseScheduleClock(words []string) (int, int, []string, bool) {
+	if len(words) == 0 {
+		return 0, 0, nil, false
+	}
+
+	word := strings.ToLower(words[0])
+	rest := words[1:]
+
+	if len(rest) > 0 && (strings.ToLower(rest[0]) == "am" || strings.ToLower(rest[0]) == "pm") {
+		word += strings.ToLower(rest[0])
+		rest = rest[1:]
+	}
+
+	match := scheduleClockRegex.FindStringSubmatch(word)
+	if match == nil {
+		return 0, 0, nil, false
+	}
+
+	hour, _ := strconv.Atoi(match[1])
+	minute := 0
+	if match[2] != "" {
+		minute, _ = strconv.Atoi(match[2])
+	}
+
+	if minute > 59 {
+		return 0, 0, nil, false
+	}
+
+	switch match[3] {
+	case "am", "pm":
+		if hour < 1 || hour > 12 {
+			return 0, 0, nil, false
+		}
+
+		hour = hour % 12
+		if match[3] == "pm" {
+			hour += 12
+		}
+	default:
+		if hour > 23 {
+			return 0, 0, nil, false
+		}
+	}
+
+	return hour, minute, rest, true
+}
@@ -0,0 +1,73 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseScheduleTime(t *testing.T) {
+	location := time.FixedZone("EST", -5*60*60)
+	now := time.Date(2017, time.July, 10, 14, 30, 0, 0, location)
+
+	for input, tc := range map[string]struct {
+		Expected time.Time
+		Rest     string
+	}{
+		"in 2 hours hello":          {now.Add(2 * time.Hour), "hello"},
+		"in 30m":                    {now.Add(30 * time.Minute), ""},
+		"in 1h30m hello there":      {now.Add(90 * time.Minute), "hello there"},
+		"IN 3 Days hello":           {now.AddDate(0, 0, 3), "hello"},
+		"at 5pm hello":              {time.Date(2017, time.July, 10, 17, 0, 0, 0, location), "hello"},
+		"at 5:15 pm hello":          {time.Date(2017, time.July, 10, 17, 15, 0, 0, location), "hello"},
+		"at 9am hello":              {time.Date(2017, time.July, 11, 9, 0, 0, 0, location), "hello"},
+		"at 16:00 tomorrow hello":   {time.Date(2017, time.July, 11, 16, 0, 0, 0, location), "hello"},
+		"at 12am":                   {time.Date(2017, time.July, 11, 0, 0, 0, 0, location), ""},
+		"tomorrow hello":            {time.Date(2017, time.July, 11, 9, 0, 0, 0, location), "hello"},
+		"tomorrow at 10:30am hello": {time.Date(2017, time.July, 11, 10, 30, 0, 0, location), "hello"},
+		"on 2017-08-01 hello":       {time.Date(2017, time.August, 1, 9, 0, 0, 0, location), "hello"},
+		"on 2017-08-01 at 8pm":      {time.Date(2017, time.August, 1, 20, 0, 0, 0, location), ""},
+	} {
+		actual, rest, ok := parseScheduleTime(strings.Fields(input), now)
+		if !ok {
+			t.Fatalf("%v: should have parsed the time", input)
+		} else if !actual.Equal(tc.Expected) {
+			t.Fatalf("%v: expected %v, got %v", input, tc.Expected, actual)
+		} else if strings.Join(rest, " ") != tc.Rest {
+			t.Fatalf("%v: returned the wrong remaining words %v", input, rest)
+		}
+	}
+
+	for _, input := range []string{
+		"",
+		"hello",
+		"in",
+		"in 2",
+		"in 2 fortnights",
+		"in -5m",
+		"at",
+		"at 25:00",
+		"at 9:75",
+		"at noon",
+		"on",
+		"on tuesday",
+		"tomorrow at",
+	} {
+		if _, _, ok := parseScheduleTime(strings.Fields(input), now); ok {
+			t.Fatalf("%v: shouldn't have parsed the time", input)
+		}
+	}
+}
+
+func TestTrimLeadingWords(t *testing.T) {
+	if actual := trimLeadingWords("  in 2 hours   hello\n  *world*  ", 3); actual != "hello\n  *world*" {
+		t.Fatalf("returned the wrong message %q", actual)
+	}
+
+	if actual := trimLeadingWords("in 2 hours", 3); actual != "" {
+		t.Fatalf("returned the wrong message %q", actual)
+	}
+}
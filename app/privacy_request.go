@@ -0,0 +1,484 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"time"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/store"
+	"github.com/mattermost/platform/utils"
+)
+
+const (
+	PRIVACY_REQUEST_TASK_NAME  = "Privacy Requests"
+	PRIVACY_REQUEST_INTERVAL   = time.Minute
+	PRIVACY_REQUEST_BATCH_SIZE = 10
+
+	DATA_EXPORT_PAGE_SIZE = 1000
+
+	DELETED_USER_PLACEHOLDER_USERNAME = "deleteduser"
+)
+
+var privacyRequestTask *model.ScheduledTask
+
+// StartPrivacyRequestProcessing starts building data exports and deleting accounts once their requests are due.
+func StartPrivacyRequestProcessing() {
+	privacyRequestTask = model.CreateRecurringTask(PRIVACY_REQUEST_TASK_NAME, ProcessDuePrivacyRequests, PRIVACY_REQUEST_INTERVAL)
+}
+
+func StopPrivacyRequestProcessing() {
+	if privacyRequestTask != nil {
+		privacyRequestTask.Cancel()
+		privacyRequestTask = nil
+	}
+}
+
+// CreatePrivacyRequest queues a data export or an account deletion. Deletions are only processed once the configured
+// cooling-off period has passed so that they can still be canceled.
+func CreatePrivacyRequest(request *model.PrivacyRequest) (*model.PrivacyRequest, *model.AppError) {
+	user, err := GetUser(request.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	request.ScheduledAt = 0
+
+	if request.Type == model.PRIVACY_REQUEST_TYPE_DELETE {
+		requests, err := GetPrivacyRequestsForUser(user.Id)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, existing := range requests {
+			if existing.Type == model.PRIVACY_REQUEST_TYPE_DELETE && existing.Status == model.PRIVACY_REQUEST_STATUS_PENDING {
+				return nil, model.NewAppError("CreatePrivacyRequest", "app.privacy_request.deletion_pending.app_error", nil, "user_id="+user.Id, http.StatusBadRequest)
+			}
+		}
+
+		request.ScheduledAt = model.GetMillis() + int64(*utils.Cfg.PrivacySettings.AccountDeletionCoolingOffInDays)*24*60*60*1000
+	}
+
+	if result := <-Srv.Store.PrivacyRequest().Save(request); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.PrivacyRequest), nil
+	}
+}
+
+func GetPrivacyRequest(id string) (*model.PrivacyRequest, *model.AppError) {
+	if result := <-Srv.Store.PrivacyRequest().Get(id); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.PrivacyRequest), nil
+	}
+}
+
+func GetPrivacyRequestsForUser(userId string) ([]*model.PrivacyRequest, *model.AppError) {
+	if result := <-Srv.Store.PrivacyRequest().GetForUser(userId); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.([]*model.PrivacyRequest), nil
+	}
+}
+
+// CancelPrivacyRequest stops a request that hasn't been processed yet.
+func CancelPrivacyRequest(request *model.PrivacyRequest) (*model.PrivacyRequest, *model.AppError) {
+	now := model.GetMillis()
+
+	if result := <-Srv.Store.PrivacyRequest().Claim(request.Id, now); result.Err != nil {
+		return nil, result.Err
+	} else if !result.Data.(bool) {
+		return nil, model.NewAppError("CancelPrivacyRequest", "app.privacy_request.cancel.not_pending.app_error", nil, "id="+request.Id, http.StatusBadRequest)
+	}
+
+	request.ProcessedAt = now
+	request.Status = model.PRIVACY_REQUEST_STATUS_CANCELED
+
+	if result := <-Srv.Store.PrivacyRequest().Update(request); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.PrivacyRequest), nil
+	}
+}
+
+// GetDataExport opens the zip file built for a finished export request. The returned reader must be closed.
+func GetDataExport(request *model.PrivacyRequest) (io.ReadCloser, *model.AppError) {
+	if request.Type != model.PRIVACY_REQUEST_TYPE_EXPORT || request.Status != model.PRIVACY_REQUEST_STATUS_FINISHED {
+		return nil, model.NewAppError("GetDataExport", "app.privacy_request.export_not_ready.app_error", nil, "id="+request.Id, http.StatusBadRequest)
+	}
+
+	return ReadFileStream(request.ExportPath())
+}
+
+func ProcessDuePrivacyRequests() {
+	result := <-Srv.Store.PrivacyRequest().GetDue(model.GetMillis(), PRIVACY_REQUEST_BATCH_SIZE)
+	if result.Err != nil {
+		l4g.Error(utils.T("app.privacy_request.process.error"), result.Err.Error())
+		return
+	}
+
+	for _, request := range result.Data.([]*model.PrivacyRequest) {
+		processPrivacyRequest(request)
+	}
+}
+
+// Builds the export or deletes the account for a due request, keeping the outcome on the request and in the audit log.
+func processPrivacyRequest(request *model.PrivacyRequest) {
+	now := model.GetMillis()
+
+	// another server may have already claimed it or the request was canceled
+	if result := <-Srv.Store.PrivacyRequest().Claim(request.Id, now); result.Err != nil {
+		l4g.Error(utils.T("app.privacy_request.process.error"), result.Err.Error())
+		return
+	} else if !result.Data.(bool) {
+		return
+	}
+
+	user, err := GetUser(request.UserId)
+	if err == nil {
+		switch request.Type {
+		case model.PRIVACY_REQUEST_TYPE_EXPORT:
+			err = exportUserData(user, request.ExportPath())
+		case model.PRIVACY_REQUEST_TYPE_DELETE:
+			err = deleteUserData(user, request.Anonymize)
+		}
+	}
+
+	request.ProcessedAt = now
+	if err != nil {
+		l4g.Warn(utils.T("app.privacy_request.failed.warn"), request.Id, err.Error())
+
+		request.Status = model.PRIVACY_REQUEST_STATUS_FAILED
+		request.ErrorCode = err.Id
+	} else {
+		request.Status = model.PRIVACY_REQUEST_STATUS_FINISHED
+	}
+
+	if result := <-Srv.Store.PrivacyRequest().Update(request); result.Err != nil {
+		l4g.Error(utils.T("app.privacy_request.process.error"), result.Err.Error())
+	}
+
+	audit := &model.Audit{
+		UserId:    request.UserId,
+		Action:    "privacy_request/" + request.Type,
+		ExtraInfo: fmt.Sprintf("request_id=%v status=%v anonymize=%v requester_id=%v", request.Id, request.Status, request.Anonymize, request.RequesterId),
+	}
+	if result := <-Srv.Store.Audit().Save(audit); result.Err != nil {
+		l4g.Error(utils.T("app.privacy_request.process.error"), result.Err.Error())
+	}
+}
+
+// Builds the export in a temporary file before storing it, so that it never has to be held in memory.
+func exportUserData(user *model.User, path string) *model.AppError {
+	f, err := ioutil.TempFile("", "data_export_")
+	if err != nil {
+		return model.NewAppError("exportUserData", "app.privacy_request.export.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	if err := BuildUserDataExport(user, f); err != nil {
+		return err
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return model.NewAppError("exportUserData", "app.privacy_request.export.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	return WriteFileFromReader(f, path)
+}
+
+// BuildUserDataExport writes a zip file holding a user's profile, preferences, posts, uploaded files and audits. Posts
+// and audits are written a page at a time and files one at a time, so that large accounts don't need to fit in memory.
+func BuildUserDataExport(user *model.User, out io.Writer) *model.AppError {
+	w := zip.NewWriter(out)
+
+	profile := *user
+	profile.Sanitize(map[string]bool{})
+	if err := writeDataExportEntry(w, "user.json", []byte(profile.ToJson())); err != nil {
+		return err
+	}
+
+	if result := <-Srv.Store.Preference().GetAll(user.Id); result.Err != nil {
+		return result.Err
+	} else {
+		preferences := result.Data.(model.Preferences)
+		if err := writeDataExportEntry(w, "preferences.json", []byte(preferences.ToJson())); err != nil {
+			return err
+		}
+	}
+
+	if err := writeDataExportPages(w, "posts.json", func(offset int) ([]interface{}, *model.AppError) {
+		result := <-Srv.Store.Post().GetPostsByUser(user.Id, offset, DATA_EXPORT_PAGE_SIZE)
+		if result.Err != nil {
+			return nil, result.Err
+		}
+
+		page := []interface{}{}
+		for _, post := range result.Data.([]*model.Post) {
+			page = append(page, post)
+		}
+		return page, nil
+	}); err != nil {
+		return err
+	}
+
+	if result := <-Srv.Store.FileInfo().GetForUser(user.Id, false); result.Err != nil {
+		return result.Err
+	} else {
+		infos := result.Data.([]*model.FileInfo)
+
+		if err := writeDataExportJson(w, "files.json", infos); err != nil {
+			return err
+		}
+
+		for _, info := range infos {
+			if err := writeDataExportFile(w, "files/"+info.Id+"/"+info.Name, info.Path); err != nil {
+				l4g.Warn(utils.T("app.privacy_request.export_file.warn"), info.Id, err.Error())
+			}
+		}
+	}
+
+	if err := writeDataExportPages(w, "audits.json", func(offset int) ([]interface{}, *model.AppError) {
+		result := <-Srv.Store.Audit().Get(user.Id, offset, DATA_EXPORT_PAGE_SIZE)
+		if result.Err != nil {
+			return nil, result.Err
+		}
+
+		page := []interface{}{}
+		for _, audit := range result.Data.(model.Audits) {
+			page = append(page, audit)
+		}
+		return page, nil
+	}); err != nil {
+		return err
+	}
+
+	if err := w.Close(); err != nil {
+		return model.NewAppError("BuildUserDataExport", "app.privacy_request.export.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	return nil
+}
+
+func writeDataExportJson(w *zip.Writer, name string, v interface{}) *model.AppError {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return model.NewAppError("BuildUserDataExport", "app.privacy_request.export.app_error", nil, "name="+name+", err="+err.Error(), http.StatusInternalServerError)
+	}
+
+	return writeDataExportEntry(w, name, data)
+}
+
+// Writes a JSON array to the export one page at a time, stopping once getPage returns a page that isn't full.
+func writeDataExportPages(w *zip.Writer, name string, getPage func(offset int) ([]interface{}, *model.AppError)) *model.AppError {
+	f, err := w.Create(name)
+	if err == nil {
+		_, err = f.Write([]byte("["))
+	}
+
+	count := 0
+	for offset := 0; err == nil; offset += DATA_EXPORT_PAGE_SIZE {
+		page, appErr := getPage(offset)
+		if appErr != nil {
+			return appErr
+		}
+
+		for _, item := range page {
+			if count > 0 {
+				if _, err = f.Write([]byte(",")); err != nil {
+					break
+				}
+			}
+
+			var data []byte
+			if data, err = json.Marshal(item); err == nil {
+				_, err = f.Write(data)
+			}
+
+			if err != nil {
+				break
+			}
+
+			count++
+		}
+
+		if len(page) < DATA_EXPORT_PAGE_SIZE {
+			break
+		}
+	}
+
+	if err == nil {
+		_, err = f.Write([]byte("]"))
+	}
+
+	if err != nil {
+		return model.NewAppError("BuildUserDataExport", "app.privacy_request.export.app_error", nil, "name="+name+", err="+err.Error(), http.StatusInternalServerError)
+	}
+
+	return nil
+}
+
+func writeDataExportEntry(w *zip.Writer, name string, data []byte) *model.AppError {
+	f, err := w.Create(name)
+	if err == nil {
+		_, err = f.Write(data)
+	}
+
+	if err != nil {
+		return model.NewAppError("BuildUserDataExport", "app.privacy_request.export.app_error", nil, "name="+name+", err="+err.Error(), http.StatusInternalServerError)
+	}
+
+	return nil
+}
+
+func writeDataExportFile(w *zip.Writer, name string, path string) *model.AppError {
+	stream, appErr := ReadFileStream(path)
+	if appErr != nil {
+		return appErr
+	}
+	defer stream.Close()
+
+	f, err := w.Create(name)
+	if err == nil {
+		_, err = io.Copy(f, stream)
+	}
+
+	if err != nil {
+		return model.NewAppError("BuildUserDataExport", "app.privacy_request.export.app_error", nil, "name="+name+", err="+err.Error(), http.StatusInternalServerError)
+	}
+
+	return nil
+}
+
+func deleteUserData(user *model.User, anonymize bool) *model.AppError {
+	// exports hold the same personal data as the account so they go with it
+	if requests, err := GetPrivacyRequestsForUser(user.Id); err != nil {
+		return err
+	} else {
+		for _, request := range requests {
+			if request.Type == model.PRIVACY_REQUEST_TYPE_EXPORT && request.Status == model.PRIVACY_REQUEST_STATUS_FINISHED {
+				if err := RemoveFile(request.ExportPath()); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if anonymize {
+		return AnonymizeUser(user)
+	}
+
+	if err := permanentDeleteFilesForUser(user.Id); err != nil {
+		return err
+	}
+
+	return PermanentDeleteUser(user)
+}
+
+// Removes every file a user uploaded from storage along with their file infos, including those of deleted posts.
+func permanentDeleteFilesForUser(userId string) *model.AppError {
+	result := <-Srv.Store.FileInfo().GetForUser(userId, true)
+	if result.Err != nil {
+		return result.Err
+	}
+
+	for _, info := range result.Data.([]*model.FileInfo) {
+		for _, path := range []string{info.Path, info.ThumbnailPath, info.PreviewPath} {
+			if path == "" {
+				continue
+			}
+
+			if err := RemoveFile(path); err != nil {
+				return err
+			}
+		}
+
+		if info.PostId != "" {
+			Srv.Store.FileInfo().InvalidateFileInfosForPostCache(info.PostId)
+		}
+	}
+
+	if result := <-Srv.Store.FileInfo().PermanentDeleteByUser(userId); result.Err != nil {
+		return result.Err
+	}
+
+	return nil
+}
+
+// AnonymizeUser permanently deletes a user while keeping their posts and files, which are attributed to a
+// deactivated placeholder user instead.
+func AnonymizeUser(user *model.User) *model.AppError {
+	placeholder, err := GetDeletedUserPlaceholder()
+	if err != nil {
+		return err
+	}
+
+	if user.Id == placeholder.Id {
+		return model.NewAppError("AnonymizeUser", "app.privacy_request.anonymize_placeholder.app_error", nil, "user_id="+user.Id, http.StatusBadRequest)
+	}
+
+	if result := <-Srv.Store.Post().ReassignUser(user.Id, placeholder.Id); result.Err != nil {
+		return result.Err
+	}
+
+	if result := <-Srv.Store.FileInfo().ReassignCreator(user.Id, placeholder.Id); result.Err != nil {
+		return result.Err
+	}
+
+	store.ClearPostCaches()
+
+	return PermanentDeleteUser(user)
+}
+
+// GetDeletedUserPlaceholder returns the deactivated user that posts of anonymized users are attributed to, creating
+// it the first time it's needed.
+func GetDeletedUserPlaceholder() (*model.User, *model.AppError) {
+	if result := <-Srv.Store.System().GetByName(model.SYSTEM_DELETED_USER_ID); result.Err == nil {
+		if user, err := GetUser(result.Data.(*model.System).Value); err == nil {
+			return user, nil
+		}
+	}
+
+	username := DELETED_USER_PLACEHOLDER_USERNAME
+	if result := <-Srv.Store.User().GetByUsername(username); result.Err == nil {
+		username += model.NewId()[:6]
+	}
+
+	user := &model.User{
+		Username:      username,
+		Email:         username + "@localhost",
+		FirstName:     "Deleted",
+		LastName:      "User",
+		EmailVerified: true,
+		Roles:         model.ROLE_SYSTEM_USER.Id,
+		Locale:        *utils.Cfg.LocalizationSettings.DefaultClientLocale,
+	}
+
+	var ruser *model.User
+	if result := <-Srv.Store.User().Save(user); result.Err != nil {
+		return nil, result.Err
+	} else {
+		ruser = result.Data.(*model.User)
+	}
+
+	if _, err := UpdateActive(ruser, false); err != nil {
+		return nil, err
+	}
+
+	if result := <-Srv.Store.System().SaveOrUpdate(&model.System{Name: model.SYSTEM_DELETED_USER_ID, Value: ruser.Id}); result.Err != nil {
+		return nil, result.Err
+	}
+
+	return ruser, nil
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+	"sort"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func isReadReceiptChannel(channel *model.Channel) bool {
+	return channel.Type == model.CHANNEL_DIRECT || channel.Type == model.CHANNEL_GROUP
+}
+
+// Returns false if the user has chosen not to let others see when they've read their messages.
+func userSendsReadReceipts(userId string) bool {
+	if pref, err := GetPreferenceByCategoryAndNameForUser(userId, model.PREFERENCE_CATEGORY_PRIVACY, model.PREFERENCE_NAME_SEND_READ_RECEIPTS); err == nil {
+		return pref.Value != "false"
+	}
+
+	return true
+}
+
+// GetReadReceipts returns which members of a direct or group channel have seen each of a page of its most recent
+// posts, based on when they last viewed the channel.
+func GetReadReceipts(channelId string, page int, perPage int) (model.ReadReceipts, *model.AppError) {
+	channel, err := GetChannel(channelId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isReadReceiptChannel(channel) {
+		return nil, model.NewAppError("GetReadReceipts", "api.channel.get_read_receipts.type.app_error", nil, "channel_id="+channelId, http.StatusBadRequest)
+	}
+
+	// direct and group channels have few enough members to get all of them at once
+	members, err := GetChannelMembersPage(channelId, 0, model.CHANNEL_GROUP_MAX_USERS)
+	if err != nil {
+		return nil, err
+	}
+
+	posts, err := GetPostsPage(channelId, page, perPage)
+	if err != nil {
+		return nil, err
+	}
+
+	viewers := []*model.ChannelMember{}
+	for i := range *members {
+		if userSendsReadReceipts((*members)[i].UserId) {
+			viewers = append(viewers, &(*members)[i])
+		}
+	}
+
+	receipts := make(model.ReadReceipts, len(posts.Posts))
+	for _, post := range posts.Posts {
+		userIds := []string{}
+		for _, member := range viewers {
+			if member.UserId != post.UserId && member.LastViewedAt >= post.CreateAt {
+				userIds = append(userIds, member.UserId)
+			}
+		}
+		sort.Strings(userIds)
+
+		receipts[post.Id] = userIds
+	}
+
+	return receipts, nil
+}
+
+// Lets the other members of a direct or group channel know that a user has viewed it up to a given time.
+func sendReadReceiptEvent(channelId string, userId string) {
+	channel, err := GetChannel(channelId)
+	if err != nil || !isReadReceiptChannel(channel) || !userSendsReadReceipts(userId) {
+		return
+	}
+
+	var member *model.ChannelMember
+	if result := <-Srv.Store.Channel().GetMember(channelId, userId); result.Err != nil {
+		l4g.Warn(utils.T("api.channel.send_read_receipt_event.member.warn"), channelId, userId, result.Err)
+		return
+	} else {
+		member = result.Data.(*model.ChannelMember)
+	}
+
+	message := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_READ_RECEIPT, "", channelId, "", map[string]bool{userId: true})
+	message.Add("user_id", userId)
+	message.Add("last_viewed_at", member.LastViewedAt)
+	Publish(message)
+}
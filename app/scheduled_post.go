@@ -0,0 +1,145 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func CreateScheduledPost(scheduledPost *model.ScheduledPost) (*model.ScheduledPost, *model.AppError) {
+	if err := checkScheduledPost("CreateScheduledPost", scheduledPost); err != nil {
+		return nil, err
+	}
+
+	if result := <-Srv.Store.ScheduledPost().Save(scheduledPost); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.ScheduledPost), nil
+	}
+}
+
+func GetScheduledPost(id string) (*model.ScheduledPost, *model.AppError) {
+	if result := <-Srv.Store.ScheduledPost().Get(id); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.ScheduledPost), nil
+	}
+}
+
+func GetScheduledPostsForUser(userId string) ([]*model.ScheduledPost, *model.AppError) {
+	if result := <-Srv.Store.ScheduledPost().GetForUser(userId); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.([]*model.ScheduledPost), nil
+	}
+}
+
+// UpdateScheduledPost changes the message or the time of a scheduled post. Rescheduling one that failed to be posted
+// tries to post it again.
+func UpdateScheduledPost(scheduledPost *model.ScheduledPost) (*model.ScheduledPost, *model.AppError) {
+	if err := checkScheduledPost("UpdateScheduledPost", scheduledPost); err != nil {
+		return nil, err
+	}
+
+	scheduledPost.ProcessedAt = 0
+	scheduledPost.ErrorCode = ""
+
+	if result := <-Srv.Store.ScheduledPost().Update(scheduledPost); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.ScheduledPost), nil
+	}
+}
+
+func DeleteScheduledPost(id string) *model.AppError {
+	if result := <-Srv.Store.ScheduledPost().Delete(id); result.Err != nil {
+		return result.Err
+	}
+
+	return nil
+}
+
+func checkScheduledPost(where string, scheduledPost *model.ScheduledPost) *model.AppError {
+	if scheduledPost.ScheduledAt <= model.GetMillis() {
+		return model.NewAppError(where, "api.scheduled_post.past.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if err := CheckChannelIsNotArchived(where, scheduledPost.ChannelId); err != nil {
+		return err
+	}
+
+	if scheduledPost.RootId != "" {
+		if root, err := GetSinglePost(scheduledPost.RootId); err != nil || root.ChannelId != scheduledPost.ChannelId || root.RootId != "" {
+			return model.NewAppError(where, "api.scheduled_post.root_id.app_error", nil, "root_id="+scheduledPost.RootId, http.StatusBadRequest)
+		}
+	}
+
+	return nil
+}
+
+// ProcessDueScheduledPosts posts every scheduled post that's due.
+func ProcessDueScheduledPosts() {
+	result := <-Srv.Store.ScheduledPost().GetDue(model.GetMillis(), POST_SCHEDULER_BATCH_SIZE)
+	if result.Err != nil {
+		l4g.Error(utils.T("app.scheduled_post.process.error"), result.Err.Error())
+		return
+	}
+
+	for _, scheduledPost := range result.Data.([]*model.ScheduledPost) {
+		sendScheduledPost(scheduledPost)
+	}
+}
+
+// Posts a scheduled post if the user is still allowed to, or otherwise keeps it along with the reason and lets the
+// user know that it wasn't posted.
+func sendScheduledPost(scheduledPost *model.ScheduledPost) {
+	now := model.GetMillis()
+
+	// another server may have already claimed it
+	if result := <-Srv.Store.ScheduledPost().Claim(scheduledPost.Id, now); result.Err != nil {
+		l4g.Error(utils.T("app.scheduled_post.process.error"), result.Err.Error())
+		return
+	} else if !result.Data.(bool) {
+		return
+	}
+
+	user, err := GetUser(scheduledPost.UserId)
+	if err == nil {
+		if user.DeleteAt != 0 || !HasPermissionToChannel(user.Id, scheduledPost.ChannelId, model.PERMISSION_CREATE_POST) {
+			err = model.NewAppError("sendScheduledPost", "api.scheduled_post.permissions.app_error", nil, "id="+scheduledPost.Id, http.StatusForbidden)
+		} else {
+			_, err = CreatePostAsUser(scheduledPost.ToPost(), *utils.Cfg.ServiceSettings.SiteURL)
+		}
+	}
+
+	if err == nil {
+		if result := <-Srv.Store.ScheduledPost().Delete(scheduledPost.Id); result.Err != nil {
+			l4g.Error(utils.T("app.scheduled_post.process.error"), result.Err.Error())
+		}
+
+		return
+	}
+
+	l4g.Warn(utils.T("app.scheduled_post.failed.warn"), scheduledPost.Id, err.Error())
+
+	scheduledPost.ProcessedAt = now
+	scheduledPost.ErrorCode = err.Id
+	if result := <-Srv.Store.ScheduledPost().Update(scheduledPost); result.Err != nil {
+		l4g.Error(utils.T("app.scheduled_post.process.error"), result.Err.Error())
+	}
+
+	if user != nil && user.DeleteAt == 0 {
+		T := utils.GetUserTranslations(user.Locale)
+		err.Translate(T)
+
+		message := T("app.scheduled_post.failed.message", map[string]interface{}{"Message": scheduledPost.Message, "Error": err.Message})
+		if err := sendPostToSelf(user.Id, message, model.POST_SCHEDULED_POST_FAILED, model.StringInterface{"scheduled_post_id": scheduledPost.Id}); err != nil {
+			l4g.Error(utils.T("app.scheduled_post.process.error"), err.Error())
+		}
+	}
+}
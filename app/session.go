@@ -8,6 +8,7 @@ import (
 	"github.com/mattermost/platform/model"
 	"github.com/mattermost/platform/utils"
 	"net/http"
+	"time"
 
 	l4g "github.com/alecthomas/log4go"
 )
@@ -22,10 +23,47 @@ func CreateSession(session *model.Session) (*model.Session, *model.AppError) {
 
 		AddSessionToCache(session)
 
+		if !session.IsOAuth {
+			enforceSessionLimit(session)
+		}
+
 		return session, nil
 	}
 }
 
+// enforceSessionLimit revokes the user's least recently active sessions once they have more than
+// MaximumSessionsPerUser. The newly created session is never evicted.
+func enforceSessionLimit(newSession *model.Session) {
+	maxSessions := *utils.Cfg.ServiceSettings.MaximumSessionsPerUser
+	if maxSessions <= 0 {
+		return
+	}
+
+	result := <-Srv.Store.Session().GetSessions(newSession.UserId)
+	if result.Err != nil {
+		l4g.Error(result.Err.Error())
+		return
+	}
+
+	// Sessions are ordered by LastActivityAt descending, so the oldest ones come last
+	kept := 1
+	for _, session := range result.Data.([]*model.Session) {
+		if session.IsOAuth || session.Id == newSession.Id {
+			continue
+		}
+
+		if kept < maxSessions {
+			kept++
+			continue
+		}
+
+		l4g.Debug(utils.T("app.session.limit.revoking.debug"), session.Id, session.UserId)
+		if err := RevokeSession(session); err != nil {
+			l4g.Error(err.Error())
+		}
+	}
+}
+
 func GetSession(token string) (*model.Session, *model.AppError) {
 	metrics := einterfaces.GetMetricsInterface()
 
@@ -49,6 +87,9 @@ func GetSession(token string) (*model.Session, *model.AppError) {
 
 			if session.IsExpired() || session.Token != token {
 				return nil, model.NewLocAppError("GetSession", "api.context.invalid_token.error", map[string]interface{}{"Token": token, "Error": sessionResult.Err.DetailedError}, "")
+			} else if isSessionIdle(session) {
+				revokeIdleSession(session)
+				return nil, model.NewLocAppError("GetSession", "api.context.invalid_token.error", map[string]interface{}{"Token": token}, "idle timeout")
 			} else {
 				AddSessionToCache(session)
 				return session, nil
@@ -60,9 +101,88 @@ func GetSession(token string) (*model.Session, *model.AppError) {
 		return nil, model.NewLocAppError("GetSession", "api.context.invalid_token.error", map[string]interface{}{"Token": token}, "")
 	}
 
+	if isSessionIdle(session) {
+		// the session may have been used through another server, which only updates its own cache
+		if sessionResult := <-Srv.Store.Session().Get(token); sessionResult.Err != nil {
+			return nil, model.NewLocAppError("GetSession", "api.context.invalid_token.error", map[string]interface{}{"Token": token, "Error": sessionResult.Err.DetailedError}, "")
+		} else if stored := sessionResult.Data.(*model.Session); !isSessionIdle(stored) {
+			session.LastActivityAt = stored.LastActivityAt
+			return session, nil
+		}
+
+		revokeIdleSession(session)
+		return nil, model.NewLocAppError("GetSession", "api.context.invalid_token.error", map[string]interface{}{"Token": token}, "idle timeout")
+	}
+
 	return session, nil
 }
 
+// isSessionIdle returns true if SessionIdleTimeoutInMinutes is set and the session hasn't been used for that long.
+// Mobile and OAuth sessions aren't subject to the idle timeout.
+func isSessionIdle(session *model.Session) bool {
+	timeout := int64(*utils.Cfg.ServiceSettings.SessionIdleTimeoutInMinutes) * 60 * 1000
+	if timeout <= 0 || session.IsOAuth || session.IsMobileApp() {
+		return false
+	}
+
+	return model.GetMillis()-session.LastActivityAt > timeout
+}
+
+func revokeIdleSession(session *model.Session) {
+	l4g.Debug(utils.T("app.session.idle.revoking.debug"), session.Id, session.UserId)
+	if err := RevokeSession(session); err != nil {
+		l4g.Error(err.Error())
+	}
+}
+
+// AddSessionCSRFToken gives a session created before CSRF tokens existed a token, reusing one that another server
+// may have already given it, and sends it to the client. It returns the updated session.
+func AddSessionCSRFToken(w http.ResponseWriter, r *http.Request, session *model.Session) (*model.Session, *model.AppError) {
+	// the session may be shared through the cache, so update a copy of it
+	updated := &model.Session{}
+	*updated = *session
+	updated.Props = make(model.StringMap)
+	for key, value := range session.Props {
+		updated.Props[key] = value
+	}
+
+	if result := <-Srv.Store.Session().Get(session.Token); result.Err == nil && len(result.Data.(*model.Session).GetCSRF()) > 0 {
+		updated.AddProp(model.SESSION_PROP_CSRF, result.Data.(*model.Session).GetCSRF())
+	} else {
+		updated.GenerateCSRF()
+
+		if result := <-Srv.Store.Session().UpdateProps(updated.Id, updated.Props); result.Err != nil {
+			return nil, result.Err
+		}
+	}
+
+	AddSessionToCache(updated)
+
+	maxAge := int((updated.ExpiresAt - model.GetMillis()) / 1000)
+	setCSRFCookie(w, r, updated.GetCSRF(), maxAge, time.Unix(updated.ExpiresAt/1000, 0))
+
+	return updated, nil
+}
+
+// UpdateLastActivityAtIfNeeded records that the session was just used. Writes are throttled to one per
+// SESSION_ACTIVITY_UPDATE_INTERVAL so that busy clients don't hit the database on every request.
+func UpdateLastActivityAtIfNeeded(session *model.Session) {
+	now := model.GetMillis()
+	if now-session.LastActivityAt < model.SESSION_ACTIVITY_UPDATE_INTERVAL {
+		return
+	}
+
+	if result := <-Srv.Store.Session().UpdateLastActivityAt(session.Id, now); result.Err != nil {
+		l4g.Error(result.Err.Error())
+		return
+	}
+
+	session.LastActivityAt = now
+	if ts, ok := sessionCache.Get(session.Token); ok {
+		ts.(*model.Session).LastActivityAt = now
+	}
+}
+
 func GetSessions(userId string) ([]*model.Session, *model.AppError) {
 	if result := <-Srv.Store.Session().GetSessions(userId); result.Err != nil {
 		return nil, result.Err
@@ -95,6 +215,60 @@ func RevokeAllSessions(userId string) *model.AppError {
 	return nil
 }
 
+// RevokeAllSessionsWithReason revokes every session belonging to the user and emails them the reason.
+func RevokeAllSessionsWithReason(userId, reason, siteURL string) *model.AppError {
+	user, err := GetUser(userId)
+	if err != nil {
+		return err
+	}
+
+	if err := RevokeAllSessions(userId); err != nil {
+		return err
+	}
+
+	go sendSessionsRevokedEmail(user, reason, siteURL)
+
+	return nil
+}
+
+// RevokeSessionsFromAllUsers revokes every session on the server and emails each affected user the reason.
+func RevokeSessionsFromAllUsers(reason, siteURL string) *model.AppError {
+	var userIds []string
+	if result := <-Srv.Store.Session().GetUserIdsWithSessions(); result.Err != nil {
+		return result.Err
+	} else {
+		userIds = result.Data.([]string)
+	}
+
+	for _, userId := range userIds {
+		if err := RevokeAllSessions(userId); err != nil {
+			return err
+		}
+	}
+
+	go func() {
+		for _, userId := range userIds {
+			if user, err := GetUser(userId); err != nil {
+				l4g.Error(err.Error())
+			} else {
+				sendSessionsRevokedEmail(user, reason, siteURL)
+			}
+		}
+	}()
+
+	return nil
+}
+
+func sendSessionsRevokedEmail(user *model.User, reason, siteURL string) {
+	if user.DeleteAt != 0 {
+		return
+	}
+
+	if err := SendSessionsRevokedEmail(user.Email, reason, user.Locale, siteURL); err != nil {
+		l4g.Error(err.Error())
+	}
+}
+
 func ClearSessionCacheForUser(userId string) {
 
 	ClearSessionCacheForUserSkipClusterSend(userId)
@@ -5,6 +5,7 @@ package app
 
 import (
 	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
 	"testing"
 )
 
@@ -29,3 +30,75 @@ func TestCache(t *testing.T) {
 		t.Fatal("should have one less")
 	}
 }
+
+func TestIsSessionIdle(t *testing.T) {
+	utils.TranslationsPreInit()
+	utils.LoadConfig("config.json")
+
+	idleTimeout := *utils.Cfg.ServiceSettings.SessionIdleTimeoutInMinutes
+	defer func() {
+		*utils.Cfg.ServiceSettings.SessionIdleTimeoutInMinutes = idleTimeout
+	}()
+
+	session := &model.Session{LastActivityAt: model.GetMillis() - 10*60*1000}
+
+	*utils.Cfg.ServiceSettings.SessionIdleTimeoutInMinutes = 0
+	if isSessionIdle(session) {
+		t.Fatal("shouldn't time out sessions when the idle timeout is disabled")
+	}
+
+	*utils.Cfg.ServiceSettings.SessionIdleTimeoutInMinutes = 15
+	if isSessionIdle(session) {
+		t.Fatal("shouldn't have timed out a recently used session")
+	}
+
+	*utils.Cfg.ServiceSettings.SessionIdleTimeoutInMinutes = 5
+	if !isSessionIdle(session) {
+		t.Fatal("should have timed out the idle session")
+	}
+
+	session.IsOAuth = true
+	if isSessionIdle(session) {
+		t.Fatal("shouldn't time out OAuth sessions")
+	}
+
+	session.IsOAuth = false
+	session.DeviceId = model.NewId()
+	if isSessionIdle(session) {
+		t.Fatal("shouldn't time out mobile sessions")
+	}
+}
+
+func TestGetSessionUsedThroughAnotherServer(t *testing.T) {
+	th := Setup().InitBasic()
+
+	idleTimeout := *utils.Cfg.ServiceSettings.SessionIdleTimeoutInMinutes
+	defer func() {
+		*utils.Cfg.ServiceSettings.SessionIdleTimeoutInMinutes = idleTimeout
+	}()
+	*utils.Cfg.ServiceSettings.SessionIdleTimeoutInMinutes = 5
+
+	session, err := CreateSession(&model.Session{UserId: th.BasicUser.Id})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// this server's cached copy hasn't seen the activity recorded by another server
+	session.LastActivityAt = model.GetMillis() - 10*60*1000
+	AddSessionToCache(session)
+
+	if _, err := GetSession(session.Token); err != nil {
+		t.Fatal("shouldn't have revoked a session that was used through another server", err)
+	}
+
+	<-Srv.Store.Session().UpdateLastActivityAt(session.Id, model.GetMillis()-10*60*1000)
+	session.LastActivityAt = model.GetMillis() - 10*60*1000
+
+	if _, err := GetSession(session.Token); err == nil {
+		t.Fatal("should have revoked the idle session")
+	}
+
+	if result := <-Srv.Store.Session().Get(session.Token); result.Err == nil {
+		t.Fatal("should have removed the idle session")
+	}
+}
@@ -201,8 +201,7 @@ func AddUserToTeamByHash(userId string, hash string, data string, siteURL string
 		return nil, model.NewLocAppError("JoinUserToTeamByHash", "api.user.create_user.signup_link_invalid.app_error", nil, "")
 	}
 
-	t, timeErr := strconv.ParseInt(props["time"], 10, 64)
-	if timeErr != nil || model.GetMillis()-t > 1000*60*60*48 { // 48 hours
+	if isInviteExpired(props) {
 		return nil, model.NewLocAppError("JoinUserToTeamByHash", "api.user.create_user.signup_link_expired.app_error", nil, "")
 	}
 
@@ -227,9 +226,49 @@ func AddUserToTeamByHash(userId string, hash string, data string, siteURL string
 		return nil, err
 	}
 
+	if isGuestInvite(props) {
+		joinInvitedChannels(team, user, props)
+	}
+
 	return team, nil
 }
 
+// isInviteExpired checks the age of a signed invitation. Guest invitations
+// carry their own expiry date, other invitations are valid for 48 hours.
+func isInviteExpired(props map[string]string) bool {
+	if isGuestInvite(props) {
+		expiresAt, err := strconv.ParseInt(props["expires_at"], 10, 64)
+		return err != nil || model.GetMillis() > expiresAt
+	}
+
+	t, err := strconv.ParseInt(props["time"], 10, 64)
+	return err != nil || model.GetMillis()-t > 1000*60*60*48 // 48 hours
+}
+
+func isGuestInvite(props map[string]string) bool {
+	return props["guest"] == "true"
+}
+
+// joinInvitedChannels adds the user to the channels listed in a guest
+// invitation that still belong to the team.
+func joinInvitedChannels(team *model.Team, user *model.User, props map[string]string) {
+	for _, channelId := range strings.Fields(props["channels"]) {
+		channel, err := GetChannel(channelId)
+		if err != nil {
+			l4g.Error(utils.T("api.team.invite_guests.join_channel.error"), user.Id, channelId, err)
+			continue
+		}
+
+		if channel.TeamId != team.Id || channel.DeleteAt != 0 {
+			continue
+		}
+
+		if _, err := AddUserToChannel(user, channel); err != nil {
+			l4g.Error(utils.T("api.team.invite_guests.join_channel.error"), user.Id, channelId, err)
+		}
+	}
+}
+
 func AddUserToTeamByInviteId(inviteId string, userId string, siteURL string) (*model.Team, *model.AppError) {
 	tchan := Srv.Store.Team().GetByInviteId(inviteId)
 	uchan := Srv.Store.User().Get(userId)
@@ -263,7 +302,9 @@ func joinUserToTeam(team *model.Team, user *model.User) (bool, *model.AppError)
 		Roles:  model.ROLE_TEAM_USER.Id,
 	}
 
-	if team.Email == user.Email {
+	if user.IsGuest() {
+		tm.Roles = model.ROLE_TEAM_GUEST.Id
+	} else if team.Email == user.Email {
 		tm.Roles = model.ROLE_TEAM_USER.Id + " " + model.ROLE_TEAM_ADMIN.Id
 	}
 
@@ -301,15 +342,18 @@ func JoinUserToTeam(team *model.Team, user *model.User, siteURL string) *model.A
 		return nil
 	}
 
-	channelRole := model.ROLE_CHANNEL_USER.Id
+	// Guests are only added to the channels they were invited to
+	if !user.IsGuest() {
+		channelRole := model.ROLE_CHANNEL_USER.Id
 
-	if team.Email == user.Email {
-		channelRole = model.ROLE_CHANNEL_USER.Id + " " + model.ROLE_CHANNEL_ADMIN.Id
-	}
+		if team.Email == user.Email {
+			channelRole = model.ROLE_CHANNEL_USER.Id + " " + model.ROLE_CHANNEL_ADMIN.Id
+		}
 
-	// Soft error if there is an issue joining the default channels
-	if err := JoinDefaultChannels(team.Id, user, channelRole, siteURL); err != nil {
-		l4g.Error(utils.T("api.user.create_user.joining.error"), user.Id, team.Id, err)
+		// Soft error if there is an issue joining the default channels
+		if err := JoinDefaultChannels(team.Id, user, channelRole, siteURL); err != nil {
+			l4g.Error(utils.T("api.user.create_user.joining.error"), user.Id, team.Id, err)
+		}
 	}
 
 	ClearSessionCacheForUser(user.Id)
@@ -627,6 +671,51 @@ func InviteNewUsersToTeam(emailList []string, teamId, senderId, siteURL string)
 	return nil
 }
 
+// InviteGuestsToTeam emails invitations to join the team as guests who can
+// only see the invited channels.
+func InviteGuestsToTeam(teamId string, invite *model.GuestsInvite, senderId, siteURL string) *model.AppError {
+	invite.PreSave()
+	if err := invite.IsValid(); err != nil {
+		err.StatusCode = http.StatusBadRequest
+		return err
+	}
+
+	tchan := Srv.Store.Team().Get(teamId)
+	uchan := Srv.Store.User().Get(senderId)
+
+	var team *model.Team
+	if result := <-tchan; result.Err != nil {
+		return result.Err
+	} else {
+		team = result.Data.(*model.Team)
+	}
+
+	var user *model.User
+	if result := <-uchan; result.Err != nil {
+		return result.Err
+	} else {
+		user = result.Data.(*model.User)
+	}
+
+	channels := []*model.Channel{}
+	for _, channelId := range invite.ChannelIds {
+		channel, err := GetChannel(channelId)
+		if err != nil {
+			return err
+		}
+
+		if channel.TeamId != team.Id || channel.DeleteAt != 0 || (channel.Type != model.CHANNEL_OPEN && channel.Type != model.CHANNEL_PRIVATE) {
+			return model.NewAppError("InviteGuestsToTeam", "api.team.invite_guests.channel.app_error", nil, "channel_id="+channelId, http.StatusBadRequest)
+		}
+
+		channels = append(channels, channel)
+	}
+
+	SendGuestInviteEmails(team, channels, user.GetDisplayName(), invite.Emails, invite.ExpiresAt, siteURL)
+
+	return nil
+}
+
 func FindTeamByName(name string) bool {
 	if result := <-Srv.Store.Team().GetByName(name); result.Err != nil {
 		return false
@@ -0,0 +1,159 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func GetFollowedThreads(userId string, page int, perPage int) ([]*model.Thread, *model.AppError) {
+	if result := <-Srv.Store.Thread().GetFollowedThreads(userId, page*perPage, perPage); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.([]*model.Thread), nil
+	}
+}
+
+func GetThreadMembership(postId string, userId string) (*model.ThreadMembership, *model.AppError) {
+	if result := <-Srv.Store.Thread().GetMembership(postId, userId); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.ThreadMembership), nil
+	}
+}
+
+// Returns the user's existing membership of a thread or a new one that hasn't been saved yet.
+func getOrCreateThreadMembership(postId string, userId string) (*model.ThreadMembership, *model.AppError) {
+	if membership, err := GetThreadMembership(postId, userId); err == nil {
+		return membership, nil
+	} else if err.StatusCode != http.StatusNotFound {
+		return nil, err
+	}
+
+	return &model.ThreadMembership{
+		PostId: postId,
+		UserId: userId,
+	}, nil
+}
+
+func saveThreadMembership(membership *model.ThreadMembership) (*model.ThreadMembership, *model.AppError) {
+	if result := <-Srv.Store.Thread().SaveMembership(membership); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.ThreadMembership), nil
+	}
+}
+
+func getThreadRootPost(postId string) (*model.Post, *model.AppError) {
+	post, err := GetSinglePost(postId)
+	if err != nil {
+		return nil, err
+	}
+
+	if post.RootId != "" {
+		return nil, model.NewAppError("getThreadRootPost", "api.thread.not_root.app_error", nil, "post_id="+postId, http.StatusBadRequest)
+	}
+
+	return post, nil
+}
+
+func SetThreadFollowing(postId string, userId string, following bool) (*model.ThreadMembership, *model.AppError) {
+	if _, err := getThreadRootPost(postId); err != nil {
+		return nil, err
+	}
+
+	membership, err := getOrCreateThreadMembership(postId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	membership.Following = following
+
+	return saveThreadMembership(membership)
+}
+
+// ViewThread marks every reply to a thread as read by the user.
+func ViewThread(postId string, userId string) (*model.ThreadMembership, *model.AppError) {
+	if _, err := getThreadRootPost(postId); err != nil {
+		return nil, err
+	}
+
+	membership, err := getOrCreateThreadMembership(postId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	membership.LastViewedAt = model.GetMillis()
+	membership.UnreadMentions = 0
+
+	return saveThreadMembership(membership)
+}
+
+// Updates the reply count, latest reply time and participants stored on the root post of a thread.
+func updateThreadStats(rootId string) {
+	if result := <-Srv.Store.Post().UpdateThreadStats(rootId); result.Err != nil {
+		l4g.Error(utils.T("api.thread.update_thread_stats.error"), rootId, result.Err)
+	}
+}
+
+// Makes the author of a reply, the author of the thread and anyone mentioned in the thread follow it, and counts the
+// mentions as unread until they view the thread.
+func updateThreadMemberships(post *model.Post, mentionedUserIds []string) {
+	if post.IsSystemMessage() {
+		return
+	}
+
+	if post.RootId == "" {
+		// being mentioned in a root post is enough to follow any thread that follows from it
+		for _, userId := range mentionedUserIds {
+			followThreadIfNew(post.Id, userId)
+		}
+
+		return
+	}
+
+	if post.Props["from_webhook"] != "true" {
+		if membership, err := getOrCreateThreadMembership(post.RootId, post.UserId); err != nil {
+			l4g.Error(utils.T("api.thread.update_thread_memberships.error"), post.RootId, post.UserId, err)
+		} else {
+			membership.Following = true
+			membership.LastViewedAt = post.CreateAt
+			membership.UnreadMentions = 0
+
+			if _, err := saveThreadMembership(membership); err != nil {
+				l4g.Error(utils.T("api.thread.update_thread_memberships.error"), post.RootId, post.UserId, err)
+			}
+		}
+	}
+
+	if root, err := GetSinglePost(post.RootId); err != nil {
+		l4g.Error(utils.T("api.thread.update_thread_memberships.error"), post.RootId, post.UserId, err)
+	} else if root.UserId != post.UserId {
+		followThreadIfNew(root.Id, root.UserId)
+	}
+
+	for _, userId := range mentionedUserIds {
+		if result := <-Srv.Store.Thread().IncrementMentionCount(post.RootId, userId); result.Err != nil {
+			l4g.Error(utils.T("api.thread.update_thread_memberships.error"), post.RootId, userId, result.Err)
+		}
+	}
+}
+
+// Starts a user following a thread unless they've already followed or unfollowed it.
+func followThreadIfNew(postId string, userId string) {
+	if _, err := GetThreadMembership(postId, userId); err == nil {
+		return
+	} else if err.StatusCode != http.StatusNotFound {
+		l4g.Error(utils.T("api.thread.update_thread_memberships.error"), postId, userId, err)
+		return
+	}
+
+	if _, err := saveThreadMembership(&model.ThreadMembership{PostId: postId, UserId: userId, Following: true}); err != nil {
+		l4g.Error(utils.T("api.thread.update_thread_memberships.error"), postId, userId, err)
+	}
+}
@@ -41,7 +41,7 @@ func CreateUserWithHash(user *model.User, hash string, data string, siteURL stri
 		return nil, model.NewLocAppError("CreateUserWithHash", "api.user.create_user.signup_link_invalid.app_error", nil, "")
 	}
 
-	if t, err := strconv.ParseInt(props["time"], 10, 64); err != nil || model.GetMillis()-t > 1000*60*60*48 { // 48 hours
+	if isInviteExpired(props) {
 		return nil, model.NewLocAppError("CreateUserWithHash", "api.user.create_user.signup_link_expired.app_error", nil, "")
 	}
 
@@ -59,6 +59,20 @@ func CreateUserWithHash(user *model.User, hash string, data string, siteURL stri
 
 	var ruser *model.User
 	var err *model.AppError
+	if isGuestInvite(props) {
+		if ruser, err = CreateGuest(user); err != nil {
+			return nil, err
+		}
+
+		if err := JoinUserToTeam(team, ruser, siteURL); err != nil {
+			return nil, err
+		}
+
+		joinInvitedChannels(team, ruser, props)
+
+		return ruser, nil
+	}
+
 	if ruser, err = CreateUser(user); err != nil {
 		return nil, err
 	}
@@ -200,6 +214,23 @@ func CreateUser(user *model.User) (*model.User, *model.AppError) {
 	}
 }
 
+// CreateGuest creates a guest account. Guests are invited by email so the
+// allowed signup domains don't apply to them.
+func CreateGuest(user *model.User) (*model.User, *model.AppError) {
+	user.Roles = model.ROLE_SYSTEM_GUEST.Id
+	user.Locale = *utils.Cfg.LocalizationSettings.DefaultClientLocale
+
+	if ruser, err := createUser(user); err != nil {
+		return nil, err
+	} else {
+		message := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_NEW_USER, "", "", "", nil)
+		message.Add("user_id", ruser.Id)
+		go Publish(message)
+
+		return ruser, nil
+	}
+}
+
 func createUser(user *model.User) (*model.User, *model.AppError) {
 	user.MakeNonNil()
 
@@ -219,6 +250,10 @@ func createUser(user *model.User) (*model.User, *model.AppError) {
 			}
 		}
 
+		if ruser.AuthService == "" && ruser.Password != "" {
+			savePasswordHistory(ruser.Id, ruser.Password)
+		}
+
 		pref := model.Preference{UserId: ruser.Id, Category: model.PREFERENCE_CATEGORY_TUTORIAL_STEPS, Name: ruser.Id, Value: "0"}
 		if presult := <-Srv.Store.Preference().Save(&model.Preferences{pref}); presult.Err != nil {
 			l4g.Error(utils.T("api.user.create_user.tutorial.error"), presult.Err.Message)
@@ -275,12 +310,17 @@ func CreateOAuthUser(service string, userData io.Reader, teamId string, siteURL
 	}
 
 	user.EmailVerified = true
+	providerRoles := user.Roles
 
 	ruser, err := CreateUser(user)
 	if err != nil {
 		return nil, err
 	}
 
+	if ruser, err = syncOAuthUserRoles(ruser, providerRoles); err != nil {
+		return nil, err
+	}
+
 	if len(teamId) > 0 {
 		err = AddUserToTeamByTeamId(teamId, user, siteURL)
 		if err != nil {
@@ -615,10 +655,7 @@ func GetUsersByIds(userIds []string, asAdmin bool) ([]*model.User, *model.AppErr
 }
 
 func GenerateMfaSecret(userId string) (*model.MfaSecret, *model.AppError) {
-	mfaInterface := einterfaces.GetMfaInterface()
-	if mfaInterface == nil {
-		return nil, model.NewAppError("generateMfaSecret", "api.user.generate_mfa_qr.not_available.app_error", nil, "", http.StatusNotImplemented)
-	}
+	mfaInterface := getMfaInterface()
 
 	var user *model.User
 	var err *model.AppError
@@ -626,6 +663,10 @@ func GenerateMfaSecret(userId string) (*model.MfaSecret, *model.AppError) {
 		return nil, err
 	}
 
+	if user.MfaActive {
+		return nil, model.NewAppError("GenerateMfaSecret", "app.mfa.already_active.app_error", nil, "user_id="+userId, http.StatusBadRequest)
+	}
+
 	secret, img, err := mfaInterface.GenerateSecret(user)
 	if err != nil {
 		return nil, err
@@ -636,12 +677,7 @@ func GenerateMfaSecret(userId string) (*model.MfaSecret, *model.AppError) {
 }
 
 func ActivateMfa(userId, token string) *model.AppError {
-	mfaInterface := einterfaces.GetMfaInterface()
-	if mfaInterface == nil {
-		err := model.NewLocAppError("ActivateMfa", "api.user.update_mfa.not_available.app_error", nil, "")
-		err.StatusCode = http.StatusNotImplemented
-		return err
-	}
+	mfaInterface := getMfaInterface()
 
 	var user *model.User
 	if result := <-Srv.Store.User().Get(userId); result.Err != nil {
@@ -654,25 +690,36 @@ func ActivateMfa(userId, token string) *model.AppError {
 		return model.NewLocAppError("ActivateMfa", "api.user.activate_mfa.email_and_ldap_only.app_error", nil, "")
 	}
 
+	if user.MfaActive {
+		return model.NewAppError("ActivateMfa", "app.mfa.already_active.app_error", nil, "user_id="+userId, http.StatusBadRequest)
+	}
+
 	if err := mfaInterface.Activate(user, token); err != nil {
 		return err
 	}
 
+	// the token used to turn on MFA can't be used again to log in
+	if err := markMfaTokenUsed(userId, token); err != nil {
+		l4g.Warn(err.Error())
+	}
+
 	return nil
 }
 
 func DeactivateMfa(userId string) *model.AppError {
-	mfaInterface := einterfaces.GetMfaInterface()
-	if mfaInterface == nil {
-		err := model.NewLocAppError("DeactivateMfa", "api.user.update_mfa.not_available.app_error", nil, "")
-		err.StatusCode = http.StatusNotImplemented
+	if _, err := GetUser(userId); err != nil {
 		return err
 	}
 
-	if err := mfaInterface.Deactivate(userId); err != nil {
+	if err := getMfaInterface().Deactivate(userId); err != nil {
 		return err
 	}
 
+	// recovery codes only make sense for the MFA device they were created with
+	if result := <-Srv.Store.Mfa().PermanentDeleteByUser(userId); result.Err != nil {
+		return result.Err
+	}
+
 	return nil
 }
 
@@ -1073,12 +1120,18 @@ func UpdatePassword(user *model.User, newPassword string) *model.AppError {
 		return err
 	}
 
+	if err := checkPasswordHistory(user, newPassword); err != nil {
+		return err
+	}
+
 	hashedPassword := model.HashPassword(newPassword)
 
 	if result := <-Srv.Store.User().UpdatePassword(user.Id, hashedPassword); result.Err != nil {
 		return model.NewLocAppError("UpdatePassword", "api.user.update_password.failed.app_error", nil, result.Err.Error())
 	}
 
+	savePasswordHistory(user.Id, hashedPassword)
+
 	return nil
 }
 
@@ -1246,6 +1299,42 @@ func PermanentDeleteUser(user *model.User) *model.AppError {
 		return result.Err
 	}
 
+	if result := <-Srv.Store.MentionGroup().PermanentDeleteMembersByUser(user.Id); result.Err != nil {
+		return result.Err
+	}
+
+	if result := <-Srv.Store.Thread().PermanentDeleteMembershipsByUser(user.Id); result.Err != nil {
+		return result.Err
+	}
+
+	if result := <-Srv.Store.ScheduledPost().PermanentDeleteByUser(user.Id); result.Err != nil {
+		return result.Err
+	}
+
+	if result := <-Srv.Store.PostReminder().PermanentDeleteByUser(user.Id); result.Err != nil {
+		return result.Err
+	}
+
+	if result := <-Srv.Store.Poll().PermanentDeleteByUser(user.Id); result.Err != nil {
+		return result.Err
+	}
+
+	if result := <-Srv.Store.PostAcknowledgement().PermanentDeleteByUser(user.Id); result.Err != nil {
+		return result.Err
+	}
+
+	if result := <-Srv.Store.Mfa().PermanentDeleteByUser(user.Id); result.Err != nil {
+		return result.Err
+	}
+
+	if result := <-Srv.Store.PasswordHistory().PermanentDeleteByUser(user.Id); result.Err != nil {
+		return result.Err
+	}
+
+	if result := <-Srv.Store.LoginAttempt().Delete(model.LoginAttemptIdForUser(user.Id)); result.Err != nil {
+		return result.Err
+	}
+
 	if result := <-Srv.Store.Post().PermanentDeleteByUser(user.Id); result.Err != nil {
 		return result.Err
 	}
@@ -1379,6 +1468,7 @@ func SearchUsersWithoutTeam(term string, searchOptions map[string]bool, asAdmin
 func AutocompleteUsersInChannel(teamId string, channelId string, term string, searchOptions map[string]bool, asAdmin bool) (*model.UserAutocompleteInChannel, *model.AppError) {
 	uchan := Srv.Store.User().SearchInChannel(channelId, term, searchOptions)
 	nuchan := Srv.Store.User().SearchNotInChannel(teamId, channelId, term, searchOptions)
+	gchan := Srv.Store.MentionGroup().Search(teamId, term, MENTION_GROUP_AUTOCOMPLETE_LIMIT)
 
 	autocomplete := &model.UserAutocompleteInChannel{}
 
@@ -1406,12 +1496,20 @@ func AutocompleteUsersInChannel(teamId string, channelId string, term string, se
 		autocomplete.OutOfChannel = users
 	}
 
+	if result := <-gchan; result.Err != nil {
+		return nil, result.Err
+	} else {
+		autocomplete.MentionGroups = result.Data.([]*model.MentionGroup)
+	}
+
 	return autocomplete, nil
 }
 
 func AutocompleteUsersInTeam(teamId string, term string, searchOptions map[string]bool, asAdmin bool) (*model.UserAutocompleteInTeam, *model.AppError) {
 	autocomplete := &model.UserAutocompleteInTeam{}
 
+	gchan := Srv.Store.MentionGroup().Search(teamId, term, MENTION_GROUP_AUTOCOMPLETE_LIMIT)
+
 	if result := <-Srv.Store.User().Search(teamId, term, searchOptions); result.Err != nil {
 		return nil, result.Err
 	} else {
@@ -1424,6 +1522,12 @@ func AutocompleteUsersInTeam(teamId string, term string, searchOptions map[strin
 		autocomplete.InTeam = users
 	}
 
+	if result := <-gchan; result.Err != nil {
+		return nil, result.Err
+	} else {
+		autocomplete.MentionGroups = result.Data.([]*model.MentionGroup)
+	}
+
 	return autocomplete, nil
 }
 
@@ -1466,5 +1570,24 @@ func UpdateOAuthUserAttrs(userData io.Reader, user *model.User, provider einterf
 		InvalidateCacheForUser(user.Id)
 	}
 
+	if _, err := syncOAuthUserRoles(user, oauthUser.Roles); err != nil {
+		return err
+	}
+
 	return nil
 }
+
+// syncOAuthUserRoles grants or removes the system admin role to match the
+// roles mapped by an OAuth provider. Providers that don't manage roles leave
+// them empty, in which case the user is left untouched.
+func syncOAuthUserRoles(user *model.User, providerRoles string) (*model.User, *model.AppError) {
+	if len(providerRoles) == 0 {
+		return user, nil
+	}
+
+	if user.IsInRole(model.ROLE_SYSTEM_ADMIN.Id) == model.IsInRole(providerRoles, model.ROLE_SYSTEM_ADMIN.Id) {
+		return user, nil
+	}
+
+	return UpdateUserRoles(user.Id, providerRoles)
+}
@@ -69,6 +69,27 @@ Channels can be specified by [team]:[channel]. ie. myteam:mychannel or by channe
 	RunE:    restoreChannelsCmdF,
 }
 
+var moveChannelsCmd = &cobra.Command{
+	Use:   "move [team] [channels]",
+	Short: "Moves channels to the specified team",
+	Long: `Moves the provided channels to the specified team.
+Members of a channel who aren't on the team stop it from being moved unless --force is used, in which case they are removed from the channel.
+Channels can be specified by [team]:[channel]. ie. myteam:mychannel or by channel ID.`,
+	Example: "  channel move newteam oldteam:mychannel --username admin",
+	RunE:    moveChannelsCmdF,
+}
+
+var modifyChannelCmd = &cobra.Command{
+	Use:   "modify [channel]",
+	Short: "Modify a channel's type or who can post in it",
+	Long: `Change a public channel to be private or a private channel to be public, or restrict who can post, reply and react in a channel.
+Channels can be specified by [team]:[channel]. ie. myteam:mychannel or by channel ID.`,
+	Example: `  channel modify myteam:mychannel --private --username admin
+  channel modify myteam:town-square --post_role channel_admin
+  channel modify myteam:town-square --post_role all`,
+	RunE: modifyChannelCmdF,
+}
+
 func init() {
 	channelCreateCmd.Flags().String("name", "", "Channel Name")
 	channelCreateCmd.Flags().String("display_name", "", "Channel Display Name")
@@ -77,6 +98,14 @@ func init() {
 	channelCreateCmd.Flags().String("purpose", "", "Channel purpose")
 	channelCreateCmd.Flags().Bool("private", false, "Create a private channel.")
 
+	moveChannelsCmd.Flags().String("username", "", "Username of the user moving the channels, who is shown in the message posted to them")
+	moveChannelsCmd.Flags().Bool("force", false, "Remove members who aren't on the team from the channels.")
+
+	modifyChannelCmd.Flags().String("username", "", "Username of the user modifying the channel, who is shown in the message posted to it")
+	modifyChannelCmd.Flags().Bool("private", false, "Convert the channel to a private channel.")
+	modifyChannelCmd.Flags().Bool("public", false, "Convert the channel to a public channel.")
+	modifyChannelCmd.Flags().String("post_role", "", "Only allow users with this role, such as channel_admin, to post in the channel, or \"all\" to allow everyone.")
+
 	channelCmd.AddCommand(
 		channelCreateCmd,
 		removeChannelUsersCmd,
@@ -84,6 +113,8 @@ func init() {
 		deleteChannelsCmd,
 		listChannelsCmd,
 		restoreChannelsCmd,
+		moveChannelsCmd,
+		modifyChannelCmd,
 	)
 }
 
@@ -285,3 +316,109 @@ func restoreChannelsCmdF(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+func moveChannelsCmdF(cmd *cobra.Command, args []string) error {
+	initDBCommandContextCobra(cmd)
+
+	if len(args) < 2 {
+		return errors.New("Enter the destination team and at least one channel to move.")
+	}
+
+	team := getTeamFromTeamArg(args[0])
+	if team == nil {
+		return errors.New("Unable to find destination team '" + args[0] + "'")
+	}
+
+	userId, err := getUserIdFromUsernameFlag(cmd)
+	if err != nil {
+		return err
+	}
+
+	force, _ := cmd.Flags().GetBool("force")
+
+	channels := getChannelsFromChannelArgs(args[1:])
+	for i, channel := range channels {
+		if channel == nil {
+			CommandPrintErrorln("Unable to find channel '" + args[i+1] + "'")
+			continue
+		}
+		if _, err := app.MoveChannel(channel, team, userId, force, utils.GetSiteURL()); err != nil {
+			CommandPrintErrorln("Unable to move channel '" + channel.Name + "' error: " + err.Error())
+		} else {
+			CommandPrettyPrintln("Moved channel '" + channel.Name + "' to " + team.Name)
+		}
+	}
+
+	return nil
+}
+
+func modifyChannelCmdF(cmd *cobra.Command, args []string) error {
+	initDBCommandContextCobra(cmd)
+
+	if len(args) != 1 {
+		return errors.New("Enter one channel to modify.")
+	}
+
+	public, _ := cmd.Flags().GetBool("public")
+	private, _ := cmd.Flags().GetBool("private")
+	changePostRole := cmd.Flags().Changed("post_role")
+
+	if public && private {
+		return errors.New("You must specify only one of --public or --private")
+	} else if !public && !private && !changePostRole {
+		return errors.New("You must specify --public, --private or --post_role")
+	}
+
+	channel := getChannelFromChannelArg(args[0])
+	if channel == nil {
+		return errors.New("Unable to find channel '" + args[0] + "'")
+	}
+
+	if changePostRole {
+		postRole, _ := cmd.Flags().GetString("post_role")
+		if postRole == "all" {
+			postRole = ""
+		}
+
+		if updated, err := app.PatchChannel(channel, &model.ChannelPatch{PostRole: &postRole}); err != nil {
+			return errors.New("Unable to modify channel '" + channel.Name + "' error: " + err.Error())
+		} else {
+			channel = updated
+		}
+	}
+
+	if !public && !private {
+		return nil
+	}
+
+	userId, err := getUserIdFromUsernameFlag(cmd)
+	if err != nil {
+		return err
+	}
+
+	channelType := model.CHANNEL_OPEN
+	if private {
+		channelType = model.CHANNEL_PRIVATE
+	}
+
+	if _, err := app.ConvertChannel(channel, channelType, userId, utils.GetSiteURL()); err != nil {
+		return errors.New("Unable to modify channel '" + channel.Name + "' error: " + err.Error())
+	}
+
+	return nil
+}
+
+// Returns the id of the user given by the optional username flag, who system messages about a change are posted as.
+func getUserIdFromUsernameFlag(cmd *cobra.Command) (string, error) {
+	username, _ := cmd.Flags().GetString("username")
+	if username == "" {
+		return "", nil
+	}
+
+	user := getUserFromUserArg(username)
+	if user == nil {
+		return "", errors.New("Unable to find user '" + username + "'")
+	}
+
+	return user.Id, nil
+}
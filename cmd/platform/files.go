@@ -0,0 +1,68 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/mattermost/platform/app"
+	"github.com/spf13/cobra"
+)
+
+var filesCmd = &cobra.Command{
+	Use:   "files",
+	Short: "Management of stored files",
+}
+
+var filesReencryptCmd = &cobra.Command{
+	Use:   "reencrypt",
+	Short: "Re-encrypt stored files",
+	Long: `Re-encrypt the stored uploads, profile images, custom emoji, brand images and data exports with the encryption
+key from the configuration. Other files in the file store, like compliance exports, are left alone. Files encrypted
+with an old key are rewritten to use the current key, unencrypted files are encrypted and, when encryption is disabled,
+encrypted files are decrypted. To rotate the key, update FileSettings.EncryptionKey or the key file and set
+FileSettings.PreviousEncryptionKey or PreviousEncryptionKeyFile to the old key so the server can keep reading files
+while this command runs, or stop the server and run this command with the previous key. Files that can't be
+rewritten are logged and skipped, and the command fails once every other file has been rewritten.`,
+	Example: `  files reencrypt --old-key-file /etc/mattermost/old.key
+  files reencrypt --old-key oldsecret`,
+	RunE: filesReencryptCmdF,
+}
+
+func init() {
+	filesReencryptCmd.Flags().StringArray("old-key", []string{}, "Previous encryption key. Can be given more than once.")
+	filesReencryptCmd.Flags().StringArray("old-key-file", []string{}, "File holding a previous encryption key. Can be given more than once.")
+
+	filesCmd.AddCommand(
+		filesReencryptCmd,
+	)
+}
+
+func filesReencryptCmdF(cmd *cobra.Command, args []string) error {
+	initDBCommandContextCobra(cmd)
+
+	oldKeys := [][]byte{}
+
+	secrets, _ := cmd.Flags().GetStringArray("old-key")
+	for _, secret := range secrets {
+		oldKeys = append(oldKeys, app.FileEncryptionKey(secret))
+	}
+
+	keyFiles, _ := cmd.Flags().GetStringArray("old-key-file")
+	for _, keyFile := range keyFiles {
+		secret, err := ioutil.ReadFile(keyFile)
+		if err != nil {
+			return err
+		}
+		oldKeys = append(oldKeys, app.FileEncryptionKey(string(secret)))
+	}
+
+	count, err := app.ReencryptFiles(oldKeys)
+	CommandPrettyPrintln(fmt.Sprintf("Rewrote %v files", count))
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
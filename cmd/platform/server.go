@@ -85,6 +85,10 @@ func runServer(configFileLocation string) {
 	resetStatuses()
 
 	app.StartServer()
+	app.StartEmailReplyIngestion()
+	app.StartPostScheduler()
+	app.StartPrivacyRequestProcessing()
+	app.StartLoginAttemptCleanup()
 
 	// If we allow testing then listen for manual testing URL hits
 	if utils.Cfg.ServiceSettings.EnableTesting {
@@ -122,6 +126,10 @@ func runServer(configFileLocation string) {
 		einterfaces.GetMetricsInterface().StopServer()
 	}
 
+	app.StopPostScheduler()
+	app.StopPrivacyRequestProcessing()
+	app.StopLoginAttemptCleanup()
+	app.StopEmailReplyIngestion()
 	app.StopServer()
 }
 
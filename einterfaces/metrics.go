@@ -16,6 +16,7 @@ type MetricsInterface interface {
 
 	IncrementHttpRequest()
 	IncrementHttpError()
+	IncrementCsrfViolation()
 	ObserveHttpRequestDuration(elapsed float64)
 
 	IncrementClusterRequest()
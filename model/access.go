@@ -9,9 +9,13 @@ import (
 )
 
 const (
-	ACCESS_TOKEN_GRANT_TYPE  = "authorization_code"
-	ACCESS_TOKEN_TYPE        = "bearer"
-	REFRESH_TOKEN_GRANT_TYPE = "refresh_token"
+	ACCESS_TOKEN_GRANT_TYPE       = "authorization_code"
+	ACCESS_TOKEN_TYPE             = "bearer"
+	REFRESH_TOKEN_GRANT_TYPE      = "refresh_token"
+	CLIENT_CREDENTIALS_GRANT_TYPE = "client_credentials"
+
+	TOKEN_TYPE_HINT_ACCESS_TOKEN  = "access_token"
+	TOKEN_TYPE_HINT_REFRESH_TOKEN = "refresh_token"
 )
 
 type AccessData struct {
@@ -21,6 +25,7 @@ type AccessData struct {
 	RefreshToken string `json:"refresh_token"`
 	RedirectUri  string `json:"redirect_uri"`
 	ExpiresAt    int64  `json:"expires_at"`
+	Scope        string `json:"scope"`
 }
 
 type AccessResponse struct {
@@ -29,6 +34,19 @@ type AccessResponse struct {
 	ExpiresIn    int32  `json:"expires_in"`
 	Scope        string `json:"scope"`
 	RefreshToken string `json:"refresh_token"`
+	IdToken      string `json:"id_token,omitempty"`
+}
+
+// IntrospectionResponse is the result of a token introspection request as
+// described in RFC 7662. Only Active is set for unknown or expired tokens.
+type IntrospectionResponse struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"`
+	ClientId  string `json:"client_id,omitempty"`
+	Username  string `json:"username,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	ExpiresAt int64  `json:"exp,omitempty"`
+	Subject   string `json:"sub,omitempty"`
 }
 
 // IsValid validates the AccessData and returns an error if it isn't configured
@@ -55,6 +73,10 @@ func (ad *AccessData) IsValid() *AppError {
 		return NewLocAppError("AccessData.IsValid", "model.access.is_valid.redirect_uri.app_error", nil, "")
 	}
 
+	if len(ad.Scope) > 128 {
+		return NewLocAppError("AccessData.IsValid", "model.access.is_valid.scope.app_error", nil, "")
+	}
+
 	return nil
 }
 
@@ -110,3 +132,23 @@ func AccessResponseFromJson(data io.Reader) *AccessResponse {
 		return nil
 	}
 }
+
+func (ir *IntrospectionResponse) ToJson() string {
+	b, err := json.Marshal(ir)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func IntrospectionResponseFromJson(data io.Reader) *IntrospectionResponse {
+	decoder := json.NewDecoder(data)
+	var ir IntrospectionResponse
+	err := decoder.Decode(&ir)
+	if err == nil {
+		return &ir
+	} else {
+		return nil
+	}
+}
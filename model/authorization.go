@@ -45,6 +45,7 @@ var PERMISSION_UPLOAD_FILE *Permission
 var PERMISSION_GET_PUBLIC_LINK *Permission
 var PERMISSION_MANAGE_WEBHOOKS *Permission
 var PERMISSION_MANAGE_OTHERS_WEBHOOKS *Permission
+var PERMISSION_MANAGE_MENTION_GROUPS *Permission
 var PERMISSION_MANAGE_OAUTH *Permission
 var PERMISSION_MANAGE_SYSTEM_WIDE_OAUTH *Permission
 var PERMISSION_CREATE_POST *Permission
@@ -52,12 +53,15 @@ var PERMISSION_EDIT_POST *Permission
 var PERMISSION_EDIT_OTHERS_POSTS *Permission
 var PERMISSION_DELETE_POST *Permission
 var PERMISSION_DELETE_OTHERS_POSTS *Permission
+var PERMISSION_SET_POST_PRIORITY *Permission
 var PERMISSION_REMOVE_USER_FROM_TEAM *Permission
 var PERMISSION_CREATE_TEAM *Permission
 var PERMISSION_MANAGE_TEAM *Permission
 var PERMISSION_IMPORT_TEAM *Permission
 var PERMISSION_VIEW_TEAM *Permission
 var PERMISSION_LIST_USERS_WITHOUT_TEAM *Permission
+var PERMISSION_VIEW_MEMBERS *Permission
+var PERMISSION_INVITE_GUEST *Permission
 
 // General permission that encompases all system admin functions
 // in the future this could be broken up to allow access to some
@@ -66,9 +70,11 @@ var PERMISSION_MANAGE_SYSTEM *Permission
 
 var ROLE_SYSTEM_USER *Role
 var ROLE_SYSTEM_ADMIN *Role
+var ROLE_SYSTEM_GUEST *Role
 
 var ROLE_TEAM_USER *Role
 var ROLE_TEAM_ADMIN *Role
+var ROLE_TEAM_GUEST *Role
 
 var ROLE_CHANNEL_USER *Role
 var ROLE_CHANNEL_ADMIN *Role
@@ -227,6 +233,11 @@ func InitalizePermissions() {
 		"authentication.permissions.manage_others_webhooks.name",
 		"authentication.permissions.manage_others_webhooks.description",
 	}
+	PERMISSION_MANAGE_MENTION_GROUPS = &Permission{
+		"manage_mention_groups",
+		"authentication.permissions.manage_mention_groups.name",
+		"authentication.permissions.manage_mention_groups.description",
+	}
 	PERMISSION_MANAGE_OAUTH = &Permission{
 		"manage_oauth",
 		"authentication.permissions.manage_oauth.name",
@@ -262,6 +273,11 @@ func InitalizePermissions() {
 		"authentication.permissions.delete_others_posts.name",
 		"authentication.permissions.delete_others_posts.description",
 	}
+	PERMISSION_SET_POST_PRIORITY = &Permission{
+		"set_post_priority",
+		"authentication.permissions.set_post_priority.name",
+		"authentication.permissions.set_post_priority.description",
+	}
 	PERMISSION_REMOVE_USER_FROM_TEAM = &Permission{
 		"remove_user_from_team",
 		"authentication.permissions.remove_user_from_team.name",
@@ -292,6 +308,16 @@ func InitalizePermissions() {
 		"authentication.permisssions.list_users_without_team.name",
 		"authentication.permisssions.list_users_without_team.description",
 	}
+	PERMISSION_VIEW_MEMBERS = &Permission{
+		"view_members",
+		"authentication.permissions.view_members.name",
+		"authentication.permissions.view_members.description",
+	}
+	PERMISSION_INVITE_GUEST = &Permission{
+		"invite_guest",
+		"authentication.permissions.invite_guest.name",
+		"authentication.permissions.invite_guest.description",
+	}
 }
 
 func InitalizeRoles() {
@@ -319,14 +345,20 @@ func InitalizeRoles() {
 		"authentication.roles.channel_admin.description",
 		[]string{
 			PERMISSION_MANAGE_CHANNEL_ROLES.Id,
+			PERMISSION_SET_POST_PRIORITY.Id,
 		},
 	}
 	BuiltInRoles[ROLE_CHANNEL_ADMIN.Id] = ROLE_CHANNEL_ADMIN
 	ROLE_CHANNEL_GUEST = &Role{
-		"guest",
-		"authentication.roles.global_guest.name",
-		"authentication.roles.global_guest.description",
-		[]string{},
+		"channel_guest",
+		"authentication.roles.channel_guest.name",
+		"authentication.roles.channel_guest.description",
+		[]string{
+			PERMISSION_READ_CHANNEL.Id,
+			PERMISSION_UPLOAD_FILE.Id,
+			PERMISSION_CREATE_POST.Id,
+			PERMISSION_EDIT_POST.Id,
+		},
 	}
 	BuiltInRoles[ROLE_CHANNEL_GUEST.Id] = ROLE_CHANNEL_GUEST
 
@@ -342,6 +374,15 @@ func InitalizeRoles() {
 		},
 	}
 	BuiltInRoles[ROLE_TEAM_USER.Id] = ROLE_TEAM_USER
+	ROLE_TEAM_GUEST = &Role{
+		"team_guest",
+		"authentication.roles.team_guest.name",
+		"authentication.roles.team_guest.description",
+		[]string{
+			PERMISSION_VIEW_TEAM.Id,
+		},
+	}
+	BuiltInRoles[ROLE_TEAM_GUEST.Id] = ROLE_TEAM_GUEST
 	ROLE_TEAM_ADMIN = &Role{
 		"team_admin",
 		"authentication.roles.team_admin.name",
@@ -351,12 +392,15 @@ func InitalizeRoles() {
 			PERMISSION_REMOVE_USER_FROM_TEAM.Id,
 			PERMISSION_MANAGE_TEAM.Id,
 			PERMISSION_IMPORT_TEAM.Id,
+			PERMISSION_INVITE_GUEST.Id,
 			PERMISSION_MANAGE_TEAM_ROLES.Id,
 			PERMISSION_MANAGE_CHANNEL_ROLES.Id,
+			PERMISSION_SET_POST_PRIORITY.Id,
 			PERMISSION_MANAGE_OTHERS_WEBHOOKS.Id,
 			PERMISSION_MANAGE_SLASH_COMMANDS.Id,
 			PERMISSION_MANAGE_OTHERS_SLASH_COMMANDS.Id,
 			PERMISSION_MANAGE_WEBHOOKS.Id,
+			PERMISSION_MANAGE_MENTION_GROUPS.Id,
 		},
 	}
 	BuiltInRoles[ROLE_TEAM_ADMIN.Id] = ROLE_TEAM_ADMIN
@@ -370,9 +414,22 @@ func InitalizeRoles() {
 			PERMISSION_CREATE_GROUP_CHANNEL.Id,
 			PERMISSION_PERMANENT_DELETE_USER.Id,
 			PERMISSION_MANAGE_OAUTH.Id,
+			PERMISSION_VIEW_MEMBERS.Id,
 		},
 	}
 	BuiltInRoles[ROLE_SYSTEM_USER.Id] = ROLE_SYSTEM_USER
+	// Guests can only see the channels they were added to and the users
+	// they share those channels with
+	ROLE_SYSTEM_GUEST = &Role{
+		"system_guest",
+		"authentication.roles.global_guest.name",
+		"authentication.roles.global_guest.description",
+		[]string{
+			PERMISSION_CREATE_DIRECT_CHANNEL.Id,
+			PERMISSION_CREATE_GROUP_CHANNEL.Id,
+		},
+	}
+	BuiltInRoles[ROLE_SYSTEM_GUEST.Id] = ROLE_SYSTEM_GUEST
 	ROLE_SYSTEM_ADMIN = &Role{
 		"system_admin",
 		"authentication.roles.global_admin.name",
@@ -406,6 +463,7 @@ func InitalizeRoles() {
 							PERMISSION_CREATE_TEAM.Id,
 							PERMISSION_ADD_USER_TO_TEAM.Id,
 							PERMISSION_LIST_USERS_WITHOUT_TEAM.Id,
+							PERMISSION_VIEW_MEMBERS.Id,
 						},
 						ROLE_TEAM_USER.Permissions...,
 					),
@@ -4,6 +4,9 @@
 package model
 
 import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
 	"encoding/json"
 	"io"
 )
@@ -12,6 +15,12 @@ const (
 	AUTHCODE_EXPIRE_TIME   = 60 * 10 // 10 minutes
 	AUTHCODE_RESPONSE_TYPE = "code"
 	DEFAULT_SCOPE          = "user"
+
+	PKCE_METHOD_PLAIN = "plain"
+	PKCE_METHOD_S256  = "S256"
+
+	PKCE_VERIFIER_MIN_LENGTH = 43
+	PKCE_VERIFIER_MAX_LENGTH = 128
 )
 
 type AuthData struct {
@@ -23,6 +32,9 @@ type AuthData struct {
 	RedirectUri string `json:"redirect_uri"`
 	State       string `json:"state"`
 	Scope       string `json:"scope"`
+
+	CodeChallenge       string `json:"code_challenge"`
+	CodeChallengeMethod string `json:"code_challenge_method"`
 }
 
 // IsValid validates the AuthData and returns an error if it isn't configured
@@ -61,6 +73,18 @@ func (ad *AuthData) IsValid() *AppError {
 		return NewLocAppError("AuthData.IsValid", "model.authorize.is_valid.scope.app_error", nil, "client_id="+ad.ClientId)
 	}
 
+	if len(ad.CodeChallenge) > 0 {
+		if len(ad.CodeChallenge) < PKCE_VERIFIER_MIN_LENGTH || len(ad.CodeChallenge) > PKCE_VERIFIER_MAX_LENGTH {
+			return NewLocAppError("AuthData.IsValid", "model.authorize.is_valid.code_challenge.app_error", nil, "client_id="+ad.ClientId)
+		}
+
+		if ad.CodeChallengeMethod != PKCE_METHOD_PLAIN && ad.CodeChallengeMethod != PKCE_METHOD_S256 {
+			return NewLocAppError("AuthData.IsValid", "model.authorize.is_valid.code_challenge_method.app_error", nil, "client_id="+ad.ClientId)
+		}
+	} else if len(ad.CodeChallengeMethod) > 0 {
+		return NewLocAppError("AuthData.IsValid", "model.authorize.is_valid.code_challenge.app_error", nil, "client_id="+ad.ClientId)
+	}
+
 	return nil
 }
 
@@ -76,6 +100,10 @@ func (ad *AuthData) PreSave() {
 	if len(ad.Scope) == 0 {
 		ad.Scope = DEFAULT_SCOPE
 	}
+
+	if len(ad.CodeChallenge) > 0 && len(ad.CodeChallengeMethod) == 0 {
+		ad.CodeChallengeMethod = PKCE_METHOD_PLAIN
+	}
 }
 
 func (ad *AuthData) ToJson() string {
@@ -106,3 +134,23 @@ func (ad *AuthData) IsExpired() bool {
 
 	return false
 }
+
+// VerifyCodeVerifier checks a PKCE code verifier (RFC 7636) against the
+// challenge sent with the authorization request.
+func (ad *AuthData) VerifyCodeVerifier(verifier string) bool {
+	if len(ad.CodeChallenge) == 0 {
+		return false
+	}
+
+	if len(verifier) < PKCE_VERIFIER_MIN_LENGTH || len(verifier) > PKCE_VERIFIER_MAX_LENGTH {
+		return false
+	}
+
+	challenge := verifier
+	if ad.CodeChallengeMethod == PKCE_METHOD_S256 {
+		hash := sha256.Sum256([]byte(verifier))
+		challenge = base64.RawURLEncoding.EncodeToString(hash[:])
+	}
+
+	return subtle.ConstantTimeCompare([]byte(challenge), []byte(ad.CodeChallenge)) == 1
+}
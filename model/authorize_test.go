@@ -134,3 +134,60 @@ func TestAuthIsValid(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestAuthCodeChallenge(t *testing.T) {
+	ad := AuthData{ClientId: NewId(), UserId: NewId(), Code: NewId(), RedirectUri: "http://example.com"}
+	ad.PreSave()
+
+	// Example from RFC 7636, appendix B
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	ad.CodeChallenge = "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+
+	ad.CodeChallengeMethod = "junk"
+	if err := ad.IsValid(); err == nil {
+		t.Fatal("Should have failed invalid code challenge method")
+	}
+
+	ad.CodeChallengeMethod = PKCE_METHOD_S256
+	if err := ad.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !ad.VerifyCodeVerifier(verifier) {
+		t.Fatal("Should have accepted the code verifier")
+	}
+
+	if ad.VerifyCodeVerifier(ad.CodeChallenge) || ad.VerifyCodeVerifier("") {
+		t.Fatal("Should have rejected the code verifier")
+	}
+
+	ad.CodeChallengeMethod = PKCE_METHOD_PLAIN
+	if ad.VerifyCodeVerifier(verifier) || !ad.VerifyCodeVerifier(ad.CodeChallenge) {
+		t.Fatal("plain code challenges should match the verifier itself")
+	}
+
+	ad.CodeChallenge = "short"
+	if err := ad.IsValid(); err == nil {
+		t.Fatal("Should have failed code challenge too short")
+	}
+
+	ad.CodeChallenge = ""
+	if err := ad.IsValid(); err == nil {
+		t.Fatal("Should have failed code challenge method without a challenge")
+	}
+
+	ad.CodeChallengeMethod = ""
+	if err := ad.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	if ad.VerifyCodeVerifier(verifier) {
+		t.Fatal("Should have rejected a verifier without a challenge")
+	}
+
+	ad2 := AuthData{ClientId: NewId(), UserId: NewId(), Code: NewId(), RedirectUri: "http://example.com", CodeChallenge: verifier}
+	ad2.PreSave()
+	if ad2.CodeChallengeMethod != PKCE_METHOD_PLAIN {
+		t.Fatal("code challenge method should default to plain")
+	}
+}
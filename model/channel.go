@@ -44,6 +44,7 @@ type Channel struct {
 	TotalMsgCount int64  `json:"total_msg_count"`
 	ExtraUpdateAt int64  `json:"extra_update_at"`
 	CreatorId     string `json:"creator_id"`
+	PostRole      string `json:"post_role"`
 }
 
 type ChannelPatch struct {
@@ -51,6 +52,7 @@ type ChannelPatch struct {
 	Name        *string `json:"name"`
 	Header      *string `json:"header"`
 	Purpose     *string `json:"purpose"`
+	PostRole    *string `json:"post_role"`
 }
 
 func (o *Channel) ToJson() string {
@@ -139,6 +141,12 @@ func (o *Channel) IsValid() *AppError {
 		return NewLocAppError("Channel.IsValid", "model.channel.is_valid.creator_id.app_error", nil, "")
 	}
 
+	if o.PostRole != "" {
+		if _, ok := BuiltInRoles[o.PostRole]; !ok || o.IsGroupOrDirect() {
+			return NewLocAppError("Channel.IsValid", "model.channel.is_valid.post_role.app_error", nil, "id="+o.Id)
+		}
+	}
+
 	return nil
 }
 
@@ -180,6 +188,10 @@ func (o *Channel) Patch(patch *ChannelPatch) {
 	if patch.Purpose != nil {
 		o.Purpose = *patch.Purpose
 	}
+
+	if patch.PostRole != nil {
+		o.PostRole = *patch.PostRole
+	}
 }
 
 func GetDMNameFromIds(userId1, userId2 string) string {
@@ -6,6 +6,8 @@ package model
 import (
 	"encoding/json"
 	"io"
+	"net/http"
+	"strconv"
 	"strings"
 )
 
@@ -16,6 +18,13 @@ const (
 	CHANNEL_NOTIFY_NONE         = "none"
 	CHANNEL_MARK_UNREAD_ALL     = "all"
 	CHANNEL_MARK_UNREAD_MENTION = "mention"
+
+	MUTE_NOTIFY_PROP                    = "mute"
+	MUTE_UNTIL_NOTIFY_PROP              = "mute_until"
+	IGNORE_CHANNEL_MENTIONS_NOTIFY_PROP = "ignore_channel_mentions"
+	IGNORE_CHANNEL_MENTIONS_DEFAULT     = "default"
+	IGNORE_CHANNEL_MENTIONS_OFF         = "off"
+	IGNORE_CHANNEL_MENTIONS_ON          = "on"
 )
 
 type ChannelUnread struct {
@@ -134,6 +143,24 @@ func (o *ChannelMember) IsValid() *AppError {
 		}
 	}
 
+	if mute, ok := o.NotifyProps[MUTE_NOTIFY_PROP]; ok {
+		if mute != "true" && mute != "false" {
+			return NewAppError("ChannelMember.IsValid", "model.channel_member.is_valid.mute_value.app_error", nil, "mute="+mute, http.StatusBadRequest)
+		}
+	}
+
+	if muteUntil, ok := o.NotifyProps[MUTE_UNTIL_NOTIFY_PROP]; ok {
+		if millis, err := strconv.ParseInt(muteUntil, 10, 64); err != nil || millis < 0 {
+			return NewAppError("ChannelMember.IsValid", "model.channel_member.is_valid.mute_until.app_error", nil, "mute_until="+muteUntil, http.StatusBadRequest)
+		}
+	}
+
+	if ignoreChannelMentions, ok := o.NotifyProps[IGNORE_CHANNEL_MENTIONS_NOTIFY_PROP]; ok {
+		if len(ignoreChannelMentions) > 20 || !IsIgnoreChannelMentionsValid(ignoreChannelMentions) {
+			return NewAppError("ChannelMember.IsValid", "model.channel_member.is_valid.ignore_channel_mentions.app_error", nil, "ignore_channel_mentions="+ignoreChannelMentions, http.StatusBadRequest)
+		}
+	}
+
 	return nil
 }
 
@@ -149,6 +176,10 @@ func (o *ChannelMember) GetRoles() []string {
 	return strings.Fields(o.Roles)
 }
 
+func (o *ChannelMember) IsMuted() bool {
+	return IsChannelMuted(o.NotifyProps)
+}
+
 func IsChannelNotifyLevelValid(notifyLevel string) bool {
 	return notifyLevel == CHANNEL_NOTIFY_DEFAULT ||
 		notifyLevel == CHANNEL_NOTIFY_ALL ||
@@ -164,11 +195,39 @@ func IsSendEmailValid(sendEmail string) bool {
 	return sendEmail == CHANNEL_NOTIFY_DEFAULT || sendEmail == "true" || sendEmail == "false"
 }
 
+func IsIgnoreChannelMentionsValid(ignoreChannelMentions string) bool {
+	return ignoreChannelMentions == IGNORE_CHANNEL_MENTIONS_DEFAULT ||
+		ignoreChannelMentions == IGNORE_CHANNEL_MENTIONS_OFF ||
+		ignoreChannelMentions == IGNORE_CHANNEL_MENTIONS_ON
+}
+
+// IsChannelMuted returns true if the given channel member notify props mute the channel. A mute with
+// an expiry time stops applying once that time has passed.
+func IsChannelMuted(notifyProps StringMap) bool {
+	if notifyProps[MUTE_NOTIFY_PROP] != "true" {
+		return false
+	}
+
+	if muteUntil, err := strconv.ParseInt(notifyProps[MUTE_UNTIL_NOTIFY_PROP], 10, 64); err == nil && muteUntil > 0 {
+		return GetMillis() < muteUntil
+	}
+
+	return true
+}
+
+// IsChannelIgnoringChannelMentions returns true if the given channel member notify props ignore
+// @channel, @here and @all in the channel.
+func IsChannelIgnoringChannelMentions(notifyProps StringMap) bool {
+	return notifyProps[IGNORE_CHANNEL_MENTIONS_NOTIFY_PROP] == IGNORE_CHANNEL_MENTIONS_ON
+}
+
 func GetDefaultChannelNotifyProps() StringMap {
 	return StringMap{
-		DESKTOP_NOTIFY_PROP:     CHANNEL_NOTIFY_DEFAULT,
-		MARK_UNREAD_NOTIFY_PROP: CHANNEL_MARK_UNREAD_ALL,
-		PUSH_NOTIFY_PROP:        CHANNEL_NOTIFY_DEFAULT,
-		EMAIL_NOTIFY_PROP:       CHANNEL_NOTIFY_DEFAULT,
+		DESKTOP_NOTIFY_PROP:                 CHANNEL_NOTIFY_DEFAULT,
+		MARK_UNREAD_NOTIFY_PROP:             CHANNEL_MARK_UNREAD_ALL,
+		PUSH_NOTIFY_PROP:                    CHANNEL_NOTIFY_DEFAULT,
+		EMAIL_NOTIFY_PROP:                   CHANNEL_NOTIFY_DEFAULT,
+		MUTE_NOTIFY_PROP:                    "false",
+		IGNORE_CHANNEL_MENTIONS_NOTIFY_PROP: IGNORE_CHANNEL_MENTIONS_DEFAULT,
 	}
 }
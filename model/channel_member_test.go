@@ -4,6 +4,7 @@
 package model
 
 import (
+	"strconv"
 	"strings"
 	"testing"
 )
@@ -64,12 +65,70 @@ func TestChannelMemberIsValid(t *testing.T) {
 		t.Fatal(err)
 	}
 
+	o.NotifyProps[MUTE_NOTIFY_PROP] = "junk"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.NotifyProps[MUTE_NOTIFY_PROP] = "true"
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.NotifyProps[MUTE_UNTIL_NOTIFY_PROP] = "tomorrow"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.NotifyProps[MUTE_UNTIL_NOTIFY_PROP] = "1500000000000"
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.NotifyProps[IGNORE_CHANNEL_MENTIONS_NOTIFY_PROP] = "junk"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.NotifyProps[IGNORE_CHANNEL_MENTIONS_NOTIFY_PROP] = IGNORE_CHANNEL_MENTIONS_ON
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
 	o.Roles = ""
 	if err := o.IsValid(); err != nil {
 		t.Fatal(err)
 	}
 }
 
+func TestChannelMemberIsMuted(t *testing.T) {
+	o := ChannelMember{NotifyProps: GetDefaultChannelNotifyProps()}
+
+	if o.IsMuted() {
+		t.Fatal("shouldn't be muted by default")
+	}
+
+	o.NotifyProps[MUTE_NOTIFY_PROP] = "true"
+	if !o.IsMuted() {
+		t.Fatal("should be muted")
+	}
+
+	o.NotifyProps[MUTE_UNTIL_NOTIFY_PROP] = "0"
+	if !o.IsMuted() {
+		t.Fatal("should be muted without an expiry")
+	}
+
+	o.NotifyProps[MUTE_UNTIL_NOTIFY_PROP] = strconv.FormatInt(GetMillis()+60000, 10)
+	if !o.IsMuted() {
+		t.Fatal("should be muted until the expiry")
+	}
+
+	o.NotifyProps[MUTE_UNTIL_NOTIFY_PROP] = strconv.FormatInt(GetMillis()-60000, 10)
+	if o.IsMuted() {
+		t.Fatal("shouldn't be muted after the expiry")
+	}
+}
+
 func TestChannelUnreadJson(t *testing.T) {
 	o := ChannelUnread{ChannelId: NewId(), TeamId: NewId(), MsgCount: 5, MentionCount: 3}
 	json := o.ToJson()
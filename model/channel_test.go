@@ -28,11 +28,12 @@ func TestChannelJson(t *testing.T) {
 }
 
 func TestChannelPatch(t *testing.T) {
-	p := &ChannelPatch{Name: new(string), DisplayName: new(string), Header: new(string), Purpose: new(string)}
+	p := &ChannelPatch{Name: new(string), DisplayName: new(string), Header: new(string), Purpose: new(string), PostRole: new(string)}
 	*p.Name = NewId()
 	*p.DisplayName = NewId()
 	*p.Header = NewId()
 	*p.Purpose = NewId()
+	*p.PostRole = ROLE_CHANNEL_ADMIN.Id
 
 	o := Channel{Id: NewId(), Name: NewId()}
 	o.Patch(p)
@@ -49,6 +50,9 @@ func TestChannelPatch(t *testing.T) {
 	if *p.Purpose != o.Purpose {
 		t.Fatal("do not match")
 	}
+	if *p.PostRole != o.PostRole {
+		t.Fatal("do not match")
+	}
 }
 
 func TestChannelIsValid(t *testing.T) {
@@ -125,6 +129,22 @@ func TestChannelIsValid(t *testing.T) {
 	if err := o.IsValid(); err != nil {
 		t.Fatal(err)
 	}
+
+	o.PostRole = "junk"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.PostRole = ROLE_CHANNEL_ADMIN.Id
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.Type = CHANNEL_DIRECT
+	o.Name = "zzzzz__yyyyy"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("direct channels shouldn't be moderated")
+	}
 }
 
 func TestChannelPreSave(t *testing.T) {
@@ -32,6 +32,11 @@ const (
 	HEADER_AUTH               = "Authorization"
 	HEADER_REQUESTED_WITH     = "X-Requested-With"
 	HEADER_REQUESTED_WITH_XML = "XMLHttpRequest"
+	HEADER_CSRF_TOKEN         = "X-CSRF-Token"
+	HEADER_USER_ACTIVITY      = "X-User-Activity"
+
+	HEADER_PASSWORD_CHANGE_REQUIRED_BY = "X-Password-Change-Required-By"
+
 	STATUS                    = "status"
 	STATUS_OK                 = "OK"
 	STATUS_FAIL               = "FAIL"
@@ -1975,6 +1980,30 @@ func (c *Client) GetAccessToken(data url.Values) (*Result, *AppError) {
 	}
 }
 
+// IntrospectAccessToken describes an access or refresh token issued to the
+// OAuth app whose credentials are included in data.
+func (c *Client) IntrospectAccessToken(data url.Values) (*Result, *AppError) {
+	if r, err := c.DoPost("/oauth/introspect", data.Encode(), "application/x-www-form-urlencoded"); err != nil {
+		return nil, err
+	} else {
+		defer closeBody(r)
+		return &Result{r.Header.Get(HEADER_REQUEST_ID),
+			r.Header.Get(HEADER_ETAG_SERVER), IntrospectionResponseFromJson(r.Body)}, nil
+	}
+}
+
+// RevokeAccessToken revokes an access or refresh token issued to the OAuth
+// app whose credentials are included in data.
+func (c *Client) RevokeAccessToken(data url.Values) (*Result, *AppError) {
+	if r, err := c.DoPost("/oauth/revoke", data.Encode(), "application/x-www-form-urlencoded"); err != nil {
+		return nil, err
+	} else {
+		defer closeBody(r)
+		return &Result{r.Header.Get(HEADER_REQUEST_ID),
+			r.Header.Get(HEADER_ETAG_SERVER), MapFromJson(r.Body)}, nil
+	}
+}
+
 func (c *Client) CreateIncomingWebhook(hook *IncomingWebhook) (*Result, *AppError) {
 	if r, err := c.DoApiPost(c.GetTeamRoute()+"/hooks/incoming/create", hook.ToJson()); err != nil {
 		return nil, err
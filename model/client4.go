@@ -16,11 +16,12 @@ import (
 )
 
 type Response struct {
-	StatusCode    int
-	Error         *AppError
-	RequestId     string
-	Etag          string
-	ServerVersion string
+	StatusCode               int
+	Error                    *AppError
+	RequestId                string
+	Etag                     string
+	ServerVersion            string
+	PasswordChangeRequiredBy string
 }
 
 type Client4 struct {
@@ -37,10 +38,11 @@ func NewAPIv4Client(url string) *Client4 {
 
 func BuildResponse(r *http.Response) *Response {
 	return &Response{
-		StatusCode:    r.StatusCode,
-		RequestId:     r.Header.Get(HEADER_REQUEST_ID),
-		Etag:          r.Header.Get(HEADER_ETAG_SERVER),
-		ServerVersion: r.Header.Get(HEADER_VERSION_ID),
+		StatusCode:               r.StatusCode,
+		RequestId:                r.Header.Get(HEADER_REQUEST_ID),
+		Etag:                     r.Header.Get(HEADER_ETAG_SERVER),
+		ServerVersion:            r.Header.Get(HEADER_VERSION_ID),
+		PasswordChangeRequiredBy: r.Header.Get(HEADER_PASSWORD_CHANGE_REQUIRED_BY),
 	}
 }
 
@@ -142,6 +144,38 @@ func (c *Client4) GetPostRoute(postId string) string {
 	return fmt.Sprintf(c.GetPostsRoute()+"/%v", postId)
 }
 
+func (c *Client4) GetUserThreadsRoute(userId string) string {
+	return c.GetUserRoute(userId) + "/threads"
+}
+
+func (c *Client4) GetUserThreadRoute(userId, postId string) string {
+	return fmt.Sprintf(c.GetUserThreadsRoute(userId)+"/%v", postId)
+}
+
+func (c *Client4) GetScheduledPostsRoute() string {
+	return fmt.Sprintf("/scheduled_posts")
+}
+
+func (c *Client4) GetScheduledPostRoute(scheduledPostId string) string {
+	return fmt.Sprintf(c.GetScheduledPostsRoute()+"/%v", scheduledPostId)
+}
+
+func (c *Client4) GetPostRemindersRoute() string {
+	return fmt.Sprintf("/post_reminders")
+}
+
+func (c *Client4) GetPostReminderRoute(reminderId string) string {
+	return fmt.Sprintf(c.GetPostRemindersRoute()+"/%v", reminderId)
+}
+
+func (c *Client4) GetPrivacyRequestsRoute() string {
+	return fmt.Sprintf("/privacy_requests")
+}
+
+func (c *Client4) GetPrivacyRequestRoute(requestId string) string {
+	return fmt.Sprintf(c.GetPrivacyRequestsRoute()+"/%v", requestId)
+}
+
 func (c *Client4) GetFilesRoute() string {
 	return fmt.Sprintf("/files")
 }
@@ -222,6 +256,26 @@ func (c *Client4) GetCommandsRoute() string {
 	return fmt.Sprintf("/commands")
 }
 
+func (c *Client4) GetMentionGroupsRoute() string {
+	return fmt.Sprintf("/mention_groups")
+}
+
+func (c *Client4) GetMentionGroupRoute(groupId string) string {
+	return fmt.Sprintf(c.GetMentionGroupsRoute()+"/%v", groupId)
+}
+
+func (c *Client4) GetMentionGroupsForTeamRoute(teamId string) string {
+	return c.GetTeamRoute(teamId) + "/mention_groups"
+}
+
+func (c *Client4) GetMentionGroupMembersRoute(groupId string) string {
+	return c.GetMentionGroupRoute(groupId) + "/members"
+}
+
+func (c *Client4) GetMentionGroupMemberRoute(groupId, userId string) string {
+	return fmt.Sprintf(c.GetMentionGroupMembersRoute(groupId)+"/%v", userId)
+}
+
 func (c *Client4) DoApiGet(url string, etag string) (*http.Response, *AppError) {
 	return c.DoApiRequest(http.MethodGet, url, "", etag)
 }
@@ -354,6 +408,15 @@ func (c *Client4) LoginWithDevice(loginId string, password string, deviceId stri
 	return c.login(m)
 }
 
+// LoginWithMFA authenticates a user by login id, password and an MFA token or recovery code.
+func (c *Client4) LoginWithMFA(loginId string, password string, mfaToken string) (*User, *Response) {
+	m := make(map[string]string)
+	m["login_id"] = loginId
+	m["password"] = password
+	m["token"] = mfaToken
+	return c.login(m)
+}
+
 func (c *Client4) login(m map[string]string) (*User, *Response) {
 	if r, err := c.DoApiPost("/users/login", MapToJson(m)); err != nil {
 		return nil, &Response{StatusCode: r.StatusCode, Error: err}
@@ -626,6 +689,29 @@ func (c *Client4) GenerateMfaSecret(userId string) (*MfaSecret, *Response) {
 	}
 }
 
+// GenerateMfaRecoveryCodes replaces a user's MFA recovery codes with new ones and returns them. A current token from
+// the user's authenticator is required. The codes can't be retrieved again afterwards.
+func (c *Client4) GenerateMfaRecoveryCodes(userId string, token string) ([]string, *Response) {
+	requestBody := map[string]string{"token": token}
+	if r, err := c.DoApiPost(c.GetUserRoute(userId)+"/mfa/recovery_codes", MapToJson(requestBody)); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return MfaRecoveryCodesFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GetMfaRecoveryCodeCount returns how many unused MFA recovery codes a user has left.
+func (c *Client4) GetMfaRecoveryCodeCount(userId string) (int64, *Response) {
+	if r, err := c.DoApiGet(c.GetUserRoute(userId)+"/mfa/recovery_codes", ""); err != nil {
+		return 0, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		count, _ := strconv.ParseInt(MapFromJson(r.Body)["count"], 10, 64)
+		return count, BuildResponse(r)
+	}
+}
+
 // UpdateUserPassword updates a user's password. Must be logged in as the user or be a system administrator.
 func (c *Client4) UpdateUserPassword(userId, currentPassword, newPassword string) (bool, *Response) {
 	requestBody := map[string]string{"current_password": currentPassword, "new_password": newPassword}
@@ -702,6 +788,28 @@ func (c *Client4) RevokeSession(userId, sessionId string) (bool, *Response) {
 	}
 }
 
+// RevokeAllSessions revokes all sessions for the provided user id, emailing them the optional reason.
+func (c *Client4) RevokeAllSessions(userId, reason string) (bool, *Response) {
+	requestBody := map[string]string{"reason": reason}
+	if r, err := c.DoApiPost(c.GetUserRoute(userId)+"/sessions/revoke/all", MapToJson(requestBody)); err != nil {
+		return false, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return CheckStatusOK(r), BuildResponse(r)
+	}
+}
+
+// RevokeSessionsFromAllUsers revokes every session on the server, emailing each affected user the optional reason.
+func (c *Client4) RevokeSessionsFromAllUsers(reason string) (bool, *Response) {
+	requestBody := map[string]string{"reason": reason}
+	if r, err := c.DoApiPost(c.GetUsersRoute()+"/sessions/revoke/all", MapToJson(requestBody)); err != nil {
+		return false, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return CheckStatusOK(r), BuildResponse(r)
+	}
+}
+
 // AttachDeviceId attaches a mobile device ID to the current session.
 func (c *Client4) AttachDeviceId(deviceId string) (bool, *Response) {
 	requestBody := map[string]string{"device_id": deviceId}
@@ -1004,6 +1112,17 @@ func (c *Client4) RemoveTeamMember(teamId, userId string) (bool, *Response) {
 	}
 }
 
+// InviteGuestsToTeam sends invitations to join a team as guests restricted to
+// the given channels.
+func (c *Client4) InviteGuestsToTeam(teamId string, invite *GuestsInvite) (bool, *Response) {
+	if r, err := c.DoApiPost(c.GetTeamRoute(teamId)+"/invite-guests/email", invite.ToJson()); err != nil {
+		return false, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return CheckStatusOK(r), BuildResponse(r)
+	}
+}
+
 // GetTeamStats returns a team stats based on the team id string.
 // Must be authenticated.
 func (c *Client4) GetTeamStats(teamId, etag string) (*TeamStats, *Response) {
@@ -1131,6 +1250,17 @@ func (c *Client4) GetPinnedPosts(channelId string, etag string) (*PostList, *Res
 	}
 }
 
+// GetReadReceipts returns which members of a direct or group channel have seen each of a page of its most recent posts.
+func (c *Client4) GetReadReceipts(channelId string, page int, perPage int) (ReadReceipts, *Response) {
+	query := fmt.Sprintf("?page=%v&per_page=%v", page, perPage)
+	if r, err := c.DoApiGet(c.GetChannelRoute(channelId)+"/read_receipts"+query, ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return ReadReceiptsFromJson(r.Body), BuildResponse(r)
+	}
+}
+
 // GetPublicChannelsForTeam returns a list of public channels based on the provided team id string.
 func (c *Client4) GetPublicChannelsForTeam(teamId string, page int, perPage int, etag string) (*ChannelList, *Response) {
 	query := fmt.Sprintf("?page=%v&per_page=%v", page, perPage)
@@ -1142,6 +1272,17 @@ func (c *Client4) GetPublicChannelsForTeam(teamId string, page int, perPage int,
 	}
 }
 
+// GetArchivedChannelsForTeam returns a page of archived channels on a team that the user can read.
+func (c *Client4) GetArchivedChannelsForTeam(teamId string, page int, perPage int, etag string) (*ChannelList, *Response) {
+	query := fmt.Sprintf("?page=%v&per_page=%v", page, perPage)
+	if r, err := c.DoApiGet(c.GetChannelsForTeamRoute(teamId)+"/archived"+query, etag); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return ChannelListFromJson(r.Body), BuildResponse(r)
+	}
+}
+
 // GetPublicChannelsByIdsForTeam returns a list of public channels based on provided team id string
 func (c *Client4) GetPublicChannelsByIdsForTeam(teamId string, channelIds []string) (*ChannelList, *Response) {
 	if r, err := c.DoApiPost(c.GetChannelsForTeamRoute(teamId)+"/ids", ArrayToJson(channelIds)); err != nil {
@@ -1182,6 +1323,42 @@ func (c *Client4) DeleteChannel(channelId string) (bool, *Response) {
 	}
 }
 
+// RestoreChannel unarchives a channel based on the provided channel id string.
+func (c *Client4) RestoreChannel(channelId string) (*Channel, *Response) {
+	if r, err := c.DoApiPost(c.GetChannelRoute(channelId)+"/restore", ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return ChannelFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// MoveChannel moves a channel to another team. Members of the channel who aren't on the team are removed
+// from it if force is set, otherwise the channel can't be moved while it has any.
+func (c *Client4) MoveChannel(channelId, teamId string, force bool) (*Channel, *Response) {
+	requestBody := make(map[string]interface{})
+	requestBody["team_id"] = teamId
+	requestBody["force"] = force
+
+	if r, err := c.DoApiPost(c.GetChannelRoute(channelId)+"/move", StringInterfaceToJson(requestBody)); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return ChannelFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// ConvertChannel changes a channel to be public or private based on the provided channel type.
+func (c *Client4) ConvertChannel(channelId, channelType string) (*Channel, *Response) {
+	requestBody := map[string]string{"type": channelType}
+	if r, err := c.DoApiPost(c.GetChannelRoute(channelId)+"/convert", MapToJson(requestBody)); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return ChannelFromJson(r.Body), BuildResponse(r)
+	}
+}
+
 // GetChannelByName returns a channel based on the provided channel name and team id strings.
 func (c *Client4) GetChannelByName(channelName, teamId string, etag string) (*Channel, *Response) {
 	if r, err := c.DoApiGet(c.GetChannelByNameRoute(channelName, teamId), etag); err != nil {
@@ -1340,6 +1517,107 @@ func (c *Client4) PatchPost(postId string, patch *PostPatch) (*Post, *Response)
 	}
 }
 
+// GetPostRevisions gets what a post said before each time that it was edited, starting with the most recent.
+func (c *Client4) GetPostRevisions(postId string) (*PostList, *Response) {
+	if r, err := c.DoApiGet(c.GetPostRoute(postId)+"/revisions", ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return PostListFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// RestorePostRevision edits a post back to what it said in one of its previous versions.
+func (c *Client4) RestorePostRevision(postId, revisionId string) (*Post, *Response) {
+	if r, err := c.DoApiPost(c.GetPostRoute(postId)+"/revisions/"+revisionId+"/restore", ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return PostFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// ForwardPost posts a link to a post in another channel along with an optional message.
+func (c *Client4) ForwardPost(postId, channelId, message string) (*Post, *Response) {
+	body := map[string]string{"channel_id": channelId, "message": message}
+	if r, err := c.DoApiPost(c.GetPostRoute(postId)+"/forward", MapToJson(body)); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return PostFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// VoteInPoll replaces the current user's votes in a poll with votes for the given options.
+func (c *Client4) VoteInPoll(postId string, optionIds []string) (*Post, *Response) {
+	if r, err := c.DoApiPost(c.GetPostRoute(postId)+"/poll/vote", ArrayToJson(optionIds)); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return PostFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// ClosePoll stops any more votes from being made in a poll.
+func (c *Client4) ClosePoll(postId string) (*Post, *Response) {
+	if r, err := c.DoApiPost(c.GetPostRoute(postId)+"/poll/close", ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return PostFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GetPollVotes gets the votes in a poll, or only the current user's votes if the poll is anonymous.
+func (c *Client4) GetPollVotes(postId string) ([]*PollVote, *Response) {
+	if r, err := c.DoApiGet(c.GetPostRoute(postId)+"/poll/votes", ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return PollVotesFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// AcknowledgePost acknowledges a post that requested acknowledgements on behalf of the current user.
+func (c *Client4) AcknowledgePost(postId string) (*PostAcknowledgement, *Response) {
+	if r, err := c.DoApiPost(c.GetPostRoute(postId)+"/ack", ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return PostAcknowledgementFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// UnacknowledgePost takes back the current user's acknowledgement of a post.
+func (c *Client4) UnacknowledgePost(postId string) (bool, *Response) {
+	if r, err := c.DoApiDelete(c.GetPostRoute(postId) + "/ack"); err != nil {
+		return false, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return CheckStatusOK(r), BuildResponse(r)
+	}
+}
+
+// GetPostAcknowledgements gets the acknowledgements of a post.
+func (c *Client4) GetPostAcknowledgements(postId string) ([]*PostAcknowledgement, *Response) {
+	if r, err := c.DoApiGet(c.GetPostRoute(postId)+"/acknowledgements", ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return PostAcknowledgementsFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GetUnacknowledgedUserIds gets the ids of the users in a post's channel who haven't acknowledged it yet.
+func (c *Client4) GetUnacknowledgedUserIds(postId string) ([]string, *Response) {
+	if r, err := c.DoApiGet(c.GetPostRoute(postId)+"/acknowledgements/pending", ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return ArrayFromJson(r.Body), BuildResponse(r)
+	}
+}
+
 // PinPost pin a post based on provided post id string.
 func (c *Client4) PinPost(postId string) (bool, *Response) {
 	if r, err := c.DoApiPost(c.GetPostRoute(postId)+"/pin", ""); err != nil {
@@ -1445,6 +1723,180 @@ func (c *Client4) SearchPosts(teamId string, terms string, isOrSearch bool) (*Po
 	}
 }
 
+// Thread Section
+
+// GetFollowedThreads gets a page of the threads a user follows, along with how many replies and mentions in each of
+// them they haven't read yet.
+func (c *Client4) GetFollowedThreads(userId string, page, perPage int) ([]*Thread, *Response) {
+	query := fmt.Sprintf("?page=%v&per_page=%v", page, perPage)
+	if r, err := c.DoApiGet(c.GetUserThreadsRoute(userId)+query, ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return ThreadListFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// FollowThread starts a user following the thread started by a root post.
+func (c *Client4) FollowThread(userId, postId string) (*ThreadMembership, *Response) {
+	if r, err := c.DoApiPost(c.GetUserThreadRoute(userId, postId)+"/follow", ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return ThreadMembershipFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// UnfollowThread stops a user following the thread started by a root post.
+func (c *Client4) UnfollowThread(userId, postId string) (*ThreadMembership, *Response) {
+	if r, err := c.DoApiPost(c.GetUserThreadRoute(userId, postId)+"/unfollow", ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return ThreadMembershipFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// ViewThread marks all of the replies to a thread as read by a user.
+func (c *Client4) ViewThread(userId, postId string) (*ThreadMembership, *Response) {
+	if r, err := c.DoApiPost(c.GetUserThreadRoute(userId, postId)+"/view", ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return ThreadMembershipFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// Scheduled Post Section
+
+// CreateScheduledPost schedules a post to be created at a later time.
+func (c *Client4) CreateScheduledPost(scheduledPost *ScheduledPost) (*ScheduledPost, *Response) {
+	if r, err := c.DoApiPost(c.GetScheduledPostsRoute(), scheduledPost.ToJson()); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return ScheduledPostFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GetScheduledPostsForUser gets the posts that a user has scheduled, including any that failed to be posted.
+func (c *Client4) GetScheduledPostsForUser(userId string) ([]*ScheduledPost, *Response) {
+	if r, err := c.DoApiGet(c.GetUserRoute(userId)+"/scheduled_posts", ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return ScheduledPostListFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// UpdateScheduledPost changes the message or the time of a scheduled post.
+func (c *Client4) UpdateScheduledPost(scheduledPost *ScheduledPost) (*ScheduledPost, *Response) {
+	if r, err := c.DoApiPut(c.GetScheduledPostRoute(scheduledPost.Id), scheduledPost.ToJson()); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return ScheduledPostFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// DeleteScheduledPost cancels a scheduled post.
+func (c *Client4) DeleteScheduledPost(scheduledPostId string) (bool, *Response) {
+	if r, err := c.DoApiDelete(c.GetScheduledPostRoute(scheduledPostId)); err != nil {
+		return false, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return CheckStatusOK(r), BuildResponse(r)
+	}
+}
+
+// Post Reminder Section
+
+// CreatePostReminder reminds the current user about a post at the given time, in milliseconds.
+func (c *Client4) CreatePostReminder(postId string, remindAt int64) (*PostReminder, *Response) {
+	requestBody := map[string]interface{}{"remind_at": remindAt}
+	if r, err := c.DoApiPost(c.GetPostRoute(postId)+"/reminders", StringInterfaceToJson(requestBody)); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return PostReminderFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GetPostRemindersForUser gets the reminders that a user has yet to receive.
+func (c *Client4) GetPostRemindersForUser(userId string) ([]*PostReminder, *Response) {
+	if r, err := c.DoApiGet(c.GetUserRoute(userId)+"/post_reminders", ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return PostReminderListFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// DeletePostReminder cancels a post reminder.
+func (c *Client4) DeletePostReminder(reminderId string) (bool, *Response) {
+	if r, err := c.DoApiDelete(c.GetPostReminderRoute(reminderId)); err != nil {
+		return false, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return CheckStatusOK(r), BuildResponse(r)
+	}
+}
+
+// Privacy Request Section
+
+// CreatePrivacyRequest asks for a user's data to be exported or for their account to be deleted. Deletions only
+// happen after the configured cooling-off period and can be anonymized, which keeps the user's posts.
+func (c *Client4) CreatePrivacyRequest(userId string, requestType string, anonymize bool) (*PrivacyRequest, *Response) {
+	request := &PrivacyRequest{Type: requestType, Anonymize: anonymize}
+	if r, err := c.DoApiPost(c.GetUserRoute(userId)+"/privacy_requests", request.ToJson()); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return PrivacyRequestFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GetPrivacyRequestsForUser gets all of the export and deletion requests made for a user, newest first.
+func (c *Client4) GetPrivacyRequestsForUser(userId string) ([]*PrivacyRequest, *Response) {
+	if r, err := c.DoApiGet(c.GetUserRoute(userId)+"/privacy_requests", ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return PrivacyRequestListFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GetPrivacyRequest gets a privacy request by id.
+func (c *Client4) GetPrivacyRequest(requestId string) (*PrivacyRequest, *Response) {
+	if r, err := c.DoApiGet(c.GetPrivacyRequestRoute(requestId), ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return PrivacyRequestFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// CancelPrivacyRequest cancels a privacy request that hasn't been processed yet.
+func (c *Client4) CancelPrivacyRequest(requestId string) (*PrivacyRequest, *Response) {
+	if r, err := c.DoApiDelete(c.GetPrivacyRequestRoute(requestId)); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return PrivacyRequestFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// DownloadDataExport gets the zip file built for a finished export request.
+func (c *Client4) DownloadDataExport(requestId string) ([]byte, *Response) {
+	if r, err := c.DoApiGet(c.GetPrivacyRequestRoute(requestId)+"/download", ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else if data, err := ioutil.ReadAll(r.Body); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: NewAppError("DownloadDataExport", "model.client.read_file.app_error", nil, err.Error(), r.StatusCode)}
+	} else {
+		return data, BuildResponse(r)
+	}
+}
+
 // File Section
 
 // UploadFile will upload a file to a channel, to be later attached to a post.
@@ -2153,3 +2605,87 @@ func (c *Client4) UpdateUserStatus(userId string, userStatus *Status) (*Status,
 
 	}
 }
+
+// Mention Groups Section
+
+// CreateMentionGroup creates a mention group on a team based on the provided group struct.
+func (c *Client4) CreateMentionGroup(group *MentionGroup) (*MentionGroup, *Response) {
+	if r, err := c.DoApiPost(c.GetMentionGroupsRoute(), group.ToJson()); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return MentionGroupFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GetMentionGroup returns a mention group based on the provided group id string.
+func (c *Client4) GetMentionGroup(groupId, etag string) (*MentionGroup, *Response) {
+	if r, err := c.DoApiGet(c.GetMentionGroupRoute(groupId), etag); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return MentionGroupFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GetMentionGroupsForTeam returns all the mention groups on a team.
+func (c *Client4) GetMentionGroupsForTeam(teamId, etag string) ([]*MentionGroup, *Response) {
+	if r, err := c.DoApiGet(c.GetMentionGroupsForTeamRoute(teamId), etag); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return MentionGroupListFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// PatchMentionGroup partially updates a mention group. Any missing fields are not updated.
+func (c *Client4) PatchMentionGroup(groupId string, patch *MentionGroupPatch) (*MentionGroup, *Response) {
+	if r, err := c.DoApiPut(c.GetMentionGroupRoute(groupId)+"/patch", patch.ToJson()); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return MentionGroupFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// DeleteMentionGroup deletes a mention group based on the provided group id string.
+func (c *Client4) DeleteMentionGroup(groupId string) (bool, *Response) {
+	if r, err := c.DoApiDelete(c.GetMentionGroupRoute(groupId)); err != nil {
+		return false, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return CheckStatusOK(r), BuildResponse(r)
+	}
+}
+
+// GetMentionGroupMembers returns a page of members of a mention group. Page counting starts at 0.
+func (c *Client4) GetMentionGroupMembers(groupId string, page, perPage int, etag string) ([]*MentionGroupMember, *Response) {
+	query := fmt.Sprintf("?page=%v&per_page=%v", page, perPage)
+	if r, err := c.DoApiGet(c.GetMentionGroupMembersRoute(groupId)+query, etag); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return MentionGroupMembersFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// AddMentionGroupMember adds a user to a mention group and returns the group member.
+func (c *Client4) AddMentionGroupMember(groupId, userId string) (*MentionGroupMember, *Response) {
+	requestBody := map[string]string{"user_id": userId}
+	if r, err := c.DoApiPost(c.GetMentionGroupMembersRoute(groupId), MapToJson(requestBody)); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return MentionGroupMemberFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// RemoveMentionGroupMember removes a user from a mention group.
+func (c *Client4) RemoveMentionGroupMember(groupId, userId string) (bool, *Response) {
+	if r, err := c.DoApiDelete(c.GetMentionGroupMemberRoute(groupId, userId)); err != nil {
+		return false, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return CheckStatusOK(r), BuildResponse(r)
+	}
+}
@@ -21,12 +21,14 @@ type CompliancePost struct {
 	UserUsername string
 	UserEmail    string
 	UserNickname string
+	UserTimezone StringMap
 
 	// From Post
 	PostId         string
 	PostCreateAt   int64
 	PostUpdateAt   int64
 	PostDeleteAt   int64
+	PostEditAt     int64
 	PostRootId     string
 	PostParentId   string
 	PostOriginalId string
@@ -61,19 +63,28 @@ func CompliancePostHeader() []string {
 		"PostProps",
 		"PostHashtags",
 		"PostFileIds",
+		"PostEditAt",
 	}
 }
 
 func (me *CompliancePost) Row() []string {
 
+	// Times are shown in the timezone of the user who made the post
+	location := GetTimezoneLocation(me.UserTimezone)
+
 	postDeleteAt := ""
 	if me.PostDeleteAt > 0 {
-		postDeleteAt = time.Unix(0, me.PostDeleteAt*int64(1000*1000)).Format(time.RFC3339)
+		postDeleteAt = time.Unix(0, me.PostDeleteAt*int64(1000*1000)).In(location).Format(time.RFC3339)
+	}
+
+	postEditAt := ""
+	if me.PostEditAt > 0 {
+		postEditAt = time.Unix(0, me.PostEditAt*int64(1000*1000)).In(location).Format(time.RFC3339)
 	}
 
 	postUpdateAt := ""
 	if me.PostUpdateAt != me.PostCreateAt {
-		postUpdateAt = time.Unix(0, me.PostUpdateAt*int64(1000*1000)).Format(time.RFC3339)
+		postUpdateAt = time.Unix(0, me.PostUpdateAt*int64(1000*1000)).In(location).Format(time.RFC3339)
 	}
 
 	return []string{
@@ -88,7 +99,7 @@ func (me *CompliancePost) Row() []string {
 		me.UserNickname,
 
 		me.PostId,
-		time.Unix(0, me.PostCreateAt*int64(1000*1000)).Format(time.RFC3339),
+		time.Unix(0, me.PostCreateAt*int64(1000*1000)).In(location).Format(time.RFC3339),
 		postUpdateAt,
 		postDeleteAt,
 
@@ -100,5 +111,6 @@ func (me *CompliancePost) Row() []string {
 		me.PostProps,
 		me.PostHashtags,
 		me.PostFileIds,
+		postEditAt,
 	}
 }
@@ -21,7 +21,35 @@ func TestCompliancePost(t *testing.T) {
 		t.Fatal()
 	}
 
-	if r[len(r)-1] != "files" {
+	if r[18] != "files" {
 		t.Fatal()
 	}
 }
+
+func TestCompliancePostTimezone(t *testing.T) {
+	o := CompliancePost{
+		PostCreateAt: 1500000000000,
+		UserTimezone: StringMap{USE_AUTOMATIC_TIMEZONE: "false", MANUAL_TIMEZONE: "Asia/Tokyo"},
+	}
+	r := o.Row()
+
+	if r[8] != "2017-07-14T11:40:00+09:00" {
+		t.Fatal("create time should have been in the user's timezone", r[8])
+	}
+}
+
+func TestCompliancePostEditAt(t *testing.T) {
+	o := CompliancePost{PostCreateAt: 1500000000000}
+	if h := CompliancePostHeader(); h[len(h)-1] != "PostEditAt" {
+		t.Fatal("edit time should be the last column so existing columns keep their positions")
+	}
+
+	if r := o.Row(); r[len(r)-1] != "" {
+		t.Fatal("edit time should be empty for a post that hasn't been edited", r[len(r)-1])
+	}
+
+	o.PostEditAt = 1500000060000
+	if r := o.Row(); r[len(r)-1] != "2017-07-14T02:41:00Z" {
+		t.Fatal("should have included the edit time", r[len(r)-1])
+	}
+}
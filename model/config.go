@@ -6,7 +6,9 @@ package model
 import (
 	"encoding/json"
 	"io"
+	"net"
 	"net/url"
+	"strings"
 )
 
 const (
@@ -21,12 +23,14 @@ const (
 	DATABASE_DRIVER_MYSQL    = "mysql"
 	DATABASE_DRIVER_POSTGRES = "postgres"
 
-	PASSWORD_MAXIMUM_LENGTH = 64
-	PASSWORD_MINIMUM_LENGTH = 5
+	PASSWORD_MAXIMUM_LENGTH        = 64
+	PASSWORD_MINIMUM_LENGTH        = 5
+	PASSWORD_MAXIMUM_HISTORY_COUNT = 24
 
 	SERVICE_GITLAB    = "gitlab"
 	SERVICE_GOOGLE    = "google"
 	SERVICE_OFFICE365 = "office365"
+	SERVICE_OPENID    = "openid"
 
 	WEBSERVER_MODE_REGULAR  = "regular"
 	WEBSERVER_MODE_GZIP     = "gzip"
@@ -69,6 +73,9 @@ const (
 	SERVICE_SETTINGS_DEFAULT_WRITE_TIMEOUT   = 300
 	SERVICE_SETTINGS_DEFAULT_ALLOW_CORS_FROM = ""
 
+	// the addresses a reverse proxy in front of the server usually has, so that logins aren't all throttled against it
+	SERVICE_SETTINGS_DEFAULT_LOGIN_THROTTLE_TRUSTED_PROXIES = "127.0.0.0/8,::1/128,10.0.0.0/8,172.16.0.0/12,192.168.0.0/16,fc00::/7"
+
 	TEAM_SETTINGS_DEFAULT_CUSTOM_BRAND_TEXT        = ""
 	TEAM_SETTINGS_DEFAULT_CUSTOM_DESCRIPTION_TEXT  = ""
 	TEAM_SETTINGS_DEFAULT_USER_STATUS_AWAY_TIMEOUT = 300
@@ -142,6 +149,13 @@ type ServiceSettings struct {
 	SessionLengthMobileInDays                *int
 	SessionLengthSSOInDays                   *int
 	SessionCacheInMinutes                    *int
+	SessionIdleTimeoutInMinutes              *int
+	MaximumSessionsPerUser                   *int
+	EnableLoginThrottling                    *bool
+	LoginThrottleAttemptsPerLoginId          *int
+	LoginThrottleAttemptsPerIp               *int
+	LoginLockoutCooldownInMinutes            *int
+	LoginThrottleTrustedProxies              *string
 	WebsocketSecurePort                      *int
 	WebsocketPort                            *int
 	WebserverMode                            *string
@@ -181,6 +195,17 @@ type SSOSettings struct {
 	UserApiEndpoint string
 }
 
+type OpenIdSettings struct {
+	Enable            *bool
+	Secret            *string
+	Id                *string
+	DiscoveryEndpoint *string
+	Scope             *string
+	UsernameClaim     *string
+	GroupsClaim       *string
+	AdminGroups       *string
+}
+
 type SqlSettings struct {
 	DriverName         string
 	DataSource         string
@@ -203,32 +228,41 @@ type LogSettings struct {
 }
 
 type PasswordSettings struct {
-	MinimumLength *int
-	Lowercase     *bool
-	Number        *bool
-	Uppercase     *bool
-	Symbol        *bool
+	MinimumLength           *int
+	Lowercase               *bool
+	Number                  *bool
+	Uppercase               *bool
+	Symbol                  *bool
+	HistoryCount            *int
+	ExpiryInDays            *int
+	ExpiryGracePeriodInDays *int
+	BlocklistFile           *string
 }
 
 type FileSettings struct {
-	MaxFileSize             *int64
-	DriverName              string
-	Directory               string
-	EnablePublicLink        bool
-	PublicLinkSalt          *string
-	ThumbnailWidth          int
-	ThumbnailHeight         int
-	PreviewWidth            int
-	PreviewHeight           int
-	ProfileWidth            int
-	ProfileHeight           int
-	InitialFont             string
-	AmazonS3AccessKeyId     string
-	AmazonS3SecretAccessKey string
-	AmazonS3Bucket          string
-	AmazonS3Region          string
-	AmazonS3Endpoint        string
-	AmazonS3SSL             *bool
+	MaxFileSize               *int64
+	DriverName                string
+	Directory                 string
+	EnablePublicLink          bool
+	PublicLinkSalt            *string
+	ThumbnailWidth            int
+	ThumbnailHeight           int
+	PreviewWidth              int
+	PreviewHeight             int
+	ProfileWidth              int
+	ProfileHeight             int
+	InitialFont               string
+	AmazonS3AccessKeyId       string
+	AmazonS3SecretAccessKey   string
+	AmazonS3Bucket            string
+	AmazonS3Region            string
+	AmazonS3Endpoint          string
+	AmazonS3SSL               *bool
+	EnableEncryption          *bool
+	EncryptionKey             *string
+	EncryptionKeyFile         *string
+	PreviousEncryptionKey     *string
+	PreviousEncryptionKeyFile *string
 }
 
 type EmailSettings struct {
@@ -254,6 +288,11 @@ type EmailSettings struct {
 	EmailBatchingBufferSize           *int
 	EmailBatchingInterval             *int
 	SkipServerCertificateVerification *bool
+	EnableEmailReplies                *bool
+	EmailReplyAddress                 *string
+	EmailReplySalt                    *string
+	EmailReplyListenAddress           *string
+	EmailReplyMaildir                 *string
 }
 
 type RateLimitSettings struct {
@@ -266,8 +305,9 @@ type RateLimitSettings struct {
 }
 
 type PrivacySettings struct {
-	ShowEmailAddress bool
-	ShowFullName     bool
+	ShowEmailAddress                bool
+	ShowFullName                    bool
+	AccountDeletionCoolingOffInDays *int
 }
 
 type SupportSettings struct {
@@ -406,6 +446,7 @@ type Config struct {
 	GitLabSettings       SSOSettings
 	GoogleSettings       SSOSettings
 	Office365Settings    SSOSettings
+	OpenIdSettings       OpenIdSettings
 	LdapSettings         LdapSettings
 	ComplianceSettings   ComplianceSettings
 	LocalizationSettings LocalizationSettings
@@ -471,6 +512,31 @@ func (o *Config) SetDefaults() {
 		*o.FileSettings.AmazonS3SSL = true // Secure by default.
 	}
 
+	if o.FileSettings.EnableEncryption == nil {
+		o.FileSettings.EnableEncryption = new(bool)
+		*o.FileSettings.EnableEncryption = false
+	}
+
+	if o.FileSettings.EncryptionKey == nil {
+		o.FileSettings.EncryptionKey = new(string)
+		*o.FileSettings.EncryptionKey = ""
+	}
+
+	if o.FileSettings.EncryptionKeyFile == nil {
+		o.FileSettings.EncryptionKeyFile = new(string)
+		*o.FileSettings.EncryptionKeyFile = ""
+	}
+
+	if o.FileSettings.PreviousEncryptionKey == nil {
+		o.FileSettings.PreviousEncryptionKey = new(string)
+		*o.FileSettings.PreviousEncryptionKey = ""
+	}
+
+	if o.FileSettings.PreviousEncryptionKeyFile == nil {
+		o.FileSettings.PreviousEncryptionKeyFile = new(string)
+		*o.FileSettings.PreviousEncryptionKeyFile = ""
+	}
+
 	if o.FileSettings.MaxFileSize == nil {
 		o.FileSettings.MaxFileSize = new(int64)
 		*o.FileSettings.MaxFileSize = 52428800 // 50 MB
@@ -558,6 +624,31 @@ func (o *Config) SetDefaults() {
 		*o.PasswordSettings.Symbol = false
 	}
 
+	if o.PasswordSettings.HistoryCount == nil {
+		o.PasswordSettings.HistoryCount = new(int)
+		*o.PasswordSettings.HistoryCount = 0
+	}
+
+	if o.PasswordSettings.ExpiryInDays == nil {
+		o.PasswordSettings.ExpiryInDays = new(int)
+		*o.PasswordSettings.ExpiryInDays = 0
+	}
+
+	if o.PasswordSettings.ExpiryGracePeriodInDays == nil {
+		o.PasswordSettings.ExpiryGracePeriodInDays = new(int)
+		*o.PasswordSettings.ExpiryGracePeriodInDays = 7
+	}
+
+	if o.PasswordSettings.BlocklistFile == nil {
+		o.PasswordSettings.BlocklistFile = new(string)
+		*o.PasswordSettings.BlocklistFile = ""
+	}
+
+	if o.PrivacySettings.AccountDeletionCoolingOffInDays == nil {
+		o.PrivacySettings.AccountDeletionCoolingOffInDays = new(int)
+		*o.PrivacySettings.AccountDeletionCoolingOffInDays = 14
+	}
+
 	if o.TeamSettings.EnableCustomBrand == nil {
 		o.TeamSettings.EnableCustomBrand = new(bool)
 		*o.TeamSettings.EnableCustomBrand = false
@@ -697,6 +788,31 @@ func (o *Config) SetDefaults() {
 		*o.EmailSettings.SkipServerCertificateVerification = false
 	}
 
+	if o.EmailSettings.EnableEmailReplies == nil {
+		o.EmailSettings.EnableEmailReplies = new(bool)
+		*o.EmailSettings.EnableEmailReplies = false
+	}
+
+	if o.EmailSettings.EmailReplyAddress == nil {
+		o.EmailSettings.EmailReplyAddress = new(string)
+		*o.EmailSettings.EmailReplyAddress = ""
+	}
+
+	if o.EmailSettings.EmailReplySalt == nil || len(*o.EmailSettings.EmailReplySalt) == 0 {
+		o.EmailSettings.EmailReplySalt = new(string)
+		*o.EmailSettings.EmailReplySalt = NewRandomString(32)
+	}
+
+	if o.EmailSettings.EmailReplyListenAddress == nil {
+		o.EmailSettings.EmailReplyListenAddress = new(string)
+		*o.EmailSettings.EmailReplyListenAddress = ""
+	}
+
+	if o.EmailSettings.EmailReplyMaildir == nil {
+		o.EmailSettings.EmailReplyMaildir = new(string)
+		*o.EmailSettings.EmailReplyMaildir = ""
+	}
+
 	if !IsSafeLink(o.SupportSettings.TermsOfServiceLink) {
 		o.SupportSettings.TermsOfServiceLink = nil
 	}
@@ -867,6 +983,41 @@ func (o *Config) SetDefaults() {
 		*o.ServiceSettings.SessionCacheInMinutes = 10
 	}
 
+	if o.ServiceSettings.SessionIdleTimeoutInMinutes == nil {
+		o.ServiceSettings.SessionIdleTimeoutInMinutes = new(int)
+		*o.ServiceSettings.SessionIdleTimeoutInMinutes = 0
+	}
+
+	if o.ServiceSettings.MaximumSessionsPerUser == nil {
+		o.ServiceSettings.MaximumSessionsPerUser = new(int)
+		*o.ServiceSettings.MaximumSessionsPerUser = 0
+	}
+
+	if o.ServiceSettings.EnableLoginThrottling == nil {
+		o.ServiceSettings.EnableLoginThrottling = new(bool)
+		*o.ServiceSettings.EnableLoginThrottling = false
+	}
+
+	if o.ServiceSettings.LoginThrottleAttemptsPerLoginId == nil {
+		o.ServiceSettings.LoginThrottleAttemptsPerLoginId = new(int)
+		*o.ServiceSettings.LoginThrottleAttemptsPerLoginId = 5
+	}
+
+	if o.ServiceSettings.LoginThrottleAttemptsPerIp == nil {
+		o.ServiceSettings.LoginThrottleAttemptsPerIp = new(int)
+		*o.ServiceSettings.LoginThrottleAttemptsPerIp = 30
+	}
+
+	if o.ServiceSettings.LoginLockoutCooldownInMinutes == nil {
+		o.ServiceSettings.LoginLockoutCooldownInMinutes = new(int)
+		*o.ServiceSettings.LoginLockoutCooldownInMinutes = 30
+	}
+
+	if o.ServiceSettings.LoginThrottleTrustedProxies == nil {
+		o.ServiceSettings.LoginThrottleTrustedProxies = new(string)
+		*o.ServiceSettings.LoginThrottleTrustedProxies = SERVICE_SETTINGS_DEFAULT_LOGIN_THROTTLE_TRUSTED_PROXIES
+	}
+
 	if o.ServiceSettings.EnableCommands == nil {
 		o.ServiceSettings.EnableCommands = new(bool)
 		*o.ServiceSettings.EnableCommands = false
@@ -1159,6 +1310,7 @@ func (o *Config) SetDefaults() {
 	}
 
 	o.defaultWebrtcSettings()
+	o.defaultOpenIdSettings()
 }
 
 func (o *Config) IsValid() *AppError {
@@ -1177,6 +1329,30 @@ func (o *Config) IsValid() *AppError {
 		return NewLocAppError("Config.IsValid", "model.config.is_valid.listen_address.app_error", nil, "")
 	}
 
+	if *o.ServiceSettings.SessionIdleTimeoutInMinutes < 0 {
+		return NewLocAppError("Config.IsValid", "model.config.is_valid.session_idle_timeout.app_error", nil, "")
+	}
+
+	if *o.ServiceSettings.MaximumSessionsPerUser < 0 {
+		return NewLocAppError("Config.IsValid", "model.config.is_valid.max_sessions_per_user.app_error", nil, "")
+	}
+
+	if *o.ServiceSettings.LoginThrottleAttemptsPerLoginId <= 0 || *o.ServiceSettings.LoginThrottleAttemptsPerIp <= 0 {
+		return NewLocAppError("Config.IsValid", "model.config.is_valid.login_throttle_attempts.app_error", nil, "")
+	}
+
+	if *o.ServiceSettings.LoginLockoutCooldownInMinutes <= 0 {
+		return NewLocAppError("Config.IsValid", "model.config.is_valid.login_lockout_cooldown.app_error", nil, "")
+	}
+
+	for _, proxy := range strings.Split(*o.ServiceSettings.LoginThrottleTrustedProxies, ",") {
+		if proxy = strings.TrimSpace(proxy); len(proxy) > 0 && net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				return NewLocAppError("Config.IsValid", "model.config.is_valid.login_throttle_trusted_proxies.app_error", nil, "")
+			}
+		}
+	}
+
 	if *o.ClusterSettings.Enable && *o.EmailSettings.EnableEmailBatching {
 		return NewLocAppError("Config.IsValid", "model.config.is_valid.cluster_email_batching.app_error", nil, "")
 	}
@@ -1257,6 +1433,20 @@ func (o *Config) IsValid() *AppError {
 		return NewLocAppError("Config.IsValid", "model.config.is_valid.file_salt.app_error", nil, "")
 	}
 
+	if *o.FileSettings.EnableEncryption {
+		if len(*o.FileSettings.EncryptionKey) > 0 && len(*o.FileSettings.EncryptionKeyFile) > 0 {
+			return NewLocAppError("Config.IsValid", "model.config.is_valid.file_encryption_key_source.app_error", nil, "")
+		}
+
+		if len(*o.FileSettings.EncryptionKeyFile) == 0 && len(*o.FileSettings.EncryptionKey) < 32 {
+			return NewLocAppError("Config.IsValid", "model.config.is_valid.file_encryption_key.app_error", nil, "")
+		}
+	}
+
+	if len(*o.FileSettings.PreviousEncryptionKey) > 0 && len(*o.FileSettings.PreviousEncryptionKeyFile) > 0 {
+		return NewLocAppError("Config.IsValid", "model.config.is_valid.file_previous_encryption_key_source.app_error", nil, "")
+	}
+
 	if !(o.EmailSettings.ConnectionSecurity == CONN_SECURITY_NONE || o.EmailSettings.ConnectionSecurity == CONN_SECURITY_TLS || o.EmailSettings.ConnectionSecurity == CONN_SECURITY_STARTTLS || o.EmailSettings.ConnectionSecurity == CONN_SECURITY_PLAIN) {
 		return NewLocAppError("Config.IsValid", "model.config.is_valid.email_security.app_error", nil, "")
 	}
@@ -1277,6 +1467,14 @@ func (o *Config) IsValid() *AppError {
 		return NewLocAppError("Config.IsValid", "model.config.is_valid.email_batching_interval.app_error", nil, "")
 	}
 
+	if *o.EmailSettings.EnableEmailReplies && !strings.Contains(*o.EmailSettings.EmailReplyAddress, "@") {
+		return NewLocAppError("Config.IsValid", "model.config.is_valid.email_reply_address.app_error", nil, "")
+	}
+
+	if len(*o.EmailSettings.EmailReplySalt) < 32 {
+		return NewLocAppError("Config.IsValid", "model.config.is_valid.email_reply_salt.app_error", nil, "")
+	}
+
 	if o.RateLimitSettings.MemoryStoreSize <= 0 {
 		return NewLocAppError("Config.IsValid", "model.config.is_valid.rate_mem.app_error", nil, "")
 	}
@@ -1365,6 +1563,18 @@ func (o *Config) IsValid() *AppError {
 		return NewLocAppError("Config.IsValid", "model.config.is_valid.password_length.app_error", map[string]interface{}{"MinLength": PASSWORD_MINIMUM_LENGTH, "MaxLength": PASSWORD_MAXIMUM_LENGTH}, "")
 	}
 
+	if *o.PasswordSettings.HistoryCount < 0 || *o.PasswordSettings.HistoryCount > PASSWORD_MAXIMUM_HISTORY_COUNT {
+		return NewLocAppError("Config.IsValid", "model.config.is_valid.password_history_count.app_error", map[string]interface{}{"MaxCount": PASSWORD_MAXIMUM_HISTORY_COUNT}, "")
+	}
+
+	if *o.PasswordSettings.ExpiryInDays < 0 || *o.PasswordSettings.ExpiryGracePeriodInDays < 0 {
+		return NewLocAppError("Config.IsValid", "model.config.is_valid.password_expiry.app_error", nil, "")
+	}
+
+	if *o.PrivacySettings.AccountDeletionCoolingOffInDays < 0 {
+		return NewLocAppError("Config.IsValid", "model.config.is_valid.privacy_account_deletion_cooling_off.app_error", nil, "")
+	}
+
 	if len(o.TeamSettings.SiteName) > SITENAME_MAX_LENGTH {
 		return NewLocAppError("Config.IsValid", "model.config.is_valid.sitename_length.app_error", map[string]interface{}{"MaxLength": SITENAME_MAX_LENGTH}, "")
 	}
@@ -1377,6 +1587,10 @@ func (o *Config) IsValid() *AppError {
 		return err
 	}
 
+	if err := o.isValidOpenIdSettings(); err != nil {
+		return err
+	}
+
 	if !(*o.ServiceSettings.ConnectionSecurity == CONN_SECURITY_NONE || *o.ServiceSettings.ConnectionSecurity == CONN_SECURITY_TLS) {
 		return NewLocAppError("Config.IsValid", "model.config.is_valid.webserver_security.app_error", nil, "")
 	}
@@ -1413,9 +1627,16 @@ func (o *Config) Sanitize() {
 	if len(o.FileSettings.AmazonS3SecretAccessKey) > 0 {
 		o.FileSettings.AmazonS3SecretAccessKey = FAKE_SETTING
 	}
+	if o.FileSettings.EncryptionKey != nil && len(*o.FileSettings.EncryptionKey) > 0 {
+		*o.FileSettings.EncryptionKey = FAKE_SETTING
+	}
+	if o.FileSettings.PreviousEncryptionKey != nil && len(*o.FileSettings.PreviousEncryptionKey) > 0 {
+		*o.FileSettings.PreviousEncryptionKey = FAKE_SETTING
+	}
 
 	o.EmailSettings.InviteSalt = FAKE_SETTING
 	o.EmailSettings.PasswordResetSalt = FAKE_SETTING
+	*o.EmailSettings.EmailReplySalt = FAKE_SETTING
 	if len(o.EmailSettings.SMTPPassword) > 0 {
 		o.EmailSettings.SMTPPassword = FAKE_SETTING
 	}
@@ -1424,6 +1645,10 @@ func (o *Config) Sanitize() {
 		o.GitLabSettings.Secret = FAKE_SETTING
 	}
 
+	if len(*o.OpenIdSettings.Secret) > 0 {
+		*o.OpenIdSettings.Secret = FAKE_SETTING
+	}
+
 	o.SqlSettings.DataSource = FAKE_SETTING
 	o.SqlSettings.AtRestEncryptKey = FAKE_SETTING
 
@@ -1499,3 +1724,63 @@ func (o *Config) isValidWebrtcSettings() *AppError {
 
 	return nil
 }
+
+func (o *Config) defaultOpenIdSettings() {
+	if o.OpenIdSettings.Enable == nil {
+		o.OpenIdSettings.Enable = new(bool)
+		*o.OpenIdSettings.Enable = false
+	}
+
+	if o.OpenIdSettings.Secret == nil {
+		o.OpenIdSettings.Secret = new(string)
+		*o.OpenIdSettings.Secret = ""
+	}
+
+	if o.OpenIdSettings.Id == nil {
+		o.OpenIdSettings.Id = new(string)
+		*o.OpenIdSettings.Id = ""
+	}
+
+	if o.OpenIdSettings.DiscoveryEndpoint == nil {
+		o.OpenIdSettings.DiscoveryEndpoint = new(string)
+		*o.OpenIdSettings.DiscoveryEndpoint = ""
+	}
+
+	if o.OpenIdSettings.Scope == nil {
+		o.OpenIdSettings.Scope = new(string)
+		*o.OpenIdSettings.Scope = "openid profile email"
+	}
+
+	if o.OpenIdSettings.UsernameClaim == nil {
+		o.OpenIdSettings.UsernameClaim = new(string)
+		*o.OpenIdSettings.UsernameClaim = "preferred_username"
+	}
+
+	if o.OpenIdSettings.GroupsClaim == nil {
+		o.OpenIdSettings.GroupsClaim = new(string)
+		*o.OpenIdSettings.GroupsClaim = "groups"
+	}
+
+	if o.OpenIdSettings.AdminGroups == nil {
+		o.OpenIdSettings.AdminGroups = new(string)
+		*o.OpenIdSettings.AdminGroups = ""
+	}
+}
+
+func (o *Config) isValidOpenIdSettings() *AppError {
+	if *o.OpenIdSettings.Enable {
+		if len(*o.OpenIdSettings.Id) == 0 {
+			return NewLocAppError("Config.IsValid", "model.config.is_valid.openid_id.app_error", nil, "")
+		} else if len(*o.OpenIdSettings.Secret) == 0 {
+			return NewLocAppError("Config.IsValid", "model.config.is_valid.openid_secret.app_error", nil, "")
+		} else if !IsValidHttpUrl(*o.OpenIdSettings.DiscoveryEndpoint) {
+			return NewLocAppError("Config.IsValid", "model.config.is_valid.openid_discovery_endpoint.app_error", nil, "")
+		} else if !strings.Contains(" "+*o.OpenIdSettings.Scope+" ", " openid ") {
+			return NewLocAppError("Config.IsValid", "model.config.is_valid.openid_scope.app_error", nil, "")
+		} else if len(*o.OpenIdSettings.UsernameClaim) == 0 {
+			return NewLocAppError("Config.IsValid", "model.config.is_valid.openid_username_claim.app_error", nil, "")
+		}
+	}
+
+	return nil
+}
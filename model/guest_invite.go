@@ -0,0 +1,77 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+)
+
+const (
+	GUEST_INVITE_DEFAULT_EXPIRY = 1000 * 60 * 60 * 24 * 7  // 7 days
+	GUEST_INVITE_MAX_EXPIRY     = 1000 * 60 * 60 * 24 * 90 // 90 days
+)
+
+// GuestsInvite invites people to join a team as guests who can only see the
+// given channels. The invitation links stop working at ExpiresAt.
+type GuestsInvite struct {
+	Emails     []string `json:"emails"`
+	ChannelIds []string `json:"channel_ids"`
+	ExpiresAt  int64    `json:"expires_at"`
+}
+
+func (o *GuestsInvite) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func GuestsInviteFromJson(data io.Reader) *GuestsInvite {
+	decoder := json.NewDecoder(data)
+	var o GuestsInvite
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func (o *GuestsInvite) PreSave() {
+	if o.ExpiresAt == 0 {
+		o.ExpiresAt = GetMillis() + GUEST_INVITE_DEFAULT_EXPIRY
+	}
+}
+
+func (o *GuestsInvite) IsValid() *AppError {
+	if len(o.Emails) == 0 {
+		return NewLocAppError("GuestsInvite.IsValid", "model.guest_invite.is_valid.emails.app_error", nil, "")
+	}
+
+	for _, email := range o.Emails {
+		if !IsValidEmail(email) {
+			return NewLocAppError("GuestsInvite.IsValid", "model.guest_invite.is_valid.emails.app_error", nil, "email="+email)
+		}
+	}
+
+	if len(o.ChannelIds) == 0 {
+		return NewLocAppError("GuestsInvite.IsValid", "model.guest_invite.is_valid.channels.app_error", nil, "")
+	}
+
+	for _, channelId := range o.ChannelIds {
+		if len(channelId) != 26 {
+			return NewLocAppError("GuestsInvite.IsValid", "model.guest_invite.is_valid.channels.app_error", nil, "channel_id="+channelId)
+		}
+	}
+
+	now := GetMillis()
+	if o.ExpiresAt <= now || o.ExpiresAt > now+GUEST_INVITE_MAX_EXPIRY {
+		return NewLocAppError("GuestsInvite.IsValid", "model.guest_invite.is_valid.expires_at.app_error", nil, "")
+	}
+
+	return nil
+}
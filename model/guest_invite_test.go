@@ -0,0 +1,60 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGuestsInviteJson(t *testing.T) {
+	o := GuestsInvite{Emails: []string{"guest@example.com"}, ChannelIds: []string{NewId()}, ExpiresAt: GetMillis()}
+	ro := GuestsInviteFromJson(strings.NewReader(o.ToJson()))
+
+	if ro.Emails[0] != o.Emails[0] || ro.ChannelIds[0] != o.ChannelIds[0] || ro.ExpiresAt != o.ExpiresAt {
+		t.Fatal("ids do not match")
+	}
+}
+
+func TestGuestsInviteIsValid(t *testing.T) {
+	o := GuestsInvite{}
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.Emails = []string{"junk"}
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.Emails = []string{"guest@example.com"}
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.ChannelIds = []string{"junk"}
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.ChannelIds = []string{NewId()}
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.PreSave()
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.ExpiresAt = GetMillis() - 1
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.ExpiresAt = GetMillis() + GUEST_INVITE_MAX_EXPIRY + 1000*60
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+)
+
+const (
+	LOGIN_ATTEMPT_ID_MAX_LENGTH = 190
+
+	LOGIN_ATTEMPT_MIN_BACKOFF = 1000 // 1 second
+)
+
+// LoginAttempt counts the recent failed logins for an IP address, a login ID or a user so that further attempts
+// can be throttled. Id is prefixed by the kind of thing being counted, e.g. "ip:127.0.0.1".
+type LoginAttempt struct {
+	Id             string `json:"id"`
+	FailedAttempts int    `json:"failed_attempts"`
+	LastFailedAt   int64  `json:"last_failed_at"`
+}
+
+func LoginAttemptIdForIpAddress(ipAddress string) string {
+	return truncateLoginAttemptId("ip:" + ipAddress)
+}
+
+func LoginAttemptIdForLoginId(loginId string) string {
+	return truncateLoginAttemptId("login:" + strings.ToLower(strings.TrimSpace(loginId)))
+}
+
+func LoginAttemptIdForUser(userId string) string {
+	return "user:" + userId
+}
+
+func truncateLoginAttemptId(id string) string {
+	if len(id) > LOGIN_ATTEMPT_ID_MAX_LENGTH {
+		return id[:LOGIN_ATTEMPT_ID_MAX_LENGTH]
+	}
+
+	return id
+}
+
+// Backoff returns how long to wait after the last failure before another attempt is allowed. The first
+// freeAttempts failures don't need any wait, after which the wait doubles with every failure up to maxBackoff.
+func (a *LoginAttempt) Backoff(freeAttempts int, maxBackoff int64) int64 {
+	if a.FailedAttempts < freeAttempts {
+		return 0
+	}
+
+	backoff := int64(LOGIN_ATTEMPT_MIN_BACKOFF)
+	for i := freeAttempts; i < a.FailedAttempts && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+
+	if backoff > maxBackoff {
+		return maxBackoff
+	}
+
+	return backoff
+}
+
+// RetryAt returns the time at which another attempt is allowed.
+func (a *LoginAttempt) RetryAt(freeAttempts int, maxBackoff int64) int64 {
+	return a.LastFailedAt + a.Backoff(freeAttempts, maxBackoff)
+}
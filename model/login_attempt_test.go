@@ -0,0 +1,50 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoginAttemptIds(t *testing.T) {
+	if LoginAttemptIdForLoginId(" User@Example.com ") != LoginAttemptIdForLoginId("user@example.com") {
+		t.Fatal("should have ignored case and surrounding whitespace")
+	}
+
+	if LoginAttemptIdForIpAddress("127.0.0.1") == LoginAttemptIdForLoginId("127.0.0.1") {
+		t.Fatal("should have kept IP addresses and login IDs apart")
+	}
+
+	if len(LoginAttemptIdForLoginId(strings.Repeat("a", 500))) != LOGIN_ATTEMPT_ID_MAX_LENGTH {
+		t.Fatal("should have truncated the login ID")
+	}
+}
+
+func TestLoginAttemptBackoff(t *testing.T) {
+	attempt := &LoginAttempt{FailedAttempts: 4, LastFailedAt: 10000}
+
+	if backoff := attempt.Backoff(5, 60000); backoff != 0 {
+		t.Fatal("shouldn't have backed off before running out of free attempts", backoff)
+	}
+
+	attempt.FailedAttempts = 5
+	if backoff := attempt.Backoff(5, 60000); backoff != 1000 {
+		t.Fatal("should have backed off for a second", backoff)
+	}
+
+	attempt.FailedAttempts = 8
+	if backoff := attempt.Backoff(5, 60000); backoff != 8000 {
+		t.Fatal("should have doubled the backoff for each failure", backoff)
+	}
+
+	if retryAt := attempt.RetryAt(5, 60000); retryAt != 18000 {
+		t.Fatal("should have allowed a retry after the backoff", retryAt)
+	}
+
+	attempt.FailedAttempts = 1000
+	if backoff := attempt.Backoff(5, 60000); backoff != 60000 {
+		t.Fatal("should have capped the backoff", backoff)
+	}
+}
@@ -0,0 +1,262 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	MENTION_GROUP_NAME_MIN_LENGTH        = 2
+	MENTION_GROUP_NAME_MAX_LENGTH        = 64
+	MENTION_GROUP_DISPLAY_NAME_MAX_RUNES = 64
+	MENTION_GROUP_DESCRIPTION_MAX_RUNES  = 255
+)
+
+var validMentionGroupNameChars = regexp.MustCompile(`^[a-z0-9\.\-_]+$`)
+
+// Mention groups can't shadow the special mentions that already notify a whole channel
+var reservedMentionGroupNames = []string{
+	"all",
+	"channel",
+	"here",
+}
+
+type MentionGroup struct {
+	Id          string `json:"id"`
+	CreateAt    int64  `json:"create_at"`
+	UpdateAt    int64  `json:"update_at"`
+	DeleteAt    int64  `json:"delete_at"`
+	TeamId      string `json:"team_id"`
+	CreatorId   string `json:"creator_id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+	Description string `json:"description"`
+}
+
+type MentionGroupPatch struct {
+	Name        *string `json:"name"`
+	DisplayName *string `json:"display_name"`
+	Description *string `json:"description"`
+}
+
+type MentionGroupMember struct {
+	GroupId  string `json:"group_id"`
+	UserId   string `json:"user_id"`
+	CreateAt int64  `json:"create_at"`
+}
+
+func IsValidMentionGroupName(name string) bool {
+	if len(name) < MENTION_GROUP_NAME_MIN_LENGTH || len(name) > MENTION_GROUP_NAME_MAX_LENGTH {
+		return false
+	}
+
+	if !validMentionGroupNameChars.MatchString(name) {
+		return false
+	}
+
+	for _, reserved := range reservedMentionGroupNames {
+		if name == reserved {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (o *MentionGroup) IsValid() *AppError {
+	if len(o.Id) != 26 {
+		return NewAppError("MentionGroup.IsValid", "model.mention_group.is_valid.id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if o.CreateAt == 0 {
+		return NewAppError("MentionGroup.IsValid", "model.mention_group.is_valid.create_at.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if o.UpdateAt == 0 {
+		return NewAppError("MentionGroup.IsValid", "model.mention_group.is_valid.update_at.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if len(o.TeamId) != 26 {
+		return NewAppError("MentionGroup.IsValid", "model.mention_group.is_valid.team_id.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if len(o.CreatorId) != 26 {
+		return NewAppError("MentionGroup.IsValid", "model.mention_group.is_valid.creator_id.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if !IsValidMentionGroupName(o.Name) {
+		return NewAppError("MentionGroup.IsValid", "model.mention_group.is_valid.name.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if utf8.RuneCountInString(o.DisplayName) > MENTION_GROUP_DISPLAY_NAME_MAX_RUNES {
+		return NewAppError("MentionGroup.IsValid", "model.mention_group.is_valid.display_name.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if utf8.RuneCountInString(o.Description) > MENTION_GROUP_DESCRIPTION_MAX_RUNES {
+		return NewAppError("MentionGroup.IsValid", "model.mention_group.is_valid.description.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func (o *MentionGroup) PreSave() {
+	if o.Id == "" {
+		o.Id = NewId()
+	}
+
+	o.Name = strings.ToLower(o.Name)
+
+	if o.CreateAt == 0 {
+		o.CreateAt = GetMillis()
+	}
+	o.UpdateAt = o.CreateAt
+}
+
+func (o *MentionGroup) PreUpdate() {
+	o.Name = strings.ToLower(o.Name)
+	o.UpdateAt = GetMillis()
+}
+
+func (o *MentionGroup) Patch(patch *MentionGroupPatch) {
+	if patch.Name != nil {
+		o.Name = *patch.Name
+	}
+
+	if patch.DisplayName != nil {
+		o.DisplayName = *patch.DisplayName
+	}
+
+	if patch.Description != nil {
+		o.Description = *patch.Description
+	}
+}
+
+// MentionKeyword returns the word that mentions every member of the group.
+func (o *MentionGroup) MentionKeyword() string {
+	return "@" + o.Name
+}
+
+func (o *MentionGroup) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func MentionGroupFromJson(data io.Reader) *MentionGroup {
+	decoder := json.NewDecoder(data)
+	var o MentionGroup
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func (o *MentionGroupPatch) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func MentionGroupPatchFromJson(data io.Reader) *MentionGroupPatch {
+	decoder := json.NewDecoder(data)
+	var o MentionGroupPatch
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func MentionGroupListToJson(l []*MentionGroup) string {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func MentionGroupListFromJson(data io.Reader) []*MentionGroup {
+	decoder := json.NewDecoder(data)
+	var l []*MentionGroup
+	err := decoder.Decode(&l)
+	if err == nil {
+		return l
+	} else {
+		return nil
+	}
+}
+
+func (o *MentionGroupMember) IsValid() *AppError {
+	if len(o.GroupId) != 26 {
+		return NewAppError("MentionGroupMember.IsValid", "model.mention_group_member.is_valid.group_id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if len(o.UserId) != 26 {
+		return NewAppError("MentionGroupMember.IsValid", "model.mention_group_member.is_valid.user_id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func (o *MentionGroupMember) PreSave() {
+	if o.CreateAt == 0 {
+		o.CreateAt = GetMillis()
+	}
+}
+
+func (o *MentionGroupMember) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func MentionGroupMemberFromJson(data io.Reader) *MentionGroupMember {
+	decoder := json.NewDecoder(data)
+	var o MentionGroupMember
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func MentionGroupMembersToJson(l []*MentionGroupMember) string {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func MentionGroupMembersFromJson(data io.Reader) []*MentionGroupMember {
+	decoder := json.NewDecoder(data)
+	var l []*MentionGroupMember
+	err := decoder.Decode(&l)
+	if err == nil {
+		return l
+	} else {
+		return nil
+	}
+}
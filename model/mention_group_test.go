@@ -0,0 +1,104 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMentionGroupJson(t *testing.T) {
+	o := MentionGroup{Id: NewId(), Name: "backend-oncall"}
+	json := o.ToJson()
+	ro := MentionGroupFromJson(strings.NewReader(json))
+
+	if o.Id != ro.Id || o.Name != ro.Name {
+		t.Fatal("Ids do not match")
+	}
+}
+
+func TestMentionGroupIsValid(t *testing.T) {
+	o := MentionGroup{}
+
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.TeamId = NewId()
+	o.CreatorId = NewId()
+	o.Name = "Backend-Oncall"
+	o.PreSave()
+
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	if o.Name != "backend-oncall" {
+		t.Fatal("name should have been lower cased")
+	}
+
+	if o.MentionKeyword() != "@backend-oncall" {
+		t.Fatal("wrong mention keyword")
+	}
+
+	o.DisplayName = strings.Repeat("0123456789", 7)
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+	o.DisplayName = ""
+
+	o.Description = strings.Repeat("0123456789", 26)
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+}
+
+func TestIsValidMentionGroupName(t *testing.T) {
+	valid := []string{"backend-oncall", "ops", "team.leads", "a_1"}
+	for _, name := range valid {
+		if !IsValidMentionGroupName(name) {
+			t.Fatal("should be valid", name)
+		}
+	}
+
+	invalid := []string{"", "a", "all", "channel", "here", "back end", "Backend", "@ops", strings.Repeat("a", 65)}
+	for _, name := range invalid {
+		if IsValidMentionGroupName(name) {
+			t.Fatal("should be invalid", name)
+		}
+	}
+}
+
+func TestMentionGroupPatch(t *testing.T) {
+	o := MentionGroup{Name: "ops", DisplayName: "Ops"}
+
+	patch := &MentionGroupPatch{DisplayName: new(string), Description: new(string)}
+	*patch.DisplayName = "Operations"
+	*patch.Description = "Everyone on call"
+
+	o.Patch(patch)
+
+	if o.Name != "ops" || o.DisplayName != "Operations" || o.Description != "Everyone on call" {
+		t.Fatal("patch failed")
+	}
+}
+
+func TestMentionGroupMemberIsValid(t *testing.T) {
+	o := MentionGroupMember{}
+
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.GroupId = NewId()
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.UserId = NewId()
+	o.PreSave()
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+}
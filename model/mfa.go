@@ -0,0 +1,104 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+const (
+	MFA_RECOVERY_CODE_COUNT    = 10
+	MFA_RECOVERY_CODE_LENGTH   = 10
+	MFA_RECOVERY_CODE_ALPHABET = "abcdefghjkmnpqrstuvwxyz23456789" // leaves out characters that are easily mixed up
+
+	MFA_TOKEN_WINDOW = 90 * 1000 // tokens are valid for one 30 second step on either side of the current one
+)
+
+// MfaRecoveryCode is a single use code that lets a user log in without their MFA device. Only a hash of the code is
+// kept since it works like a password.
+type MfaRecoveryCode struct {
+	UserId   string `json:"user_id"`
+	CodeHash string `json:"code_hash"`
+	CreateAt int64  `json:"create_at"`
+}
+
+// MfaUsedToken records that an MFA token has been used, so that it can't be used again while it's still valid.
+type MfaUsedToken struct {
+	UserId string `json:"user_id"`
+	Token  string `json:"token"`
+	UsedAt int64  `json:"used_at"`
+}
+
+// NewMfaRecoveryCode returns a random recovery code formatted for display as two groups of five characters.
+func NewMfaRecoveryCode() string {
+	// only use bytes that map evenly onto the alphabet so that every character is as likely
+	limit := byte(256 / len(MFA_RECOVERY_CODE_ALPHABET) * len(MFA_RECOVERY_CODE_ALPHABET))
+
+	code := make([]byte, 0, MFA_RECOVERY_CODE_LENGTH+1)
+	b := make([]byte, 1)
+	for count := 0; count < MFA_RECOVERY_CODE_LENGTH; {
+		if _, err := rand.Read(b); err != nil {
+			panic(err)
+		} else if b[0] >= limit {
+			continue
+		}
+
+		if count == MFA_RECOVERY_CODE_LENGTH/2 {
+			code = append(code, '-')
+		}
+
+		code = append(code, MFA_RECOVERY_CODE_ALPHABET[int(b[0])%len(MFA_RECOVERY_CODE_ALPHABET)])
+		count++
+	}
+
+	return string(code)
+}
+
+// NormalizeMfaRecoveryCode strips out the formatting that a user may have entered along with a recovery code.
+func NormalizeMfaRecoveryCode(code string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+
+		return r
+	}, strings.ToLower(strings.TrimSpace(code)))
+}
+
+// IsMfaRecoveryCode returns true if what the user entered in place of an MFA token looks like a recovery code.
+func IsMfaRecoveryCode(code string) bool {
+	return len(NormalizeMfaRecoveryCode(code)) == MFA_RECOVERY_CODE_LENGTH
+}
+
+// HashMfaRecoveryCode hashes a recovery code to be stored. Recovery codes are random and long enough that they
+// don't need a slow hash like passwords do.
+func HashMfaRecoveryCode(code string) string {
+	hash := sha256.Sum256([]byte(NormalizeMfaRecoveryCode(code)))
+	return hex.EncodeToString(hash[:])
+}
+
+func MfaRecoveryCodesToJson(codes []string) string {
+	b, err := json.Marshal(map[string][]string{"recovery_codes": codes})
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func MfaRecoveryCodesFromJson(data io.Reader) []string {
+	decoder := json.NewDecoder(data)
+	var o map[string][]string
+	err := decoder.Decode(&o)
+	if err == nil {
+		return o["recovery_codes"]
+	} else {
+		return nil
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewMfaRecoveryCode(t *testing.T) {
+	code := NewMfaRecoveryCode()
+
+	if len(code) != MFA_RECOVERY_CODE_LENGTH+1 || code[MFA_RECOVERY_CODE_LENGTH/2] != '-' {
+		t.Fatal("should have formatted the code as two groups of characters", code)
+	}
+
+	for _, r := range strings.Replace(code, "-", "", 1) {
+		if !strings.ContainsRune(MFA_RECOVERY_CODE_ALPHABET, r) {
+			t.Fatal("should only have used characters from the alphabet", code)
+		}
+	}
+
+	if NewMfaRecoveryCode() == code {
+		t.Fatal("should have generated a different code")
+	}
+}
+
+func TestMfaRecoveryCodeFormat(t *testing.T) {
+	code := NewMfaRecoveryCode()
+
+	if !IsMfaRecoveryCode(code) || !IsMfaRecoveryCode(" "+strings.ToUpper(strings.Replace(code, "-", " ", 1))) {
+		t.Fatal("should be a recovery code")
+	}
+
+	if IsMfaRecoveryCode("123456") {
+		t.Fatal("an MFA token isn't a recovery code")
+	}
+
+	if HashMfaRecoveryCode(code) != HashMfaRecoveryCode(strings.ToUpper(strings.Replace(code, "-", "", 1))) {
+		t.Fatal("should have hashed the code the same way regardless of formatting")
+	}
+
+	if HashMfaRecoveryCode(code) == HashMfaRecoveryCode(NewMfaRecoveryCode()) {
+		t.Fatal("should have hashed different codes differently")
+	}
+}
+
+func TestMfaRecoveryCodesJson(t *testing.T) {
+	codes := []string{NewMfaRecoveryCode(), NewMfaRecoveryCode()}
+
+	if decoded := MfaRecoveryCodesFromJson(strings.NewReader(MfaRecoveryCodesToJson(codes))); len(decoded) != 2 || decoded[0] != codes[0] {
+		t.Fatal("should have decoded the codes")
+	}
+}
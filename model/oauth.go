@@ -15,6 +15,10 @@ const (
 	OAUTH_ACTION_LOGIN        = "login"
 	OAUTH_ACTION_EMAIL_TO_SSO = "email_to_sso"
 	OAUTH_ACTION_SSO_TO_EMAIL = "sso_to_email"
+
+	// Users created for OAuth apps that use the client credentials grant
+	// have this auth service and the app's client id as auth data.
+	USER_AUTH_SERVICE_OAUTH_APP = "oauth_app"
 )
 
 type OAuthApp struct {
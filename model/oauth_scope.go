@@ -0,0 +1,77 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+)
+
+// OAuth scopes granted to tokens issued by the OAuth 2.0 service provider.
+// Each scope includes the ones before it.
+const (
+	OAUTH_SCOPE_READ  = "read"  // read-only access to the API
+	OAUTH_SCOPE_POST  = "post"  // read access plus creating and editing posts and files
+	OAUTH_SCOPE_USER  = "user"  // everything the user can do, except system administration
+	OAUTH_SCOPE_ADMIN = "admin" // everything the user can do, including system administration
+)
+
+var oauthScopeLevels = map[string]int{
+	OAUTH_SCOPE_READ:  1,
+	OAUTH_SCOPE_POST:  2,
+	OAUTH_SCOPE_USER:  3,
+	OAUTH_SCOPE_ADMIN: 4,
+}
+
+// NormalizeOAuthScope drops unknown and duplicate scopes from a space
+// separated scope string, as the authorization server is allowed to ignore
+// scopes it doesn't support. DEFAULT_SCOPE is returned if nothing is left.
+func NormalizeOAuthScope(scope string) string {
+	scopes := []string{}
+	seen := map[string]bool{}
+	for _, s := range strings.Fields(scope) {
+		if _, ok := oauthScopeLevels[s]; ok && !seen[s] {
+			scopes = append(scopes, s)
+			seen[s] = true
+		}
+	}
+
+	if len(scopes) == 0 {
+		return DEFAULT_SCOPE
+	}
+
+	return strings.Join(scopes, " ")
+}
+
+// OAuthScopeAllows returns true if the granted scopes include the required one.
+func OAuthScopeAllows(granted string, required string) bool {
+	level := 0
+	for _, s := range strings.Fields(granted) {
+		if oauthScopeLevels[s] > level {
+			level = oauthScopeLevels[s]
+		}
+	}
+
+	return level >= oauthScopeLevels[required]
+}
+
+// Apps authorized before scopes were recorded have "true" saved instead of
+// the scopes they were granted.
+const oauthLegacyAuthorizedValue = "true"
+
+// OAuthScopeIncludes returns true if the granted scopes allow every one of
+// the requested scopes. Apps authorized before scopes were recorded are
+// treated as having been granted DEFAULT_SCOPE, like their sessions are.
+func OAuthScopeIncludes(granted string, requested string) bool {
+	if granted == oauthLegacyAuthorizedValue {
+		granted = DEFAULT_SCOPE
+	}
+
+	for _, s := range strings.Fields(requested) {
+		if !OAuthScopeAllows(granted, s) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"testing"
+)
+
+func TestNormalizeOAuthScope(t *testing.T) {
+	if scope := NormalizeOAuthScope(""); scope != DEFAULT_SCOPE {
+		t.Fatal("should default to the user scope", scope)
+	}
+
+	if scope := NormalizeOAuthScope("all junk"); scope != DEFAULT_SCOPE {
+		t.Fatal("should ignore unknown scopes", scope)
+	}
+
+	if scope := NormalizeOAuthScope(" read  junk post read "); scope != "read post" {
+		t.Fatal("should keep known scopes once", scope)
+	}
+}
+
+func TestOAuthScopeAllows(t *testing.T) {
+	if !OAuthScopeAllows(OAUTH_SCOPE_READ, OAUTH_SCOPE_READ) {
+		t.Fatal("read should allow read")
+	}
+
+	if OAuthScopeAllows(OAUTH_SCOPE_READ, OAUTH_SCOPE_POST) {
+		t.Fatal("read shouldn't allow post")
+	}
+
+	if !OAuthScopeAllows(OAUTH_SCOPE_POST, OAUTH_SCOPE_READ) {
+		t.Fatal("post should allow read")
+	}
+
+	if !OAuthScopeAllows(OAUTH_SCOPE_USER, OAUTH_SCOPE_POST) || OAuthScopeAllows(OAUTH_SCOPE_USER, OAUTH_SCOPE_ADMIN) {
+		t.Fatal("user should allow post but not admin")
+	}
+
+	if !OAuthScopeAllows("read admin", OAUTH_SCOPE_USER) {
+		t.Fatal("the widest scope should count")
+	}
+
+	if OAuthScopeAllows("", OAUTH_SCOPE_READ) || OAuthScopeAllows("junk", OAUTH_SCOPE_READ) {
+		t.Fatal("unknown scopes shouldn't allow anything")
+	}
+}
+
+func TestOAuthScopeIncludes(t *testing.T) {
+	if !OAuthScopeIncludes(OAUTH_SCOPE_USER, "read post") {
+		t.Fatal("user should include read and post")
+	}
+
+	if OAuthScopeIncludes(OAUTH_SCOPE_POST, "read user") {
+		t.Fatal("post shouldn't include user")
+	}
+
+	if !OAuthScopeIncludes("true", DEFAULT_SCOPE) || !OAuthScopeIncludes("true", "read post") {
+		t.Fatal("apps authorized before scopes should keep the default scope")
+	}
+
+	if OAuthScopeIncludes("true", OAUTH_SCOPE_ADMIN) {
+		t.Fatal("apps authorized before scopes shouldn't get more than the default scope")
+	}
+}
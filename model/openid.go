@@ -0,0 +1,80 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+)
+
+const (
+	USER_AUTH_SERVICE_OPENID = "openid"
+)
+
+// OpenIdConfiguration is the subset of an issuer's discovery document
+// (/.well-known/openid-configuration) that the server relies on.
+type OpenIdConfiguration struct {
+	Issuer                           string   `json:"issuer"`
+	AuthorizationEndpoint            string   `json:"authorization_endpoint"`
+	TokenEndpoint                    string   `json:"token_endpoint"`
+	UserinfoEndpoint                 string   `json:"userinfo_endpoint"`
+	JwksUri                          string   `json:"jwks_uri"`
+	IdTokenSigningAlgValuesSupported []string `json:"id_token_signing_alg_values_supported"`
+}
+
+// OpenIdUser holds the claims of a verified ID token after they have been
+// mapped using the configured claim names. IsAdmin is only set when admin
+// groups are configured, so that roles are left alone otherwise.
+type OpenIdUser struct {
+	Subject   string   `json:"sub"`
+	Email     string   `json:"email"`
+	Username  string   `json:"username"`
+	FirstName string   `json:"first_name"`
+	LastName  string   `json:"last_name"`
+	Groups    []string `json:"groups,omitempty"`
+	IsAdmin   *bool    `json:"is_admin,omitempty"`
+}
+
+func (o *OpenIdConfiguration) IsValid() bool {
+	if len(o.Issuer) == 0 || !IsValidHttpUrl(o.Issuer) {
+		return false
+	}
+
+	if !IsValidHttpUrl(o.AuthorizationEndpoint) || !IsValidHttpUrl(o.TokenEndpoint) || !IsValidHttpUrl(o.JwksUri) {
+		return false
+	}
+
+	return true
+}
+
+func OpenIdConfigurationFromJson(data io.Reader) *OpenIdConfiguration {
+	decoder := json.NewDecoder(data)
+	var o OpenIdConfiguration
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func (o *OpenIdUser) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func OpenIdUserFromJson(data io.Reader) *OpenIdUser {
+	decoder := json.NewDecoder(data)
+	var o OpenIdUser
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
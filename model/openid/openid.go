@@ -0,0 +1,82 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package oauthopenid
+
+import (
+	"io"
+	"strings"
+
+	"github.com/mattermost/platform/einterfaces"
+	"github.com/mattermost/platform/model"
+)
+
+// OpenIdProvider maps the claims of an already verified ID token to a user.
+// Signature and claim validation happen in the app layer before the claims
+// reach the provider.
+type OpenIdProvider struct {
+}
+
+func init() {
+	provider := &OpenIdProvider{}
+	einterfaces.RegisterOauthProvider(model.USER_AUTH_SERVICE_OPENID, provider)
+}
+
+func userFromOpenIdUser(oiu *model.OpenIdUser) *model.User {
+	user := &model.User{}
+	username := oiu.Username
+	if username == "" {
+		username = strings.Split(oiu.Email, "@")[0]
+	}
+	user.Username = model.CleanUsername(username)
+	user.FirstName = oiu.FirstName
+	user.LastName = oiu.LastName
+	user.Email = strings.ToLower(strings.TrimSpace(oiu.Email))
+	authData := oiu.Subject
+	user.AuthData = &authData
+	user.AuthService = model.USER_AUTH_SERVICE_OPENID
+
+	if oiu.IsAdmin != nil {
+		if *oiu.IsAdmin {
+			user.Roles = model.ROLE_SYSTEM_ADMIN.Id + " " + model.ROLE_SYSTEM_USER.Id
+		} else {
+			user.Roles = model.ROLE_SYSTEM_USER.Id
+		}
+	}
+
+	return user
+}
+
+func isValidOpenIdUser(oiu *model.OpenIdUser) bool {
+	if oiu == nil {
+		return false
+	}
+
+	if len(oiu.Subject) == 0 || len(oiu.Email) == 0 {
+		return false
+	}
+
+	return true
+}
+
+func (m *OpenIdProvider) GetIdentifier() string {
+	return model.USER_AUTH_SERVICE_OPENID
+}
+
+func (m *OpenIdProvider) GetUserFromJson(data io.Reader) *model.User {
+	oiu := model.OpenIdUserFromJson(data)
+	if isValidOpenIdUser(oiu) {
+		return userFromOpenIdUser(oiu)
+	}
+
+	return &model.User{}
+}
+
+func (m *OpenIdProvider) GetAuthDataFromJson(data io.Reader) string {
+	oiu := model.OpenIdUserFromJson(data)
+	if isValidOpenIdUser(oiu) {
+		return oiu.Subject
+	}
+
+	return ""
+}
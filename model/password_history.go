@@ -0,0 +1,11 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+// PasswordHistory stores the hash of a password that a user has had so that it can't be reused.
+type PasswordHistory struct {
+	UserId       string `json:"user_id"`
+	PasswordHash string `json:"password_hash"`
+	CreateAt     int64  `json:"create_at"`
+}
@@ -0,0 +1,206 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	POST_PROPS_POLL       = "poll"
+	POLL_OPTIONS_MIN      = 2
+	POLL_OPTIONS_MAX      = 20
+	POLL_OPTION_MAX_RUNES = 200
+)
+
+// Poll is kept in the props of a poll post, whose message is the question being asked. The vote counts are kept up
+// to date as users vote, while the votes themselves are stored separately.
+type Poll struct {
+	Options        []*PollOption `json:"options"`
+	MultipleChoice bool          `json:"multiple_choice"`
+	Anonymous      bool          `json:"anonymous"`
+	CloseAt        int64         `json:"close_at"`
+	VoterCount     int64         `json:"voter_count"`
+}
+
+type PollOption struct {
+	Id        string `json:"id"`
+	Text      string `json:"text"`
+	VoteCount int64  `json:"vote_count"`
+}
+
+type PollVote struct {
+	PostId   string `json:"post_id"`
+	UserId   string `json:"user_id"`
+	OptionId string `json:"option_id"`
+	CreateAt int64  `json:"create_at"`
+}
+
+// PollFromPost returns the poll of a poll post, or nil if it doesn't have one.
+func PollFromPost(post *Post) *Poll {
+	if post.Type != POST_POLL || post.Props == nil {
+		return nil
+	}
+
+	value, ok := post.Props[POST_PROPS_POLL]
+	if !ok {
+		return nil
+	}
+
+	// the props will only contain a Poll until they've been serialized
+	if poll, ok := value.(*Poll); ok {
+		return poll
+	}
+
+	b, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+
+	var poll Poll
+	if err := json.Unmarshal(b, &poll); err != nil {
+		return nil
+	}
+
+	return &poll
+}
+
+func (o *Poll) IsValid() *AppError {
+	if len(o.Options) < POLL_OPTIONS_MIN || len(o.Options) > POLL_OPTIONS_MAX {
+		return NewAppError("Poll.IsValid", "model.poll.is_valid.options.app_error", map[string]interface{}{"Min": POLL_OPTIONS_MIN, "Max": POLL_OPTIONS_MAX}, "", http.StatusBadRequest)
+	}
+
+	ids := make(map[string]bool)
+	for _, option := range o.Options {
+		if len(option.Id) != 26 || ids[option.Id] {
+			return NewAppError("Poll.IsValid", "model.poll.is_valid.option_id.app_error", nil, "id="+option.Id, http.StatusBadRequest)
+		}
+		ids[option.Id] = true
+
+		if len(strings.TrimSpace(option.Text)) == 0 || utf8.RuneCountInString(option.Text) > POLL_OPTION_MAX_RUNES {
+			return NewAppError("Poll.IsValid", "model.poll.is_valid.option_text.app_error", map[string]interface{}{"Max": POLL_OPTION_MAX_RUNES}, "", http.StatusBadRequest)
+		}
+
+		if option.VoteCount < 0 {
+			return NewAppError("Poll.IsValid", "model.poll.is_valid.vote_count.app_error", nil, "id="+option.Id, http.StatusBadRequest)
+		}
+	}
+
+	if o.CloseAt < 0 {
+		return NewAppError("Poll.IsValid", "model.poll.is_valid.close_at.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+// PreSave gives each of the options an id and clears any votes so that a new poll starts without any.
+func (o *Poll) PreSave() {
+	for _, option := range o.Options {
+		option.Id = NewId()
+		option.Text = strings.TrimSpace(option.Text)
+		option.VoteCount = 0
+	}
+
+	o.VoterCount = 0
+}
+
+func (o *Poll) IsClosed(now int64) bool {
+	return o.CloseAt != 0 && o.CloseAt <= now
+}
+
+func (o *Poll) HasOption(optionId string) bool {
+	for _, option := range o.Options {
+		if option.Id == optionId {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SetVotes updates the vote counts of the poll to match the given votes.
+func (o *Poll) SetVotes(votes []*PollVote) {
+	counts := make(map[string]int64)
+	voters := make(map[string]bool)
+	for _, vote := range votes {
+		counts[vote.OptionId]++
+		voters[vote.UserId] = true
+	}
+
+	for _, option := range o.Options {
+		option.VoteCount = counts[option.Id]
+	}
+
+	o.VoterCount = int64(len(voters))
+}
+
+func (o *Poll) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func PollFromJson(data io.Reader) *Poll {
+	decoder := json.NewDecoder(data)
+	var o Poll
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func (o *PollVote) IsValid() *AppError {
+	if len(o.PostId) != 26 {
+		return NewAppError("PollVote.IsValid", "model.poll_vote.is_valid.post_id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if len(o.UserId) != 26 {
+		return NewAppError("PollVote.IsValid", "model.poll_vote.is_valid.user_id.app_error", nil, "post_id="+o.PostId, http.StatusBadRequest)
+	}
+
+	if len(o.OptionId) != 26 {
+		return NewAppError("PollVote.IsValid", "model.poll_vote.is_valid.option_id.app_error", nil, "post_id="+o.PostId, http.StatusBadRequest)
+	}
+
+	if o.CreateAt == 0 {
+		return NewAppError("PollVote.IsValid", "model.poll_vote.is_valid.create_at.app_error", nil, "post_id="+o.PostId, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func (o *PollVote) PreSave() {
+	if o.CreateAt == 0 {
+		o.CreateAt = GetMillis()
+	}
+}
+
+func PollVotesToJson(l []*PollVote) string {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func PollVotesFromJson(data io.Reader) []*PollVote {
+	decoder := json.NewDecoder(data)
+	var l []*PollVote
+	err := decoder.Decode(&l)
+	if err == nil {
+		return l
+	} else {
+		return nil
+	}
+}
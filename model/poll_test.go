@@ -0,0 +1,125 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPollFromPost(t *testing.T) {
+	poll := &Poll{Options: []*PollOption{{Text: "a"}, {Text: "b"}}, Anonymous: true}
+
+	post := &Post{Type: POST_POLL}
+	post.AddProp(POST_PROPS_POLL, poll)
+
+	if PollFromPost(post) != poll {
+		t.Fatal("should have returned the poll")
+	}
+
+	rpost := PostFromJson(strings.NewReader(post.ToJson()))
+	if rpoll := PollFromPost(rpost); rpoll == nil {
+		t.Fatal("should have read the poll")
+	} else if len(rpoll.Options) != 2 || rpoll.Options[1].Text != "b" || !rpoll.Anonymous {
+		t.Fatal("read the wrong poll")
+	}
+
+	rpost.Type = POST_DEFAULT
+	if PollFromPost(rpost) != nil {
+		t.Fatal("shouldn't have returned a poll for other posts")
+	}
+
+	if PollFromPost(&Post{Type: POST_POLL}) != nil {
+		t.Fatal("shouldn't have returned a poll for a post without one")
+	}
+}
+
+func TestPollIsValid(t *testing.T) {
+	poll := &Poll{Options: []*PollOption{{Text: " a "}}}
+	poll.PreSave()
+
+	if err := poll.IsValid(); err == nil {
+		t.Fatal("should be invalid with one option")
+	}
+
+	poll.Options = append(poll.Options, &PollOption{Text: "b", VoteCount: 3})
+	poll.VoterCount = 3
+	poll.PreSave()
+
+	if err := poll.IsValid(); err != nil {
+		t.Fatal(err)
+	} else if poll.Options[0].Text != "a" || poll.Options[1].VoteCount != 0 || poll.VoterCount != 0 {
+		t.Fatal("should have started the poll without votes")
+	} else if poll.Options[0].Id == poll.Options[1].Id {
+		t.Fatal("should have given each option an id")
+	}
+
+	poll.Options[1].Id = poll.Options[0].Id
+	if err := poll.IsValid(); err == nil {
+		t.Fatal("should be invalid with duplicate options")
+	}
+
+	poll.Options[1].Id = NewId()
+	poll.Options[1].Text = strings.Repeat("a", POLL_OPTION_MAX_RUNES+1)
+	if err := poll.IsValid(); err == nil {
+		t.Fatal("should be invalid with a long option")
+	}
+
+	poll.Options[1].Text = " "
+	if err := poll.IsValid(); err == nil {
+		t.Fatal("should be invalid with an empty option")
+	}
+}
+
+func TestPollSetVotes(t *testing.T) {
+	poll := &Poll{Options: []*PollOption{{Text: "a"}, {Text: "b"}, {Text: "c"}}}
+	poll.PreSave()
+
+	userId1 := NewId()
+	userId2 := NewId()
+	poll.SetVotes([]*PollVote{
+		{UserId: userId1, OptionId: poll.Options[0].Id},
+		{UserId: userId1, OptionId: poll.Options[1].Id},
+		{UserId: userId2, OptionId: poll.Options[1].Id},
+	})
+
+	if poll.Options[0].VoteCount != 1 || poll.Options[1].VoteCount != 2 || poll.Options[2].VoteCount != 0 {
+		t.Fatal("counted the wrong votes")
+	} else if poll.VoterCount != 2 {
+		t.Fatal("counted the wrong voters")
+	}
+
+	if !poll.HasOption(poll.Options[2].Id) || poll.HasOption(NewId()) {
+		t.Fatal("didn't find the right options")
+	}
+
+	if poll.IsClosed(GetMillis()) {
+		t.Fatal("polls without a close time shouldn't close")
+	}
+
+	poll.CloseAt = GetMillis()
+	if !poll.IsClosed(poll.CloseAt) || poll.IsClosed(poll.CloseAt-1) {
+		t.Fatal("should have closed at the close time")
+	}
+}
+
+func TestPollVoteIsValid(t *testing.T) {
+	o := PollVote{}
+
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.PostId = NewId()
+	o.UserId = NewId()
+	o.OptionId = NewId()
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid without a time")
+	}
+
+	o.PreSave()
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+}
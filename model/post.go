@@ -13,6 +13,7 @@ const (
 	POST_SYSTEM_MESSAGE_PREFIX = "system_"
 	POST_DEFAULT               = ""
 	POST_SLACK_ATTACHMENT      = "slack_attachment"
+	POST_POLL                  = "poll"
 	POST_SYSTEM_GENERIC        = "system_generic"
 	POST_JOIN_LEAVE            = "system_join_leave" // Deprecated, use POST_JOIN_CHANNEL or POST_LEAVE_CHANNEL instead
 	POST_JOIN_CHANNEL          = "system_join_channel"
@@ -24,6 +25,11 @@ const (
 	POST_DISPLAYNAME_CHANGE    = "system_displayname_change"
 	POST_PURPOSE_CHANGE        = "system_purpose_change"
 	POST_CHANNEL_DELETED       = "system_channel_deleted"
+	POST_CHANNEL_RESTORED      = "system_channel_restored"
+	POST_CHANNEL_MOVED         = "system_channel_moved"
+	POST_CHANNEL_TYPE_CHANGE   = "system_channel_type_change"
+	POST_REMINDER              = "system_post_reminder"
+	POST_SCHEDULED_POST_FAILED = "system_scheduled_post_failed"
 	POST_EPHEMERAL             = "system_ephemeral"
 	POST_FILEIDS_MAX_RUNES     = 150
 	POST_FILENAMES_MAX_RUNES   = 4000
@@ -52,6 +58,9 @@ type Post struct {
 	FileIds       StringArray     `json:"file_ids,omitempty"`
 	PendingPostId string          `json:"pending_post_id" db:"-"`
 	HasReactions  bool            `json:"has_reactions,omitempty"`
+	ReplyCount    int64           `json:"reply_count"`
+	LastReplyAt   int64           `json:"last_reply_at"`
+	Participants  StringArray     `json:"participants"`
 }
 
 type PostPatch struct {
@@ -137,7 +146,9 @@ func (o *Post) IsValid() *AppError {
 		o.Type == POST_JOIN_CHANNEL || o.Type == POST_LEAVE_CHANNEL ||
 		o.Type == POST_REMOVE_FROM_CHANNEL || o.Type == POST_ADD_TO_CHANNEL ||
 		o.Type == POST_SLACK_ATTACHMENT || o.Type == POST_HEADER_CHANGE || o.Type == POST_PURPOSE_CHANGE ||
-		o.Type == POST_DISPLAYNAME_CHANGE || o.Type == POST_CHANNEL_DELETED) {
+		o.Type == POST_DISPLAYNAME_CHANGE || o.Type == POST_CHANNEL_DELETED || o.Type == POST_CHANNEL_RESTORED ||
+		o.Type == POST_CHANNEL_MOVED || o.Type == POST_CHANNEL_TYPE_CHANGE || o.Type == POST_REMINDER ||
+		o.Type == POST_SCHEDULED_POST_FAILED || o.Type == POST_POLL) {
 		return NewLocAppError("Post.IsValid", "model.post.is_valid.type.app_error", nil, "id="+o.Type)
 	}
 
@@ -153,6 +164,10 @@ func (o *Post) IsValid() *AppError {
 		return NewLocAppError("Post.IsValid", "model.post.is_valid.props.app_error", nil, "id="+o.Id)
 	}
 
+	if utf8.RuneCountInString(ArrayToJson(o.Participants)) > THREAD_PARTICIPANTS_MAX_RUNES {
+		return NewLocAppError("Post.IsValid", "model.post.is_valid.participants.app_error", nil, "id="+o.Id)
+	}
+
 	return nil
 }
 
@@ -163,6 +178,11 @@ func (o *Post) PreSave() {
 
 	o.OriginalId = ""
 
+	// thread statistics are only ever calculated from the replies that have been saved
+	o.ReplyCount = 0
+	o.LastReplyAt = 0
+	o.Participants = []string{}
+
 	if o.CreateAt == 0 {
 		o.CreateAt = GetMillis()
 	}
@@ -199,6 +219,24 @@ func (o *Post) IsSystemMessage() bool {
 	return len(o.Type) >= len(POST_SYSTEM_MESSAGE_PREFIX) && o.Type[:len(POST_SYSTEM_MESSAGE_PREFIX)] == POST_SYSTEM_MESSAGE_PREFIX
 }
 
+// GetPriority returns the priority that the post was sent with or an empty string if it doesn't have one.
+func (o *Post) GetPriority() string {
+	if priority, ok := o.Props[POST_PROPS_PRIORITY].(string); ok && IsValidPostPriority(priority) {
+		return priority
+	}
+
+	return ""
+}
+
+func (o *Post) IsUrgent() bool {
+	return o.GetPriority() == POST_PRIORITY_URGENT
+}
+
+func (o *Post) RequestsAcknowledgement() bool {
+	requested, _ := o.Props[POST_PROPS_REQUESTED_ACK].(bool)
+	return requested
+}
+
 func (p *Post) Patch(patch *PostPatch) {
 	if patch.IsPinned != nil {
 		p.IsPinned = *patch.IsPinned
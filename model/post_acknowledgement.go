@@ -0,0 +1,103 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+const (
+	POST_PROPS_PRIORITY      = "priority"
+	POST_PROPS_REQUESTED_ACK = "requested_ack"
+
+	POST_PRIORITY_IMPORTANT = "important"
+	POST_PRIORITY_URGENT    = "urgent"
+
+	URGENT_POST_PUSH_INTERVAL = 5 * 60 * 1000 // 5 minutes
+	URGENT_POST_MAX_PUSHES    = 6
+)
+
+// PostAcknowledgement records that a user has acknowledged a post that requested it.
+type PostAcknowledgement struct {
+	PostId         string `json:"post_id"`
+	UserId         string `json:"user_id"`
+	AcknowledgedAt int64  `json:"acknowledged_at"`
+}
+
+// UrgentPostPush keeps track of the push notifications sent to a user about an urgent post, which are repeated until
+// the user acknowledges it or enough of them have been sent.
+type UrgentPostPush struct {
+	PostId     string `json:"post_id"`
+	UserId     string `json:"user_id"`
+	PushCount  int    `json:"push_count"`
+	NextPushAt int64  `json:"next_push_at"`
+}
+
+func IsValidPostPriority(priority string) bool {
+	return priority == POST_PRIORITY_IMPORTANT || priority == POST_PRIORITY_URGENT
+}
+
+func (o *PostAcknowledgement) IsValid() *AppError {
+	if len(o.PostId) != 26 {
+		return NewAppError("PostAcknowledgement.IsValid", "model.post_acknowledgement.is_valid.post_id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if len(o.UserId) != 26 {
+		return NewAppError("PostAcknowledgement.IsValid", "model.post_acknowledgement.is_valid.user_id.app_error", nil, "post_id="+o.PostId, http.StatusBadRequest)
+	}
+
+	if o.AcknowledgedAt == 0 {
+		return NewAppError("PostAcknowledgement.IsValid", "model.post_acknowledgement.is_valid.acknowledged_at.app_error", nil, "post_id="+o.PostId, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func (o *PostAcknowledgement) PreSave() {
+	if o.AcknowledgedAt == 0 {
+		o.AcknowledgedAt = GetMillis()
+	}
+}
+
+func (o *PostAcknowledgement) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func PostAcknowledgementFromJson(data io.Reader) *PostAcknowledgement {
+	decoder := json.NewDecoder(data)
+	var o PostAcknowledgement
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func PostAcknowledgementsToJson(l []*PostAcknowledgement) string {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func PostAcknowledgementsFromJson(data io.Reader) []*PostAcknowledgement {
+	decoder := json.NewDecoder(data)
+	var l []*PostAcknowledgement
+	err := decoder.Decode(&l)
+	if err == nil {
+		return l
+	} else {
+		return nil
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPostPriority(t *testing.T) {
+	post := &Post{}
+	if post.GetPriority() != "" || post.IsUrgent() || post.RequestsAcknowledgement() {
+		t.Fatal("shouldn't have a priority")
+	}
+
+	post.AddProp(POST_PROPS_PRIORITY, "junk")
+	post.AddProp(POST_PROPS_REQUESTED_ACK, "true")
+	if post.GetPriority() != "" || post.RequestsAcknowledgement() {
+		t.Fatal("should have ignored invalid props")
+	}
+
+	post.AddProp(POST_PROPS_PRIORITY, POST_PRIORITY_URGENT)
+	post.AddProp(POST_PROPS_REQUESTED_ACK, true)
+	post = PostFromJson(strings.NewReader(post.ToJson()))
+	if !post.IsUrgent() || !post.RequestsAcknowledgement() {
+		t.Fatal("should be urgent and request acknowledgements")
+	}
+}
+
+func TestPostAcknowledgementIsValid(t *testing.T) {
+	ack := &PostAcknowledgement{PostId: NewId(), UserId: NewId()}
+	if ack.IsValid() == nil {
+		t.Fatal("should be invalid without a time")
+	}
+
+	ack.PreSave()
+	if err := ack.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	ack.UserId = "junk"
+	if ack.IsValid() == nil {
+		t.Fatal("should be invalid")
+	}
+}
+
+func TestPostAcknowledgementJson(t *testing.T) {
+	ack := &PostAcknowledgement{PostId: NewId(), UserId: NewId(), AcknowledgedAt: 1}
+	if decoded := PostAcknowledgementFromJson(strings.NewReader(ack.ToJson())); *decoded != *ack {
+		t.Fatal("should have decoded the acknowledgement")
+	}
+
+	if decoded := PostAcknowledgementsFromJson(strings.NewReader(PostAcknowledgementsToJson([]*PostAcknowledgement{ack}))); len(decoded) != 1 || *decoded[0] != *ack {
+		t.Fatal("should have decoded the acknowledgements")
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+)
+
+const (
+	POST_PROPS_PERMALINK_EMBED        = "permalink_embed"
+	PERMALINK_EMBED_MAX_MESSAGE_RUNES = 1000
+)
+
+// PermalinkEmbed is kept in the props of a post that links to another post, so that clients can show what the linked
+// post says. Only the id of the linked post is stored and the rest is filled in for each user viewing the post, so it
+// only includes the linked post if that user can read it and is marked as unavailable otherwise.
+type PermalinkEmbed struct {
+	PostId      string      `json:"post_id"`
+	Unavailable bool        `json:"unavailable,omitempty"`
+	ChannelId   string      `json:"channel_id,omitempty"`
+	UserId      string      `json:"user_id,omitempty"`
+	Message     string      `json:"message,omitempty"`
+	FileIds     StringArray `json:"file_ids,omitempty"`
+	CreateAt    int64       `json:"create_at,omitempty"`
+	EditAt      int64       `json:"edit_at,omitempty"`
+}
+
+// NewPermalinkEmbed returns an embed of a post, shortening its message if needed to keep the props of the post
+// embedding it from getting too large.
+func NewPermalinkEmbed(post *Post) *PermalinkEmbed {
+	message := post.Message
+	if runes := []rune(message); len(runes) > PERMALINK_EMBED_MAX_MESSAGE_RUNES {
+		message = string(runes[:PERMALINK_EMBED_MAX_MESSAGE_RUNES])
+	}
+
+	return &PermalinkEmbed{
+		PostId:    post.Id,
+		ChannelId: post.ChannelId,
+		UserId:    post.UserId,
+		Message:   message,
+		FileIds:   post.FileIds,
+		CreateAt:  post.CreateAt,
+		EditAt:    post.EditAt,
+	}
+}
+
+// PermalinkEmbedFromPost returns the embed of the post linked to by a post, or nil if it doesn't have one.
+func PermalinkEmbedFromPost(post *Post) *PermalinkEmbed {
+	value, ok := post.Props[POST_PROPS_PERMALINK_EMBED]
+	if !ok {
+		return nil
+	}
+
+	// the props will only contain a PermalinkEmbed until they've been serialized
+	if embed, ok := value.(*PermalinkEmbed); ok {
+		return embed
+	}
+
+	b, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+
+	var embed PermalinkEmbed
+	if err := json.Unmarshal(b, &embed); err != nil {
+		return nil
+	}
+
+	return &embed
+}
@@ -0,0 +1,39 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewPermalinkEmbed(t *testing.T) {
+	post := &Post{Id: NewId(), ChannelId: NewId(), UserId: NewId(), Message: strings.Repeat("ä", PERMALINK_EMBED_MAX_MESSAGE_RUNES+1), CreateAt: 1}
+
+	embed := NewPermalinkEmbed(post)
+	if embed.PostId != post.Id || embed.ChannelId != post.ChannelId || embed.UserId != post.UserId || embed.CreateAt != 1 {
+		t.Fatal("should've embedded the post")
+	} else if embed.Message != strings.Repeat("ä", PERMALINK_EMBED_MAX_MESSAGE_RUNES) {
+		t.Fatal("should've shortened the message")
+	}
+}
+
+func TestPermalinkEmbedFromPost(t *testing.T) {
+	post := &Post{}
+	if PermalinkEmbedFromPost(post) != nil {
+		t.Fatal("shouldn't have an embed")
+	}
+
+	embed := &PermalinkEmbed{PostId: NewId(), Message: "hello"}
+	post.AddProp(POST_PROPS_PERMALINK_EMBED, embed)
+
+	if PermalinkEmbedFromPost(post) != embed {
+		t.Fatal("should've returned the embed")
+	}
+
+	post = PostFromJson(strings.NewReader(post.ToJson()))
+	if decoded := PermalinkEmbedFromPost(post); decoded == nil || decoded.PostId != embed.PostId || decoded.Message != "hello" {
+		t.Fatal("should've decoded the embed")
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+// PostReminder asks for a user to be reminded about a post at a later time.
+type PostReminder struct {
+	Id       string `json:"id"`
+	CreateAt int64  `json:"create_at"`
+	UserId   string `json:"user_id"`
+	PostId   string `json:"post_id"`
+	RemindAt int64  `json:"remind_at"`
+}
+
+func (o *PostReminder) IsValid() *AppError {
+	if len(o.Id) != 26 {
+		return NewAppError("PostReminder.IsValid", "model.post_reminder.is_valid.id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if o.CreateAt == 0 {
+		return NewAppError("PostReminder.IsValid", "model.post_reminder.is_valid.create_at.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if len(o.UserId) != 26 {
+		return NewAppError("PostReminder.IsValid", "model.post_reminder.is_valid.user_id.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if len(o.PostId) != 26 {
+		return NewAppError("PostReminder.IsValid", "model.post_reminder.is_valid.post_id.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if o.RemindAt == 0 {
+		return NewAppError("PostReminder.IsValid", "model.post_reminder.is_valid.remind_at.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func (o *PostReminder) PreSave() {
+	if o.Id == "" {
+		o.Id = NewId()
+	}
+
+	o.CreateAt = GetMillis()
+}
+
+func (o *PostReminder) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func PostReminderFromJson(data io.Reader) *PostReminder {
+	decoder := json.NewDecoder(data)
+	var o PostReminder
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func PostReminderListToJson(l []*PostReminder) string {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func PostReminderListFromJson(data io.Reader) []*PostReminder {
+	decoder := json.NewDecoder(data)
+	var l []*PostReminder
+	err := decoder.Decode(&l)
+	if err == nil {
+		return l
+	} else {
+		return nil
+	}
+}
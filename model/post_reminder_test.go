@@ -0,0 +1,43 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPostReminderJson(t *testing.T) {
+	o := PostReminder{Id: NewId(), UserId: NewId(), PostId: NewId(), RemindAt: GetMillis()}
+	json := o.ToJson()
+	ro := PostReminderFromJson(strings.NewReader(json))
+
+	if o.Id != ro.Id || o.PostId != ro.PostId || o.RemindAt != ro.RemindAt {
+		t.Fatal("reminders do not match")
+	}
+}
+
+func TestPostReminderIsValid(t *testing.T) {
+	o := PostReminder{}
+
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.PreSave()
+	o.UserId = NewId()
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.PostId = NewId()
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid without a time")
+	}
+
+	o.RemindAt = GetMillis()
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+}
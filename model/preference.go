@@ -38,6 +38,9 @@ const (
 	PREFERENCE_CATEGORY_NOTIFICATIONS = "notifications"
 	PREFERENCE_NAME_EMAIL_INTERVAL    = "email_interval"
 	PREFERENCE_DEFAULT_EMAIL_INTERVAL = "30" // default to match the interval of the "immediate" setting (ie 30 seconds)
+
+	PREFERENCE_CATEGORY_PRIVACY        = "privacy"
+	PREFERENCE_NAME_SEND_READ_RECEIPTS = "send_read_receipts" // set to "false" to stop others from seeing when the user has read their messages
 )
 
 type Preference struct {
@@ -0,0 +1,153 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+const (
+	PRIVACY_REQUEST_TYPE_EXPORT = "export"
+	PRIVACY_REQUEST_TYPE_DELETE = "delete"
+
+	PRIVACY_REQUEST_STATUS_PENDING  = "pending"
+	PRIVACY_REQUEST_STATUS_FINISHED = "finished"
+	PRIVACY_REQUEST_STATUS_FAILED   = "failed"
+	PRIVACY_REQUEST_STATUS_CANCELED = "canceled"
+
+	PRIVACY_REQUEST_ERROR_CODE_MAX_LENGTH = 128
+
+	// a request that is still pending this long after being claimed was left behind by a server that stopped while
+	// processing it, so it can be claimed again
+	PRIVACY_REQUEST_CLAIM_TIMEOUT = 6 * 60 * 60 * 1000
+)
+
+// PrivacyRequest is a request to export a user's data or to delete their account. It's processed once ScheduledAt
+// has passed, which for deletions leaves a cooling-off period during which the request can be canceled.
+type PrivacyRequest struct {
+	Id          string `json:"id"`
+	CreateAt    int64  `json:"create_at"`
+	UpdateAt    int64  `json:"update_at"`
+	UserId      string `json:"user_id"`
+	RequesterId string `json:"requester_id"`
+	Type        string `json:"type"`
+	Anonymize   bool   `json:"anonymize"`
+	Status      string `json:"status"`
+	ScheduledAt int64  `json:"scheduled_at"`
+	ProcessedAt int64  `json:"processed_at"`
+	ErrorCode   string `json:"error_code"`
+}
+
+func (o *PrivacyRequest) IsValid() *AppError {
+	if len(o.Id) != 26 {
+		return NewAppError("PrivacyRequest.IsValid", "model.privacy_request.is_valid.id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if o.CreateAt == 0 {
+		return NewAppError("PrivacyRequest.IsValid", "model.privacy_request.is_valid.create_at.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if o.UpdateAt == 0 {
+		return NewAppError("PrivacyRequest.IsValid", "model.privacy_request.is_valid.update_at.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if len(o.UserId) != 26 {
+		return NewAppError("PrivacyRequest.IsValid", "model.privacy_request.is_valid.user_id.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if len(o.RequesterId) != 26 {
+		return NewAppError("PrivacyRequest.IsValid", "model.privacy_request.is_valid.requester_id.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if o.Type != PRIVACY_REQUEST_TYPE_EXPORT && o.Type != PRIVACY_REQUEST_TYPE_DELETE {
+		return NewAppError("PrivacyRequest.IsValid", "model.privacy_request.is_valid.type.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if o.Anonymize && o.Type != PRIVACY_REQUEST_TYPE_DELETE {
+		return NewAppError("PrivacyRequest.IsValid", "model.privacy_request.is_valid.anonymize.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	switch o.Status {
+	case PRIVACY_REQUEST_STATUS_PENDING, PRIVACY_REQUEST_STATUS_FINISHED, PRIVACY_REQUEST_STATUS_FAILED, PRIVACY_REQUEST_STATUS_CANCELED:
+	default:
+		return NewAppError("PrivacyRequest.IsValid", "model.privacy_request.is_valid.status.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if o.ScheduledAt == 0 {
+		return NewAppError("PrivacyRequest.IsValid", "model.privacy_request.is_valid.scheduled_at.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if len(o.ErrorCode) > PRIVACY_REQUEST_ERROR_CODE_MAX_LENGTH {
+		return NewAppError("PrivacyRequest.IsValid", "model.privacy_request.is_valid.error_code.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func (o *PrivacyRequest) PreSave() {
+	if o.Id == "" {
+		o.Id = NewId()
+	}
+
+	o.CreateAt = GetMillis()
+	o.UpdateAt = o.CreateAt
+	o.Status = PRIVACY_REQUEST_STATUS_PENDING
+	o.ProcessedAt = 0
+	o.ErrorCode = ""
+
+	if o.ScheduledAt == 0 {
+		o.ScheduledAt = o.CreateAt
+	}
+}
+
+func (o *PrivacyRequest) PreUpdate() {
+	o.UpdateAt = GetMillis()
+}
+
+// ExportPath returns where the zip file built for an export request is stored.
+func (o *PrivacyRequest) ExportPath() string {
+	return "privacy/exports/" + o.UserId + "/" + o.Id + ".zip"
+}
+
+func (o *PrivacyRequest) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func PrivacyRequestFromJson(data io.Reader) *PrivacyRequest {
+	decoder := json.NewDecoder(data)
+	var o PrivacyRequest
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func PrivacyRequestListToJson(l []*PrivacyRequest) string {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func PrivacyRequestListFromJson(data io.Reader) []*PrivacyRequest {
+	decoder := json.NewDecoder(data)
+	var l []*PrivacyRequest
+	err := decoder.Decode(&l)
+	if err == nil {
+		return l
+	} else {
+		return nil
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPrivacyRequestJson(t *testing.T) {
+	o := PrivacyRequest{Id: NewId(), UserId: NewId(), Type: PRIVACY_REQUEST_TYPE_DELETE, Anonymize: true, ScheduledAt: GetMillis()}
+	json := o.ToJson()
+	ro := PrivacyRequestFromJson(strings.NewReader(json))
+
+	if o.Id != ro.Id || o.Type != ro.Type || o.Anonymize != ro.Anonymize || o.ScheduledAt != ro.ScheduledAt {
+		t.Fatal("privacy requests do not match")
+	}
+
+	l := PrivacyRequestListFromJson(strings.NewReader(PrivacyRequestListToJson([]*PrivacyRequest{&o})))
+	if len(l) != 1 || l[0].Id != o.Id {
+		t.Fatal("privacy request lists do not match")
+	}
+}
+
+func TestPrivacyRequestIsValid(t *testing.T) {
+	o := PrivacyRequest{}
+
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.UserId = NewId()
+	o.RequesterId = o.UserId
+	o.Type = PRIVACY_REQUEST_TYPE_EXPORT
+	o.PreSave()
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	if o.Status != PRIVACY_REQUEST_STATUS_PENDING || o.ScheduledAt != o.CreateAt {
+		t.Fatal("should be pending and due now")
+	}
+
+	o.Anonymize = true
+	if err := o.IsValid(); err == nil {
+		t.Fatal("exports can't be anonymized")
+	}
+
+	o.Type = PRIVACY_REQUEST_TYPE_DELETE
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.Type = "junk"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid with an unknown type")
+	}
+
+	o.Type = PRIVACY_REQUEST_TYPE_DELETE
+	o.Status = "junk"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid with an unknown status")
+	}
+
+	o.Status = PRIVACY_REQUEST_STATUS_CANCELED
+	o.ErrorCode = strings.Repeat("a", PRIVACY_REQUEST_ERROR_CODE_MAX_LENGTH+1)
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid with a long error code")
+	}
+}
+
+func TestPrivacyRequestExportPath(t *testing.T) {
+	o := PrivacyRequest{Id: NewId(), UserId: NewId()}
+
+	if path := o.ExportPath(); path != "privacy/exports/"+o.UserId+"/"+o.Id+".zip" {
+		t.Fatal("wrong export path", path)
+	}
+}
@@ -0,0 +1,33 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// ReadReceipts maps the ids of posts in a direct or group channel to the ids of the members who have seen them, not
+// including their authors or members who don't send read receipts.
+type ReadReceipts map[string][]string
+
+func (o ReadReceipts) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func ReadReceiptsFromJson(data io.Reader) ReadReceipts {
+	decoder := json.NewDecoder(data)
+	var o ReadReceipts
+	err := decoder.Decode(&o)
+	if err == nil {
+		return o
+	} else {
+		return nil
+	}
+}
@@ -0,0 +1,21 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadReceiptsJson(t *testing.T) {
+	postId := NewId()
+	userId := NewId()
+
+	receipts := ReadReceipts{postId: []string{userId}}
+	decoded := ReadReceiptsFromJson(strings.NewReader(receipts.ToJson()))
+
+	if len(decoded) != 1 || len(decoded[postId]) != 1 || decoded[postId][0] != userId {
+		t.Fatal("should have decoded the read receipts")
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScheduledPostJson(t *testing.T) {
+	o := ScheduledPost{Id: NewId(), UserId: NewId(), ChannelId: NewId(), Message: "hello", ScheduledAt: GetMillis()}
+	json := o.ToJson()
+	ro := ScheduledPostFromJson(strings.NewReader(json))
+
+	if o.Id != ro.Id || o.Message != ro.Message || o.ScheduledAt != ro.ScheduledAt {
+		t.Fatal("scheduled posts do not match")
+	}
+
+	l := ScheduledPostListFromJson(strings.NewReader(ScheduledPostListToJson([]*ScheduledPost{&o})))
+	if len(l) != 1 || l[0].Id != o.Id {
+		t.Fatal("scheduled post lists do not match")
+	}
+}
+
+func TestScheduledPostIsValid(t *testing.T) {
+	o := ScheduledPost{}
+
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.Id = NewId()
+	o.CreateAt = GetMillis()
+	o.UpdateAt = o.CreateAt
+	o.UserId = NewId()
+	o.ChannelId = NewId()
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid without a message")
+	}
+
+	o.Message = "hello"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid without a time")
+	}
+
+	o.ScheduledAt = GetMillis()
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.RootId = "123"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.RootId = NewId()
+	o.ErrorCode = strings.Repeat("a", SCHEDULED_POST_ERROR_CODE_MAX_LENGTH+1)
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+}
+
+func TestScheduledPostPreSave(t *testing.T) {
+	o := ScheduledPost{ProcessedAt: 1, ErrorCode: "error"}
+	o.PreSave()
+
+	if o.Id == "" || o.CreateAt == 0 || o.UpdateAt != o.CreateAt {
+		t.Fatal("should have set the id and times")
+	}
+
+	if o.ProcessedAt != 0 || o.ErrorCode != "" {
+		t.Fatal("should have cleared the result of processing it")
+	}
+}
+
+func TestScheduledPostToPost(t *testing.T) {
+	o := ScheduledPost{UserId: NewId(), ChannelId: NewId(), RootId: NewId(), Message: "hello"}
+	post := o.ToPost()
+
+	if post.UserId != o.UserId || post.ChannelId != o.ChannelId || post.Message != o.Message {
+		t.Fatal("created the wrong post")
+	}
+
+	if post.RootId != o.RootId || post.ParentId != o.RootId {
+		t.Fatal("should have replied to the root post")
+	}
+}
@@ -11,10 +11,16 @@ import (
 
 const (
 	SESSION_COOKIE_TOKEN  = "MMAUTHTOKEN"
+	SESSION_COOKIE_CSRF   = "MMCSRF"
 	SESSION_CACHE_SIZE    = 35000
 	SESSION_PROP_PLATFORM = "platform"
 	SESSION_PROP_OS       = "os"
 	SESSION_PROP_BROWSER  = "browser"
+
+	SESSION_PROP_OAUTH_SCOPE = "oauth_scope"
+	SESSION_PROP_CSRF        = "csrf"
+
+	SESSION_ACTIVITY_UPDATE_INTERVAL = 60000 // 1 minute
 )
 
 type Session struct {
@@ -68,6 +74,7 @@ func (me *Session) PreSave() {
 
 func (me *Session) Sanitize() {
 	me.Token = ""
+	delete(me.Props, SESSION_PROP_CSRF)
 }
 
 func (me *Session) IsExpired() bool {
@@ -100,6 +107,19 @@ func (me *Session) AddProp(key string, value string) {
 	me.Props[key] = value
 }
 
+// GenerateCSRF creates a new CSRF token for the session and returns it.
+func (me *Session) GenerateCSRF() string {
+	token := NewId()
+	me.AddProp(SESSION_PROP_CSRF, token)
+	return token
+}
+
+// GetCSRF returns the session's CSRF token, or an empty string for sessions
+// that were never issued one.
+func (me *Session) GetCSRF() string {
+	return me.Props[SESSION_PROP_CSRF]
+}
+
 func (me *Session) GetTeamByTeamId(teamId string) *TeamMember {
 	for _, team := range me.TeamMembers {
 		if team.TeamId == teamId {
@@ -114,8 +134,41 @@ func (me *Session) IsMobileApp() bool {
 	return len(me.DeviceId) > 0
 }
 
+// GetUserRoles returns the roles of the session. OAuth sessions without the
+// admin scope don't get the system admin role, whatever the user's roles are.
 func (me *Session) GetUserRoles() []string {
-	return strings.Fields(me.Roles)
+	roles := strings.Fields(me.Roles)
+	if !me.HasOAuthScope(OAUTH_SCOPE_ADMIN) {
+		filtered := []string{}
+		for _, role := range roles {
+			if role != ROLE_SYSTEM_ADMIN.Id {
+				filtered = append(filtered, role)
+			}
+		}
+		roles = filtered
+	}
+
+	return roles
+}
+
+// GetOAuthScope returns the scope granted to an OAuth session. Sessions
+// created before scopes were recorded get DEFAULT_SCOPE.
+func (me *Session) GetOAuthScope() string {
+	if scope := me.Props[SESSION_PROP_OAUTH_SCOPE]; len(scope) > 0 {
+		return scope
+	}
+
+	return DEFAULT_SCOPE
+}
+
+// HasOAuthScope returns true if the session may be used for requests that
+// need the given scope. Sessions that aren't OAuth sessions have every scope.
+func (me *Session) HasOAuthScope(scope string) bool {
+	if !me.IsOAuth {
+		return true
+	}
+
+	return OAuthScopeAllows(me.GetOAuthScope(), scope)
 }
 
 func SessionsToJson(o []*Session) string {
@@ -33,3 +33,51 @@ func TestSessionJson(t *testing.T) {
 
 	session.SetExpireInDays(10)
 }
+
+func TestSessionOAuthScope(t *testing.T) {
+	session := Session{Roles: ROLE_SYSTEM_USER.Id + " " + ROLE_SYSTEM_ADMIN.Id}
+
+	if !session.HasOAuthScope(OAUTH_SCOPE_ADMIN) || len(session.GetUserRoles()) != 2 {
+		t.Fatal("regular sessions should have every scope")
+	}
+
+	session.IsOAuth = true
+	if session.GetOAuthScope() != DEFAULT_SCOPE {
+		t.Fatal("should default to the user scope")
+	}
+
+	if !session.HasOAuthScope(OAUTH_SCOPE_USER) || session.HasOAuthScope(OAUTH_SCOPE_ADMIN) {
+		t.Fatal("should only have the default scope")
+	}
+
+	if roles := session.GetUserRoles(); len(roles) != 1 || roles[0] != ROLE_SYSTEM_USER.Id {
+		t.Fatal("should drop the system admin role without the admin scope", roles)
+	}
+
+	session.AddProp(SESSION_PROP_OAUTH_SCOPE, OAUTH_SCOPE_READ)
+	if !session.HasOAuthScope(OAUTH_SCOPE_READ) || session.HasOAuthScope(OAUTH_SCOPE_POST) {
+		t.Fatal("should only have the read scope")
+	}
+
+	session.AddProp(SESSION_PROP_OAUTH_SCOPE, OAUTH_SCOPE_ADMIN)
+	if len(session.GetUserRoles()) != 2 {
+		t.Fatal("should keep the system admin role with the admin scope")
+	}
+}
+
+func TestSessionCSRF(t *testing.T) {
+	session := Session{}
+	if len(session.GetCSRF()) != 0 {
+		t.Fatal("shouldn't have a CSRF token")
+	}
+
+	token := session.GenerateCSRF()
+	if len(token) == 0 || session.GetCSRF() != token {
+		t.Fatal("should have returned the CSRF token")
+	}
+
+	session.Sanitize()
+	if len(session.GetCSRF()) != 0 {
+		t.Fatal("sanitize should remove the CSRF token")
+	}
+}
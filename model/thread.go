@@ -0,0 +1,94 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+const (
+	THREAD_PARTICIPANTS_MAX       = 100
+	THREAD_PARTICIPANTS_MAX_RUNES = 3000
+)
+
+// ThreadMembership tracks whether a user is following the thread started by a root post and what they've read of it.
+type ThreadMembership struct {
+	PostId         string `json:"post_id"`
+	UserId         string `json:"user_id"`
+	Following      bool   `json:"following"`
+	LastViewedAt   int64  `json:"last_viewed_at"`
+	UnreadMentions int64  `json:"unread_mentions"`
+	LastUpdateAt   int64  `json:"last_update_at"`
+}
+
+// Thread is a thread followed by a user along with what they haven't read of it yet.
+type Thread struct {
+	PostId         string `json:"id"`
+	Post           *Post  `json:"post" db:"-"`
+	LastViewedAt   int64  `json:"last_viewed_at"`
+	UnreadReplies  int64  `json:"unread_replies"`
+	UnreadMentions int64  `json:"unread_mentions"`
+}
+
+func (o *ThreadMembership) IsValid() *AppError {
+	if len(o.PostId) != 26 {
+		return NewAppError("ThreadMembership.IsValid", "model.thread_membership.is_valid.post_id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if len(o.UserId) != 26 {
+		return NewAppError("ThreadMembership.IsValid", "model.thread_membership.is_valid.user_id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if o.UnreadMentions < 0 {
+		return NewAppError("ThreadMembership.IsValid", "model.thread_membership.is_valid.unread_mentions.app_error", nil, "post_id="+o.PostId+", user_id="+o.UserId, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func (o *ThreadMembership) PreSave() {
+	o.LastUpdateAt = GetMillis()
+}
+
+func (o *ThreadMembership) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func ThreadMembershipFromJson(data io.Reader) *ThreadMembership {
+	decoder := json.NewDecoder(data)
+	var o ThreadMembership
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func ThreadListToJson(l []*Thread) string {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func ThreadListFromJson(data io.Reader) []*Thread {
+	decoder := json.NewDecoder(data)
+	var l []*Thread
+	err := decoder.Decode(&l)
+	if err == nil {
+		return l
+	} else {
+		return nil
+	}
+}
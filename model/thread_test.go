@@ -0,0 +1,52 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestThreadMembershipJson(t *testing.T) {
+	o := ThreadMembership{PostId: NewId(), UserId: NewId(), Following: true}
+	json := o.ToJson()
+	ro := ThreadMembershipFromJson(strings.NewReader(json))
+
+	if o.PostId != ro.PostId || o.UserId != ro.UserId || !ro.Following {
+		t.Fatal("memberships do not match")
+	}
+}
+
+func TestThreadMembershipIsValid(t *testing.T) {
+	o := ThreadMembership{}
+
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.PostId = NewId()
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.UserId = NewId()
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.UnreadMentions = -1
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+}
+
+func TestThreadListJson(t *testing.T) {
+	threads := []*Thread{{PostId: NewId(), Post: &Post{Id: NewId(), ReplyCount: 2}, UnreadReplies: 1}}
+	json := ThreadListToJson(threads)
+	rthreads := ThreadListFromJson(strings.NewReader(json))
+
+	if len(rthreads) != 1 || rthreads[0].PostId != threads[0].PostId || rthreads[0].Post.ReplyCount != 2 || rthreads[0].UnreadReplies != 1 {
+		t.Fatal("threads do not match")
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"time"
+)
+
+const (
+	USE_AUTOMATIC_TIMEZONE = "useAutomaticTimezone"
+	AUTOMATIC_TIMEZONE     = "automaticTimezone"
+	MANUAL_TIMEZONE        = "manualTimezone"
+
+	USER_TIMEZONE_MAX_RUNES = 64
+)
+
+func DefaultUserTimezone() map[string]string {
+	defaultTimezone := make(map[string]string)
+	defaultTimezone[USE_AUTOMATIC_TIMEZONE] = "true"
+	defaultTimezone[AUTOMATIC_TIMEZONE] = ""
+	defaultTimezone[MANUAL_TIMEZONE] = ""
+
+	return defaultTimezone
+}
+
+// IsValidTimezone returns true if the given name is empty or names a location
+// known to the IANA time zone database.
+func IsValidTimezone(name string) bool {
+	if len(name) == 0 {
+		return true
+	}
+
+	if len(name) > USER_TIMEZONE_MAX_RUNES {
+		return false
+	}
+
+	_, err := time.LoadLocation(name)
+	return err == nil
+}
+
+// GetPreferredTimezone returns the name of the timezone the user has chosen,
+// either the one reported by their client or the one they set manually.
+func GetPreferredTimezone(timezone StringMap) string {
+	if timezone[USE_AUTOMATIC_TIMEZONE] == "true" {
+		return timezone[AUTOMATIC_TIMEZONE]
+	}
+
+	return timezone[MANUAL_TIMEZONE]
+}
+
+// GetTimezoneLocation returns the location for the given timezone props,
+// falling back to the server's local time if none is set or it can't be loaded.
+func GetTimezoneLocation(timezone StringMap) *time.Location {
+	if name := GetPreferredTimezone(timezone); len(name) > 0 {
+		if location, err := time.LoadLocation(name); err == nil {
+			return location
+		}
+	}
+
+	return time.Local
+}
@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"regexp"
 	"strings"
+	"time"
 	"unicode"
 	"unicode/utf8"
 
@@ -63,6 +64,7 @@ type User struct {
 	LastPictureUpdate  int64     `json:"last_picture_update,omitempty"`
 	FailedAttempts     int       `json:"failed_attempts,omitempty"`
 	Locale             string    `json:"locale"`
+	Timezone           StringMap `json:"timezone"`
 	MfaActive          bool      `json:"mfa_active,omitempty"`
 	MfaSecret          string    `json:"mfa_secret,omitempty"`
 	LastActivityAt     int64     `db:"-" json:"last_activity_at,omitempty"`
@@ -78,6 +80,7 @@ type UserPatch struct {
 	Props       *StringMap `json:"props,omitempty"`
 	NotifyProps *StringMap `json:"notify_props,omitempty"`
 	Locale      *string    `json:"locale"`
+	Timezone    *StringMap `json:"timezone"`
 }
 
 // IsValid validates the user and returns an error if it isn't configured
@@ -132,6 +135,10 @@ func (u *User) IsValid() *AppError {
 		return NewAppError("User.IsValid", "model.user.is_valid.auth_data_pwd.app_error", nil, "user_id="+u.Id, http.StatusBadRequest)
 	}
 
+	if !IsValidTimezone(u.Timezone[AUTOMATIC_TIMEZONE]) || !IsValidTimezone(u.Timezone[MANUAL_TIMEZONE]) {
+		return NewAppError("User.IsValid", "model.user.is_valid.timezone.app_error", nil, "user_id="+u.Id, http.StatusBadRequest)
+	}
+
 	return nil
 }
 
@@ -173,6 +180,10 @@ func (u *User) PreSave() {
 		u.SetDefaultNotifications()
 	}
 
+	if u.Timezone == nil {
+		u.Timezone = DefaultUserTimezone()
+	}
+
 	if len(u.Password) > 0 {
 		u.Password = HashPassword(u.Password)
 	}
@@ -201,6 +212,10 @@ func (u *User) PreUpdate() {
 		}
 		u.NotifyProps["mention_keys"] = strings.Join(goodKeys, ",")
 	}
+
+	if u.Timezone == nil {
+		u.Timezone = DefaultUserTimezone()
+	}
 }
 
 func (u *User) SetDefaultNotifications() {
@@ -270,6 +285,10 @@ func (u *User) Patch(patch *UserPatch) {
 	if patch.Locale != nil {
 		u.Locale = *patch.Locale
 	}
+
+	if patch.Timezone != nil {
+		u.Timezone = *patch.Timezone
+	}
 }
 
 // ToJson convert a User to a json string
@@ -346,6 +365,10 @@ func (u *User) MakeNonNil() {
 	if u.NotifyProps == nil {
 		u.NotifyProps = make(map[string]string)
 	}
+
+	if u.Timezone == nil {
+		u.Timezone = make(map[string]string)
+	}
 }
 
 func (u *User) AddProp(key string, value string) {
@@ -400,6 +423,18 @@ func (u *User) GetDisplayNameForPreference(nameFormat string) string {
 	return displayName
 }
 
+// GetPreferredTimezone returns the name of the timezone server-rendered times
+// should be shown in for this user, or an empty string if none is set.
+func (u *User) GetPreferredTimezone() string {
+	return GetPreferredTimezone(u.Timezone)
+}
+
+// GetTimezoneLocation returns the location server-rendered times should be
+// shown in for this user.
+func (u *User) GetTimezoneLocation() *time.Location {
+	return GetTimezoneLocation(u.Timezone)
+}
+
 func (u *User) GetRoles() []string {
 	return strings.Fields(u.Roles)
 }
@@ -423,6 +458,11 @@ func IsValidUserRoles(userRoles string) bool {
 		return false
 	}
 
+	// Guests can't also be full users
+	if IsInRole(userRoles, ROLE_SYSTEM_GUEST.Id) && (IsInRole(userRoles, ROLE_SYSTEM_USER.Id) || IsInRole(userRoles, ROLE_SYSTEM_ADMIN.Id)) {
+		return false
+	}
+
 	return true
 }
 
@@ -452,6 +492,12 @@ func IsInRole(userRoles string, inRole string) bool {
 	return false
 }
 
+// IsGuest returns true for guest accounts, which can only see the channels
+// they were added to.
+func (u *User) IsGuest() bool {
+	return IsInRole(u.Roles, ROLE_SYSTEM_GUEST.Id)
+}
+
 func (u *User) IsSSOUser() bool {
 	if u.AuthService != "" && u.AuthService != USER_AUTH_SERVICE_EMAIL {
 		return true
@@ -460,7 +506,7 @@ func (u *User) IsSSOUser() bool {
 }
 
 func (u *User) IsOAuthUser() bool {
-	if u.AuthService == USER_AUTH_SERVICE_GITLAB {
+	if u.AuthService == USER_AUTH_SERVICE_GITLAB || u.AuthService == USER_AUTH_SERVICE_OPENID {
 		return true
 	}
 	return false
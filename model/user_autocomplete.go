@@ -9,17 +9,20 @@ import (
 )
 
 type UserAutocompleteInChannel struct {
-	InChannel    []*User `json:"in_channel"`
-	OutOfChannel []*User `json:"out_of_channel"`
+	InChannel     []*User         `json:"in_channel"`
+	OutOfChannel  []*User         `json:"out_of_channel"`
+	MentionGroups []*MentionGroup `json:"mention_groups,omitempty"`
 }
 
 type UserAutocompleteInTeam struct {
-	InTeam []*User `json:"in_team"`
+	InTeam        []*User         `json:"in_team"`
+	MentionGroups []*MentionGroup `json:"mention_groups,omitempty"`
 }
 
 type UserAutocomplete struct {
-	Users        []*User `json:"users"`
-	OutOfChannel []*User `json:"out_of_channel,omitempty"`
+	Users         []*User         `json:"users"`
+	OutOfChannel  []*User         `json:"out_of_channel,omitempty"`
+	MentionGroups []*MentionGroup `json:"mention_groups,omitempty"`
 }
 
 func (o *UserAutocomplete) ToJson() string {
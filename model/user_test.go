@@ -6,6 +6,7 @@ package model
 import (
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestPasswordHash(t *testing.T) {
@@ -139,6 +140,45 @@ func TestUserIsValid(t *testing.T) {
 	if err := user.IsValid(); err == nil {
 		t.Fatal(err)
 	}
+
+	user.Position = ""
+	user.Timezone = StringMap{USE_AUTOMATIC_TIMEZONE: "false", MANUAL_TIMEZONE: "America/Toronto"}
+	if err := user.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	user.Timezone[MANUAL_TIMEZONE] = "Nowhere/Atlantis"
+	if err := user.IsValid(); err == nil {
+		t.Fatal("should have failed with an unknown timezone")
+	}
+}
+
+func TestUserGetPreferredTimezone(t *testing.T) {
+	user := User{}
+	user.PreSave()
+
+	if user.GetPreferredTimezone() != "" {
+		t.Fatal("new user should not have a timezone")
+	}
+
+	if user.GetTimezoneLocation() != time.Local {
+		t.Fatal("should fall back to server time")
+	}
+
+	user.Timezone[AUTOMATIC_TIMEZONE] = "Europe/Paris"
+	user.Timezone[MANUAL_TIMEZONE] = "Asia/Tokyo"
+	if user.GetPreferredTimezone() != "Europe/Paris" {
+		t.Fatal("should have used the automatic timezone")
+	}
+
+	user.Timezone[USE_AUTOMATIC_TIMEZONE] = "false"
+	if user.GetPreferredTimezone() != "Asia/Tokyo" {
+		t.Fatal("should have used the manual timezone")
+	}
+
+	if user.GetTimezoneLocation().String() != "Asia/Tokyo" {
+		t.Fatal("should have loaded the manual timezone")
+	}
 }
 
 func TestUserGetFullName(t *testing.T) {
@@ -245,6 +285,14 @@ func TestRoles(t *testing.T) {
 		t.Fatal()
 	}
 
+	if !IsValidUserRoles("system_guest") {
+		t.Fatal()
+	}
+
+	if IsValidUserRoles("system_guest system_user") {
+		t.Fatal()
+	}
+
 	if IsInRole("system_admin junk", "admin") {
 		t.Fatal()
 	}
@@ -257,3 +305,15 @@ func TestRoles(t *testing.T) {
 		t.Fatal()
 	}
 }
+
+func TestUserIsGuest(t *testing.T) {
+	user := User{Roles: ROLE_SYSTEM_USER.Id}
+	if user.IsGuest() {
+		t.Fatal("shouldn't be a guest")
+	}
+
+	user.Roles = ROLE_SYSTEM_GUEST.Id
+	if !user.IsGuest() {
+		t.Fatal("should be a guest")
+	}
+}
@@ -9,28 +9,33 @@ import (
 )
 
 const (
-	WEBSOCKET_EVENT_TYPING             = "typing"
-	WEBSOCKET_EVENT_POSTED             = "posted"
-	WEBSOCKET_EVENT_POST_EDITED        = "post_edited"
-	WEBSOCKET_EVENT_POST_DELETED       = "post_deleted"
-	WEBSOCKET_EVENT_CHANNEL_DELETED    = "channel_deleted"
-	WEBSOCKET_EVENT_CHANNEL_CREATED    = "channel_created"
-	WEBSOCKET_EVENT_DIRECT_ADDED       = "direct_added"
-	WEBSOCKET_EVENT_GROUP_ADDED        = "group_added"
-	WEBSOCKET_EVENT_NEW_USER           = "new_user"
-	WEBSOCKET_EVENT_LEAVE_TEAM         = "leave_team"
-	WEBSOCKET_EVENT_UPDATE_TEAM        = "update_team"
-	WEBSOCKET_EVENT_USER_ADDED         = "user_added"
-	WEBSOCKET_EVENT_USER_UPDATED       = "user_updated"
-	WEBSOCKET_EVENT_USER_REMOVED       = "user_removed"
-	WEBSOCKET_EVENT_PREFERENCE_CHANGED = "preference_changed"
-	WEBSOCKET_EVENT_EPHEMERAL_MESSAGE  = "ephemeral_message"
-	WEBSOCKET_EVENT_STATUS_CHANGE      = "status_change"
-	WEBSOCKET_EVENT_HELLO              = "hello"
-	WEBSOCKET_EVENT_WEBRTC             = "webrtc"
-	WEBSOCKET_AUTHENTICATION_CHALLENGE = "authentication_challenge"
-	WEBSOCKET_EVENT_REACTION_ADDED     = "reaction_added"
-	WEBSOCKET_EVENT_REACTION_REMOVED   = "reaction_removed"
+	WEBSOCKET_EVENT_TYPING              = "typing"
+	WEBSOCKET_EVENT_POSTED              = "posted"
+	WEBSOCKET_EVENT_POST_EDITED         = "post_edited"
+	WEBSOCKET_EVENT_POST_DELETED        = "post_deleted"
+	WEBSOCKET_EVENT_CHANNEL_DELETED     = "channel_deleted"
+	WEBSOCKET_EVENT_CHANNEL_CREATED     = "channel_created"
+	WEBSOCKET_EVENT_CHANNEL_RESTORED    = "channel_restored"
+	WEBSOCKET_EVENT_CHANNEL_UPDATED     = "channel_updated"
+	WEBSOCKET_EVENT_DIRECT_ADDED        = "direct_added"
+	WEBSOCKET_EVENT_GROUP_ADDED         = "group_added"
+	WEBSOCKET_EVENT_NEW_USER            = "new_user"
+	WEBSOCKET_EVENT_LEAVE_TEAM          = "leave_team"
+	WEBSOCKET_EVENT_UPDATE_TEAM         = "update_team"
+	WEBSOCKET_EVENT_USER_ADDED          = "user_added"
+	WEBSOCKET_EVENT_USER_UPDATED        = "user_updated"
+	WEBSOCKET_EVENT_USER_REMOVED        = "user_removed"
+	WEBSOCKET_EVENT_PREFERENCE_CHANGED  = "preference_changed"
+	WEBSOCKET_EVENT_EPHEMERAL_MESSAGE   = "ephemeral_message"
+	WEBSOCKET_EVENT_STATUS_CHANGE       = "status_change"
+	WEBSOCKET_EVENT_HELLO               = "hello"
+	WEBSOCKET_EVENT_WEBRTC              = "webrtc"
+	WEBSOCKET_AUTHENTICATION_CHALLENGE  = "authentication_challenge"
+	WEBSOCKET_EVENT_REACTION_ADDED      = "reaction_added"
+	WEBSOCKET_EVENT_REACTION_REMOVED    = "reaction_removed"
+	WEBSOCKET_EVENT_POST_ACKNOWLEDGED   = "post_acknowledged"
+	WEBSOCKET_EVENT_POST_UNACKNOWLEDGED = "post_unacknowledged"
+	WEBSOCKET_EVENT_READ_RECEIPT        = "read_receipt"
 )
 
 type WebSocketMessage interface {
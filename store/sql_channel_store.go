@@ -66,6 +66,7 @@ func NewSqlChannelStore(sqlStore *SqlStore) ChannelStore {
 		table.ColMap("Header").SetMaxSize(1024)
 		table.ColMap("Purpose").SetMaxSize(250)
 		table.ColMap("CreatorId").SetMaxSize(26)
+		table.ColMap("PostRole").SetMaxSize(64)
 
 		tablem := db.AddTableWithName(model.ChannelMember{}, "ChannelMembers").SetKeys(false, "ChannelId", "UserId")
 		tablem.ColMap("ChannelId").SetMaxSize(26)
@@ -168,7 +169,11 @@ func (s SqlChannelStore) SaveDirectChannel(directchannel *model.Channel, member1
 					member2.ChannelId = newChannel.Id
 
 					member1Result := s.saveMemberT(transaction, member1, newChannel)
-					member2Result := s.saveMemberT(transaction, member2, newChannel)
+					member2Result := member1Result
+					// a user's direct channel with themselves only has the one member
+					if member2.UserId != member1.UserId {
+						member2Result = s.saveMemberT(transaction, member2, newChannel)
+					}
 
 					if member1Result.Err != nil || member2Result.Err != nil {
 						transaction.Rollback()
@@ -812,6 +817,46 @@ func (s SqlChannelStore) GetDeletedByName(teamId string, name string) StoreChann
 	return storeChannel
 }
 
+func (s SqlChannelStore) GetDeleted(teamId string, userId string, offset int, limit int) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		data := &model.ChannelList{}
+		_, err := s.GetReplica().Select(data,
+			`SELECT
+			    *
+			FROM
+			    Channels
+			WHERE
+			    TeamId = :TeamId
+					AND DeleteAt != 0
+					AND (Type = 'O'
+						OR Id IN (SELECT
+							ChannelId
+						FROM
+							ChannelMembers
+						WHERE
+							UserId = :UserId))
+			ORDER BY DisplayName
+			LIMIT :Limit
+			OFFSET :Offset`,
+			map[string]interface{}{"TeamId": teamId, "UserId": userId, "Limit": limit, "Offset": offset})
+
+		if err != nil {
+			result.Err = model.NewLocAppError("SqlChannelStore.GetDeleted", "store.sql_channel.get_deleted.app_error", nil, "teamId="+teamId+", userId="+userId+", err="+err.Error())
+		} else {
+			result.Data = data
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
 func (s SqlChannelStore) SaveMember(member *model.ChannelMember) StoreChannel {
 	storeChannel := make(StoreChannel, 1)
 
@@ -1422,6 +1467,54 @@ func (s SqlChannelStore) AnalyticsTypeCount(teamId string, channelType string) S
 	return storeChannel
 }
 
+// GetUsersSharingChannels returns the ids of the other users that are members
+// of at least one channel userId is also a member of.
+func (s SqlChannelStore) GetUsersSharingChannels(userId string, otherUserIds []string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var userIds []string
+		props := make(map[string]interface{})
+		idQuery := ""
+
+		for index, otherUserId := range otherUserIds {
+			if len(idQuery) > 0 {
+				idQuery += ", "
+			}
+
+			props["userId"+strconv.Itoa(index)] = otherUserId
+			idQuery += ":userId" + strconv.Itoa(index)
+		}
+
+		props["UserId"] = userId
+
+		if len(otherUserIds) == 0 {
+			result.Data = []string{}
+		} else if _, err := s.GetReplica().Select(&userIds,
+			`SELECT DISTINCT
+				Others.UserId
+			FROM
+				ChannelMembers Mine
+			INNER JOIN ChannelMembers Others ON Others.ChannelId = Mine.ChannelId
+			INNER JOIN Channels ON Channels.Id = Mine.ChannelId
+			WHERE
+				Mine.UserId = :UserId
+				AND Channels.DeleteAt = 0
+				AND Others.UserId IN (`+idQuery+`)`, props); err != nil {
+			result.Err = model.NewLocAppError("SqlChannelStore.GetUsersSharingChannels", "store.sql_channel.get_users_sharing_channels.app_error", nil, "userId="+userId+", "+err.Error())
+		} else {
+			result.Data = userIds
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
 func (s SqlChannelStore) AnalyticsDeletedTypeCount(teamId string, channelType string) StoreChannel {
 	storeChannel := make(StoreChannel, 1)
 
@@ -512,6 +512,76 @@ func TestChannelStoreGetDeletedByName(t *testing.T) {
 	}
 }
 
+func TestChannelStoreGetDeleted(t *testing.T) {
+	Setup()
+
+	teamId := model.NewId()
+	userId := model.NewId()
+
+	o1 := model.Channel{}
+	o1.TeamId = teamId
+	o1.DisplayName = "Channel1"
+	o1.Name = "a" + model.NewId() + "b"
+	o1.Type = model.CHANNEL_OPEN
+	o1.DeleteAt = model.GetMillis()
+	Must(store.Channel().Save(&o1))
+
+	o2 := model.Channel{}
+	o2.TeamId = teamId
+	o2.DisplayName = "Channel2"
+	o2.Name = "a" + model.NewId() + "b"
+	o2.Type = model.CHANNEL_PRIVATE
+	o2.DeleteAt = model.GetMillis()
+	Must(store.Channel().Save(&o2))
+
+	o3 := model.Channel{}
+	o3.TeamId = teamId
+	o3.DisplayName = "Channel3"
+	o3.Name = "a" + model.NewId() + "b"
+	o3.Type = model.CHANNEL_PRIVATE
+	o3.DeleteAt = model.GetMillis()
+	Must(store.Channel().Save(&o3))
+
+	o4 := model.Channel{}
+	o4.TeamId = teamId
+	o4.DisplayName = "Channel4"
+	o4.Name = "a" + model.NewId() + "b"
+	o4.Type = model.CHANNEL_OPEN
+	Must(store.Channel().Save(&o4))
+
+	m1 := model.ChannelMember{}
+	m1.ChannelId = o2.Id
+	m1.UserId = userId
+	m1.NotifyProps = model.GetDefaultChannelNotifyProps()
+	Must(store.Channel().SaveMember(&m1))
+
+	if r1 := <-store.Channel().GetDeleted(teamId, userId, 0, 100); r1.Err != nil {
+		t.Fatal(r1.Err)
+	} else {
+		list := r1.Data.(*model.ChannelList)
+
+		if len(*list) != 2 {
+			t.Fatal("wrong list length")
+		}
+
+		if (*list)[0].Id != o1.Id || (*list)[1].Id != o2.Id {
+			t.Fatal("wrong channels returned")
+		}
+	}
+
+	if r2 := <-store.Channel().GetDeleted(teamId, userId, 1, 1); r2.Err != nil {
+		t.Fatal(r2.Err)
+	} else if list := r2.Data.(*model.ChannelList); len(*list) != 1 || (*list)[0].Id != o2.Id {
+		t.Fatal("wrong page returned")
+	}
+
+	if r3 := <-store.Channel().GetDeleted(teamId, model.NewId(), 0, 100); r3.Err != nil {
+		t.Fatal(r3.Err)
+	} else if list := r3.Data.(*model.ChannelList); len(*list) != 1 || (*list)[0].Id != o1.Id {
+		t.Fatal("should only have returned the public channel")
+	}
+}
+
 func TestChannelMemberStore(t *testing.T) {
 	Setup()
 
@@ -1715,6 +1785,53 @@ func TestChannelStoreGetMembersByIds(t *testing.T) {
 	}
 }
 
+func TestChannelStoreGetUsersSharingChannels(t *testing.T) {
+	Setup()
+
+	o1 := model.Channel{}
+	o1.TeamId = model.NewId()
+	o1.DisplayName = "ChannelA"
+	o1.Name = "a" + model.NewId() + "b"
+	o1.Type = model.CHANNEL_PRIVATE
+	Must(store.Channel().Save(&o1))
+
+	o2 := model.Channel{}
+	o2.TeamId = o1.TeamId
+	o2.DisplayName = "ChannelB"
+	o2.Name = "a" + model.NewId() + "b"
+	o2.Type = model.CHANNEL_OPEN
+	Must(store.Channel().Save(&o2))
+
+	userId := model.NewId()
+	sharedUserId := model.NewId()
+	otherUserId := model.NewId()
+
+	Must(store.Channel().SaveMember(&model.ChannelMember{ChannelId: o1.Id, UserId: userId, NotifyProps: model.GetDefaultChannelNotifyProps()}))
+	Must(store.Channel().SaveMember(&model.ChannelMember{ChannelId: o1.Id, UserId: sharedUserId, NotifyProps: model.GetDefaultChannelNotifyProps()}))
+	Must(store.Channel().SaveMember(&model.ChannelMember{ChannelId: o2.Id, UserId: sharedUserId, NotifyProps: model.GetDefaultChannelNotifyProps()}))
+	Must(store.Channel().SaveMember(&model.ChannelMember{ChannelId: o2.Id, UserId: otherUserId, NotifyProps: model.GetDefaultChannelNotifyProps()}))
+
+	if r := <-store.Channel().GetUsersSharingChannels(userId, []string{sharedUserId, otherUserId, model.NewId()}); r.Err != nil {
+		t.Fatal(r.Err)
+	} else if userIds := r.Data.([]string); len(userIds) != 1 || userIds[0] != sharedUserId {
+		t.Fatal("should only have returned the user sharing a channel", userIds)
+	}
+
+	if r := <-store.Channel().GetUsersSharingChannels(userId, []string{}); r.Err != nil {
+		t.Fatal(r.Err)
+	} else if len(r.Data.([]string)) != 0 {
+		t.Fatal("should have returned no users")
+	}
+
+	Must(store.Channel().Delete(o1.Id, model.GetMillis()))
+
+	if r := <-store.Channel().GetUsersSharingChannels(userId, []string{sharedUserId}); r.Err != nil {
+		t.Fatal(r.Err)
+	} else if len(r.Data.([]string)) != 0 {
+		t.Fatal("deleted channels shouldn't count")
+	}
+}
+
 func TestChannelStoreAnalyticsDeletedTypeCount(t *testing.T) {
 	Setup()
 
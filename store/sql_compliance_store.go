@@ -188,10 +188,12 @@ func (s SqlComplianceStore) ComplianceExport(job *model.Compliance) StoreChannel
 			    Users.Username AS UserUsername,
 			    Users.Email AS UserEmail,
 			    Users.Nickname AS UserNickname,
+			    Users.Timezone AS UserTimezone,
 			    Posts.Id AS PostId,
 			    Posts.CreateAt AS PostCreateAt,
 			    Posts.UpdateAt AS PostUpdateAt,
 			    Posts.DeleteAt AS PostDeleteAt,
+			    Posts.EditAt AS PostEditAt,
 			    Posts.RootId AS PostRootId,
 			    Posts.ParentId AS PostParentId,
 			    Posts.OriginalId AS PostOriginalId,
@@ -209,8 +211,8 @@ func (s SqlComplianceStore) ComplianceExport(job *model.Compliance) StoreChannel
 			    Teams.Id = Channels.TeamId
 			        AND Posts.ChannelId = Channels.Id
 			        AND Posts.UserId = Users.Id
-			        AND Posts.CreateAt > :StartTime
-			        AND Posts.CreateAt <= :EndTime
+			        AND ((Posts.CreateAt > :StartTime AND Posts.CreateAt <= :EndTime)
+			            OR (Posts.OriginalId != '' AND Posts.UpdateAt > :StartTime AND Posts.UpdateAt <= :EndTime))
 			        ` + emailQuery + `
 			        ` + keywordQuery + `
 			ORDER BY Posts.CreateAt
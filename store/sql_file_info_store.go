@@ -139,6 +139,88 @@ func (fs SqlFileInfoStore) GetByPath(path string) StoreChannel {
 	return storeChannel
 }
 
+func (fs SqlFileInfoStore) GetForUser(userId string, includeDeleted bool) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var infos []*model.FileInfo
+
+		deleteFilter := "AND DeleteAt = 0"
+		if includeDeleted {
+			deleteFilter = ""
+		}
+
+		if _, err := fs.GetReplica().Select(&infos,
+			`SELECT
+				*
+			FROM
+				FileInfo
+			WHERE
+				CreatorId = :CreatorId
+				`+deleteFilter+`
+			ORDER BY
+				CreateAt`, map[string]interface{}{"CreatorId": userId}); err != nil {
+			result.Err = model.NewLocAppError("SqlFileInfoStore.GetForUser",
+				"store.sql_file_info.get_for_user.app_error", nil, "creator_id="+userId+", "+err.Error())
+		} else {
+			result.Data = infos
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (fs SqlFileInfoStore) ReassignCreator(userId string, newUserId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := fs.GetMaster().Exec(
+			`UPDATE
+				FileInfo
+			SET
+				CreatorId = :NewCreatorId
+			WHERE
+				CreatorId = :CreatorId`, map[string]interface{}{"CreatorId": userId, "NewCreatorId": newUserId}); err != nil {
+			result.Err = model.NewLocAppError("SqlFileInfoStore.ReassignCreator",
+				"store.sql_file_info.reassign_creator.app_error", nil, "creator_id="+userId+", err="+err.Error())
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (fs SqlFileInfoStore) PermanentDeleteByUser(userId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := fs.GetMaster().Exec(
+			`DELETE FROM
+				FileInfo
+			WHERE
+				CreatorId = :CreatorId`, map[string]interface{}{"CreatorId": userId}); err != nil {
+			result.Err = model.NewLocAppError("SqlFileInfoStore.PermanentDeleteByUser",
+				"store.sql_file_info.permanent_delete_by_user.app_error", nil, "creator_id="+userId+", err="+err.Error())
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
 func (s SqlFileInfoStore) InvalidateFileInfosForPostCache(postId string) {
 	fileInfoCache.Remove(postId)
 }
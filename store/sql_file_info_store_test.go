@@ -218,3 +218,33 @@ func TestFileInfoDeleteForPost(t *testing.T) {
 		t.Fatal("shouldn't have returned any file infos")
 	}
 }
+
+func TestFileInfoPermanentDeleteByUser(t *testing.T) {
+	Setup()
+
+	userId := model.NewId()
+
+	Must(store.FileInfo().Save(&model.FileInfo{CreatorId: userId, Path: "file.txt"}))
+	Must(store.FileInfo().Save(&model.FileInfo{CreatorId: userId, Path: "file.txt", DeleteAt: 123}))
+	other := Must(store.FileInfo().Save(&model.FileInfo{CreatorId: model.NewId(), Path: "file.txt"})).(*model.FileInfo)
+
+	if infos := Must(store.FileInfo().GetForUser(userId, false)).([]*model.FileInfo); len(infos) != 1 {
+		t.Fatal("should only have returned the file info that isn't deleted")
+	}
+
+	if infos := Must(store.FileInfo().GetForUser(userId, true)).([]*model.FileInfo); len(infos) != 2 {
+		t.Fatal("should have returned the deleted file info too")
+	}
+
+	if result := <-store.FileInfo().PermanentDeleteByUser(userId); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	if infos := Must(store.FileInfo().GetForUser(userId, true)).([]*model.FileInfo); len(infos) != 0 {
+		t.Fatal("should have deleted the user's file infos")
+	}
+
+	if result := <-store.FileInfo().Get(other.Id); result.Err != nil {
+		t.Fatal("shouldn't have deleted another user's file info")
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/mattermost/platform/model"
+)
+
+type SqlLoginAttemptStore struct {
+	*SqlStore
+}
+
+func NewSqlLoginAttemptStore(sqlStore *SqlStore) LoginAttemptStore {
+	s := &SqlLoginAttemptStore{sqlStore}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.LoginAttempt{}, "LoginAttempts").SetKeys(false, "Id")
+		table.ColMap("Id").SetMaxSize(model.LOGIN_ATTEMPT_ID_MAX_LENGTH)
+	}
+
+	return s
+}
+
+func (s SqlLoginAttemptStore) CreateIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_login_attempts_last_failed_at", "LoginAttempts", "LastFailedAt")
+}
+
+// RecordFailure counts another failed attempt for the given id and returns the updated *model.LoginAttempt. The count
+// starts over if the previous failure happened before resetBefore.
+func (s SqlLoginAttemptStore) RecordFailure(id string, failedAt int64, resetBefore int64) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		updated, err := s.incrementFailedAttempts(id, failedAt, resetBefore)
+		if err == nil && !updated {
+			if err = s.GetMaster().Insert(&model.LoginAttempt{Id: id, FailedAttempts: 1, LastFailedAt: failedAt}); err != nil {
+				// someone else inserted the row first
+				_, err = s.incrementFailedAttempts(id, failedAt, resetBefore)
+			}
+		}
+
+		var attempt model.LoginAttempt
+		if err != nil {
+			result.Err = model.NewAppError("SqlLoginAttemptStore.RecordFailure", "store.sql_login_attempt.record_failure.app_error", nil, "id="+id+", "+err.Error(), http.StatusInternalServerError)
+		} else if err := s.GetMaster().SelectOne(&attempt, "SELECT * FROM LoginAttempts WHERE Id = :Id", map[string]interface{}{"Id": id}); err != nil {
+			result.Err = model.NewAppError("SqlLoginAttemptStore.RecordFailure", "store.sql_login_attempt.record_failure.app_error", nil, "id="+id+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = &attempt
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlLoginAttemptStore) incrementFailedAttempts(id string, failedAt int64, resetBefore int64) (bool, error) {
+	res, err := s.GetMaster().Exec(
+		`UPDATE
+			LoginAttempts
+		SET
+			FailedAttempts = CASE WHEN LastFailedAt < :ResetBefore THEN 1 ELSE FailedAttempts + 1 END,
+			LastFailedAt = :FailedAt
+		WHERE
+			Id = :Id`, map[string]interface{}{"Id": id, "FailedAt": failedAt, "ResetBefore": resetBefore})
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
+func (s SqlLoginAttemptStore) Get(id string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var attempt model.LoginAttempt
+		if err := s.GetMaster().SelectOne(&attempt, "SELECT * FROM LoginAttempts WHERE Id = :Id", map[string]interface{}{"Id": id}); err == sql.ErrNoRows {
+			result.Err = model.NewAppError("SqlLoginAttemptStore.Get", "store.sql_login_attempt.get.missing.app_error", nil, "id="+id, http.StatusNotFound)
+		} else if err != nil {
+			result.Err = model.NewAppError("SqlLoginAttemptStore.Get", "store.sql_login_attempt.get.app_error", nil, "id="+id+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = &attempt
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlLoginAttemptStore) Delete(id string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := s.GetMaster().Exec("DELETE FROM LoginAttempts WHERE Id = :Id", map[string]interface{}{"Id": id}); err != nil {
+			result.Err = model.NewAppError("SqlLoginAttemptStore.Delete", "store.sql_login_attempt.delete.app_error", nil, "id="+id+", "+err.Error(), http.StatusInternalServerError)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlLoginAttemptStore) DeleteOlderThan(lastFailedAt int64) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if res, err := s.GetMaster().Exec("DELETE FROM LoginAttempts WHERE LastFailedAt < :LastFailedAt", map[string]interface{}{"LastFailedAt": lastFailedAt}); err != nil {
+			result.Err = model.NewAppError("SqlLoginAttemptStore.DeleteOlderThan", "store.sql_login_attempt.delete_older_than.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else if rows, err := res.RowsAffected(); err != nil {
+			result.Err = model.NewAppError("SqlLoginAttemptStore.DeleteOlderThan", "store.sql_login_attempt.delete_older_than.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = rows
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestLoginAttemptStoreRecordFailure(t *testing.T) {
+	Setup()
+
+	id := model.LoginAttemptIdForLoginId(model.NewId())
+
+	if result := <-store.LoginAttempt().Get(id); result.Err == nil {
+		t.Fatal("shouldn't have found any attempts")
+	}
+
+	attempt := Must(store.LoginAttempt().RecordFailure(id, 1000, 0)).(*model.LoginAttempt)
+	if attempt.Id != id || attempt.FailedAttempts != 1 || attempt.LastFailedAt != 1000 {
+		t.Fatal("should have recorded the first failure", attempt)
+	}
+
+	attempt = Must(store.LoginAttempt().RecordFailure(id, 2000, 500)).(*model.LoginAttempt)
+	if attempt.FailedAttempts != 2 || attempt.LastFailedAt != 2000 {
+		t.Fatal("should have counted another failure", attempt)
+	}
+
+	attempt = Must(store.LoginAttempt().RecordFailure(id, 9000, 5000)).(*model.LoginAttempt)
+	if attempt.FailedAttempts != 1 || attempt.LastFailedAt != 9000 {
+		t.Fatal("should have started counting over", attempt)
+	}
+
+	if attempt = Must(store.LoginAttempt().Get(id)).(*model.LoginAttempt); attempt.FailedAttempts != 1 {
+		t.Fatal("should have returned the attempt", attempt)
+	}
+
+	Must(store.LoginAttempt().Delete(id))
+
+	if result := <-store.LoginAttempt().Get(id); result.Err == nil {
+		t.Fatal("should have deleted the attempts")
+	}
+}
+
+func TestLoginAttemptStoreDeleteOlderThan(t *testing.T) {
+	Setup()
+
+	id1 := model.LoginAttemptIdForIpAddress(model.NewId())
+	id2 := model.LoginAttemptIdForIpAddress(model.NewId())
+
+	Must(store.LoginAttempt().RecordFailure(id1, 1000, 0))
+	Must(store.LoginAttempt().RecordFailure(id2, model.GetMillis(), 0))
+
+	Must(store.LoginAttempt().DeleteOlderThan(2000))
+
+	if result := <-store.LoginAttempt().Get(id1); result.Err == nil {
+		t.Fatal("should have deleted the old attempt")
+	}
+
+	if result := <-store.LoginAttempt().Get(id2); result.Err != nil {
+		t.Fatal("shouldn't have deleted the recent attempt")
+	}
+
+	Must(store.LoginAttempt().Delete(id2))
+}
@@ -0,0 +1,388 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/mattermost/platform/model"
+)
+
+type SqlMentionGroupStore struct {
+	*SqlStore
+}
+
+func NewSqlMentionGroupStore(sqlStore *SqlStore) MentionGroupStore {
+	s := &SqlMentionGroupStore{sqlStore}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.MentionGroup{}, "MentionGroups").SetKeys(false, "Id")
+		table.ColMap("Id").SetMaxSize(26)
+		table.ColMap("TeamId").SetMaxSize(26)
+		table.ColMap("CreatorId").SetMaxSize(26)
+		table.ColMap("Name").SetMaxSize(64)
+		table.ColMap("DisplayName").SetMaxSize(64)
+		table.ColMap("Description").SetMaxSize(255)
+		table.SetUniqueTogether("TeamId", "Name", "DeleteAt")
+
+		tablem := db.AddTableWithName(model.MentionGroupMember{}, "MentionGroupMembers").SetKeys(false, "GroupId", "UserId")
+		tablem.ColMap("GroupId").SetMaxSize(26)
+		tablem.ColMap("UserId").SetMaxSize(26)
+	}
+
+	return s
+}
+
+func (s SqlMentionGroupStore) CreateIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_mention_groups_team_id", "MentionGroups", "TeamId")
+	s.CreateIndexIfNotExists("idx_mention_groups_delete_at", "MentionGroups", "DeleteAt")
+
+	s.CreateIndexIfNotExists("idx_mention_group_members_user_id", "MentionGroupMembers", "UserId")
+}
+
+func (s SqlMentionGroupStore) Save(group *model.MentionGroup) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if len(group.Id) > 0 {
+			result.Err = model.NewAppError("SqlMentionGroupStore.Save", "store.sql_mention_group.save.existing.app_error", nil, "id="+group.Id, http.StatusBadRequest)
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		group.PreSave()
+		if result.Err = group.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if err := s.GetMaster().Insert(group); err != nil {
+			if IsUniqueConstraintError(err.Error(), []string{"Name", "mentiongroups_teamid_name_deleteat_key"}) {
+				result.Err = model.NewAppError("SqlMentionGroupStore.Save", "store.sql_mention_group.save.name_exists.app_error", nil, "id="+group.Id+", "+err.Error(), http.StatusBadRequest)
+			} else {
+				result.Err = model.NewAppError("SqlMentionGroupStore.Save", "store.sql_mention_group.save.app_error", nil, "id="+group.Id+", "+err.Error(), http.StatusInternalServerError)
+			}
+		} else {
+			result.Data = group
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlMentionGroupStore) Update(group *model.MentionGroup) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		group.PreUpdate()
+		if result.Err = group.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if count, err := s.GetMaster().Update(group); err != nil {
+			if IsUniqueConstraintError(err.Error(), []string{"Name", "mentiongroups_teamid_name_deleteat_key"}) {
+				result.Err = model.NewAppError("SqlMentionGroupStore.Update", "store.sql_mention_group.save.name_exists.app_error", nil, "id="+group.Id+", "+err.Error(), http.StatusBadRequest)
+			} else {
+				result.Err = model.NewAppError("SqlMentionGroupStore.Update", "store.sql_mention_group.update.app_error", nil, "id="+group.Id+", "+err.Error(), http.StatusInternalServerError)
+			}
+		} else if count != 1 {
+			result.Err = model.NewAppError("SqlMentionGroupStore.Update", "store.sql_mention_group.update.app_error", nil, "id="+group.Id, http.StatusInternalServerError)
+		} else {
+			result.Data = group
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlMentionGroupStore) Get(id string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var group *model.MentionGroup
+
+		if err := s.GetReplica().SelectOne(&group,
+			`SELECT
+				*
+			FROM
+				MentionGroups
+			WHERE
+				Id = :Id
+				AND DeleteAt = 0`, map[string]interface{}{"Id": id}); err != nil {
+			result.Err = model.NewAppError("SqlMentionGroupStore.Get", "store.sql_mention_group.get.app_error", nil, "id="+id+", "+err.Error(), http.StatusNotFound)
+		} else {
+			result.Data = group
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlMentionGroupStore) GetByName(teamId string, name string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var group *model.MentionGroup
+
+		if err := s.GetReplica().SelectOne(&group,
+			`SELECT
+				*
+			FROM
+				MentionGroups
+			WHERE
+				TeamId = :TeamId
+				AND Name = :Name
+				AND DeleteAt = 0`, map[string]interface{}{"TeamId": teamId, "Name": strings.ToLower(name)}); err != nil {
+			result.Err = model.NewAppError("SqlMentionGroupStore.GetByName", "store.sql_mention_group.get_by_name.app_error", nil, "team_id="+teamId+", name="+name+", "+err.Error(), http.StatusNotFound)
+		} else {
+			result.Data = group
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlMentionGroupStore) GetForTeam(teamId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var groups []*model.MentionGroup
+
+		if _, err := s.GetReplica().Select(&groups,
+			`SELECT
+				*
+			FROM
+				MentionGroups
+			WHERE
+				TeamId = :TeamId
+				AND DeleteAt = 0
+			ORDER BY Name`, map[string]interface{}{"TeamId": teamId}); err != nil {
+			result.Err = model.NewAppError("SqlMentionGroupStore.GetForTeam", "store.sql_mention_group.get_for_team.app_error", nil, "team_id="+teamId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = groups
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlMentionGroupStore) Search(teamId string, term string, limit int) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var groups []*model.MentionGroup
+
+		if _, err := s.GetReplica().Select(&groups,
+			`SELECT
+				*
+			FROM
+				MentionGroups
+			WHERE
+				TeamId = :TeamId
+				AND DeleteAt = 0
+				AND Name LIKE :Term
+			ORDER BY Name
+			LIMIT :Limit`, map[string]interface{}{"TeamId": teamId, "Term": escapeLikeTerm(strings.ToLower(term)) + "%", "Limit": limit}); err != nil {
+			result.Err = model.NewAppError("SqlMentionGroupStore.Search", "store.sql_mention_group.search.app_error", nil, "team_id="+teamId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = groups
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// escapeLikeTerm stops the wildcards in a user supplied term from matching anything. Both
+// MySQL and Postgres use a backslash as the default LIKE escape character.
+func escapeLikeTerm(term string) string {
+	term = strings.Replace(term, "\\", "\\\\", -1)
+	term = strings.Replace(term, "%", "\\%", -1)
+	return strings.Replace(term, "_", "\\_", -1)
+}
+
+func (s SqlMentionGroupStore) Delete(id string, time int64) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if sqlResult, err := s.GetMaster().Exec(
+			`UPDATE
+				MentionGroups
+			SET
+				DeleteAt = :DeleteAt,
+				UpdateAt = :UpdateAt
+			WHERE
+				Id = :Id
+				AND DeleteAt = 0`, map[string]interface{}{"DeleteAt": time, "UpdateAt": time, "Id": id}); err != nil {
+			result.Err = model.NewAppError("SqlMentionGroupStore.Delete", "store.sql_mention_group.delete.app_error", nil, "id="+id+", err="+err.Error(), http.StatusInternalServerError)
+		} else if rows, _ := sqlResult.RowsAffected(); rows == 0 {
+			result.Err = model.NewAppError("SqlMentionGroupStore.Delete", "store.sql_mention_group.delete.no_results", nil, "id="+id, http.StatusNotFound)
+		} else if _, err := s.GetMaster().Exec("DELETE FROM MentionGroupMembers WHERE GroupId = :GroupId", map[string]interface{}{"GroupId": id}); err != nil {
+			result.Err = model.NewAppError("SqlMentionGroupStore.Delete", "store.sql_mention_group.delete.app_error", nil, "id="+id+", err="+err.Error(), http.StatusInternalServerError)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlMentionGroupStore) SaveMember(member *model.MentionGroupMember) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		member.PreSave()
+		if result.Err = member.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if err := s.GetMaster().Insert(member); err != nil {
+			if IsUniqueConstraintError(err.Error(), []string{"GroupId", "mentiongroupmembers_pkey", "PRIMARY"}) {
+				result.Err = model.NewAppError("SqlMentionGroupStore.SaveMember", "store.sql_mention_group.save_member.exists.app_error", nil, "group_id="+member.GroupId+", user_id="+member.UserId+", "+err.Error(), http.StatusBadRequest)
+			} else {
+				result.Err = model.NewAppError("SqlMentionGroupStore.SaveMember", "store.sql_mention_group.save_member.save.app_error", nil, "group_id="+member.GroupId+", user_id="+member.UserId+", "+err.Error(), http.StatusInternalServerError)
+			}
+		} else {
+			result.Data = member
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlMentionGroupStore) RemoveMember(groupId string, userId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := s.GetMaster().Exec("DELETE FROM MentionGroupMembers WHERE GroupId = :GroupId AND UserId = :UserId", map[string]interface{}{"GroupId": groupId, "UserId": userId}); err != nil {
+			result.Err = model.NewAppError("SqlMentionGroupStore.RemoveMember", "store.sql_mention_group.remove_member.app_error", nil, "group_id="+groupId+", user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlMentionGroupStore) GetMembers(groupId string, offset int, limit int) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var members []*model.MentionGroupMember
+
+		if _, err := s.GetReplica().Select(&members,
+			`SELECT
+				*
+			FROM
+				MentionGroupMembers
+			WHERE
+				GroupId = :GroupId
+			ORDER BY CreateAt
+			LIMIT :Limit OFFSET :Offset`, map[string]interface{}{"GroupId": groupId, "Limit": limit, "Offset": offset}); err != nil {
+			result.Err = model.NewAppError("SqlMentionGroupStore.GetMembers", "store.sql_mention_group.get_members.app_error", nil, "group_id="+groupId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = members
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlMentionGroupStore) GetMembersForTeam(teamId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var members []*model.MentionGroupMember
+
+		if _, err := s.GetReplica().Select(&members,
+			`SELECT
+				MentionGroupMembers.*
+			FROM
+				MentionGroupMembers, MentionGroups
+			WHERE
+				MentionGroupMembers.GroupId = MentionGroups.Id
+				AND MentionGroups.TeamId = :TeamId
+				AND MentionGroups.DeleteAt = 0`, map[string]interface{}{"TeamId": teamId}); err != nil {
+			result.Err = model.NewAppError("SqlMentionGroupStore.GetMembersForTeam", "store.sql_mention_group.get_members.app_error", nil, "team_id="+teamId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = members
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlMentionGroupStore) PermanentDeleteMembersByUser(userId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := s.GetMaster().Exec("DELETE FROM MentionGroupMembers WHERE UserId = :UserId", map[string]interface{}{"UserId": userId}); err != nil {
+			result.Err = model.NewAppError("SqlMentionGroupStore.PermanentDeleteMembersByUser", "store.sql_mention_group.permanent_delete_members_by_user.app_error", nil, "user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
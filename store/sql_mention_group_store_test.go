@@ -0,0 +1,181 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestMentionGroupStoreSaveGetDelete(t *testing.T) {
+	Setup()
+
+	teamId := model.NewId()
+
+	group := &model.MentionGroup{
+		TeamId:    teamId,
+		CreatorId: model.NewId(),
+		Name:      "oncall" + model.NewId()[:10],
+	}
+
+	if result := <-store.MentionGroup().Save(group); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	if result := <-store.MentionGroup().Save(group); result.Err == nil {
+		t.Fatal("shouldn't be able to save an existing group")
+	}
+
+	duplicate := &model.MentionGroup{
+		TeamId:    teamId,
+		CreatorId: model.NewId(),
+		Name:      group.Name,
+	}
+	if result := <-store.MentionGroup().Save(duplicate); result.Err == nil {
+		t.Fatal("shouldn't be able to save a group with a duplicate name")
+	}
+
+	if result := <-store.MentionGroup().Get(group.Id); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if result.Data.(*model.MentionGroup).Name != group.Name {
+		t.Fatal("got the wrong group")
+	}
+
+	if result := <-store.MentionGroup().GetByName(teamId, group.Name); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if result.Data.(*model.MentionGroup).Id != group.Id {
+		t.Fatal("got the wrong group")
+	}
+
+	if result := <-store.MentionGroup().GetByName(model.NewId(), group.Name); result.Err == nil {
+		t.Fatal("shouldn't find a group on another team")
+	}
+
+	if result := <-store.MentionGroup().Delete(group.Id, model.GetMillis()); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	if result := <-store.MentionGroup().Get(group.Id); result.Err == nil {
+		t.Fatal("shouldn't get a deleted group")
+	}
+
+	duplicate.Id = ""
+	if result := <-store.MentionGroup().Save(duplicate); result.Err != nil {
+		t.Fatal("should be able to reuse the name of a deleted group", result.Err)
+	}
+}
+
+func TestMentionGroupStoreUpdate(t *testing.T) {
+	Setup()
+
+	group := &model.MentionGroup{
+		TeamId:    model.NewId(),
+		CreatorId: model.NewId(),
+		Name:      "ops" + model.NewId()[:10],
+	}
+	Must(store.MentionGroup().Save(group))
+
+	group.DisplayName = "Operations"
+	if result := <-store.MentionGroup().Update(group); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	if result := <-store.MentionGroup().Get(group.Id); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if result.Data.(*model.MentionGroup).DisplayName != "Operations" {
+		t.Fatal("display name should have updated")
+	}
+}
+
+func TestMentionGroupStoreGetForTeamAndSearch(t *testing.T) {
+	Setup()
+
+	teamId := model.NewId()
+
+	group1 := &model.MentionGroup{TeamId: teamId, CreatorId: model.NewId(), Name: "backend-oncall"}
+	Must(store.MentionGroup().Save(group1))
+
+	group2 := &model.MentionGroup{TeamId: teamId, CreatorId: model.NewId(), Name: "frontend"}
+	Must(store.MentionGroup().Save(group2))
+
+	group3 := &model.MentionGroup{TeamId: model.NewId(), CreatorId: model.NewId(), Name: "backend-oncall"}
+	Must(store.MentionGroup().Save(group3))
+
+	if result := <-store.MentionGroup().GetForTeam(teamId); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if groups := result.Data.([]*model.MentionGroup); len(groups) != 2 {
+		t.Fatal("should have returned both groups on the team")
+	} else if groups[0].Id != group1.Id || groups[1].Id != group2.Id {
+		t.Fatal("groups should be sorted by name")
+	}
+
+	if result := <-store.MentionGroup().Search(teamId, "Back", 10); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if groups := result.Data.([]*model.MentionGroup); len(groups) != 1 || groups[0].Id != group1.Id {
+		t.Fatal("should have found only the matching group on the team")
+	}
+
+	if result := <-store.MentionGroup().Search(teamId, "%end", 10); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if groups := result.Data.([]*model.MentionGroup); len(groups) != 0 {
+		t.Fatal("shouldn't have treated % as a wildcard")
+	}
+
+	group4 := &model.MentionGroup{TeamId: teamId, CreatorId: model.NewId(), Name: "front_end"}
+	Must(store.MentionGroup().Save(group4))
+
+	if result := <-store.MentionGroup().Search(teamId, "front_", 10); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if groups := result.Data.([]*model.MentionGroup); len(groups) != 1 || groups[0].Id != group4.Id {
+		t.Fatal("shouldn't have treated _ as a wildcard")
+	}
+}
+
+func TestMentionGroupStoreMembers(t *testing.T) {
+	Setup()
+
+	teamId := model.NewId()
+
+	group := &model.MentionGroup{TeamId: teamId, CreatorId: model.NewId(), Name: "leads" + model.NewId()[:10]}
+	Must(store.MentionGroup().Save(group))
+
+	member1 := &model.MentionGroupMember{GroupId: group.Id, UserId: model.NewId()}
+	if result := <-store.MentionGroup().SaveMember(member1); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	if result := <-store.MentionGroup().SaveMember(member1); result.Err == nil {
+		t.Fatal("shouldn't be able to add the same member twice")
+	}
+
+	member2 := &model.MentionGroupMember{GroupId: group.Id, UserId: model.NewId()}
+	Must(store.MentionGroup().SaveMember(member2))
+
+	if result := <-store.MentionGroup().GetMembers(group.Id, 0, 100); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if members := result.Data.([]*model.MentionGroupMember); len(members) != 2 {
+		t.Fatal("should have returned 2 members")
+	}
+
+	if result := <-store.MentionGroup().GetMembersForTeam(teamId); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if members := result.Data.([]*model.MentionGroupMember); len(members) != 2 {
+		t.Fatal("should have returned 2 members")
+	}
+
+	if result := <-store.MentionGroup().RemoveMember(group.Id, member1.UserId); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	if result := <-store.MentionGroup().PermanentDeleteMembersByUser(member2.UserId); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	if result := <-store.MentionGroup().GetMembers(group.Id, 0, 100); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if members := result.Data.([]*model.MentionGroupMember); len(members) != 0 {
+		t.Fatal("should have removed all members")
+	}
+}
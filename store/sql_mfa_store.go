@@ -0,0 +1,158 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"net/http"
+
+	"github.com/mattermost/platform/model"
+)
+
+type SqlMfaStore struct {
+	*SqlStore
+}
+
+func NewSqlMfaStore(sqlStore *SqlStore) MfaStore {
+	s := &SqlMfaStore{sqlStore}
+
+	for _, db := range sqlStore.GetAllConns() {
+		codes := db.AddTableWithName(model.MfaRecoveryCode{}, "MfaRecoveryCodes").SetKeys(false, "UserId", "CodeHash")
+		codes.ColMap("UserId").SetMaxSize(26)
+		codes.ColMap("CodeHash").SetMaxSize(64)
+
+		tokens := db.AddTableWithName(model.MfaUsedToken{}, "MfaUsedTokens").SetKeys(false, "UserId", "Token")
+		tokens.ColMap("UserId").SetMaxSize(26)
+		tokens.ColMap("Token").SetMaxSize(16)
+	}
+
+	return s
+}
+
+func (s SqlMfaStore) CreateIndexesIfNotExists() {
+}
+
+// SaveRecoveryCodes replaces all of a user's recovery codes with new ones.
+func (s SqlMfaStore) SaveRecoveryCodes(userId string, codeHashes []string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		transaction, err := s.GetMaster().Begin()
+		if err != nil {
+			result.Err = model.NewAppError("SqlMfaStore.SaveRecoveryCodes", "store.sql_mfa.save_recovery_codes.app_error", nil, "user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if _, err := transaction.Exec("DELETE FROM MfaRecoveryCodes WHERE UserId = :UserId", map[string]interface{}{"UserId": userId}); err != nil {
+			transaction.Rollback()
+			result.Err = model.NewAppError("SqlMfaStore.SaveRecoveryCodes", "store.sql_mfa.save_recovery_codes.app_error", nil, "user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			createAt := model.GetMillis()
+
+			for _, codeHash := range codeHashes {
+				if err := transaction.Insert(&model.MfaRecoveryCode{UserId: userId, CodeHash: codeHash, CreateAt: createAt}); err != nil {
+					transaction.Rollback()
+					result.Err = model.NewAppError("SqlMfaStore.SaveRecoveryCodes", "store.sql_mfa.save_recovery_codes.app_error", nil, "user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+					break
+				}
+			}
+		}
+
+		if result.Err == nil {
+			if err := transaction.Commit(); err != nil {
+				result.Err = model.NewAppError("SqlMfaStore.SaveRecoveryCodes", "store.sql_mfa.save_recovery_codes.app_error", nil, "user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+			}
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// UseRecoveryCode deletes a user's recovery code so that it can only be used once. The result's data is true if the
+// user had that recovery code.
+func (s SqlMfaStore) UseRecoveryCode(userId string, codeHash string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if sqlResult, err := s.GetMaster().Exec("DELETE FROM MfaRecoveryCodes WHERE UserId = :UserId AND CodeHash = :CodeHash", map[string]interface{}{"UserId": userId, "CodeHash": codeHash}); err != nil {
+			result.Err = model.NewAppError("SqlMfaStore.UseRecoveryCode", "store.sql_mfa.use_recovery_code.app_error", nil, "user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+		} else if rows, err := sqlResult.RowsAffected(); err != nil {
+			result.Err = model.NewAppError("SqlMfaStore.UseRecoveryCode", "store.sql_mfa.use_recovery_code.app_error", nil, "user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = rows == 1
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlMfaStore) GetRecoveryCodeCount(userId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if count, err := s.GetReplica().SelectInt("SELECT COUNT(*) FROM MfaRecoveryCodes WHERE UserId = :UserId", map[string]interface{}{"UserId": userId}); err != nil {
+			result.Err = model.NewAppError("SqlMfaStore.GetRecoveryCodeCount", "store.sql_mfa.get_recovery_code_count.app_error", nil, "user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = count
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// SaveUsedToken records that a user has used an MFA token, failing if they've already used it since the given time.
+func (s SqlMfaStore) SaveUsedToken(userId string, token string, usedAt int64, since int64) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := s.GetMaster().Exec("DELETE FROM MfaUsedTokens WHERE UserId = :UserId AND UsedAt < :Since", map[string]interface{}{"UserId": userId, "Since": since}); err != nil {
+			result.Err = model.NewAppError("SqlMfaStore.SaveUsedToken", "store.sql_mfa.save_used_token.app_error", nil, "user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+		} else if err := s.GetMaster().Insert(&model.MfaUsedToken{UserId: userId, Token: token, UsedAt: usedAt}); err != nil {
+			// the primary key stops the same token from being saved twice
+			result.Err = model.NewAppError("SqlMfaStore.SaveUsedToken", "store.sql_mfa.save_used_token.used.app_error", nil, "user_id="+userId+", "+err.Error(), http.StatusUnauthorized)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlMfaStore) PermanentDeleteByUser(userId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := s.GetMaster().Exec("DELETE FROM MfaRecoveryCodes WHERE UserId = :UserId", map[string]interface{}{"UserId": userId}); err != nil {
+			result.Err = model.NewAppError("SqlMfaStore.PermanentDeleteByUser", "store.sql_mfa.permanent_delete_by_user.app_error", nil, "user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+		} else if _, err := s.GetMaster().Exec("DELETE FROM MfaUsedTokens WHERE UserId = :UserId", map[string]interface{}{"UserId": userId}); err != nil {
+			result.Err = model.NewAppError("SqlMfaStore.PermanentDeleteByUser", "store.sql_mfa.permanent_delete_by_user.app_error", nil, "user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestMfaStoreRecoveryCodes(t *testing.T) {
+	Setup()
+
+	userId := model.NewId()
+	hash1 := model.HashMfaRecoveryCode(model.NewMfaRecoveryCode())
+	hash2 := model.HashMfaRecoveryCode(model.NewMfaRecoveryCode())
+
+	Must(store.Mfa().SaveRecoveryCodes(userId, []string{model.HashMfaRecoveryCode(model.NewMfaRecoveryCode())}))
+	Must(store.Mfa().SaveRecoveryCodes(userId, []string{hash1, hash2}))
+
+	if count := Must(store.Mfa().GetRecoveryCodeCount(userId)).(int64); count != 2 {
+		t.Fatal("should have replaced the old recovery codes")
+	}
+
+	if used := Must(store.Mfa().UseRecoveryCode(userId, hash1)).(bool); !used {
+		t.Fatal("should have used the recovery code")
+	}
+
+	if used := Must(store.Mfa().UseRecoveryCode(userId, hash1)).(bool); used {
+		t.Fatal("shouldn't have used the recovery code twice")
+	}
+
+	if used := Must(store.Mfa().UseRecoveryCode(model.NewId(), hash2)).(bool); used {
+		t.Fatal("shouldn't have used another user's recovery code")
+	}
+
+	Must(store.Mfa().PermanentDeleteByUser(userId))
+
+	if count := Must(store.Mfa().GetRecoveryCodeCount(userId)).(int64); count != 0 {
+		t.Fatal("should have deleted the recovery codes")
+	}
+}
+
+func TestMfaStoreSaveUsedToken(t *testing.T) {
+	Setup()
+
+	userId := model.NewId()
+	now := model.GetMillis()
+
+	Must(store.Mfa().SaveUsedToken(userId, "123456", now, now-model.MFA_TOKEN_WINDOW))
+
+	if result := <-store.Mfa().SaveUsedToken(userId, "123456", now+1000, now+1000-model.MFA_TOKEN_WINDOW); result.Err == nil {
+		t.Fatal("shouldn't have let the token be used twice")
+	}
+
+	Must(store.Mfa().SaveUsedToken(model.NewId(), "123456", now, now-model.MFA_TOKEN_WINDOW))
+	Must(store.Mfa().SaveUsedToken(userId, "654321", now, now-model.MFA_TOKEN_WINDOW))
+
+	later := now + model.MFA_TOKEN_WINDOW + 1000
+	Must(store.Mfa().SaveUsedToken(userId, "123456", later, later-model.MFA_TOKEN_WINDOW))
+
+	Must(store.Mfa().PermanentDeleteByUser(userId))
+}
@@ -169,6 +169,7 @@ func TestOAuthUpdateAccessData(t *testing.T) {
 
 	// Should update fine
 	a1.RedirectUri = "http://example.com"
+	a1.Scope = model.OAUTH_SCOPE_READ
 	if result := <-store.OAuth().UpdateAccessData(&a1); result.Err != nil {
 		t.Fatal(result.Err)
 	} else {
@@ -177,6 +178,12 @@ func TestOAuthUpdateAccessData(t *testing.T) {
 			t.Fatal("refresh tokens didn't match")
 		}
 	}
+
+	if result := <-store.OAuth().GetAccessData(a1.Token); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if result.Data.(*model.AccessData).Scope != model.OAUTH_SCOPE_READ {
+		t.Fatal("scope should have been updated")
+	}
 }
 
 func TestOAuthStoreGetAccessData(t *testing.T) {
@@ -272,10 +279,14 @@ func TestOAuthStoreGetAuthData(t *testing.T) {
 	a1.UserId = model.NewId()
 	a1.Code = model.NewId()
 	a1.RedirectUri = "http://example.com"
+	a1.CodeChallenge = "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+	a1.CodeChallengeMethod = model.PKCE_METHOD_S256
 	Must(store.OAuth().SaveAuthData(&a1))
 
-	if err := (<-store.OAuth().GetAuthData(a1.Code)).Err; err != nil {
-		t.Fatal(err)
+	if result := <-store.OAuth().GetAuthData(a1.Code); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if ra1 := result.Data.(*model.AuthData); ra1.CodeChallenge != a1.CodeChallenge || ra1.CodeChallengeMethod != a1.CodeChallengeMethod {
+		t.Fatal("code challenge should have been saved")
 	}
 }
 
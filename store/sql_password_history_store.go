@@ -0,0 +1,95 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"net/http"
+
+	"github.com/mattermost/platform/model"
+)
+
+type SqlPasswordHistoryStore struct {
+	*SqlStore
+}
+
+func NewSqlPasswordHistoryStore(sqlStore *SqlStore) PasswordHistoryStore {
+	s := &SqlPasswordHistoryStore{sqlStore}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.PasswordHistory{}, "PasswordHistory").SetKeys(false, "UserId", "PasswordHash")
+		table.ColMap("UserId").SetMaxSize(26)
+		table.ColMap("PasswordHash").SetMaxSize(128)
+	}
+
+	return s
+}
+
+func (s SqlPasswordHistoryStore) CreateIndexesIfNotExists() {
+}
+
+// Save adds the password to the user's history and then forgets all but the keep most recent ones.
+func (s SqlPasswordHistoryStore) Save(history *model.PasswordHistory, keep int) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if history.CreateAt == 0 {
+			history.CreateAt = model.GetMillis()
+		}
+
+		if err := s.GetMaster().Insert(history); err != nil {
+			result.Err = model.NewAppError("SqlPasswordHistoryStore.Save", "store.sql_password_history.save.app_error", nil, "user_id="+history.UserId+", "+err.Error(), http.StatusInternalServerError)
+		} else if oldest, err := s.GetMaster().SelectInt("SELECT CreateAt FROM PasswordHistory WHERE UserId = :UserId ORDER BY CreateAt DESC LIMIT 1 OFFSET :Offset", map[string]interface{}{"UserId": history.UserId, "Offset": keep - 1}); err != nil {
+			result.Err = model.NewAppError("SqlPasswordHistoryStore.Save", "store.sql_password_history.save.app_error", nil, "user_id="+history.UserId+", "+err.Error(), http.StatusInternalServerError)
+		} else if _, err := s.GetMaster().Exec("DELETE FROM PasswordHistory WHERE UserId = :UserId AND CreateAt < :CreateAt", map[string]interface{}{"UserId": history.UserId, "CreateAt": oldest}); err != nil {
+			result.Err = model.NewAppError("SqlPasswordHistoryStore.Save", "store.sql_password_history.save.app_error", nil, "user_id="+history.UserId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = history
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetForUser returns the user's most recent passwords, newest first.
+func (s SqlPasswordHistoryStore) GetForUser(userId string, limit int) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var history []*model.PasswordHistory
+		if _, err := s.GetMaster().Select(&history, "SELECT * FROM PasswordHistory WHERE UserId = :UserId ORDER BY CreateAt DESC LIMIT :Limit", map[string]interface{}{"UserId": userId, "Limit": limit}); err != nil {
+			result.Err = model.NewAppError("SqlPasswordHistoryStore.GetForUser", "store.sql_password_history.get_for_user.app_error", nil, "user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = history
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlPasswordHistoryStore) PermanentDeleteByUser(userId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := s.GetMaster().Exec("DELETE FROM PasswordHistory WHERE UserId = :UserId", map[string]interface{}{"UserId": userId}); err != nil {
+			result.Err = model.NewAppError("SqlPasswordHistoryStore.PermanentDeleteByUser", "store.sql_password_history.permanent_delete_by_user.app_error", nil, "user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
@@ -0,0 +1,37 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestPasswordHistoryStore(t *testing.T) {
+	Setup()
+
+	userId := model.NewId()
+
+	for i := 1; i <= 4; i++ {
+		Must(store.PasswordHistory().Save(&model.PasswordHistory{UserId: userId, PasswordHash: model.NewId(), CreateAt: int64(i * 1000)}, 3))
+	}
+
+	history := Must(store.PasswordHistory().GetForUser(userId, 10)).([]*model.PasswordHistory)
+	if len(history) != 3 {
+		t.Fatal("should have only kept the 3 most recent passwords", len(history))
+	} else if history[0].CreateAt != 4000 || history[2].CreateAt != 2000 {
+		t.Fatal("should have returned the newest passwords first")
+	}
+
+	if history = Must(store.PasswordHistory().GetForUser(userId, 2)).([]*model.PasswordHistory); len(history) != 2 {
+		t.Fatal("should have limited the results")
+	}
+
+	Must(store.PasswordHistory().PermanentDeleteByUser(userId))
+
+	if history = Must(store.PasswordHistory().GetForUser(userId, 10)).([]*model.PasswordHistory); len(history) != 0 {
+		t.Fatal("should have deleted the history")
+	}
+}
@@ -0,0 +1,230 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-gorp/gorp"
+	"github.com/mattermost/platform/model"
+)
+
+type SqlPollStore struct {
+	*SqlStore
+}
+
+func NewSqlPollStore(sqlStore *SqlStore) PollStore {
+	s := &SqlPollStore{sqlStore}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.PollVote{}, "PollVotes").SetKeys(false, "PostId", "UserId", "OptionId")
+		table.ColMap("PostId").SetMaxSize(26)
+		table.ColMap("UserId").SetMaxSize(26)
+		table.ColMap("OptionId").SetMaxSize(26)
+	}
+
+	return s
+}
+
+func (s SqlPollStore) CreateIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_poll_votes_user_id", "PollVotes", "UserId")
+}
+
+// SetVotes replaces a user's votes in a poll with votes for the given options and updates the vote counts on the
+// poll's post to match. The post is locked while this happens so that votes can't be made after the poll closes and
+// concurrent votes can't overwrite each other's counts. The result's data is the updated post.
+func (s SqlPollStore) SetVotes(postId string, userId string, optionIds []string, time int64) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		votes := make([]*model.PollVote, 0, len(optionIds))
+		for _, optionId := range optionIds {
+			vote := &model.PollVote{PostId: postId, UserId: userId, OptionId: optionId}
+			vote.PreSave()
+			if result.Err = vote.IsValid(); result.Err != nil {
+				storeChannel <- result
+				close(storeChannel)
+				return
+			}
+
+			votes = append(votes, vote)
+		}
+
+		transaction, err := s.GetMaster().Begin()
+		if err != nil {
+			result.Err = model.NewAppError("SqlPollStore.SetVotes", "store.sql_poll.set_votes.app_error", nil, "post_id="+postId+", "+err.Error(), http.StatusInternalServerError)
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		post, poll, err := lockPollPost(transaction, postId)
+		if err != nil {
+			result.Err = model.NewAppError("SqlPollStore.SetVotes", "store.sql_poll.set_votes.app_error", nil, "post_id="+postId+", "+err.Error(), http.StatusInternalServerError)
+		} else if poll.IsClosed(time) {
+			result.Err = model.NewAppError("SqlPollStore.SetVotes", "store.sql_poll.set_votes.closed.app_error", nil, "post_id="+postId, http.StatusBadRequest)
+		} else if _, err := transaction.Exec("DELETE FROM PollVotes WHERE PostId = :PostId AND UserId = :UserId", map[string]interface{}{"PostId": postId, "UserId": userId}); err != nil {
+			result.Err = model.NewAppError("SqlPollStore.SetVotes", "store.sql_poll.set_votes.app_error", nil, "post_id="+postId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			for _, vote := range votes {
+				if err := transaction.Insert(vote); err != nil {
+					result.Err = model.NewAppError("SqlPollStore.SetVotes", "store.sql_poll.set_votes.app_error", nil, "post_id="+postId+", "+err.Error(), http.StatusInternalServerError)
+					break
+				}
+			}
+		}
+
+		if result.Err == nil {
+			var allVotes []*model.PollVote
+			if _, err := transaction.Select(&allVotes, "SELECT * FROM PollVotes WHERE PostId = :PostId ORDER BY CreateAt", map[string]interface{}{"PostId": postId}); err != nil {
+				result.Err = model.NewAppError("SqlPollStore.SetVotes", "store.sql_poll.set_votes.app_error", nil, "post_id="+postId+", "+err.Error(), http.StatusInternalServerError)
+			} else {
+				poll.SetVotes(allVotes)
+
+				if err := savePollPost(transaction, post, poll); err != nil {
+					result.Err = model.NewAppError("SqlPollStore.SetVotes", "store.sql_poll.set_votes.app_error", nil, "post_id="+postId+", "+err.Error(), http.StatusInternalServerError)
+				}
+			}
+		}
+
+		if result.Err != nil {
+			transaction.Rollback()
+		} else if err := transaction.Commit(); err != nil {
+			result.Err = model.NewAppError("SqlPollStore.SetVotes", "store.sql_poll.set_votes.app_error", nil, "post_id="+postId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = post
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// Close stops any more votes from being made in a poll, leaving it alone if it has already closed. The result's data
+// is the poll's post afterwards.
+func (s SqlPollStore) Close(postId string, time int64) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		transaction, err := s.GetMaster().Begin()
+		if err != nil {
+			result.Err = model.NewAppError("SqlPollStore.Close", "store.sql_poll.close.app_error", nil, "post_id="+postId+", "+err.Error(), http.StatusInternalServerError)
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		post, poll, err := lockPollPost(transaction, postId)
+		if err == nil && !poll.IsClosed(time) {
+			poll.CloseAt = time
+			err = savePollPost(transaction, post, poll)
+		}
+
+		if err != nil {
+			transaction.Rollback()
+			result.Err = model.NewAppError("SqlPollStore.Close", "store.sql_poll.close.app_error", nil, "post_id="+postId+", "+err.Error(), http.StatusInternalServerError)
+		} else if err := transaction.Commit(); err != nil {
+			result.Err = model.NewAppError("SqlPollStore.Close", "store.sql_poll.close.app_error", nil, "post_id="+postId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = post
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// Reads the post holding a poll and locks it until the transaction ends.
+func lockPollPost(transaction *gorp.Transaction, postId string) (*model.Post, *model.Poll, error) {
+	var post model.Post
+	if err := transaction.SelectOne(&post, "SELECT * FROM Posts WHERE Id = :PostId AND DeleteAt = 0 FOR UPDATE", map[string]interface{}{"PostId": postId}); err != nil {
+		return nil, nil, err
+	}
+
+	poll := model.PollFromPost(&post)
+	if poll == nil {
+		return nil, nil, errors.New("post doesn't contain a poll")
+	}
+
+	return &post, poll, nil
+}
+
+// Saves the poll of a poll post without counting it as an edit.
+func savePollPost(transaction *gorp.Transaction, post *model.Post, poll *model.Poll) error {
+	post.AddProp(model.POST_PROPS_POLL, poll)
+	post.UpdateAt = model.GetMillis()
+
+	if err := post.IsValid(); err != nil {
+		return err
+	}
+
+	_, err := transaction.Update(post)
+	return err
+}
+
+func (s SqlPollStore) GetVotes(postId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var votes []*model.PollVote
+		if _, err := s.GetReplica().Select(&votes, "SELECT * FROM PollVotes WHERE PostId = :PostId ORDER BY CreateAt", map[string]interface{}{"PostId": postId}); err != nil {
+			result.Err = model.NewAppError("SqlPollStore.GetVotes", "store.sql_poll.get_votes.app_error", nil, "post_id="+postId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = votes
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlPollStore) GetVotesForUser(postId string, userId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var votes []*model.PollVote
+		if _, err := s.GetReplica().Select(&votes, "SELECT * FROM PollVotes WHERE PostId = :PostId AND UserId = :UserId ORDER BY CreateAt", map[string]interface{}{"PostId": postId, "UserId": userId}); err != nil {
+			result.Err = model.NewAppError("SqlPollStore.GetVotesForUser", "store.sql_poll.get_votes.app_error", nil, "post_id="+postId+", user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = votes
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlPollStore) PermanentDeleteByUser(userId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := s.GetMaster().Exec("DELETE FROM PollVotes WHERE UserId = :UserId", map[string]interface{}{"UserId": userId}); err != nil {
+			result.Err = model.NewAppError("SqlPollStore.PermanentDeleteByUser", "store.sql_poll.permanent_delete_by_user.app_error", nil, "user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestPollStoreVotes(t *testing.T) {
+	Setup()
+
+	userId1 := model.NewId()
+	userId2 := model.NewId()
+
+	poll := &model.Poll{Options: []*model.PollOption{{Text: "a"}, {Text: "b"}}, MultipleChoice: true}
+	poll.PreSave()
+	optionId1 := poll.Options[0].Id
+	optionId2 := poll.Options[1].Id
+
+	post := &model.Post{ChannelId: model.NewId(), UserId: userId1, Message: "question", Type: model.POST_POLL}
+	post.AddProp(model.POST_PROPS_POLL, poll)
+	postId := Must(store.Post().Save(post)).(*model.Post).Id
+
+	if result := <-store.Poll().SetVotes(postId, userId1, []string{optionId1, optionId2}, model.GetMillis()); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if rpoll := model.PollFromPost(result.Data.(*model.Post)); rpoll.VoterCount != 1 || rpoll.Options[0].VoteCount != 1 || rpoll.Options[1].VoteCount != 1 {
+		t.Fatal("should have counted both votes")
+	}
+
+	if rpost := Must(store.Poll().SetVotes(postId, userId2, []string{optionId2}, model.GetMillis())).(*model.Post); model.PollFromPost(rpost).Options[1].VoteCount != 2 {
+		t.Fatal("should have counted every vote in the poll")
+	}
+
+	if rpost := Must(store.Poll().SetVotes(postId, userId1, []string{optionId1}, model.GetMillis())).(*model.Post); model.PollFromPost(rpost).Options[1].VoteCount != 1 {
+		t.Fatal("should have replaced the user's votes")
+	}
+
+	if result := <-store.Poll().SetVotes(postId, userId1, []string{"junk"}, model.GetMillis()); result.Err == nil {
+		t.Fatal("shouldn't have saved an invalid vote")
+	}
+
+	if result := <-store.Post().Get(postId); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if rpoll := model.PollFromPost(result.Data.(*model.PostList).Posts[postId]); rpoll.VoterCount != 2 || rpoll.Options[0].VoteCount != 1 || rpoll.Options[1].VoteCount != 1 {
+		t.Fatal("should have saved the vote counts on the post")
+	}
+
+	if votes := Must(store.Poll().GetVotesForUser(postId, userId1)).([]*model.PollVote); len(votes) != 1 || votes[0].OptionId != optionId1 {
+		t.Fatal("returned the wrong votes for the user")
+	}
+
+	Must(store.Poll().SetVotes(postId, userId2, []string{}, model.GetMillis()))
+
+	if votes := Must(store.Poll().GetVotes(postId)).([]*model.PollVote); len(votes) != 1 || votes[0].UserId != userId1 {
+		t.Fatal("should have taken back the user's vote")
+	}
+
+	closeAt := model.GetMillis()
+	if rpost := Must(store.Poll().Close(postId, closeAt)).(*model.Post); model.PollFromPost(rpost).CloseAt != closeAt {
+		t.Fatal("should have closed the poll")
+	}
+
+	if rpost := Must(store.Poll().Close(postId, closeAt+1000)).(*model.Post); model.PollFromPost(rpost).CloseAt != closeAt {
+		t.Fatal("shouldn't have changed when a closed poll closed")
+	}
+
+	if result := <-store.Poll().SetVotes(postId, userId2, []string{optionId2}, closeAt+1); result.Err == nil {
+		t.Fatal("shouldn't have voted in a closed poll")
+	} else if result.Err.Id != "store.sql_poll.set_votes.closed.app_error" {
+		t.Fatal("returned the wrong error", result.Err.Id)
+	}
+
+	if votes := Must(store.Poll().GetVotes(postId)).([]*model.PollVote); len(votes) != 1 {
+		t.Fatal("shouldn't have saved a vote in a closed poll")
+	}
+
+	Must(store.Poll().PermanentDeleteByUser(userId1))
+
+	if votes := Must(store.Poll().GetVotes(postId)).([]*model.PollVote); len(votes) != 0 {
+		t.Fatal("should have deleted the user's votes")
+	}
+}
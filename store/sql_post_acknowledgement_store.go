@@ -0,0 +1,243 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/mattermost/platform/model"
+)
+
+type SqlPostAcknowledgementStore struct {
+	*SqlStore
+}
+
+func NewSqlPostAcknowledgementStore(sqlStore *SqlStore) PostAcknowledgementStore {
+	s := &SqlPostAcknowledgementStore{sqlStore}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.PostAcknowledgement{}, "PostAcknowledgements").SetKeys(false, "PostId", "UserId")
+		table.ColMap("PostId").SetMaxSize(26)
+		table.ColMap("UserId").SetMaxSize(26)
+
+		pushes := db.AddTableWithName(model.UrgentPostPush{}, "UrgentPostPushes").SetKeys(false, "PostId", "UserId")
+		pushes.ColMap("PostId").SetMaxSize(26)
+		pushes.ColMap("UserId").SetMaxSize(26)
+	}
+
+	return s
+}
+
+func (s SqlPostAcknowledgementStore) CreateIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_post_acknowledgements_user_id", "PostAcknowledgements", "UserId")
+	s.CreateIndexIfNotExists("idx_urgent_post_pushes_next_push_at", "UrgentPostPushes", "NextPushAt")
+}
+
+func (s SqlPostAcknowledgementStore) Save(ack *model.PostAcknowledgement) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		ack.PreSave()
+		if result.Err = ack.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if err := s.GetMaster().Insert(ack); err != nil {
+			result.Err = model.NewAppError("SqlPostAcknowledgementStore.Save", "store.sql_post_acknowledgement.save.app_error", nil, "post_id="+ack.PostId+", user_id="+ack.UserId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = ack
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlPostAcknowledgementStore) Get(postId string, userId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var ack model.PostAcknowledgement
+		if err := s.GetMaster().SelectOne(&ack, "SELECT * FROM PostAcknowledgements WHERE PostId = :PostId AND UserId = :UserId", map[string]interface{}{"PostId": postId, "UserId": userId}); err == sql.ErrNoRows {
+			result.Err = model.NewAppError("SqlPostAcknowledgementStore.Get", "store.sql_post_acknowledgement.get.missing.app_error", nil, "post_id="+postId+", user_id="+userId, http.StatusNotFound)
+		} else if err != nil {
+			result.Err = model.NewAppError("SqlPostAcknowledgementStore.Get", "store.sql_post_acknowledgement.get.app_error", nil, "post_id="+postId+", user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = &ack
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlPostAcknowledgementStore) GetForPost(postId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var acks []*model.PostAcknowledgement
+		if _, err := s.GetReplica().Select(&acks, "SELECT * FROM PostAcknowledgements WHERE PostId = :PostId ORDER BY AcknowledgedAt", map[string]interface{}{"PostId": postId}); err != nil {
+			result.Err = model.NewAppError("SqlPostAcknowledgementStore.GetForPost", "store.sql_post_acknowledgement.get_for_post.app_error", nil, "post_id="+postId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = acks
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlPostAcknowledgementStore) Delete(postId string, userId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := s.GetMaster().Exec("DELETE FROM PostAcknowledgements WHERE PostId = :PostId AND UserId = :UserId", map[string]interface{}{"PostId": postId, "UserId": userId}); err != nil {
+			result.Err = model.NewAppError("SqlPostAcknowledgementStore.Delete", "store.sql_post_acknowledgement.delete.app_error", nil, "post_id="+postId+", user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlPostAcknowledgementStore) SaveUrgentPushes(pushes []*model.UrgentPostPush) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		for _, push := range pushes {
+			if err := s.GetMaster().Insert(push); err != nil {
+				result.Err = model.NewAppError("SqlPostAcknowledgementStore.SaveUrgentPushes", "store.sql_post_acknowledgement.save_urgent_pushes.app_error", nil, "post_id="+push.PostId+", user_id="+push.UserId+", "+err.Error(), http.StatusInternalServerError)
+				break
+			}
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlPostAcknowledgementStore) GetDueUrgentPushes(time int64, limit int) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var pushes []*model.UrgentPostPush
+		if _, err := s.GetMaster().Select(&pushes, "SELECT * FROM UrgentPostPushes WHERE NextPushAt <= :Time ORDER BY NextPushAt LIMIT :Limit", map[string]interface{}{"Time": time, "Limit": limit}); err != nil {
+			result.Err = model.NewAppError("SqlPostAcknowledgementStore.GetDueUrgentPushes", "store.sql_post_acknowledgement.get_due_urgent_pushes.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = pushes
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// ClaimUrgentPush moves a due push notification on to the given time so that no other server will send it. The
+// result's data is true only if this call was the one to claim it.
+func (s SqlPostAcknowledgementStore) ClaimUrgentPush(push *model.UrgentPostPush, nextPushAt int64) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if sqlResult, err := s.GetMaster().Exec("UPDATE UrgentPostPushes SET NextPushAt = :NextPushAt WHERE PostId = :PostId AND UserId = :UserId AND NextPushAt = :DueAt",
+			map[string]interface{}{"NextPushAt": nextPushAt, "PostId": push.PostId, "UserId": push.UserId, "DueAt": push.NextPushAt}); err != nil {
+			result.Err = model.NewAppError("SqlPostAcknowledgementStore.ClaimUrgentPush", "store.sql_post_acknowledgement.claim_urgent_push.app_error", nil, "post_id="+push.PostId+", user_id="+push.UserId+", "+err.Error(), http.StatusInternalServerError)
+		} else if rows, err := sqlResult.RowsAffected(); err != nil {
+			result.Err = model.NewAppError("SqlPostAcknowledgementStore.ClaimUrgentPush", "store.sql_post_acknowledgement.claim_urgent_push.app_error", nil, "post_id="+push.PostId+", user_id="+push.UserId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			if rows == 1 {
+				push.NextPushAt = nextPushAt
+			}
+			result.Data = rows == 1
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlPostAcknowledgementStore) UpdateUrgentPush(push *model.UrgentPostPush) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := s.GetMaster().Update(push); err != nil {
+			result.Err = model.NewAppError("SqlPostAcknowledgementStore.UpdateUrgentPush", "store.sql_post_acknowledgement.update_urgent_push.app_error", nil, "post_id="+push.PostId+", user_id="+push.UserId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = push
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlPostAcknowledgementStore) DeleteUrgentPush(postId string, userId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := s.GetMaster().Exec("DELETE FROM UrgentPostPushes WHERE PostId = :PostId AND UserId = :UserId", map[string]interface{}{"PostId": postId, "UserId": userId}); err != nil {
+			result.Err = model.NewAppError("SqlPostAcknowledgementStore.DeleteUrgentPush", "store.sql_post_acknowledgement.delete_urgent_push.app_error", nil, "post_id="+postId+", user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlPostAcknowledgementStore) PermanentDeleteByUser(userId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := s.GetMaster().Exec("DELETE FROM PostAcknowledgements WHERE UserId = :UserId", map[string]interface{}{"UserId": userId}); err != nil {
+			result.Err = model.NewAppError("SqlPostAcknowledgementStore.PermanentDeleteByUser", "store.sql_post_acknowledgement.permanent_delete_by_user.app_error", nil, "user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+		} else if _, err := s.GetMaster().Exec("DELETE FROM UrgentPostPushes WHERE UserId = :UserId", map[string]interface{}{"UserId": userId}); err != nil {
+			result.Err = model.NewAppError("SqlPostAcknowledgementStore.PermanentDeleteByUser", "store.sql_post_acknowledgement.permanent_delete_by_user.app_error", nil, "user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
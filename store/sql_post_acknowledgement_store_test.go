@@ -0,0 +1,109 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestPostAcknowledgementStore(t *testing.T) {
+	Setup()
+
+	postId := model.NewId()
+	userId1 := model.NewId()
+	userId2 := model.NewId()
+
+	ack1 := Must(store.PostAcknowledgement().Save(&model.PostAcknowledgement{PostId: postId, UserId: userId1})).(*model.PostAcknowledgement)
+	if ack1.AcknowledgedAt == 0 {
+		t.Fatal("should have set the time of the acknowledgement")
+	}
+
+	if result := <-store.PostAcknowledgement().Save(&model.PostAcknowledgement{PostId: postId, UserId: userId1}); result.Err == nil {
+		t.Fatal("shouldn't have acknowledged the post twice")
+	}
+
+	if result := <-store.PostAcknowledgement().Save(&model.PostAcknowledgement{PostId: "junk", UserId: userId1}); result.Err == nil {
+		t.Fatal("shouldn't have saved an invalid acknowledgement")
+	}
+
+	Must(store.PostAcknowledgement().Save(&model.PostAcknowledgement{PostId: postId, UserId: userId2, AcknowledgedAt: ack1.AcknowledgedAt + 1}))
+
+	if ack := Must(store.PostAcknowledgement().Get(postId, userId2)).(*model.PostAcknowledgement); ack.UserId != userId2 {
+		t.Fatal("returned the wrong acknowledgement")
+	}
+
+	if acks := Must(store.PostAcknowledgement().GetForPost(postId)).([]*model.PostAcknowledgement); len(acks) != 2 || acks[0].UserId != userId1 || acks[1].UserId != userId2 {
+		t.Fatal("should have returned both acknowledgements in order")
+	}
+
+	Must(store.PostAcknowledgement().Delete(postId, userId1))
+
+	if result := <-store.PostAcknowledgement().Get(postId, userId1); result.Err == nil {
+		t.Fatal("should have deleted the acknowledgement")
+	}
+
+	Must(store.PostAcknowledgement().PermanentDeleteByUser(userId2))
+
+	if acks := Must(store.PostAcknowledgement().GetForPost(postId)).([]*model.PostAcknowledgement); len(acks) != 0 {
+		t.Fatal("should have deleted the user's acknowledgements")
+	}
+}
+
+func TestPostAcknowledgementStoreUrgentPushes(t *testing.T) {
+	Setup()
+
+	postId := model.NewId()
+	userId1 := model.NewId()
+	userId2 := model.NewId()
+	now := model.GetMillis()
+
+	Must(store.PostAcknowledgement().SaveUrgentPushes([]*model.UrgentPostPush{
+		{PostId: postId, UserId: userId1, PushCount: 1, NextPushAt: now - 1000},
+		{PostId: postId, UserId: userId2, PushCount: 1, NextPushAt: now + 60000},
+	}))
+
+	isDue := func(userId string) bool {
+		for _, push := range Must(store.PostAcknowledgement().GetDueUrgentPushes(now, 1000)).([]*model.UrgentPostPush) {
+			if push.PostId == postId && push.UserId == userId {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if !isDue(userId1) || isDue(userId2) {
+		t.Fatal("should only have returned the push that's due")
+	}
+
+	push := &model.UrgentPostPush{PostId: postId, UserId: userId1, PushCount: 1, NextPushAt: now - 1000}
+	if claimed := Must(store.PostAcknowledgement().ClaimUrgentPush(push, now+60000)).(bool); !claimed || push.NextPushAt != now+60000 {
+		t.Fatal("should have claimed the push")
+	}
+
+	if claimed := Must(store.PostAcknowledgement().ClaimUrgentPush(&model.UrgentPostPush{PostId: postId, UserId: userId1, NextPushAt: now - 1000}, now+60000)).(bool); claimed {
+		t.Fatal("shouldn't have claimed the push twice")
+	}
+
+	if isDue(userId1) {
+		t.Fatal("should have rescheduled the claimed push")
+	}
+
+	Must(store.PostAcknowledgement().UpdateUrgentPush(&model.UrgentPostPush{PostId: postId, UserId: userId1, PushCount: 2, NextPushAt: now + 60000}))
+
+	if isDue(userId1) {
+		t.Fatal("should have rescheduled the push")
+	}
+
+	Must(store.PostAcknowledgement().UpdateUrgentPush(&model.UrgentPostPush{PostId: postId, UserId: userId2, PushCount: 2, NextPushAt: now - 1000}))
+	Must(store.PostAcknowledgement().DeleteUrgentPush(postId, userId2))
+
+	if isDue(userId2) {
+		t.Fatal("should have deleted the push")
+	}
+
+	Must(store.PostAcknowledgement().PermanentDeleteByUser(userId1))
+}
@@ -0,0 +1,168 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/mattermost/platform/model"
+)
+
+type SqlPostReminderStore struct {
+	*SqlStore
+}
+
+func NewSqlPostReminderStore(sqlStore *SqlStore) PostReminderStore {
+	s := &SqlPostReminderStore{sqlStore}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.PostReminder{}, "PostReminders").SetKeys(false, "Id")
+		table.ColMap("Id").SetMaxSize(26)
+		table.ColMap("UserId").SetMaxSize(26)
+		table.ColMap("PostId").SetMaxSize(26)
+	}
+
+	return s
+}
+
+func (s SqlPostReminderStore) CreateIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_post_reminders_user_id", "PostReminders", "UserId")
+	s.CreateIndexIfNotExists("idx_post_reminders_remind_at", "PostReminders", "RemindAt")
+}
+
+func (s SqlPostReminderStore) Save(reminder *model.PostReminder) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if len(reminder.Id) > 0 {
+			result.Err = model.NewAppError("SqlPostReminderStore.Save", "store.sql_post_reminder.save.existing.app_error", nil, "id="+reminder.Id, http.StatusBadRequest)
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		reminder.PreSave()
+		if result.Err = reminder.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if err := s.GetMaster().Insert(reminder); err != nil {
+			result.Err = model.NewAppError("SqlPostReminderStore.Save", "store.sql_post_reminder.save.app_error", nil, "id="+reminder.Id+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = reminder
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlPostReminderStore) Get(id string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var reminder model.PostReminder
+		if err := s.GetReplica().SelectOne(&reminder, "SELECT * FROM PostReminders WHERE Id = :Id", map[string]interface{}{"Id": id}); err == sql.ErrNoRows {
+			result.Err = model.NewAppError("SqlPostReminderStore.Get", "store.sql_post_reminder.get.missing.app_error", nil, "id="+id, http.StatusNotFound)
+		} else if err != nil {
+			result.Err = model.NewAppError("SqlPostReminderStore.Get", "store.sql_post_reminder.get.app_error", nil, "id="+id+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = &reminder
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlPostReminderStore) GetForUser(userId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var reminders []*model.PostReminder
+		if _, err := s.GetReplica().Select(&reminders, "SELECT * FROM PostReminders WHERE UserId = :UserId ORDER BY RemindAt", map[string]interface{}{"UserId": userId}); err != nil {
+			result.Err = model.NewAppError("SqlPostReminderStore.GetForUser", "store.sql_post_reminder.get_for_user.app_error", nil, "user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = reminders
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlPostReminderStore) GetDue(time int64, limit int) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var reminders []*model.PostReminder
+		if _, err := s.GetMaster().Select(&reminders, "SELECT * FROM PostReminders WHERE RemindAt <= :Time ORDER BY RemindAt LIMIT :Limit", map[string]interface{}{"Time": time, "Limit": limit}); err != nil {
+			result.Err = model.NewAppError("SqlPostReminderStore.GetDue", "store.sql_post_reminder.get_due.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = reminders
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// Delete removes a reminder. The result's data is true only if this call was the one to remove it, which lets a
+// server claim a due reminder before sending it.
+func (s SqlPostReminderStore) Delete(id string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if sqlResult, err := s.GetMaster().Exec("DELETE FROM PostReminders WHERE Id = :Id", map[string]interface{}{"Id": id}); err != nil {
+			result.Err = model.NewAppError("SqlPostReminderStore.Delete", "store.sql_post_reminder.delete.app_error", nil, "id="+id+", "+err.Error(), http.StatusInternalServerError)
+		} else if rows, err := sqlResult.RowsAffected(); err != nil {
+			result.Err = model.NewAppError("SqlPostReminderStore.Delete", "store.sql_post_reminder.delete.app_error", nil, "id="+id+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = rows == 1
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlPostReminderStore) PermanentDeleteByUser(userId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := s.GetMaster().Exec("DELETE FROM PostReminders WHERE UserId = :UserId", map[string]interface{}{"UserId": userId}); err != nil {
+			result.Err = model.NewAppError("SqlPostReminderStore.PermanentDeleteByUser", "store.sql_post_reminder.permanent_delete_by_user.app_error", nil, "user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
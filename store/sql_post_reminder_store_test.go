@@ -0,0 +1,61 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestPostReminderStore(t *testing.T) {
+	Setup()
+
+	userId := model.NewId()
+	now := model.GetMillis()
+
+	due := &model.PostReminder{UserId: userId, PostId: model.NewId(), RemindAt: now - 1000}
+	if result := <-store.PostReminder().Save(due); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	later := Must(store.PostReminder().Save(&model.PostReminder{UserId: userId, PostId: model.NewId(), RemindAt: now + 60000})).(*model.PostReminder)
+
+	if result := <-store.PostReminder().Get(due.Id); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if result.Data.(*model.PostReminder).PostId != due.PostId {
+		t.Fatal("returned the wrong reminder")
+	}
+
+	if result := <-store.PostReminder().GetForUser(userId); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if reminders := result.Data.([]*model.PostReminder); len(reminders) != 2 || reminders[0].Id != due.Id || reminders[1].Id != later.Id {
+		t.Fatal("returned the wrong reminders")
+	}
+
+	found := false
+	for _, reminder := range Must(store.PostReminder().GetDue(now, 1000)).([]*model.PostReminder) {
+		if reminder.Id == later.Id {
+			t.Fatal("shouldn't have returned a reminder that isn't due")
+		}
+		found = found || reminder.Id == due.Id
+	}
+	if !found {
+		t.Fatal("should have returned the due reminder")
+	}
+
+	if deleted := Must(store.PostReminder().Delete(due.Id)).(bool); !deleted {
+		t.Fatal("should have deleted the reminder")
+	}
+
+	if deleted := Must(store.PostReminder().Delete(due.Id)).(bool); deleted {
+		t.Fatal("shouldn't have deleted the reminder twice")
+	}
+
+	Must(store.PostReminder().PermanentDeleteByUser(userId))
+
+	if reminders := Must(store.PostReminder().GetForUser(userId)).([]*model.PostReminder); len(reminders) != 0 {
+		t.Fatal("should have deleted the user's reminders")
+	}
+}
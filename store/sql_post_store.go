@@ -52,6 +52,7 @@ func NewSqlPostStore(sqlStore *SqlStore) PostStore {
 		table.ColMap("Props").SetMaxSize(8000)
 		table.ColMap("Filenames").SetMaxSize(4000)
 		table.ColMap("FileIds").SetMaxSize(150)
+		table.ColMap("Participants").SetMaxSize(model.THREAD_PARTICIPANTS_MAX_RUNES)
 	}
 
 	return s
@@ -65,6 +66,7 @@ func (s SqlPostStore) CreateIndexesIfNotExists() {
 	s.CreateIndexIfNotExists("idx_posts_root_id", "Posts", "RootId")
 	s.CreateIndexIfNotExists("idx_posts_user_id", "Posts", "UserId")
 	s.CreateIndexIfNotExists("idx_posts_is_pinned", "Posts", "IsPinned")
+	s.CreateIndexIfNotExists("idx_posts_original_id", "Posts", "OriginalId")
 
 	s.CreateFullTextIndexIfNotExists("idx_posts_message_txt", "Posts", "Message")
 	s.CreateFullTextIndexIfNotExists("idx_posts_hashtags_txt", "Posts", "Hashtags")
@@ -126,6 +128,9 @@ func (s SqlPostStore) Update(newPost *model.Post, oldPost *model.Post) StoreChan
 
 		newPost.UpdateAt = model.GetMillis()
 
+		// only changes to what the post says are kept as revisions, not ones like pinning it
+		keepRevision := newPost.Message != oldPost.Message || strings.Join(newPost.FileIds, ",") != strings.Join(oldPost.FileIds, ",")
+
 		oldPost.DeleteAt = newPost.UpdateAt
 		oldPost.UpdateAt = newPost.UpdateAt
 		oldPost.OriginalId = oldPost.Id
@@ -147,8 +152,10 @@ func (s SqlPostStore) Update(newPost *model.Post, oldPost *model.Post) StoreChan
 				s.GetMaster().Exec("UPDATE Posts SET UpdateAt = :UpdateAt WHERE Id = :RootId", map[string]interface{}{"UpdateAt": time, "RootId": newPost.RootId})
 			}
 
-			// mark the old post as deleted
-			s.GetMaster().Insert(oldPost)
+			// keep the old version of the post as a deleted post that refers to the new one
+			if keepRevision {
+				s.GetMaster().Insert(oldPost)
+			}
 
 			result.Data = newPost
 		}
@@ -187,6 +194,46 @@ func (s SqlPostStore) Overwrite(post *model.Post) StoreChannel {
 	return storeChannel
 }
 
+// UpdateThreadStats recalculates how many replies a root post has, when the latest of them was made and who has taken
+// part in the thread.
+func (s SqlPostStore) UpdateThreadStats(rootId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var stats struct {
+			ReplyCount  int64
+			LastReplyAt int64
+		}
+
+		if err := s.GetMaster().SelectOne(&stats, "SELECT COUNT(*) AS ReplyCount, COALESCE(MAX(CreateAt), 0) AS LastReplyAt FROM Posts WHERE RootId = :RootId AND DeleteAt = 0", map[string]interface{}{"RootId": rootId}); err != nil {
+			result.Err = model.NewLocAppError("SqlPostStore.UpdateThreadStats", "store.sql_post.update_thread_stats.app_error", nil, "root_id="+rootId+", "+err.Error())
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		var participants []string
+		if _, err := s.GetMaster().Select(&participants, "SELECT UserId FROM Posts WHERE (Id = :RootId OR RootId = :RootId) AND DeleteAt = 0 GROUP BY UserId ORDER BY MIN(CreateAt) LIMIT :Limit", map[string]interface{}{"RootId": rootId, "Limit": model.THREAD_PARTICIPANTS_MAX}); err != nil {
+			result.Err = model.NewLocAppError("SqlPostStore.UpdateThreadStats", "store.sql_post.update_thread_stats.app_error", nil, "root_id="+rootId+", "+err.Error())
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if _, err := s.GetMaster().Exec("UPDATE Posts SET ReplyCount = :ReplyCount, LastReplyAt = :LastReplyAt, Participants = :Participants WHERE Id = :RootId",
+			map[string]interface{}{"ReplyCount": stats.ReplyCount, "LastReplyAt": stats.LastReplyAt, "Participants": model.ArrayToJson(participants), "RootId": rootId}); err != nil {
+			result.Err = model.NewLocAppError("SqlPostStore.UpdateThreadStats", "store.sql_post.update_thread_stats.app_error", nil, "root_id="+rootId+", "+err.Error())
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
 func (s SqlPostStore) GetFlaggedPosts(userId string, offset int, limit int) StoreChannel {
 	storeChannel := make(StoreChannel, 1)
 	go func() {
@@ -212,6 +259,43 @@ func (s SqlPostStore) GetFlaggedPosts(userId string, offset int, limit int) Stor
 	return storeChannel
 }
 
+// GetPostsByUser returns the posts made by a user, including deleted ones, oldest first.
+func (s SqlPostStore) GetPostsByUser(userId string, offset int, limit int) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+	go func() {
+		result := StoreResult{}
+
+		var posts []*model.Post
+		if _, err := s.GetReplica().Select(&posts, "SELECT * FROM Posts WHERE UserId = :UserId ORDER BY CreateAt, Id LIMIT :Limit OFFSET :Offset", map[string]interface{}{"UserId": userId, "Offset": offset, "Limit": limit}); err != nil {
+			result.Err = model.NewLocAppError("SqlPostStore.GetPostsByUser", "store.sql_post.get_posts_by_user.app_error", nil, "userId="+userId+", err="+err.Error())
+		} else {
+			result.Data = posts
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// ReassignUser attributes all of a user's posts to another user.
+func (s SqlPostStore) ReassignUser(userId string, newUserId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+	go func() {
+		result := StoreResult{}
+
+		if _, err := s.GetMaster().Exec("UPDATE Posts SET UserId = :NewUserId WHERE UserId = :UserId", map[string]interface{}{"UserId": userId, "NewUserId": newUserId}); err != nil {
+			result.Err = model.NewLocAppError("SqlPostStore.ReassignUser", "store.sql_post.reassign_user.app_error", nil, "userId="+userId+", err="+err.Error())
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
 func (s SqlPostStore) GetFlaggedPostsForTeam(userId, teamId string, offset int, limit int) StoreChannel {
 	storeChannel := make(StoreChannel, 1)
 	go func() {
@@ -346,6 +430,34 @@ func (s SqlPostStore) GetSingle(id string) StoreChannel {
 	return storeChannel
 }
 
+// GetRevisions returns the previous versions of a post, starting with the most recent one. Each of them is deleted
+// at the time that it was replaced.
+func (s SqlPostStore) GetRevisions(postId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var posts []*model.Post
+		if _, err := s.GetReplica().Select(&posts, "SELECT * FROM Posts WHERE OriginalId = :PostId ORDER BY UpdateAt DESC", map[string]interface{}{"PostId": postId}); err != nil {
+			result.Err = model.NewAppError("SqlPostStore.GetRevisions", "store.sql_post.get_revisions.app_error", nil, "post_id="+postId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			list := model.NewPostList()
+			for _, post := range posts {
+				list.AddPost(post)
+				list.AddOrder(post.Id)
+			}
+
+			result.Data = list
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
 type etagPosts struct {
 	Id       string
 	UpdateAt int64
@@ -920,7 +1032,6 @@ func (s SqlPostStore) Search(teamId string, userId string, params *model.SearchP
 						Id = ChannelId
 							AND (TeamId = :TeamId OR TeamId = '')
 							AND UserId = :UserId
-							AND DeleteAt = 0
 							CHANNEL_FILTER)
 				SEARCH_CLAUSE
 				ORDER BY CreateAt DESC
@@ -219,6 +219,40 @@ func TestPostStoreUpdate(t *testing.T) {
 	}
 }
 
+func TestPostStoreGetRevisions(t *testing.T) {
+	Setup()
+
+	post := Must(store.Post().Save(&model.Post{ChannelId: model.NewId(), UserId: model.NewId(), Message: "first"})).(*model.Post)
+
+	update := func(change func(post *model.Post)) *model.Post {
+		oldPost := (<-store.Post().Get(post.Id)).Data.(*model.PostList).Posts[post.Id]
+		newPost := &model.Post{}
+		*newPost = *oldPost
+		change(newPost)
+		return Must(store.Post().Update(newPost, oldPost)).(*model.Post)
+	}
+
+	update(func(post *model.Post) { post.Message = "second" })
+	time.Sleep(2 * time.Millisecond)
+	update(func(post *model.Post) { post.IsPinned = true })
+	time.Sleep(2 * time.Millisecond)
+	update(func(post *model.Post) { post.Message = "third" })
+
+	if result := <-store.Post().GetRevisions(post.Id); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if revisions := result.Data.(*model.PostList); len(revisions.Order) != 2 {
+		t.Fatal("should only have kept revisions for changes to the message", len(revisions.Order))
+	} else if first, second := revisions.Posts[revisions.Order[1]], revisions.Posts[revisions.Order[0]]; first.Message != "first" || second.Message != "second" {
+		t.Fatal("returned the wrong revisions")
+	} else if second.OriginalId != post.Id || second.DeleteAt == 0 {
+		t.Fatal("revisions should refer to the post and be deleted")
+	}
+
+	if revisions := Must(store.Post().GetRevisions(model.NewId())).(*model.PostList); len(revisions.Order) != 0 {
+		t.Fatal("shouldn't have returned any revisions")
+	}
+}
+
 func TestPostStoreDelete(t *testing.T) {
 	Setup()
 
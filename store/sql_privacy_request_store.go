@@ -0,0 +1,193 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/mattermost/platform/model"
+)
+
+type SqlPrivacyRequestStore struct {
+	*SqlStore
+}
+
+func NewSqlPrivacyRequestStore(sqlStore *SqlStore) PrivacyRequestStore {
+	s := &SqlPrivacyRequestStore{sqlStore}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.PrivacyRequest{}, "PrivacyRequests").SetKeys(false, "Id")
+		table.ColMap("Id").SetMaxSize(26)
+		table.ColMap("UserId").SetMaxSize(26)
+		table.ColMap("RequesterId").SetMaxSize(26)
+		table.ColMap("Type").SetMaxSize(32)
+		table.ColMap("Status").SetMaxSize(32)
+		table.ColMap("ErrorCode").SetMaxSize(model.PRIVACY_REQUEST_ERROR_CODE_MAX_LENGTH)
+	}
+
+	return s
+}
+
+func (s SqlPrivacyRequestStore) CreateIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_privacy_requests_user_id", "PrivacyRequests", "UserId")
+	s.CreateIndexIfNotExists("idx_privacy_requests_scheduled_at", "PrivacyRequests", "ScheduledAt")
+}
+
+func (s SqlPrivacyRequestStore) Save(request *model.PrivacyRequest) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if len(request.Id) > 0 {
+			result.Err = model.NewAppError("SqlPrivacyRequestStore.Save", "store.sql_privacy_request.save.existing.app_error", nil, "id="+request.Id, http.StatusBadRequest)
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		request.PreSave()
+		if result.Err = request.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if err := s.GetMaster().Insert(request); err != nil {
+			result.Err = model.NewAppError("SqlPrivacyRequestStore.Save", "store.sql_privacy_request.save.app_error", nil, "id="+request.Id+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = request
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlPrivacyRequestStore) Update(request *model.PrivacyRequest) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		request.PreUpdate()
+		if result.Err = request.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if count, err := s.GetMaster().Update(request); err != nil {
+			result.Err = model.NewAppError("SqlPrivacyRequestStore.Update", "store.sql_privacy_request.update.app_error", nil, "id="+request.Id+", "+err.Error(), http.StatusInternalServerError)
+		} else if count != 1 {
+			result.Err = model.NewAppError("SqlPrivacyRequestStore.Update", "store.sql_privacy_request.get.missing.app_error", nil, "id="+request.Id, http.StatusNotFound)
+		} else {
+			result.Data = request
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlPrivacyRequestStore) Get(id string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var request model.PrivacyRequest
+		if err := s.GetReplica().SelectOne(&request, "SELECT * FROM PrivacyRequests WHERE Id = :Id", map[string]interface{}{"Id": id}); err == sql.ErrNoRows {
+			result.Err = model.NewAppError("SqlPrivacyRequestStore.Get", "store.sql_privacy_request.get.missing.app_error", nil, "id="+id, http.StatusNotFound)
+		} else if err != nil {
+			result.Err = model.NewAppError("SqlPrivacyRequestStore.Get", "store.sql_privacy_request.get.app_error", nil, "id="+id+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = &request
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlPrivacyRequestStore) GetForUser(userId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var requests []*model.PrivacyRequest
+		if _, err := s.GetReplica().Select(&requests, "SELECT * FROM PrivacyRequests WHERE UserId = :UserId ORDER BY CreateAt DESC", map[string]interface{}{"UserId": userId}); err != nil {
+			result.Err = model.NewAppError("SqlPrivacyRequestStore.GetForUser", "store.sql_privacy_request.get_for_user.app_error", nil, "user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = requests
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetDue returns the pending requests that were due to be processed by the given time and haven't been claimed yet.
+func (s SqlPrivacyRequestStore) GetDue(time int64, limit int) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var requests []*model.PrivacyRequest
+		if _, err := s.GetMaster().Select(&requests, "SELECT * FROM PrivacyRequests WHERE ScheduledAt <= :Time AND ProcessedAt < :StaleClaimTime AND Status = :Status ORDER BY ScheduledAt LIMIT :Limit", map[string]interface{}{"Time": time, "StaleClaimTime": staleClaimTime(time), "Status": model.PRIVACY_REQUEST_STATUS_PENDING, "Limit": limit}); err != nil {
+			result.Err = model.NewAppError("SqlPrivacyRequestStore.GetDue", "store.sql_privacy_request.get_due.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = requests
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// Claim marks a pending request as processed so that no other server will process or cancel it. The result's data is
+// true only if this call was the one to claim it. Claims that are older than PRIVACY_REQUEST_CLAIM_TIMEOUT are taken
+// over, since the server that made them must have stopped before it finished the request.
+func (s SqlPrivacyRequestStore) Claim(id string, time int64) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if sqlResult, err := s.GetMaster().Exec("UPDATE PrivacyRequests SET ProcessedAt = :ProcessedAt WHERE Id = :Id AND ProcessedAt < :StaleClaimTime AND Status = :Status", map[string]interface{}{"ProcessedAt": time, "Id": id, "StaleClaimTime": staleClaimTime(time), "Status": model.PRIVACY_REQUEST_STATUS_PENDING}); err != nil {
+			result.Err = model.NewAppError("SqlPrivacyRequestStore.Claim", "store.sql_privacy_request.claim.app_error", nil, "id="+id+", "+err.Error(), http.StatusInternalServerError)
+		} else if rows, err := sqlResult.RowsAffected(); err != nil {
+			result.Err = model.NewAppError("SqlPrivacyRequestStore.Claim", "store.sql_privacy_request.claim.app_error", nil, "id="+id+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = rows == 1
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// Pending requests with ProcessedAt before the returned time are either unclaimed or were abandoned by their server.
+func staleClaimTime(time int64) int64 {
+	if stale := time - model.PRIVACY_REQUEST_CLAIM_TIMEOUT; stale > 0 {
+		return stale
+	}
+
+	return 1
+}
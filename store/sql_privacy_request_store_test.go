@@ -0,0 +1,99 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestPrivacyRequestStore(t *testing.T) {
+	Setup()
+
+	userId := model.NewId()
+	now := model.GetMillis()
+
+	export := &model.PrivacyRequest{UserId: userId, RequesterId: userId, Type: model.PRIVACY_REQUEST_TYPE_EXPORT}
+	if result := <-store.PrivacyRequest().Save(export); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	if result := <-store.PrivacyRequest().Save(export); result.Err == nil {
+		t.Fatal("shouldn't have saved an existing privacy request")
+	}
+
+	deletion := Must(store.PrivacyRequest().Save(&model.PrivacyRequest{UserId: userId, RequesterId: userId, Type: model.PRIVACY_REQUEST_TYPE_DELETE, Anonymize: true, ScheduledAt: now + 60000})).(*model.PrivacyRequest)
+
+	if result := <-store.PrivacyRequest().Get(deletion.Id); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if request := result.Data.(*model.PrivacyRequest); request.Type != model.PRIVACY_REQUEST_TYPE_DELETE || !request.Anonymize {
+		t.Fatal("returned the wrong privacy request")
+	}
+
+	if result := <-store.PrivacyRequest().Get(model.NewId()); result.Err == nil {
+		t.Fatal("shouldn't have found a privacy request")
+	}
+
+	if result := <-store.PrivacyRequest().GetForUser(userId); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if requests := result.Data.([]*model.PrivacyRequest); len(requests) != 2 {
+		t.Fatal("returned the wrong privacy requests")
+	}
+
+	found := false
+	for _, request := range Must(store.PrivacyRequest().GetDue(model.GetMillis(), 1000)).([]*model.PrivacyRequest) {
+		if request.Id == deletion.Id {
+			t.Fatal("shouldn't have returned a deletion that is still cooling off")
+		}
+		found = found || request.Id == export.Id
+	}
+	if !found {
+		t.Fatal("should have returned the due export")
+	}
+
+	if claimed := Must(store.PrivacyRequest().Claim(export.Id, now)).(bool); !claimed {
+		t.Fatal("should have claimed the privacy request")
+	}
+
+	if claimed := Must(store.PrivacyRequest().Claim(export.Id, now)).(bool); claimed {
+		t.Fatal("shouldn't have claimed the privacy request twice")
+	}
+
+	for _, request := range Must(store.PrivacyRequest().GetDue(model.GetMillis(), 1000)).([]*model.PrivacyRequest) {
+		if request.Id == export.Id {
+			t.Fatal("shouldn't have returned a claimed privacy request")
+		}
+	}
+
+	abandoned := Must(store.PrivacyRequest().Save(&model.PrivacyRequest{UserId: userId, RequesterId: userId, Type: model.PRIVACY_REQUEST_TYPE_EXPORT})).(*model.PrivacyRequest)
+	if claimed := Must(store.PrivacyRequest().Claim(abandoned.Id, now-model.PRIVACY_REQUEST_CLAIM_TIMEOUT-1)).(bool); !claimed {
+		t.Fatal("should have claimed the privacy request")
+	}
+
+	found = false
+	for _, request := range Must(store.PrivacyRequest().GetDue(model.GetMillis(), 1000)).([]*model.PrivacyRequest) {
+		found = found || request.Id == abandoned.Id
+	}
+	if !found {
+		t.Fatal("should have returned a privacy request whose claim has gone stale")
+	}
+
+	if claimed := Must(store.PrivacyRequest().Claim(abandoned.Id, now)).(bool); !claimed {
+		t.Fatal("should have taken over a stale claim")
+	}
+
+	if claimed := Must(store.PrivacyRequest().Claim(abandoned.Id, now)).(bool); claimed {
+		t.Fatal("shouldn't have taken over a fresh claim")
+	}
+
+	export.ProcessedAt = now
+	export.Status = model.PRIVACY_REQUEST_STATUS_FAILED
+	export.ErrorCode = "app.privacy_request.export.app_error"
+	if result := <-store.PrivacyRequest().Update(export); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if result := <-store.PrivacyRequest().Get(export.Id); result.Data.(*model.PrivacyRequest).ErrorCode != export.ErrorCode {
+		t.Fatal("should have updated the privacy request")
+	}
+}
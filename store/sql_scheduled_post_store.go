@@ -0,0 +1,217 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/mattermost/platform/model"
+)
+
+type SqlScheduledPostStore struct {
+	*SqlStore
+}
+
+func NewSqlScheduledPostStore(sqlStore *SqlStore) ScheduledPostStore {
+	s := &SqlScheduledPostStore{sqlStore}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.ScheduledPost{}, "ScheduledPosts").SetKeys(false, "Id")
+		table.ColMap("Id").SetMaxSize(26)
+		table.ColMap("UserId").SetMaxSize(26)
+		table.ColMap("ChannelId").SetMaxSize(26)
+		table.ColMap("RootId").SetMaxSize(26)
+		table.ColMap("Message").SetMaxSize(model.POST_MESSAGE_MAX_RUNES)
+		table.ColMap("ErrorCode").SetMaxSize(model.SCHEDULED_POST_ERROR_CODE_MAX_LENGTH)
+	}
+
+	return s
+}
+
+func (s SqlScheduledPostStore) CreateIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_scheduled_posts_user_id", "ScheduledPosts", "UserId")
+	s.CreateIndexIfNotExists("idx_scheduled_posts_scheduled_at", "ScheduledPosts", "ScheduledAt")
+}
+
+func (s SqlScheduledPostStore) Save(scheduledPost *model.ScheduledPost) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if len(scheduledPost.Id) > 0 {
+			result.Err = model.NewAppError("SqlScheduledPostStore.Save", "store.sql_scheduled_post.save.existing.app_error", nil, "id="+scheduledPost.Id, http.StatusBadRequest)
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		scheduledPost.PreSave()
+		if result.Err = scheduledPost.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if err := s.GetMaster().Insert(scheduledPost); err != nil {
+			result.Err = model.NewAppError("SqlScheduledPostStore.Save", "store.sql_scheduled_post.save.app_error", nil, "id="+scheduledPost.Id+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = scheduledPost
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlScheduledPostStore) Update(scheduledPost *model.ScheduledPost) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		scheduledPost.PreUpdate()
+		if result.Err = scheduledPost.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if count, err := s.GetMaster().Update(scheduledPost); err != nil {
+			result.Err = model.NewAppError("SqlScheduledPostStore.Update", "store.sql_scheduled_post.update.app_error", nil, "id="+scheduledPost.Id+", "+err.Error(), http.StatusInternalServerError)
+		} else if count != 1 {
+			result.Err = model.NewAppError("SqlScheduledPostStore.Update", "store.sql_scheduled_post.get.missing.app_error", nil, "id="+scheduledPost.Id, http.StatusNotFound)
+		} else {
+			result.Data = scheduledPost
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlScheduledPostStore) Get(id string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var scheduledPost model.ScheduledPost
+		if err := s.GetReplica().SelectOne(&scheduledPost, "SELECT * FROM ScheduledPosts WHERE Id = :Id", map[string]interface{}{"Id": id}); err == sql.ErrNoRows {
+			result.Err = model.NewAppError("SqlScheduledPostStore.Get", "store.sql_scheduled_post.get.missing.app_error", nil, "id="+id, http.StatusNotFound)
+		} else if err != nil {
+			result.Err = model.NewAppError("SqlScheduledPostStore.Get", "store.sql_scheduled_post.get.app_error", nil, "id="+id+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = &scheduledPost
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlScheduledPostStore) GetForUser(userId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var scheduledPosts []*model.ScheduledPost
+		if _, err := s.GetReplica().Select(&scheduledPosts, "SELECT * FROM ScheduledPosts WHERE UserId = :UserId ORDER BY ScheduledAt", map[string]interface{}{"UserId": userId}); err != nil {
+			result.Err = model.NewAppError("SqlScheduledPostStore.GetForUser", "store.sql_scheduled_post.get_for_user.app_error", nil, "user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = scheduledPosts
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetDue returns the scheduled posts that were due to be posted by the given time and haven't been processed yet.
+func (s SqlScheduledPostStore) GetDue(time int64, limit int) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var scheduledPosts []*model.ScheduledPost
+		if _, err := s.GetMaster().Select(&scheduledPosts, "SELECT * FROM ScheduledPosts WHERE ScheduledAt <= :Time AND ProcessedAt = 0 ORDER BY ScheduledAt LIMIT :Limit", map[string]interface{}{"Time": time, "Limit": limit}); err != nil {
+			result.Err = model.NewAppError("SqlScheduledPostStore.GetDue", "store.sql_scheduled_post.get_due.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = scheduledPosts
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// Claim marks a scheduled post as processed so that no other server will post it. The result's data is true only if
+// this call was the one to claim it.
+func (s SqlScheduledPostStore) Claim(id string, time int64) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if sqlResult, err := s.GetMaster().Exec("UPDATE ScheduledPosts SET ProcessedAt = :ProcessedAt WHERE Id = :Id AND ProcessedAt = 0", map[string]interface{}{"ProcessedAt": time, "Id": id}); err != nil {
+			result.Err = model.NewAppError("SqlScheduledPostStore.Claim", "store.sql_scheduled_post.claim.app_error", nil, "id="+id+", "+err.Error(), http.StatusInternalServerError)
+		} else if rows, err := sqlResult.RowsAffected(); err != nil {
+			result.Err = model.NewAppError("SqlScheduledPostStore.Claim", "store.sql_scheduled_post.claim.app_error", nil, "id="+id+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = rows == 1
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlScheduledPostStore) Delete(id string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := s.GetMaster().Exec("DELETE FROM ScheduledPosts WHERE Id = :Id", map[string]interface{}{"Id": id}); err != nil {
+			result.Err = model.NewAppError("SqlScheduledPostStore.Delete", "store.sql_scheduled_post.delete.app_error", nil, "id="+id+", "+err.Error(), http.StatusInternalServerError)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlScheduledPostStore) PermanentDeleteByUser(userId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := s.GetMaster().Exec("DELETE FROM ScheduledPosts WHERE UserId = :UserId", map[string]interface{}{"UserId": userId}); err != nil {
+			result.Err = model.NewAppError("SqlScheduledPostStore.PermanentDeleteByUser", "store.sql_scheduled_post.permanent_delete_by_user.app_error", nil, "user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
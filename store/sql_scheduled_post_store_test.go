@@ -0,0 +1,89 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestScheduledPostStore(t *testing.T) {
+	Setup()
+
+	userId := model.NewId()
+	now := model.GetMillis()
+
+	due := &model.ScheduledPost{UserId: userId, ChannelId: model.NewId(), Message: "due", ScheduledAt: now - 1000}
+	if result := <-store.ScheduledPost().Save(due); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	if result := <-store.ScheduledPost().Save(due); result.Err == nil {
+		t.Fatal("shouldn't have saved an existing scheduled post")
+	}
+
+	later := Must(store.ScheduledPost().Save(&model.ScheduledPost{UserId: userId, ChannelId: model.NewId(), Message: "later", ScheduledAt: now + 60000})).(*model.ScheduledPost)
+
+	if result := <-store.ScheduledPost().Get(due.Id); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if result.Data.(*model.ScheduledPost).Message != "due" {
+		t.Fatal("returned the wrong scheduled post")
+	}
+
+	if result := <-store.ScheduledPost().Get(model.NewId()); result.Err == nil {
+		t.Fatal("shouldn't have found a scheduled post")
+	}
+
+	if result := <-store.ScheduledPost().GetForUser(userId); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if scheduledPosts := result.Data.([]*model.ScheduledPost); len(scheduledPosts) != 2 || scheduledPosts[0].Id != due.Id || scheduledPosts[1].Id != later.Id {
+		t.Fatal("returned the wrong scheduled posts")
+	}
+
+	found := false
+	for _, scheduledPost := range Must(store.ScheduledPost().GetDue(now, 1000)).([]*model.ScheduledPost) {
+		if scheduledPost.Id == later.Id {
+			t.Fatal("shouldn't have returned a scheduled post that isn't due")
+		}
+		found = found || scheduledPost.Id == due.Id
+	}
+	if !found {
+		t.Fatal("should have returned the due scheduled post")
+	}
+
+	if claimed := Must(store.ScheduledPost().Claim(due.Id, now)).(bool); !claimed {
+		t.Fatal("should have claimed the scheduled post")
+	}
+
+	if claimed := Must(store.ScheduledPost().Claim(due.Id, now)).(bool); claimed {
+		t.Fatal("shouldn't have claimed the scheduled post twice")
+	}
+
+	for _, scheduledPost := range Must(store.ScheduledPost().GetDue(now, 1000)).([]*model.ScheduledPost) {
+		if scheduledPost.Id == due.Id {
+			t.Fatal("shouldn't have returned a claimed scheduled post")
+		}
+	}
+
+	due.ProcessedAt = now
+	due.ErrorCode = "api.scheduled_post.permissions.app_error"
+	if result := <-store.ScheduledPost().Update(due); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if result := <-store.ScheduledPost().Get(due.Id); result.Data.(*model.ScheduledPost).ErrorCode != due.ErrorCode {
+		t.Fatal("should have updated the scheduled post")
+	}
+
+	Must(store.ScheduledPost().Delete(due.Id))
+
+	if result := <-store.ScheduledPost().Get(due.Id); result.Err == nil {
+		t.Fatal("should have deleted the scheduled post")
+	}
+
+	Must(store.ScheduledPost().PermanentDeleteByUser(userId))
+
+	if scheduledPosts := Must(store.ScheduledPost().GetForUser(userId)).([]*model.ScheduledPost); len(scheduledPosts) != 0 {
+		t.Fatal("should have deleted the user's scheduled posts")
+	}
+}
@@ -226,6 +226,26 @@ func (me SqlSessionStore) RemoveAllSessions() StoreChannel {
 	return storeChannel
 }
 
+func (me SqlSessionStore) GetUserIdsWithSessions() StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var userIds []string
+		if _, err := me.GetReplica().Select(&userIds, "SELECT DISTINCT UserId FROM Sessions"); err != nil {
+			result.Err = model.NewLocAppError("SqlSessionStore.GetUserIdsWithSessions", "store.sql_session.get_user_ids_with_sessions.app_error", nil, err.Error())
+		} else {
+			result.Data = userIds
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
 func (me SqlSessionStore) PermanentDeleteSessionsByUser(userId string) StoreChannel {
 	storeChannel := make(StoreChannel, 1)
 
@@ -282,6 +302,25 @@ func (me SqlSessionStore) UpdateLastActivityAt(sessionId string, time int64) Sto
 	return storeChannel
 }
 
+func (me SqlSessionStore) UpdateProps(sessionId string, props model.StringMap) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := me.GetMaster().Exec("UPDATE Sessions SET Props = :Props WHERE Id = :Id", map[string]interface{}{"Props": model.MapToJson(props), "Id": sessionId}); err != nil {
+			result.Err = model.NewLocAppError("SqlSessionStore.UpdateProps", "store.sql_session.update_props.app_error", nil, "sessionId="+sessionId+", "+err.Error())
+		} else {
+			result.Data = sessionId
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
 func (me SqlSessionStore) UpdateRoles(userId, roles string) StoreChannel {
 	storeChannel := make(StoreChannel, 1)
 
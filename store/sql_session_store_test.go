@@ -125,6 +125,31 @@ func TestSessionRemoveAll(t *testing.T) {
 	}
 }
 
+func TestSessionGetUserIdsWithSessions(t *testing.T) {
+	Setup()
+
+	s1 := model.Session{}
+	s1.UserId = model.NewId()
+	Must(store.Session().Save(&s1))
+
+	s2 := model.Session{}
+	s2.UserId = s1.UserId
+	Must(store.Session().Save(&s2))
+
+	userIds := Must(store.Session().GetUserIdsWithSessions()).([]string)
+
+	found := 0
+	for _, userId := range userIds {
+		if userId == s1.UserId {
+			found++
+		}
+	}
+
+	if found != 1 {
+		t.Fatal("should have returned the user once")
+	}
+}
+
 func TestSessionRemoveByUser(t *testing.T) {
 	Setup()
 
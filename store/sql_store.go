@@ -65,28 +65,38 @@ const (
 )
 
 type SqlStore struct {
-	master        *gorp.DbMap
-	replicas      []*gorp.DbMap
-	team          TeamStore
-	channel       ChannelStore
-	post          PostStore
-	user          UserStore
-	audit         AuditStore
-	compliance    ComplianceStore
-	session       SessionStore
-	oauth         OAuthStore
-	system        SystemStore
-	webhook       WebhookStore
-	command       CommandStore
-	preference    PreferenceStore
-	license       LicenseStore
-	recovery      PasswordRecoveryStore
-	emoji         EmojiStore
-	status        StatusStore
-	fileInfo      FileInfoStore
-	reaction      ReactionStore
-	SchemaVersion string
-	rrCounter     int64
+	master              *gorp.DbMap
+	replicas            []*gorp.DbMap
+	team                TeamStore
+	channel             ChannelStore
+	post                PostStore
+	user                UserStore
+	audit               AuditStore
+	compliance          ComplianceStore
+	session             SessionStore
+	oauth               OAuthStore
+	system              SystemStore
+	webhook             WebhookStore
+	command             CommandStore
+	preference          PreferenceStore
+	license             LicenseStore
+	recovery            PasswordRecoveryStore
+	emoji               EmojiStore
+	status              StatusStore
+	fileInfo            FileInfoStore
+	reaction            ReactionStore
+	mentionGroup        MentionGroupStore
+	thread              ThreadStore
+	scheduledPost       ScheduledPostStore
+	postReminder        PostReminderStore
+	poll                PollStore
+	postAcknowledgement PostAcknowledgementStore
+	mfa                 MfaStore
+	loginAttempt        LoginAttemptStore
+	passwordHistory     PasswordHistoryStore
+	privacyRequest      PrivacyRequestStore
+	SchemaVersion       string
+	rrCounter           int64
 }
 
 func initConnection() *SqlStore {
@@ -136,6 +146,16 @@ func NewSqlStore() Store {
 	sqlStore.status = NewSqlStatusStore(sqlStore)
 	sqlStore.fileInfo = NewSqlFileInfoStore(sqlStore)
 	sqlStore.reaction = NewSqlReactionStore(sqlStore)
+	sqlStore.mentionGroup = NewSqlMentionGroupStore(sqlStore)
+	sqlStore.thread = NewSqlThreadStore(sqlStore)
+	sqlStore.scheduledPost = NewSqlScheduledPostStore(sqlStore)
+	sqlStore.postReminder = NewSqlPostReminderStore(sqlStore)
+	sqlStore.poll = NewSqlPollStore(sqlStore)
+	sqlStore.postAcknowledgement = NewSqlPostAcknowledgementStore(sqlStore)
+	sqlStore.mfa = NewSqlMfaStore(sqlStore)
+	sqlStore.loginAttempt = NewSqlLoginAttemptStore(sqlStore)
+	sqlStore.passwordHistory = NewSqlPasswordHistoryStore(sqlStore)
+	sqlStore.privacyRequest = NewSqlPrivacyRequestStore(sqlStore)
 
 	err := sqlStore.master.CreateTablesIfNotExists()
 	if err != nil {
@@ -164,6 +184,16 @@ func NewSqlStore() Store {
 	sqlStore.status.(*SqlStatusStore).CreateIndexesIfNotExists()
 	sqlStore.fileInfo.(*SqlFileInfoStore).CreateIndexesIfNotExists()
 	sqlStore.reaction.(*SqlReactionStore).CreateIndexesIfNotExists()
+	sqlStore.mentionGroup.(*SqlMentionGroupStore).CreateIndexesIfNotExists()
+	sqlStore.thread.(*SqlThreadStore).CreateIndexesIfNotExists()
+	sqlStore.scheduledPost.(*SqlScheduledPostStore).CreateIndexesIfNotExists()
+	sqlStore.postReminder.(*SqlPostReminderStore).CreateIndexesIfNotExists()
+	sqlStore.poll.(*SqlPollStore).CreateIndexesIfNotExists()
+	sqlStore.postAcknowledgement.(*SqlPostAcknowledgementStore).CreateIndexesIfNotExists()
+	sqlStore.mfa.(*SqlMfaStore).CreateIndexesIfNotExists()
+	sqlStore.loginAttempt.(*SqlLoginAttemptStore).CreateIndexesIfNotExists()
+	sqlStore.passwordHistory.(*SqlPasswordHistoryStore).CreateIndexesIfNotExists()
+	sqlStore.privacyRequest.(*SqlPrivacyRequestStore).CreateIndexesIfNotExists()
 
 	sqlStore.preference.(*SqlPreferenceStore).DeleteUnusedFeatures()
 
@@ -687,6 +717,46 @@ func (ss *SqlStore) Reaction() ReactionStore {
 	return ss.reaction
 }
 
+func (ss *SqlStore) MentionGroup() MentionGroupStore {
+	return ss.mentionGroup
+}
+
+func (ss *SqlStore) Thread() ThreadStore {
+	return ss.thread
+}
+
+func (ss *SqlStore) ScheduledPost() ScheduledPostStore {
+	return ss.scheduledPost
+}
+
+func (ss *SqlStore) PostReminder() PostReminderStore {
+	return ss.postReminder
+}
+
+func (ss *SqlStore) Poll() PollStore {
+	return ss.poll
+}
+
+func (ss *SqlStore) PostAcknowledgement() PostAcknowledgementStore {
+	return ss.postAcknowledgement
+}
+
+func (ss *SqlStore) Mfa() MfaStore {
+	return ss.mfa
+}
+
+func (ss *SqlStore) LoginAttempt() LoginAttemptStore {
+	return ss.loginAttempt
+}
+
+func (ss *SqlStore) PasswordHistory() PasswordHistoryStore {
+	return ss.passwordHistory
+}
+
+func (ss *SqlStore) PrivacyRequest() PrivacyRequestStore {
+	return ss.privacyRequest
+}
+
 func (ss *SqlStore) DropAllTables() {
 	ss.master.TruncateTables()
 }
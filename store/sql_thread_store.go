@@ -0,0 +1,227 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	"github.com/mattermost/platform/model"
+)
+
+type SqlThreadStore struct {
+	*SqlStore
+}
+
+func NewSqlThreadStore(sqlStore *SqlStore) ThreadStore {
+	s := &SqlThreadStore{sqlStore}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.ThreadMembership{}, "ThreadMemberships").SetKeys(false, "PostId", "UserId")
+		table.ColMap("PostId").SetMaxSize(26)
+		table.ColMap("UserId").SetMaxSize(26)
+	}
+
+	return s
+}
+
+func (s SqlThreadStore) CreateIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_thread_memberships_user_id", "ThreadMemberships", "UserId")
+}
+
+// SaveMembership creates or updates a user's membership of a thread.
+func (s SqlThreadStore) SaveMembership(membership *model.ThreadMembership) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		membership.PreSave()
+		if result.Err = membership.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if count, err := s.GetMaster().Update(membership); err != nil {
+			result.Err = model.NewAppError("SqlThreadStore.SaveMembership", "store.sql_thread.save_membership.app_error", nil, "post_id="+membership.PostId+", user_id="+membership.UserId+", "+err.Error(), http.StatusInternalServerError)
+		} else if count == 0 {
+			// MySQL doesn't count rows that were matched but left unchanged, so the membership may already exist
+			if err := s.GetMaster().Insert(membership); err != nil && !IsUniqueConstraintError(err.Error(), []string{"PRIMARY", "threadmemberships_pkey"}) {
+				result.Err = model.NewAppError("SqlThreadStore.SaveMembership", "store.sql_thread.save_membership.app_error", nil, "post_id="+membership.PostId+", user_id="+membership.UserId+", "+err.Error(), http.StatusInternalServerError)
+			}
+		}
+
+		if result.Err == nil {
+			result.Data = membership
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// IncrementMentionCount starts a user following a thread if they weren't already and adds a mention to their unread
+// count, creating their membership of the thread if needed.
+func (s SqlThreadStore) IncrementMentionCount(postId string, userId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		// the membership may be created by another mention between the update and the insert, so try the update again
+		for attempt := 0; attempt < 2; attempt++ {
+			if sqlResult, err := s.GetMaster().Exec(
+				`UPDATE
+					ThreadMemberships
+				SET
+					Following = :Following,
+					UnreadMentions = UnreadMentions + 1,
+					LastUpdateAt = :LastUpdateAt
+				WHERE
+					PostId = :PostId
+					AND UserId = :UserId`, map[string]interface{}{"Following": true, "LastUpdateAt": model.GetMillis(), "PostId": postId, "UserId": userId}); err != nil {
+				result.Err = model.NewAppError("SqlThreadStore.IncrementMentionCount", "store.sql_thread.increment_mention_count.app_error", nil, "post_id="+postId+", user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+				break
+			} else if count, _ := sqlResult.RowsAffected(); count > 0 {
+				break
+			}
+
+			membership := &model.ThreadMembership{PostId: postId, UserId: userId, Following: true, UnreadMentions: 1}
+			membership.PreSave()
+			if err := s.GetMaster().Insert(membership); err == nil {
+				break
+			} else if !IsUniqueConstraintError(err.Error(), []string{"PRIMARY", "threadmemberships_pkey"}) || attempt > 0 {
+				result.Err = model.NewAppError("SqlThreadStore.IncrementMentionCount", "store.sql_thread.increment_mention_count.app_error", nil, "post_id="+postId+", user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+				break
+			}
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlThreadStore) GetMembership(postId string, userId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var membership model.ThreadMembership
+		if err := s.GetReplica().SelectOne(&membership, "SELECT * FROM ThreadMemberships WHERE PostId = :PostId AND UserId = :UserId", map[string]interface{}{"PostId": postId, "UserId": userId}); err == sql.ErrNoRows {
+			result.Err = model.NewAppError("SqlThreadStore.GetMembership", "store.sql_thread.get_membership.missing.app_error", nil, "post_id="+postId+", user_id="+userId, http.StatusNotFound)
+		} else if err != nil {
+			result.Err = model.NewAppError("SqlThreadStore.GetMembership", "store.sql_thread.get_membership.app_error", nil, "post_id="+postId+", user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = &membership
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetFollowedThreads returns the threads a user follows in channels they still belong to, along with their unread
+// state, starting with the ones that were replied to most recently.
+func (s SqlThreadStore) GetFollowedThreads(userId string, offset int, limit int) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var threads []*model.Thread
+		if _, err := s.GetReplica().Select(&threads,
+			`SELECT
+				ThreadMemberships.PostId,
+				ThreadMemberships.LastViewedAt,
+				ThreadMemberships.UnreadMentions,
+				(SELECT
+					COUNT(*)
+				FROM
+					Posts Replies
+				WHERE
+					Replies.RootId = ThreadMemberships.PostId
+					AND Replies.CreateAt > ThreadMemberships.LastViewedAt
+					AND Replies.UserId != ThreadMemberships.UserId
+					AND Replies.DeleteAt = 0) AS UnreadReplies
+			FROM
+				ThreadMemberships
+				INNER JOIN Posts ON Posts.Id = ThreadMemberships.PostId
+				INNER JOIN ChannelMembers ON ChannelMembers.ChannelId = Posts.ChannelId AND ChannelMembers.UserId = ThreadMemberships.UserId
+			WHERE
+				ThreadMemberships.UserId = :UserId
+				AND ThreadMemberships.Following = :Following
+				AND Posts.DeleteAt = 0
+			ORDER BY Posts.LastReplyAt DESC, Posts.Id
+			LIMIT :Limit OFFSET :Offset`,
+			map[string]interface{}{"UserId": userId, "Following": true, "Limit": limit, "Offset": offset}); err != nil {
+			result.Err = model.NewAppError("SqlThreadStore.GetFollowedThreads", "store.sql_thread.get_followed_threads.app_error", nil, "user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if len(threads) > 0 {
+			props := make(map[string]interface{})
+			idQuery := ""
+			for index, thread := range threads {
+				if len(idQuery) > 0 {
+					idQuery += ", "
+				}
+
+				props["PostId"+strconv.Itoa(index)] = thread.PostId
+				idQuery += ":PostId" + strconv.Itoa(index)
+			}
+
+			var posts []*model.Post
+			if _, err := s.GetReplica().Select(&posts, "SELECT * FROM Posts WHERE Id IN ("+idQuery+")", props); err != nil {
+				result.Err = model.NewAppError("SqlThreadStore.GetFollowedThreads", "store.sql_thread.get_followed_threads.app_error", nil, "user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+				storeChannel <- result
+				close(storeChannel)
+				return
+			}
+
+			postsById := make(map[string]*model.Post, len(posts))
+			for _, post := range posts {
+				postsById[post.Id] = post
+			}
+
+			for _, thread := range threads {
+				thread.Post = postsById[thread.PostId]
+			}
+		}
+
+		result.Data = threads
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlThreadStore) PermanentDeleteMembershipsByUser(userId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := s.GetMaster().Exec("DELETE FROM ThreadMemberships WHERE UserId = :UserId", map[string]interface{}{"UserId": userId}); err != nil {
+			result.Err = model.NewAppError("SqlThreadStore.PermanentDeleteMembershipsByUser", "store.sql_thread.permanent_delete_memberships_by_user.app_error", nil, "user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestThreadStoreMemberships(t *testing.T) {
+	Setup()
+
+	channel := Must(store.Channel().Save(&model.Channel{
+		TeamId:      model.NewId(),
+		DisplayName: "Name",
+		Name:        "a" + model.NewId() + "b",
+		Type:        model.CHANNEL_OPEN,
+	})).(*model.Channel)
+
+	userId := model.NewId()
+	Must(store.Channel().SaveMember(&model.ChannelMember{ChannelId: channel.Id, UserId: userId, NotifyProps: model.GetDefaultChannelNotifyProps()}))
+
+	root := Must(store.Post().Save(&model.Post{ChannelId: channel.Id, UserId: userId, Message: "root"})).(*model.Post)
+	otherRoot := Must(store.Post().Save(&model.Post{ChannelId: channel.Id, UserId: userId, Message: "other root"})).(*model.Post)
+
+	if result := <-store.Thread().GetMembership(root.Id, userId); result.Err == nil {
+		t.Fatal("shouldn't have found a membership")
+	}
+
+	membership := &model.ThreadMembership{PostId: root.Id, UserId: userId, Following: true}
+	if result := <-store.Thread().SaveMembership(membership); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+	Must(store.Thread().SaveMembership(&model.ThreadMembership{PostId: otherRoot.Id, UserId: userId, Following: false}))
+
+	membership.UnreadMentions = 2
+	if result := <-store.Thread().SaveMembership(membership); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	if result := <-store.Thread().GetMembership(root.Id, userId); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if rmembership := result.Data.(*model.ThreadMembership); !rmembership.Following || rmembership.UnreadMentions != 2 {
+		t.Fatal("saved the wrong membership")
+	}
+
+	Must(store.Thread().IncrementMentionCount(root.Id, userId))
+	if rmembership := Must(store.Thread().GetMembership(root.Id, userId)).(*model.ThreadMembership); rmembership.UnreadMentions != 3 {
+		t.Fatal("should have added a mention to the existing membership")
+	}
+
+	mentionedUserId := model.NewId()
+	Must(store.Thread().IncrementMentionCount(root.Id, mentionedUserId))
+	Must(store.Thread().IncrementMentionCount(root.Id, mentionedUserId))
+
+	if result := <-store.Thread().GetMembership(root.Id, mentionedUserId); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if rmembership := result.Data.(*model.ThreadMembership); !rmembership.Following || rmembership.UnreadMentions != 2 {
+		t.Fatal("should have created the membership and counted both mentions")
+	}
+
+	membership.UnreadMentions = 2
+	Must(store.Thread().SaveMembership(membership))
+
+	otherUserId := model.NewId()
+	Must(store.Post().Save(&model.Post{ChannelId: channel.Id, UserId: otherUserId, RootId: root.Id, ParentId: root.Id, Message: "reply"}))
+	Must(store.Post().Save(&model.Post{ChannelId: channel.Id, UserId: userId, RootId: root.Id, ParentId: root.Id, Message: "own reply"}))
+	Must(store.Post().UpdateThreadStats(root.Id))
+
+	if result := <-store.Thread().GetFollowedThreads(userId, 0, 10); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if threads := result.Data.([]*model.Thread); len(threads) != 1 {
+		t.Fatal("should only have returned the followed thread")
+	} else if threads[0].PostId != root.Id || threads[0].Post == nil || threads[0].Post.ReplyCount != 2 {
+		t.Fatal("returned the wrong thread")
+	} else if threads[0].UnreadReplies != 1 || threads[0].UnreadMentions != 2 {
+		t.Fatal("returned the wrong unread state", threads[0].UnreadReplies, threads[0].UnreadMentions)
+	} else if len(threads[0].Post.Participants) != 2 || threads[0].Post.Participants[0] != userId {
+		t.Fatal("returned the wrong participants")
+	}
+
+	if result := <-store.Thread().GetFollowedThreads(otherUserId, 0, 10); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if len(result.Data.([]*model.Thread)) != 0 {
+		t.Fatal("shouldn't have returned threads the user doesn't follow")
+	}
+
+	if result := <-store.Thread().PermanentDeleteMembershipsByUser(userId); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	if result := <-store.Thread().GetMembership(root.Id, userId); result.Err == nil {
+		t.Fatal("should have deleted the membership")
+	}
+}
@@ -250,6 +250,22 @@ func UpgradeDatabaseToVersion38(sqlStore *SqlStore) {
 	// Add the IsPinned column to posts.
 	sqlStore.CreateColumnIfNotExists("Posts", "IsPinned", "boolean", "boolean", "0")
 
+	// Add the Timezone column to users.
+	sqlStore.CreateColumnIfNotExists("Users", "Timezone", "varchar(256)", "varchar(256)", model.MapToJson(model.DefaultUserTimezone()))
+
+	// Add the PostRole column to channels.
+	sqlStore.CreateColumnIfNotExists("Channels", "PostRole", "varchar(64)", "varchar(64)", "")
+
+	// Add the thread statistics columns to posts.
+	sqlStore.CreateColumnIfNotExists("Posts", "ReplyCount", "bigint", "bigint", "0")
+	sqlStore.CreateColumnIfNotExists("Posts", "LastReplyAt", "bigint", "bigint", "0")
+	sqlStore.CreateColumnIfNotExists("Posts", "Participants", "varchar(3000)", "varchar(3000)", "[]")
+
+	// Add the OAuth scope and PKCE columns.
+	sqlStore.CreateColumnIfNotExists("OAuthAccessData", "Scope", "varchar(128)", "varchar(128)", model.DEFAULT_SCOPE)
+	sqlStore.CreateColumnIfNotExists("OAuthAuthData", "CodeChallenge", "varchar(128)", "varchar(128)", "")
+	sqlStore.CreateColumnIfNotExists("OAuthAuthData", "CodeChallengeMethod", "varchar(32)", "varchar(32)", "")
+
 	// saveSchemaVersion(sqlStore, VERSION_3_8_0)
 	// }
 }
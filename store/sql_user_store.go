@@ -68,6 +68,7 @@ func NewSqlUserStore(sqlStore *SqlStore) UserStore {
 		table.ColMap("Locale").SetMaxSize(5)
 		table.ColMap("MfaSecret").SetMaxSize(128)
 		table.ColMap("Position").SetMaxSize(64)
+		table.ColMap("Timezone").SetMaxSize(256)
 	}
 
 	return us
@@ -193,6 +193,26 @@ func (s SqlWebhookStore) DeleteIncoming(webhookId string, time int64) StoreChann
 	return storeChannel
 }
 
+func (s SqlWebhookStore) RestoreIncomingByChannel(channelId string, deleteAt int64, time int64) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if sqlResult, err := s.GetMaster().Exec("Update IncomingWebhooks SET DeleteAt = 0, UpdateAt = :UpdateAt WHERE ChannelId = :ChannelId AND DeleteAt = :DeleteAt", map[string]interface{}{"UpdateAt": time, "ChannelId": channelId, "DeleteAt": deleteAt}); err != nil {
+			result.Err = model.NewLocAppError("SqlWebhookStore.RestoreIncomingByChannel", "store.sql_webhooks.restore_incoming_by_channel.app_error", nil, "channelId="+channelId+", err="+err.Error())
+		} else {
+			rows, _ := sqlResult.RowsAffected()
+			result.Data = rows
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
 func (s SqlWebhookStore) PermanentDeleteIncomingByUser(userId string) StoreChannel {
 	storeChannel := make(StoreChannel, 1)
 
@@ -424,6 +444,26 @@ func (s SqlWebhookStore) DeleteOutgoing(webhookId string, time int64) StoreChann
 	return storeChannel
 }
 
+func (s SqlWebhookStore) RestoreOutgoingByChannel(channelId string, deleteAt int64, time int64) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if sqlResult, err := s.GetMaster().Exec("Update OutgoingWebhooks SET DeleteAt = 0, UpdateAt = :UpdateAt WHERE ChannelId = :ChannelId AND DeleteAt = :DeleteAt", map[string]interface{}{"UpdateAt": time, "ChannelId": channelId, "DeleteAt": deleteAt}); err != nil {
+			result.Err = model.NewLocAppError("SqlWebhookStore.RestoreOutgoingByChannel", "store.sql_webhooks.restore_outgoing_by_channel.app_error", nil, "channelId="+channelId+", err="+err.Error())
+		} else {
+			rows, _ := sqlResult.RowsAffected()
+			result.Data = rows
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
 func (s SqlWebhookStore) PermanentDeleteOutgoingByUser(userId string) StoreChannel {
 	storeChannel := make(StoreChannel, 1)
 
@@ -165,6 +165,55 @@ func TestWebhookStoreDeleteIncoming(t *testing.T) {
 	}
 }
 
+func TestWebhookStoreRestoreByChannel(t *testing.T) {
+	Setup()
+
+	channelId := model.NewId()
+	deleteAt := model.GetMillis()
+
+	i1 := buildIncomingWebhook()
+	i1.ChannelId = channelId
+	i1 = (<-store.Webhook().SaveIncoming(i1)).Data.(*model.IncomingWebhook)
+	Must(store.Webhook().DeleteIncoming(i1.Id, deleteAt))
+
+	i2 := buildIncomingWebhook()
+	i2.ChannelId = channelId
+	i2 = (<-store.Webhook().SaveIncoming(i2)).Data.(*model.IncomingWebhook)
+	Must(store.Webhook().DeleteIncoming(i2.Id, deleteAt-1000))
+
+	o1 := &model.OutgoingWebhook{}
+	o1.ChannelId = channelId
+	o1.CreatorId = model.NewId()
+	o1.TeamId = model.NewId()
+	o1.CallbackURLs = []string{"http://nowhere.com/"}
+	o1 = (<-store.Webhook().SaveOutgoing(o1)).Data.(*model.OutgoingWebhook)
+	Must(store.Webhook().DeleteOutgoing(o1.Id, deleteAt))
+
+	if r1 := <-store.Webhook().RestoreIncomingByChannel(channelId, deleteAt, model.GetMillis()); r1.Err != nil {
+		t.Fatal(r1.Err)
+	} else if r1.Data.(int64) != 1 {
+		t.Fatal("should have restored one incoming webhook")
+	}
+
+	if r2 := <-store.Webhook().RestoreOutgoingByChannel(channelId, deleteAt, model.GetMillis()); r2.Err != nil {
+		t.Fatal(r2.Err)
+	}
+
+	ClearWebhookCaches()
+
+	if r3 := <-store.Webhook().GetIncoming(i1.Id, true); r3.Err != nil {
+		t.Fatal("webhook deleted with the channel should have been restored", r3.Err)
+	}
+
+	if r4 := <-store.Webhook().GetIncoming(i2.Id, true); r4.Err == nil {
+		t.Fatal("webhook deleted separately shouldn't have been restored")
+	}
+
+	if r5 := <-store.Webhook().GetOutgoing(o1.Id); r5.Err != nil {
+		t.Fatal("outgoing webhook should have been restored", r5.Err)
+	}
+}
+
 func TestWebhookStoreDeleteIncomingByUser(t *testing.T) {
 	Setup()
 	o1 := buildIncomingWebhook()
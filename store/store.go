@@ -47,6 +47,16 @@ type Store interface {
 	Status() StatusStore
 	FileInfo() FileInfoStore
 	Reaction() ReactionStore
+	MentionGroup() MentionGroupStore
+	Thread() ThreadStore
+	ScheduledPost() ScheduledPostStore
+	PostReminder() PostReminderStore
+	Poll() PollStore
+	PostAcknowledgement() PostAcknowledgementStore
+	Mfa() MfaStore
+	LoginAttempt() LoginAttemptStore
+	PasswordHistory() PasswordHistoryStore
+	PrivacyRequest() PrivacyRequestStore
 	MarkSystemRanUnitTests()
 	Close()
 	DropAllTables()
@@ -102,6 +112,7 @@ type ChannelStore interface {
 	GetByName(team_id string, name string, allowFromCache bool) StoreChannel
 	GetByNameIncludeDeleted(team_id string, name string, allowFromCache bool) StoreChannel
 	GetDeletedByName(team_id string, name string) StoreChannel
+	GetDeleted(teamId string, userId string, offset int, limit int) StoreChannel
 	GetChannels(teamId string, userId string) StoreChannel
 	GetMoreChannels(teamId string, userId string, offset int, limit int) StoreChannel
 	GetPublicChannelsForTeam(teamId string, offset int, limit int) StoreChannel
@@ -135,6 +146,7 @@ type ChannelStore interface {
 	SearchInTeam(teamId string, term string) StoreChannel
 	SearchMore(userId string, teamId string, term string) StoreChannel
 	GetMembersByIds(channelId string, userIds []string) StoreChannel
+	GetUsersSharingChannels(userId string, otherUserIds []string) StoreChannel
 	AnalyticsDeletedTypeCount(teamId string, channelType string) StoreChannel
 	GetChannelUnread(channelId, userId string) StoreChannel
 }
@@ -149,6 +161,8 @@ type PostStore interface {
 	PermanentDeleteByChannel(channelId string) StoreChannel
 	GetPosts(channelId string, offset int, limit int, allowFromCache bool) StoreChannel
 	GetFlaggedPosts(userId string, offset int, limit int) StoreChannel
+	GetPostsByUser(userId string, offset int, limit int) StoreChannel
+	ReassignUser(userId string, newUserId string) StoreChannel
 	GetFlaggedPostsForTeam(userId, teamId string, offset int, limit int) StoreChannel
 	GetPostsBefore(channelId string, postId string, numPosts int, offset int) StoreChannel
 	GetPostsAfter(channelId string, postId string, numPosts int, offset int) StoreChannel
@@ -161,6 +175,8 @@ type PostStore interface {
 	InvalidateLastPostTimeCache(channelId string)
 	GetPostsCreatedAt(channelId string, time int64) StoreChannel
 	Overwrite(post *model.Post) StoreChannel
+	UpdateThreadStats(rootId string) StoreChannel
+	GetRevisions(postId string) StoreChannel
 }
 
 type UserStore interface {
@@ -220,8 +236,10 @@ type SessionStore interface {
 	GetSessionsWithActiveDeviceIds(userId string) StoreChannel
 	Remove(sessionIdOrToken string) StoreChannel
 	RemoveAllSessions() StoreChannel
+	GetUserIdsWithSessions() StoreChannel
 	PermanentDeleteSessionsByUser(teamId string) StoreChannel
 	UpdateLastActivityAt(sessionId string, time int64) StoreChannel
+	UpdateProps(sessionId string, props model.StringMap) StoreChannel
 	UpdateRoles(userId string, roles string) StoreChannel
 	UpdateDeviceId(id string, deviceId string, expiresAt int64) StoreChannel
 	AnalyticsSessionCount() StoreChannel
@@ -278,6 +296,7 @@ type WebhookStore interface {
 	UpdateIncoming(webhook *model.IncomingWebhook) StoreChannel
 	GetIncomingByChannel(channelId string) StoreChannel
 	DeleteIncoming(webhookId string, time int64) StoreChannel
+	RestoreIncomingByChannel(channelId string, deleteAt int64, time int64) StoreChannel
 	PermanentDeleteIncomingByUser(userId string) StoreChannel
 
 	SaveOutgoing(webhook *model.OutgoingWebhook) StoreChannel
@@ -286,6 +305,7 @@ type WebhookStore interface {
 	GetOutgoingByChannel(channelId string, offset, limit int) StoreChannel
 	GetOutgoingByTeam(teamId string, offset, limit int) StoreChannel
 	DeleteOutgoing(webhookId string, time int64) StoreChannel
+	RestoreOutgoingByChannel(channelId string, deleteAt int64, time int64) StoreChannel
 	PermanentDeleteOutgoingByUser(userId string) StoreChannel
 	UpdateOutgoing(hook *model.OutgoingWebhook) StoreChannel
 
@@ -356,6 +376,9 @@ type FileInfoStore interface {
 	InvalidateFileInfosForPostCache(postId string)
 	AttachToPost(fileId string, postId string) StoreChannel
 	DeleteForPost(postId string) StoreChannel
+	GetForUser(userId string, includeDeleted bool) StoreChannel
+	ReassignCreator(userId string, newUserId string) StoreChannel
+	PermanentDeleteByUser(userId string) StoreChannel
 }
 
 type ReactionStore interface {
@@ -366,3 +389,97 @@ type ReactionStore interface {
 	GetForPost(postId string, allowFromCache bool) StoreChannel
 	DeleteAllWithEmojiName(emojiName string) StoreChannel
 }
+
+type ThreadStore interface {
+	SaveMembership(membership *model.ThreadMembership) StoreChannel
+	GetMembership(postId string, userId string) StoreChannel
+	IncrementMentionCount(postId string, userId string) StoreChannel
+	GetFollowedThreads(userId string, offset int, limit int) StoreChannel
+	PermanentDeleteMembershipsByUser(userId string) StoreChannel
+}
+
+type ScheduledPostStore interface {
+	Save(scheduledPost *model.ScheduledPost) StoreChannel
+	Update(scheduledPost *model.ScheduledPost) StoreChannel
+	Get(id string) StoreChannel
+	GetForUser(userId string) StoreChannel
+	GetDue(time int64, limit int) StoreChannel
+	Claim(id string, time int64) StoreChannel
+	Delete(id string) StoreChannel
+	PermanentDeleteByUser(userId string) StoreChannel
+}
+
+type PrivacyRequestStore interface {
+	Save(request *model.PrivacyRequest) StoreChannel
+	Update(request *model.PrivacyRequest) StoreChannel
+	Get(id string) StoreChannel
+	GetForUser(userId string) StoreChannel
+	GetDue(time int64, limit int) StoreChannel
+	Claim(id string, time int64) StoreChannel
+}
+
+type PostReminderStore interface {
+	Save(reminder *model.PostReminder) StoreChannel
+	Get(id string) StoreChannel
+	GetForUser(userId string) StoreChannel
+	GetDue(time int64, limit int) StoreChannel
+	Delete(id string) StoreChannel
+	PermanentDeleteByUser(userId string) StoreChannel
+}
+
+type PollStore interface {
+	SetVotes(postId string, userId string, optionIds []string, time int64) StoreChannel
+	Close(postId string, time int64) StoreChannel
+	GetVotes(postId string) StoreChannel
+	GetVotesForUser(postId string, userId string) StoreChannel
+	PermanentDeleteByUser(userId string) StoreChannel
+}
+
+type PostAcknowledgementStore interface {
+	Save(ack *model.PostAcknowledgement) StoreChannel
+	Get(postId string, userId string) StoreChannel
+	GetForPost(postId string) StoreChannel
+	Delete(postId string, userId string) StoreChannel
+	SaveUrgentPushes(pushes []*model.UrgentPostPush) StoreChannel
+	GetDueUrgentPushes(time int64, limit int) StoreChannel
+	ClaimUrgentPush(push *model.UrgentPostPush, nextPushAt int64) StoreChannel
+	UpdateUrgentPush(push *model.UrgentPostPush) StoreChannel
+	DeleteUrgentPush(postId string, userId string) StoreChannel
+	PermanentDeleteByUser(userId string) StoreChannel
+}
+
+type MfaStore interface {
+	SaveRecoveryCodes(userId string, codeHashes []string) StoreChannel
+	UseRecoveryCode(userId string, codeHash string) StoreChannel
+	GetRecoveryCodeCount(userId string) StoreChannel
+	SaveUsedToken(userId string, token string, usedAt int64, since int64) StoreChannel
+	PermanentDeleteByUser(userId string) StoreChannel
+}
+
+type LoginAttemptStore interface {
+	RecordFailure(id string, failedAt int64, resetBefore int64) StoreChannel
+	Get(id string) StoreChannel
+	Delete(id string) StoreChannel
+	DeleteOlderThan(lastFailedAt int64) StoreChannel
+}
+
+type PasswordHistoryStore interface {
+	Save(history *model.PasswordHistory, keep int) StoreChannel
+	GetForUser(userId string, limit int) StoreChannel
+	PermanentDeleteByUser(userId string) StoreChannel
+}
+
+type MentionGroupStore interface {
+	Save(group *model.MentionGroup) StoreChannel
+	Update(group *model.MentionGroup) StoreChannel
+	Get(id string) StoreChannel
+	GetByName(teamId string, name string) StoreChannel
+	GetForTeam(teamId string) StoreChannel
+	Search(teamId string, term string, limit int) StoreChannel
+	Delete(id string, time int64) StoreChannel
+	SaveMember(member *model.MentionGroupMember) StoreChannel
+	RemoveMember(groupId string, userId string) StoreChannel
+	GetMembers(groupId string, offset int, limit int) StoreChannel
+	GetMembersForTeam(teamId string) StoreChannel
+	PermanentDeleteMembersByUser(userId string) StoreChannel
+}
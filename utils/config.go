@@ -212,7 +212,8 @@ func LoadConfig(fileName string) {
 	CfgFileName = viper.ConfigFileUsed()
 
 	needSave := len(config.SqlSettings.AtRestEncryptKey) == 0 || len(*config.FileSettings.PublicLinkSalt) == 0 ||
-		len(config.EmailSettings.InviteSalt) == 0 || len(config.EmailSettings.PasswordResetSalt) == 0
+		len(config.EmailSettings.InviteSalt) == 0 || len(config.EmailSettings.PasswordResetSalt) == 0 ||
+		config.EmailSettings.EmailReplySalt == nil || len(*config.EmailSettings.EmailReplySalt) == 0
 
 	config.SetDefaults()
 
@@ -315,6 +316,7 @@ func getClientConfig(c *model.Config) map[string]string {
 	props["EnableEmailBatching"] = strconv.FormatBool(*c.EmailSettings.EnableEmailBatching)
 
 	props["EnableSignUpWithGitLab"] = strconv.FormatBool(c.GitLabSettings.Enable)
+	props["EnableSignUpWithOpenId"] = strconv.FormatBool(*c.OpenIdSettings.Enable)
 
 	props["ShowEmailAddress"] = strconv.FormatBool(c.PrivacySettings.ShowEmailAddress)
 
@@ -353,6 +355,9 @@ func getClientConfig(c *model.Config) map[string]string {
 	props["DiagnosticId"] = CfgDiagnosticId
 	props["DiagnosticsEnabled"] = strconv.FormatBool(*c.LogSettings.EnableDiagnostics)
 
+	props["EnableMultifactorAuthentication"] = strconv.FormatBool(*c.ServiceSettings.EnableMultifactorAuthentication)
+	props["EnforceMultifactorAuthentication"] = strconv.FormatBool(*c.ServiceSettings.EnforceMultifactorAuthentication)
+
 	if IsLicensed {
 		if *License.Features.CustomBrand {
 			props["EnableCustomBrand"] = strconv.FormatBool(*c.TeamSettings.EnableCustomBrand)
@@ -368,11 +373,6 @@ func getClientConfig(c *model.Config) map[string]string {
 			props["LastNameAttributeSet"] = strconv.FormatBool(*c.LdapSettings.LastNameAttribute != "")
 		}
 
-		if *License.Features.MFA {
-			props["EnableMultifactorAuthentication"] = strconv.FormatBool(*c.ServiceSettings.EnableMultifactorAuthentication)
-			props["EnforceMultifactorAuthentication"] = strconv.FormatBool(*c.ServiceSettings.EnforceMultifactorAuthentication)
-		}
-
 		if *License.Features.Compliance {
 			props["EnableCompliance"] = strconv.FormatBool(*c.ComplianceSettings.Enable)
 		}
@@ -457,6 +457,12 @@ func Desanitize(cfg *model.Config) {
 	if cfg.FileSettings.AmazonS3SecretAccessKey == model.FAKE_SETTING {
 		cfg.FileSettings.AmazonS3SecretAccessKey = Cfg.FileSettings.AmazonS3SecretAccessKey
 	}
+	if cfg.FileSettings.EncryptionKey != nil && *cfg.FileSettings.EncryptionKey == model.FAKE_SETTING {
+		*cfg.FileSettings.EncryptionKey = *Cfg.FileSettings.EncryptionKey
+	}
+	if cfg.FileSettings.PreviousEncryptionKey != nil && *cfg.FileSettings.PreviousEncryptionKey == model.FAKE_SETTING {
+		*cfg.FileSettings.PreviousEncryptionKey = *Cfg.FileSettings.PreviousEncryptionKey
+	}
 
 	if cfg.EmailSettings.InviteSalt == model.FAKE_SETTING {
 		cfg.EmailSettings.InviteSalt = Cfg.EmailSettings.InviteSalt
@@ -464,6 +470,9 @@ func Desanitize(cfg *model.Config) {
 	if cfg.EmailSettings.PasswordResetSalt == model.FAKE_SETTING {
 		cfg.EmailSettings.PasswordResetSalt = Cfg.EmailSettings.PasswordResetSalt
 	}
+	if cfg.EmailSettings.EmailReplySalt != nil && *cfg.EmailSettings.EmailReplySalt == model.FAKE_SETTING {
+		*cfg.EmailSettings.EmailReplySalt = *Cfg.EmailSettings.EmailReplySalt
+	}
 	if cfg.EmailSettings.SMTPPassword == model.FAKE_SETTING {
 		cfg.EmailSettings.SMTPPassword = Cfg.EmailSettings.SMTPPassword
 	}
@@ -472,6 +481,10 @@ func Desanitize(cfg *model.Config) {
 		cfg.GitLabSettings.Secret = Cfg.GitLabSettings.Secret
 	}
 
+	if cfg.OpenIdSettings.Secret != nil && *cfg.OpenIdSettings.Secret == model.FAKE_SETTING {
+		*cfg.OpenIdSettings.Secret = *Cfg.OpenIdSettings.Secret
+	}
+
 	if cfg.SqlSettings.DataSource == model.FAKE_SETTING {
 		cfg.SqlSettings.DataSource = Cfg.SqlSettings.DataSource
 	}
@@ -4,8 +4,13 @@
 package utils
 
 import (
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/mattermost/platform/model"
 )
 
 func TestConfig(t *testing.T) {
@@ -59,3 +64,49 @@ func TestConfigFromEnviroVars(t *testing.T) {
 	}
 
 }
+
+func TestDesanitizeEmailReplySalt(t *testing.T) {
+	TranslationsPreInit()
+	LoadConfig("config.json")
+	defer LoadConfig("config.json")
+
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	fileName := filepath.Join(dir, "reply_salt_config.json")
+
+	// a missing salt is generated once and saved so that it survives restarts
+	cfg := model.ConfigFromJson(strings.NewReader(Cfg.ToJson()))
+	*cfg.EmailSettings.EmailReplySalt = ""
+	if err := SaveConfig(fileName, cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	LoadConfig(fileName)
+	salt := *Cfg.EmailSettings.EmailReplySalt
+	if len(salt) == 0 {
+		t.Fatal("should have generated a salt")
+	}
+
+	if data, err := ioutil.ReadFile(fileName); err != nil {
+		t.Fatal(err)
+	} else if saved := model.ConfigFromJson(strings.NewReader(string(data))); *saved.EmailSettings.EmailReplySalt != salt {
+		t.Fatal("should have saved the generated salt")
+	}
+
+	// saving a config returned by the system console shouldn't replace the salt
+	cfg = model.ConfigFromJson(strings.NewReader(Cfg.ToJson()))
+	cfg.Sanitize()
+	Desanitize(cfg)
+	if err := SaveConfig(fileName, cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	LoadConfig(fileName)
+	if *Cfg.EmailSettings.EmailReplySalt != salt {
+		t.Fatal("salt should have survived saving a sanitized config")
+	}
+}
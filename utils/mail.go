@@ -97,7 +97,16 @@ func SendMail(to, subject, body string) *model.AppError {
 	return SendMailUsingConfig(to, subject, body, Cfg)
 }
 
+// SendMailWithReplyTo sends an email whose replies go to the given address rather than the sender.
+func SendMailWithReplyTo(to, replyTo, subject, body string) *model.AppError {
+	return sendMailUsingConfig(to, replyTo, subject, body, Cfg)
+}
+
 func SendMailUsingConfig(to, subject, body string, config *model.Config) *model.AppError {
+	return sendMailUsingConfig(to, "", subject, body, config)
+}
+
+func sendMailUsingConfig(to, replyTo, subject, body string, config *model.Config) *model.AppError {
 	if !config.EmailSettings.SendEmailNotifications || len(config.EmailSettings.SMTPServer) == 0 {
 		return nil
 	}
@@ -110,6 +119,10 @@ func SendMailUsingConfig(to, subject, body string, config *model.Config) *model.
 	headers := make(map[string]string)
 	headers["From"] = fromMail.String()
 	headers["To"] = toMail.String()
+	if len(replyTo) > 0 {
+		replyToMail := mail.Address{Name: "", Address: replyTo}
+		headers["Reply-To"] = replyToMail.String()
+	}
 	headers["Subject"] = encodeRFC2047Word(subject)
 	headers["MIME-version"] = "1.0"
 	headers["Content-Type"] = "text/html; charset=\"utf-8\""
@@ -4,12 +4,25 @@
 package utils
 
 import (
+	"bufio"
 	"net/http"
+	"os"
 	"strings"
+	"sync"
+	"time"
+
+	l4g "github.com/alecthomas/log4go"
 
 	"github.com/mattermost/platform/model"
 )
 
+var passwordBlocklist = struct {
+	sync.Mutex
+	path      string
+	modTime   time.Time
+	passwords map[string]bool
+}{}
+
 func IsPasswordValid(password string) *model.AppError {
 	id := "model.user.is_valid.pwd"
 	isError := false
@@ -62,5 +75,63 @@ func IsPasswordValid(password string) *model.AppError {
 		return model.NewAppError("User.IsValid", id+".app_error", map[string]interface{}{"Min": min}, "", http.StatusBadRequest)
 	}
 
+	if IsPasswordBlocklisted(password) {
+		return model.NewAppError("User.IsValid", "model.user.is_valid.pwd_blocklisted.app_error", nil, "", http.StatusBadRequest)
+	}
+
 	return nil
 }
+
+// IsPasswordBlocklisted returns true if the password, ignoring case, is listed in PasswordSettings.BlocklistFile.
+func IsPasswordBlocklisted(password string) bool {
+	if *Cfg.PasswordSettings.BlocklistFile == "" {
+		return false
+	}
+
+	passwords := getPasswordBlocklist(FindConfigFile(*Cfg.PasswordSettings.BlocklistFile))
+
+	return passwords[strings.ToLower(password)]
+}
+
+// getPasswordBlocklist returns the passwords listed one per line in the file, only reading it again when it changes.
+func getPasswordBlocklist(path string) map[string]bool {
+	passwordBlocklist.Lock()
+	defer passwordBlocklist.Unlock()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		l4g.Error(T("utils.password.blocklist.error"), path, err.Error())
+		return nil
+	}
+
+	if path == passwordBlocklist.path && info.ModTime().Equal(passwordBlocklist.modTime) {
+		return passwordBlocklist.passwords
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		l4g.Error(T("utils.password.blocklist.error"), path, err.Error())
+		return nil
+	}
+	defer file.Close()
+
+	passwords := make(map[string]bool)
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			passwords[strings.ToLower(line)] = true
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		l4g.Error(T("utils.password.blocklist.error"), path, err.Error())
+		return nil
+	}
+
+	passwordBlocklist.path = path
+	passwordBlocklist.modTime = info.ModTime()
+	passwordBlocklist.passwords = passwords
+
+	return passwords
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package utils
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestIsPasswordBlocklisted(t *testing.T) {
+	TranslationsPreInit()
+	LoadConfig("config.json")
+
+	blocklistFile := *Cfg.PasswordSettings.BlocklistFile
+	defer func() {
+		*Cfg.PasswordSettings.BlocklistFile = blocklistFile
+	}()
+
+	file, err := ioutil.TempFile("", "blocklist")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	file.WriteString("password\n  Summer2017 \n\n")
+	file.Close()
+
+	*Cfg.PasswordSettings.BlocklistFile = ""
+	if IsPasswordBlocklisted("password") {
+		t.Fatal("shouldn't block passwords without a blocklist")
+	}
+
+	*Cfg.PasswordSettings.BlocklistFile = file.Name()
+	if !IsPasswordBlocklisted("password") || !IsPasswordBlocklisted("PassWord") || !IsPasswordBlocklisted("summer2017") {
+		t.Fatal("should have blocked the listed passwords")
+	}
+
+	if IsPasswordBlocklisted("correcthorsebatterystaple") || IsPasswordBlocklisted("") {
+		t.Fatal("shouldn't have blocked an unlisted password")
+	}
+
+	if err := IsPasswordValid("password"); err == nil || err.Id != "model.user.is_valid.pwd_blocklisted.app_error" {
+		t.Fatal("should have rejected the blocklisted password", err)
+	}
+
+	ioutil.WriteFile(file.Name(), []byte("letmein\n"), 0600)
+	later := time.Now().Add(time.Minute)
+	os.Chtimes(file.Name(), later, later)
+
+	if IsPasswordBlocklisted("password") || !IsPasswordBlocklisted("letmein") {
+		t.Fatal("should have reloaded the changed blocklist")
+	}
+
+	*Cfg.PasswordSettings.BlocklistFile = file.Name() + ".missing"
+	if IsPasswordBlocklisted("letmein") {
+		t.Fatal("shouldn't block passwords when the blocklist is missing")
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"net"
 	"net/http"
 	"os"
+	"strings"
 
 	"github.com/mattermost/platform/model"
 )
@@ -28,6 +29,16 @@ func StringArrayIntersection(arr1, arr2 []string) []string {
 	return result
 }
 
+func StringInSlice(a string, slice []string) bool {
+	for _, b := range slice {
+		if b == a {
+			return true
+		}
+	}
+
+	return false
+}
+
 func FileExistsInConfigFolder(filename string) bool {
 	if len(filename) == 0 {
 		return false
@@ -53,6 +64,61 @@ func RemoveDuplicatesFromStringArray(arr []string) []string {
 	return result
 }
 
+// GetClientIpAddress returns the IP address of the client that made the request. The X-Forwarded-For and X-Real-IP
+// headers are only used when the request came from one of the trusted proxies, which are given as a comma separated
+// list of IP addresses and CIDR ranges.
+func GetClientIpAddress(r *http.Request, trustedProxies string) string {
+	address, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		address = r.RemoteAddr
+	}
+
+	if !isTrustedProxy(address, trustedProxies) {
+		return address
+	}
+
+	if forwarded := r.Header.Get(model.HEADER_FORWARDED); len(forwarded) > 0 {
+		// walk back through the proxies that the request was forwarded by until reaching one that isn't trusted
+		hops := strings.Split(forwarded, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			if hop := strings.TrimSpace(hops[i]); len(hop) > 0 {
+				address = hop
+				if !isTrustedProxy(hop, trustedProxies) {
+					break
+				}
+			}
+		}
+	} else if realIp := strings.TrimSpace(r.Header.Get(model.HEADER_REAL_IP)); len(realIp) > 0 {
+		address = realIp
+	}
+
+	return address
+}
+
+func isTrustedProxy(address string, trustedProxies string) bool {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return false
+	}
+
+	for _, proxy := range strings.Split(trustedProxies, ",") {
+		proxy = strings.TrimSpace(proxy)
+		if len(proxy) == 0 {
+			continue
+		}
+
+		if _, network, err := net.ParseCIDR(proxy); err == nil {
+			if network.Contains(ip) {
+				return true
+			}
+		} else if proxyIp := net.ParseIP(proxy); proxyIp != nil && proxyIp.Equal(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
 func GetIpAddress(r *http.Request) string {
 	address := r.Header.Get(model.HEADER_FORWARDED)
 
@@ -4,7 +4,10 @@
 package utils
 
 import (
+	"net/http"
 	"testing"
+
+	"github.com/mattermost/platform/model"
 )
 
 func TestStringArrayIntersection(t *testing.T) {
@@ -29,6 +32,21 @@ func TestStringArrayIntersection(t *testing.T) {
 	}
 }
 
+func TestStringInSlice(t *testing.T) {
+	a := []string{
+		"abc",
+		"def",
+	}
+
+	if !StringInSlice("def", a) {
+		t.Fatal("should be in slice")
+	}
+
+	if StringInSlice("ghi", a) || StringInSlice("abc", nil) {
+		t.Fatal("should not be in slice")
+	}
+}
+
 func TestRemoveDuplicatesFromStringArray(t *testing.T) {
 	a := []string{
 		"a",
@@ -44,3 +62,38 @@ func TestRemoveDuplicatesFromStringArray(t *testing.T) {
 		t.Fatal("should be 3")
 	}
 }
+
+func TestGetClientIpAddress(t *testing.T) {
+	request := func(remoteAddr, forwarded, realIp string) *http.Request {
+		r, _ := http.NewRequest("GET", "http://localhost", nil)
+		r.RemoteAddr = remoteAddr
+		if len(forwarded) > 0 {
+			r.Header.Set(model.HEADER_FORWARDED, forwarded)
+		}
+		if len(realIp) > 0 {
+			r.Header.Set(model.HEADER_REAL_IP, realIp)
+		}
+		return r
+	}
+
+	for name, tc := range map[string]struct {
+		Request        *http.Request
+		TrustedProxies string
+		Expected       string
+	}{
+		"no proxies":              {request("10.0.0.1:1234", "", ""), "", "10.0.0.1"},
+		"untrusted forwarded for": {request("10.0.0.1:1234", "192.168.0.1", ""), "", "10.0.0.1"},
+		"untrusted real ip":       {request("10.0.0.1:1234", "", "192.168.0.1"), "10.0.0.2", "10.0.0.1"},
+		"trusted forwarded for":   {request("10.0.0.1:1234", "192.168.0.1", ""), "10.0.0.1", "192.168.0.1"},
+		"trusted real ip":         {request("10.0.0.1:1234", "", "192.168.0.1"), "10.0.0.0/8", "192.168.0.1"},
+		"trusted proxy chain":     {request("10.0.0.1:1234", "1.2.3.4, 192.168.0.1, 10.0.0.5", ""), "10.0.0.0/8", "192.168.0.1"},
+		"spoofed forwarded chain": {request("10.0.0.1:1234", "1.2.3.4, 5.6.7.8", ""), "10.0.0.1", "5.6.7.8"},
+		"trusted without headers": {request("10.0.0.1:1234", "", ""), "10.0.0.1", "10.0.0.1"},
+		"ipv6 remote address":     {request("[::1]:1234", "192.168.0.1", ""), "::1", "192.168.0.1"},
+		"invalid trusted proxies": {request("10.0.0.1:1234", "192.168.0.1", ""), "junk, ,", "10.0.0.1"},
+	} {
+		if actual := GetClientIpAddress(tc.Request, tc.TrustedProxies); actual != tc.Expected {
+			t.Fatalf("%v: expected %v, got %v", name, tc.Expected, actual)
+		}
+	}
+}
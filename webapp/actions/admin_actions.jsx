@@ -233,8 +233,10 @@ export function allowOAuth2(params, success, error) {
     const redirectUri = params.redirect_uri;
     const state = params.state;
     const scope = params.scope;
+    const codeChallenge = params.code_challenge;
+    const codeChallengeMethod = params.code_challenge_method;
 
-    Client.allowOAuth2(responseType, clientId, redirectUri, state, scope,
+    Client.allowOAuth2(responseType, clientId, redirectUri, state, scope, codeChallenge, codeChallengeMethod,
         (data) => {
             if (success) {
                 success(data);
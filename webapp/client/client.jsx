@@ -1927,7 +1927,7 @@ export default class Client {
         this.trackEvent('api', 'api_apps_register');
     }
 
-    allowOAuth2(responseType, clientId, redirectUri, state, scope, success, error) {
+    allowOAuth2(responseType, clientId, redirectUri, state, scope, codeChallenge, codeChallengeMethod, success, error) {
         request.
             get(`${this.getOAuthRoute()}/allow`).
             set(this.defaultHeaders).
@@ -1938,6 +1938,8 @@ export default class Client {
             query({redirect_uri: redirectUri}).
             query({scope}).
             query({state}).
+            query({code_challenge: codeChallenge || ''}).
+            query({code_challenge_method: codeChallengeMethod || ''}).
             end(this.handleResponse.bind(this, 'allowOAuth2', success, error));
     }
 
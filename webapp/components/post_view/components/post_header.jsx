@@ -6,6 +6,7 @@ import PostInfo from './post_info.jsx';
 import {FormattedMessage} from 'react-intl';
 
 import * as PostUtils from 'utils/post_utils.jsx';
+import * as Utils from 'utils/utils.jsx';
 
 import Constants from 'utils/constants.jsx';
 
@@ -66,6 +67,8 @@ export default class PostHeader extends React.Component {
                     disablePopover={true}
                 />
             );
+        } else if (this.props.user && this.props.user.roles && Utils.isGuest(this.props.user.roles)) {
+            botIndicator = <li className='bot-indicator'>{Constants.GUEST_NAME}</li>;
         }
 
         if (this.props.compactDisplay) {
@@ -144,6 +144,8 @@ export default class SearchResultsItem extends React.Component {
         let botIndicator;
         if (post.props && post.props.from_webhook) {
             botIndicator = <li className='bot-indicator'>{Constants.BOT_NAME}</li>;
+        } else if (this.props.user && this.props.user.roles && Utils.isGuest(this.props.user.roles)) {
+            botIndicator = <li className='bot-indicator'>{Constants.GUEST_NAME}</li>;
         }
 
         const profilePic = (
@@ -37,6 +37,8 @@ describe('Client.OAuth', function() {
                 'http://nowhere.com',
                 'state',
                 'scope',
+                '',
+                '',
                 function() {
                     done.fail(new Error('not enabled'));
                 },
@@ -82,6 +82,10 @@ export function isAdmin(roles) {
     return false;
 }
 
+export function isGuest(roles) {
+    return isInRole(roles, 'system_guest');
+}
+
 export function isSystemAdmin(roles) {
     if (isInRole(roles, 'system_admin')) {
         return true;